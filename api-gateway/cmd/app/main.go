@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -18,6 +20,7 @@ type Config struct {
 	ResponseServiceURL string
 	JWTSecret          string
 	Port               string
+	TrustedProxies     []string // Addresses allowed to report the client address in X-Forwarded-For, e.g. the frontend's nginx
 }
 
 func loadConfig() Config {
@@ -32,6 +35,7 @@ func loadConfig() Config {
 		ResponseServiceURL: getEnv("RESPONSE_SERVICE_URL", "http://localhost:8083"),
 		JWTSecret:          getEnv("JWT_SECRET", "your_jwt_secret_key"),
 		Port:               getEnv("PORT", "8080"),
+		TrustedProxies:     getEnvList("TRUSTED_PROXIES"),
 	}
 }
 
@@ -42,14 +46,30 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvList reads a comma-separated list, which is empty when the variable is unset
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func setupRouter(config Config) *gin.Engine {
 	r := gin.Default()
 
+	// Only trust X-Forwarded-For from the configured proxies, so clients cannot pick the address services see
+	if err := r.SetTrustedProxies(config.TrustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	// Add CORS middleware
 	r.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Device-Token, X-Draft-Token")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
 
 		if c.Request.Method == "OPTIONS" {
@@ -81,11 +101,25 @@ func setupRouter(config Config) *gin.Engine {
 		userRoutes.Any("/*path", createReverseProxy(config.AuthServiceURL, "/api/v1/users"))
 	}
 
+	// Protected organization routes - auth-service checks membership and org roles
+	orgRoutes := r.Group("/api/v1/orgs")
+	{
+		orgRoutes.Use(jwtAuthMiddleware(config.JWTSecret))
+		orgRoutes.POST("", createReverseProxy(config.AuthServiceURL, "/api/v1/orgs"))
+		orgRoutes.GET("", createReverseProxy(config.AuthServiceURL, "/api/v1/orgs"))
+		orgRoutes.Any("/:id/*path", createReverseProxy(config.AuthServiceURL, "/api/v1/orgs"))
+	}
+
 	// Protected survey routes
 	surveyRoutes := r.Group("/api/v1/surveys")
 	{
-		// Public route for taking surveys (getting survey details)
-		surveyRoutes.GET("/:id", jwtAuthMiddleware(config.JWTSecret), createReverseProxy(config.SurveyServiceURL, "/api/v1/surveys"))
+		// Route for taking surveys (getting survey details). Anonymous access is allowed; survey-service
+		// only serves surveys with allow_anonymous set to requests without a token.
+		surveyRoutes.GET("/:id", optionalJWTAuthMiddleware(config.JWTSecret), createReverseProxy(config.SurveyServiceURL, "/api/v1/surveys"))
+		// Validating a page of a multi-page survey follows the same rules as submitting a response
+		surveyRoutes.POST("/:id/sections/:sectionId/validate", optionalJWTAuthMiddleware(config.JWTSecret), createReverseProxy(config.ResponseServiceURL, "/api/v1/surveys"))
+		// So is uploading a file for a file_upload question
+		surveyRoutes.POST("/:id/questions/:questionId/uploads", optionalJWTAuthMiddleware(config.JWTSecret), createReverseProxy(config.ResponseServiceURL, "/api/v1/surveys"))
 
 		// Routes for survey management (CRUD, analytics)
 		// Now protected by jwtAuthMiddleware; service layer handles owner/admin logic.
@@ -97,9 +131,21 @@ func setupRouter(config Config) *gin.Engine {
 			managedSurveyRoutes.PATCH("/:id", createReverseProxy(config.SurveyServiceURL, "/api/v1/surveys"))        // Partially update survey
 			managedSurveyRoutes.PATCH("/:id/status", createReverseProxy(config.SurveyServiceURL, "/api/v1/surveys")) // Update survey status
 			managedSurveyRoutes.DELETE("/:id", createReverseProxy(config.SurveyServiceURL, "/api/v1/surveys"))       // Delete survey
-			// Survey analytics - service layer will check ownership or admin role.
-			// Assuming analytics are now part of survey-service and it checks perms.
-			// If analytics were in response-service, it would also need to check X-User-ID/Roles or get survey creator info.
+			managedSurveyRoutes.GET("/:id/versions", createReverseProxy(config.SurveyServiceURL, "/api/v1/surveys")) // Survey version history
+			managedSurveyRoutes.GET("/:id/versions/:version", createReverseProxy(config.SurveyServiceURL, "/api/v1/surveys"))
+			managedSurveyRoutes.GET("/:id/status-history", createReverseProxy(config.SurveyServiceURL, "/api/v1/surveys"))           // When and why the survey opened or closed
+			managedSurveyRoutes.PUT("/:id/template", createReverseProxy(config.SurveyServiceURL, "/api/v1/surveys"))                 // Mark as template
+			managedSurveyRoutes.DELETE("/:id/template", createReverseProxy(config.SurveyServiceURL, "/api/v1/surveys"))              // Unmark as template
+			managedSurveyRoutes.GET("/:id/definition", createReverseProxy(config.SurveyServiceURL, "/api/v1/surveys"))               // Export as a JSON or YAML document
+			managedSurveyRoutes.POST("/import", createReverseProxy(config.SurveyServiceURL, "/api/v1/surveys"))                      // Create a survey from such a document
+			managedSurveyRoutes.POST("/import/:format", createReverseProxy(config.SurveyServiceURL, "/api/v1/surveys"))              // Create a survey from a LimeSurvey or QTI export
+			managedSurveyRoutes.POST("/:id/clone", createReverseProxy(config.SurveyServiceURL, "/api/v1/surveys"))                   // Copy into a new survey of the caller's
+			managedSurveyRoutes.POST("/:id/transfer", createReverseProxy(config.SurveyServiceURL, "/api/v1/surveys"))                // Hand to another user
+			managedSurveyRoutes.GET("/:id/transfers", createReverseProxy(config.SurveyServiceURL, "/api/v1/surveys"))                // Ownership transfer audit trail
+			managedSurveyRoutes.POST("/:id/collaborators", createReverseProxy(config.SurveyServiceURL, "/api/v1/surveys"))           // Invite, or change a collaborator's role
+			managedSurveyRoutes.GET("/:id/collaborators", createReverseProxy(config.SurveyServiceURL, "/api/v1/surveys"))            // List collaborators
+			managedSurveyRoutes.DELETE("/:id/collaborators/:userId", createReverseProxy(config.SurveyServiceURL, "/api/v1/surveys")) // Remove a collaborator, or leave
+			// Survey analytics - response-service allows the survey's owner, its analysts and admins
 			managedSurveyRoutes.GET("/:id/analytics", createReverseProxy(config.ResponseServiceURL, "/api/v1/surveys")) // Proxy to response-service for analytics
 		}
 
@@ -113,15 +159,24 @@ func setupRouter(config Config) *gin.Engine {
 			userAccessibleSurveyRoutes.GET("/all", createReverseProxy(config.SurveyServiceURL, "/api/v1/surveys/all"))
 		}
 
-		// Routes for survey responses and exports - service layer in response-service will handle owner/admin logic.
+		// Routes for survey responses and exports - response-service allows the survey's owner, its analysts and admins.
 		surveyResponseRoutes := surveyRoutes.Group("")
 		surveyResponseRoutes.Use(jwtAuthMiddleware(config.JWTSecret)) // Changed from roleAuthMiddleware("admin")
 		{
 			surveyResponseRoutes.GET("/:id/responses", createReverseProxy(config.ResponseServiceURL, "/api/v1/surveys"))
 			surveyResponseRoutes.GET("/:id/responses/export", createReverseProxy(config.ResponseServiceURL, "/api/v1/surveys"))
+			surveyResponseRoutes.GET("/:id/responses/draft", createReverseProxy(config.ResponseServiceURL, "/api/v1/surveys")) // Current user's draft
 		}
 	}
 
+	// Template library routes - proxied to survey-service, which filters templates by visibility
+	templateRoutes := r.Group("/api/v1/templates")
+	{
+		templateRoutes.Use(jwtAuthMiddleware(config.JWTSecret))
+		templateRoutes.GET("", createReverseProxy(config.SurveyServiceURL, "/api/v1/templates"))
+		templateRoutes.POST("/:id/instantiate", createReverseProxy(config.SurveyServiceURL, "/api/v1/templates"))
+	}
+
 	// Questions routes - proxied to survey-service. Service layer handles owner/admin logic for survey.
 	questionRoutes := r.Group("/api/v1/questions")
 	{
@@ -129,11 +184,26 @@ func setupRouter(config Config) *gin.Engine {
 		questionRoutes.Any("/*path", createReverseProxy(config.SurveyServiceURL, "/api/v1/questions"))
 	}
 
-	// Response routes (for submitting new responses) - remains jwtAuthMiddleware
+	// Response routes (for submitting new responses)
 	responseSubmissionRoutes := r.Group("/api/v1/responses")
 	{
-		// Route for submitting responses - requires authentication
-		responseSubmissionRoutes.POST("", jwtAuthMiddleware(config.JWTSecret), createReverseProxy(config.ResponseServiceURL, "/api/v1/responses"))
+		// Route for submitting responses - anonymous submissions are accepted for surveys that allow them
+		responseSubmissionRoutes.POST("", optionalJWTAuthMiddleware(config.JWTSecret), createReverseProxy(config.ResponseServiceURL, "/api/v1/responses"))
+
+		// Drafts, and editing or withdrawing submitted responses - response-service checks the respondent owns them
+		respondentRoutes := responseSubmissionRoutes.Group("")
+		respondentRoutes.Use(optionalJWTAuthMiddleware(config.JWTSecret))
+		{
+			respondentRoutes.POST("/drafts", createReverseProxy(config.ResponseServiceURL, "/api/v1/responses"))
+			respondentRoutes.GET("/drafts/:id", createReverseProxy(config.ResponseServiceURL, "/api/v1/responses"))
+			respondentRoutes.PUT("/drafts/:id", createReverseProxy(config.ResponseServiceURL, "/api/v1/responses"))
+			respondentRoutes.POST("/drafts/:id/submit", createReverseProxy(config.ResponseServiceURL, "/api/v1/responses"))
+			respondentRoutes.PUT("/:id", createReverseProxy(config.ResponseServiceURL, "/api/v1/responses"))
+			respondentRoutes.DELETE("/:id", createReverseProxy(config.ResponseServiceURL, "/api/v1/responses"))
+		}
+
+		// Files uploaded with a response - response-service serves them to the survey owner only
+		responseSubmissionRoutes.GET("/:id/files/:questionId", jwtAuthMiddleware(config.JWTSecret), createReverseProxy(config.ResponseServiceURL, "/api/v1/responses"))
 	}
 
 	return r
@@ -173,6 +243,9 @@ func createReverseProxy(targetHost string, serviceBasePath string) gin.HandlerFu
 				req.Header.Add(name, value)
 			}
 		}
+		// Replace any client address the client reported itself with the one the gateway saw
+		req.Header.Set("X-Forwarded-For", c.ClientIP())
+		req.Header.Set("X-Real-IP", c.ClientIP())
 
 		// Execute the request
 		resp, err := client.Do(req)
@@ -211,66 +284,104 @@ func createReverseProxy(targetHost string, serviceBasePath string) gin.HandlerFu
 func jwtAuthMiddleware(jwtSecret string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get the Authorization header
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
+		if c.GetHeader("Authorization") == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "authorization header is required"})
 			c.Abort()
 			return
 		}
 
-		// Check if it starts with "Bearer "
-		if len(authHeader) < 7 || authHeader[:7] != "Bearer " {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization header format"})
-			c.Abort()
+		if !authenticateRequest(c, jwtSecret) {
 			return
 		}
 
-		// Extract the token
-		tokenString := authHeader[7:]
+		c.Next()
+	}
+}
 
-		// Parse the token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Validate the signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(jwtSecret), nil
-		})
+// optionalJWTAuthMiddleware lets requests without an Authorization header through anonymously,
+// and authenticates the rest like jwtAuthMiddleware. Downstream services only see X-User-ID when a valid token was sent.
+func optionalJWTAuthMiddleware(jwtSecret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Never forward identity headers supplied by the client itself
+		c.Request.Header.Del("X-User-ID")
+		c.Request.Header.Del("X-User-Roles")
+		c.Request.Header.Del("X-Org-ID")
 
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
-			c.Abort()
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
 			return
 		}
 
-		// Get claims
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token claims"})
-			c.Abort()
+		if !authenticateRequest(c, jwtSecret) {
 			return
 		}
 
-		// Check token type
-		tokenType, ok := claims["type"].(string)
-		if !ok || tokenType != "access" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token type"})
-			c.Abort()
-			return
+		c.Next()
+	}
+}
+
+// authenticateRequest validates the bearer token and forwards the user's identity to downstream services.
+// It aborts the request with 401 and returns false if the token is missing or invalid.
+func authenticateRequest(c *gin.Context, jwtSecret string) bool {
+	authHeader := c.GetHeader("Authorization")
+
+	// Check if it starts with "Bearer "
+	if len(authHeader) < 7 || authHeader[:7] != "Bearer " {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization header format"})
+		c.Abort()
+		return false
+	}
+
+	// Extract the token
+	tokenString := authHeader[7:]
+
+	// Parse the token
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// Validate the signing method
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
+		return []byte(jwtSecret), nil
+	})
 
-		// Store user info in context for downstream services
-		c.Set("user_id", claims["user_id"])
-		c.Set("username", claims["username"])
-		c.Set("email", claims["email"])
-		c.Set("roles", claims["roles"])
+	if err != nil || !token.Valid {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+		c.Abort()
+		return false
+	}
 
-		// Forward the Authorization header to the underlying service
-		c.Request.Header.Set("X-User-ID", fmt.Sprintf("%v", claims["user_id"]))
-		c.Request.Header.Set("X-User-Roles", fmt.Sprintf("%v", claims["roles"]))
+	// Get claims
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token claims"})
+		c.Abort()
+		return false
+	}
 
-		c.Next()
+	// Check token type
+	tokenType, ok := claims["type"].(string)
+	if !ok || tokenType != "access" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token type"})
+		c.Abort()
+		return false
+	}
+
+	// Store user info in context for downstream services
+	c.Set("user_id", claims["user_id"])
+	c.Set("username", claims["username"])
+	c.Set("email", claims["email"])
+	c.Set("roles", claims["roles"])
+	c.Set("org_id", claims["org_id"])
+
+	// Forward the Authorization header to the underlying service
+	c.Request.Header.Set("X-User-ID", fmt.Sprintf("%v", claims["user_id"]))
+	c.Request.Header.Set("X-User-Roles", fmt.Sprintf("%v", claims["roles"]))
+	// Surveys are scoped to the organization in the token; users outside every organization have none
+	c.Request.Header.Del("X-Org-ID")
+	if orgID, ok := claims["org_id"].(float64); ok {
+		c.Request.Header.Set("X-Org-ID", strconv.Itoa(int(orgID)))
 	}
+	return true
 }
 
 // roleAuthMiddleware checks if the user has one of the required roles
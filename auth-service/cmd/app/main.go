@@ -57,6 +57,7 @@ func main() {
 	// Initialize service
 	authService := service.NewAuthService(repo, cfg.JWT.Secret, cfg.JWT.ExpirationHours)
 	userService := service.NewUserService(repo)
+	orgService := service.NewOrganizationService(repo)
 
 	// Initialize router
 	router := gin.Default()
@@ -89,6 +90,20 @@ func main() {
 			users.GET("/me", userHandler.GetCurrentUser)
 			users.PUT("/me", userHandler.UpdateCurrentUser)
 		}
+
+		// Organization routes (protected)
+		orgs := api.Group("/orgs")
+		{
+			orgHandler := handlers.NewOrganizationHandler(orgService, authService)
+			orgs.Use(handlers.JWTAuthMiddleware(cfg.JWT.Secret))
+			orgs.POST("", orgHandler.CreateOrganization)
+			orgs.GET("", orgHandler.ListOrganizations)
+			orgs.GET("/:id/members", orgHandler.ListMembers)
+			orgs.POST("/:id/members", orgHandler.AddMember)                     // Add a member, or change their role
+			orgs.DELETE("/:id/members/:userId", orgHandler.RemoveMember)        // Remove a member, or leave
+			orgs.POST("/:id/switch", orgHandler.SwitchOrganization)             // New tokens scoped to the organization
+			orgs.POST("/personal/switch", orgHandler.SwitchToPersonalWorkspace) // New tokens outside of every organization
+		}
 	}
 
 	// Start server
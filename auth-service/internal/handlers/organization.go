@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/VitaliySynytskyi/survey-platform/auth-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/auth-service/internal/service"
+)
+
+// OrganizationHandler handles organization and membership endpoints
+type OrganizationHandler struct {
+	service     *service.OrganizationService
+	authService *service.AuthService
+}
+
+// NewOrganizationHandler creates a new OrganizationHandler instance
+func NewOrganizationHandler(service *service.OrganizationService, authService *service.AuthService) *OrganizationHandler {
+	return &OrganizationHandler{
+		service:     service,
+		authService: authService,
+	}
+}
+
+// CreateOrganization creates an organization owned by the current user
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	var req models.CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	org, err := h.service.CreateOrganization(c.Request.Context(), c.GetInt("user_id"), &req)
+	if err != nil {
+		writeOrganizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, org)
+}
+
+// ListOrganizations lists the current user's organizations with their role in each
+func (h *OrganizationHandler) ListOrganizations(c *gin.Context) {
+	orgs, err := h.service.ListUserOrganizations(c.Request.Context(), c.GetInt("user_id"))
+	if err != nil {
+		writeOrganizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, orgs)
+}
+
+// ListMembers lists the members of one of the current user's organizations
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	orgID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization ID"})
+		return
+	}
+
+	members, err := h.service.ListMembers(c.Request.Context(), orgID, c.GetInt("user_id"))
+	if err != nil {
+		writeOrganizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// AddMember adds a user to an organization or changes their role
+func (h *OrganizationHandler) AddMember(c *gin.Context) {
+	orgID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization ID"})
+		return
+	}
+
+	var req models.AddMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := h.service.AddMember(c.Request.Context(), orgID, c.GetInt("user_id"), &req)
+	if err != nil {
+		writeOrganizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// RemoveMember removes a member from an organization, or lets the current user leave it
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	orgID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization ID"})
+		return
+	}
+	memberID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	if err := h.service.RemoveMember(c.Request.Context(), orgID, c.GetInt("user_id"), memberID); err != nil {
+		writeOrganizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "member removed"})
+}
+
+// SwitchOrganization issues new tokens scoped to one of the current user's organizations
+func (h *OrganizationHandler) SwitchOrganization(c *gin.Context) {
+	orgID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization ID"})
+		return
+	}
+
+	response, err := h.authService.SwitchOrganization(c.Request.Context(), c.GetInt("user_id"), orgID)
+	if err != nil {
+		writeOrganizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SwitchToPersonalWorkspace issues new tokens outside of every organization, for the surveys the current user owns
+// on their own
+func (h *OrganizationHandler) SwitchToPersonalWorkspace(c *gin.Context) {
+	response, err := h.authService.SwitchOrganization(c.Request.Context(), c.GetInt("user_id"), service.PersonalWorkspace)
+	if err != nil {
+		writeOrganizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// writeOrganizationError maps organization errors to HTTP statuses
+func writeOrganizationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrOrganizationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrOrganizationForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidOrganization), errors.Is(err, service.ErrInvalidMember):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
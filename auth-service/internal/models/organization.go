@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Roles of a user in an organization
+const (
+	OrgRoleOwner  = "owner"  // Manages the organization and its members, including other owners
+	OrgRoleAdmin  = "admin"  // Manages the organization's members, except owners
+	OrgRoleMember = "member" // Works on the organization's surveys
+)
+
+// IsValidOrgRole reports whether role is a known organization role
+func IsValidOrgRole(role string) bool {
+	return role == OrgRoleOwner || role == OrgRoleAdmin || role == OrgRoleMember
+}
+
+// Organization groups users, such as a department, whose surveys are kept apart from other organizations'
+type Organization struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	Role      string    `json:"role,omitempty"` // The current user's role in the organization, when listed for them
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// OrganizationMember is a user's membership in an organization
+type OrganizationMember struct {
+	OrgID     int       `json:"org_id"`
+	UserID    int       `json:"user_id"`
+	Username  string    `json:"username,omitempty"`
+	Email     string    `json:"email,omitempty"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateOrganizationRequest represents the data needed to create an organization.
+// An empty slug is derived from the name.
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=255"`
+	Slug string `json:"slug" binding:"max=100"`
+}
+
+// AddMemberRequest adds a user, given by ID or else by email, to an organization or changes their role
+type AddMemberRequest struct {
+	UserID int    `json:"user_id"`
+	Email  string `json:"email"`
+	Role   string `json:"role" binding:"required"`
+}
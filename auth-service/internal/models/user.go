@@ -16,6 +16,8 @@ type User struct {
 	LastName     string    `json:"last_name,omitempty"`
 	IsActive     bool      `json:"is_active"`
 	Roles        []string  `json:"roles,omitempty"`
+	OrgID        *int      `json:"org_id,omitempty"`   // The organization the user's tokens are scoped to, if any
+	OrgRole      string    `json:"org_role,omitempty"` // The user's role in that organization
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
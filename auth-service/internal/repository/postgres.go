@@ -260,3 +260,147 @@ func (r *PostgresRepository) GetRoleByName(ctx context.Context, name string) (*m
 
 	return &role, nil
 }
+
+// CreateOrganization creates an organization with ownerID as its first owner in one transaction
+func (r *PostgresRepository) CreateOrganization(ctx context.Context, org *models.Organization, ownerID int) (err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	query := `
+		INSERT INTO organizations (name, slug)
+		VALUES ($1, $2)
+		RETURNING id, created_at, updated_at
+	`
+	if err = tx.QueryRow(ctx, query, org.Name, org.Slug).Scan(&org.ID, &org.CreatedAt, &org.UpdatedAt); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, "INSERT INTO organization_members (org_id, user_id, role) VALUES ($1, $2, $3)", org.ID, ownerID, models.OrgRoleOwner)
+	if err != nil {
+		return err
+	}
+	org.Role = models.OrgRoleOwner
+	return nil
+}
+
+// GetOrganizationBySlug retrieves an organization by its slug
+func (r *PostgresRepository) GetOrganizationBySlug(ctx context.Context, slug string) (*models.Organization, error) {
+	query := `
+		SELECT id, name, slug, created_at, updated_at
+		FROM organizations
+		WHERE slug = $1
+	`
+
+	var org models.Organization
+	err := r.db.QueryRow(ctx, query, slug).Scan(&org.ID, &org.Name, &org.Slug, &org.CreatedAt, &org.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("organization not found")
+		}
+		return nil, err
+	}
+	return &org, nil
+}
+
+// ListUserOrganizations retrieves the organizations a user belongs to with their role in each, oldest membership first
+func (r *PostgresRepository) ListUserOrganizations(ctx context.Context, userID int) ([]*models.Organization, error) {
+	query := `
+		SELECT o.id, o.name, o.slug, m.role, o.created_at, o.updated_at
+		FROM organizations o
+		JOIN organization_members m ON o.id = m.org_id
+		WHERE m.user_id = $1
+		ORDER BY m.created_at, o.id
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orgs := []*models.Organization{}
+	for rows.Next() {
+		var org models.Organization
+		if err := rows.Scan(&org.ID, &org.Name, &org.Slug, &org.Role, &org.CreatedAt, &org.UpdatedAt); err != nil {
+			return nil, err
+		}
+		orgs = append(orgs, &org)
+	}
+	return orgs, rows.Err()
+}
+
+// GetOrganizationRole returns a user's role in an organization, or an empty string if they are not a member
+func (r *PostgresRepository) GetOrganizationRole(ctx context.Context, orgID, userID int) (string, error) {
+	var role string
+	err := r.db.QueryRow(ctx, "SELECT role FROM organization_members WHERE org_id = $1 AND user_id = $2", orgID, userID).Scan(&role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	return role, err
+}
+
+// ListOrganizationMembers retrieves the members of an organization with their username and email, oldest first
+func (r *PostgresRepository) ListOrganizationMembers(ctx context.Context, orgID int) ([]*models.OrganizationMember, error) {
+	query := `
+		SELECT m.org_id, m.user_id, u.username, u.email, m.role, m.created_at
+		FROM organization_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.org_id = $1
+		ORDER BY m.created_at, m.user_id
+	`
+
+	rows, err := r.db.Query(ctx, query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []*models.OrganizationMember{}
+	for rows.Next() {
+		var member models.OrganizationMember
+		if err := rows.Scan(&member.OrgID, &member.UserID, &member.Username, &member.Email, &member.Role, &member.CreatedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, &member)
+	}
+	return members, rows.Err()
+}
+
+// UpsertOrganizationMember adds a user to an organization, or changes their role if they already belong to it
+func (r *PostgresRepository) UpsertOrganizationMember(ctx context.Context, member *models.OrganizationMember) error {
+	query := `
+		INSERT INTO organization_members (org_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (org_id, user_id) DO UPDATE SET role = EXCLUDED.role
+		RETURNING created_at
+	`
+	return r.db.QueryRow(ctx, query, member.OrgID, member.UserID, member.Role).Scan(&member.CreatedAt)
+}
+
+// DeleteOrganizationMember removes a user from an organization
+func (r *PostgresRepository) DeleteOrganizationMember(ctx context.Context, orgID, userID int) error {
+	result, err := r.db.Exec(ctx, "DELETE FROM organization_members WHERE org_id = $1 AND user_id = $2", orgID, userID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("member not found")
+	}
+	return nil
+}
+
+// CountOrganizationOwners returns how many owners an organization has
+func (r *PostgresRepository) CountOrganizationOwners(ctx context.Context, orgID int) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM organization_members WHERE org_id = $1 AND role = $2", orgID, models.OrgRoleOwner).Scan(&count)
+	return count, err
+}
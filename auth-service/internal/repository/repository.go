@@ -19,4 +19,14 @@ type Repository interface {
 	GetUserRoles(ctx context.Context, userID int) ([]string, error)
 	AddUserRole(ctx context.Context, userID, roleID int) error
 	GetRoleByName(ctx context.Context, name string) (*models.Role, error)
+
+	// Organization operations
+	CreateOrganization(ctx context.Context, org *models.Organization, ownerID int) error
+	GetOrganizationBySlug(ctx context.Context, slug string) (*models.Organization, error)
+	ListUserOrganizations(ctx context.Context, userID int) ([]*models.Organization, error)
+	GetOrganizationRole(ctx context.Context, orgID, userID int) (string, error)
+	ListOrganizationMembers(ctx context.Context, orgID int) ([]*models.OrganizationMember, error)
+	UpsertOrganizationMember(ctx context.Context, member *models.OrganizationMember) error
+	DeleteOrganizationMember(ctx context.Context, orgID, userID int) error
+	CountOrganizationOwners(ctx context.Context, orgID int) (int, error)
 }
@@ -112,6 +112,11 @@ func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest) (*mod
 		return nil, errors.New("invalid username or password")
 	}
 
+	// Scope the tokens to the organization the user joined first
+	if err := s.scopeToOrganization(ctx, user, 0); err != nil {
+		return nil, fmt.Errorf("error getting user organizations: %w", err)
+	}
+
 	// Generate tokens
 	accessToken, refreshToken, err := s.generateTokens(user)
 	if err != nil {
@@ -175,6 +180,15 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*m
 		return nil, errors.New("account is disabled")
 	}
 
+	// Keep the organization of the refreshed token while the user still belongs to it. An org_id of 0 keeps the
+	// user in their personal workspace; tokens issued before it was recorded have no org_id at all.
+	orgID, ok := claims["org_id"].(float64)
+	if ok && orgID == PersonalWorkspace {
+		user.OrgID, user.OrgRole = nil, ""
+	} else if err := s.scopeToOrganization(ctx, user, int(orgID)); err != nil {
+		return nil, fmt.Errorf("error getting user organizations: %w", err)
+	}
+
 	// Generate new tokens
 	newAccessToken, newRefreshToken, err := s.generateTokens(user)
 	if err != nil {
@@ -188,6 +202,71 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*m
 	}, nil
 }
 
+// PersonalWorkspace is the organization ID that switches a user out of every organization, back to the surveys they
+// own outside of one
+const PersonalWorkspace = 0
+
+// SwitchOrganization issues new tokens for a user scoped to one of their organizations, or unscoped for
+// PersonalWorkspace
+func (s *AuthService) SwitchOrganization(ctx context.Context, userID, orgID int) (*models.AuthResponse, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if !user.IsActive {
+		return nil, errors.New("account is disabled")
+	}
+
+	if orgID == PersonalWorkspace {
+		user.OrgID, user.OrgRole = nil, ""
+	} else {
+		role, err := s.repo.GetOrganizationRole(ctx, orgID, userID)
+		if err != nil {
+			return nil, fmt.Errorf("error getting organization role: %w", err)
+		}
+		if role == "" {
+			return nil, ErrOrganizationNotFound
+		}
+		user.OrgID = &orgID
+		user.OrgRole = role
+	}
+
+	accessToken, refreshToken, err := s.generateTokens(user)
+	if err != nil {
+		return nil, fmt.Errorf("error generating tokens: %w", err)
+	}
+
+	return &models.AuthResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		User:         *user,
+	}, nil
+}
+
+// scopeToOrganization sets the organization a user's tokens are scoped to: orgID if they still belong to it, else the
+// organization they joined first. Users outside every organization get unscoped tokens.
+func (s *AuthService) scopeToOrganization(ctx context.Context, user *models.User, orgID int) error {
+	orgs, err := s.repo.ListUserOrganizations(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if len(orgs) == 0 {
+		user.OrgID, user.OrgRole = nil, ""
+		return nil
+	}
+
+	scoped := orgs[0]
+	for _, org := range orgs {
+		if org.ID == orgID {
+			scoped = org
+			break
+		}
+	}
+	user.OrgID = &scoped.ID
+	user.OrgRole = scoped.Role
+	return nil
+}
+
 // ValidateToken validates a JWT token and returns the claims
 func (s *AuthService) ValidateToken(tokenString string) (jwt.MapClaims, error) {
 	// Parse the token
@@ -234,6 +313,10 @@ func (s *AuthService) generateTokens(user *models.User) (string, string, error)
 		"type":     "access",
 		"exp":      time.Now().Add(time.Hour * time.Duration(s.expirationHours)).Unix(),
 	}
+	if user.OrgID != nil {
+		accessTokenClaims["org_id"] = *user.OrgID
+		accessTokenClaims["org_role"] = user.OrgRole
+	}
 
 	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessTokenClaims)
 	accessTokenString, err := accessToken.SignedString([]byte(s.jwtSecret))
@@ -247,6 +330,11 @@ func (s *AuthService) generateTokens(user *models.User) (string, string, error)
 		"type":    "refresh",
 		"exp":     time.Now().Add(time.Hour * 24 * 30).Unix(),
 	}
+	if user.OrgID != nil {
+		refreshTokenClaims["org_id"] = *user.OrgID
+	} else {
+		refreshTokenClaims["org_id"] = PersonalWorkspace
+	}
 
 	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshTokenClaims)
 	refreshTokenString, err := refreshToken.SignedString([]byte(s.jwtSecret))
@@ -69,6 +69,49 @@ func (m *MockRepository) GetRoleByName(ctx context.Context, name string) (*model
 	return args.Get(0).(*models.Role), args.Error(1)
 }
 
+func (m *MockRepository) CreateOrganization(ctx context.Context, org *models.Organization, ownerID int) error {
+	args := m.Called(ctx, org, ownerID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetOrganizationBySlug(ctx context.Context, slug string) (*models.Organization, error) {
+	args := m.Called(ctx, slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Organization), args.Error(1)
+}
+
+func (m *MockRepository) ListUserOrganizations(ctx context.Context, userID int) ([]*models.Organization, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*models.Organization), args.Error(1)
+}
+
+func (m *MockRepository) GetOrganizationRole(ctx context.Context, orgID, userID int) (string, error) {
+	args := m.Called(ctx, orgID, userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockRepository) ListOrganizationMembers(ctx context.Context, orgID int) ([]*models.OrganizationMember, error) {
+	args := m.Called(ctx, orgID)
+	return args.Get(0).([]*models.OrganizationMember), args.Error(1)
+}
+
+func (m *MockRepository) UpsertOrganizationMember(ctx context.Context, member *models.OrganizationMember) error {
+	args := m.Called(ctx, member)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DeleteOrganizationMember(ctx context.Context, orgID, userID int) error {
+	args := m.Called(ctx, orgID, userID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CountOrganizationOwners(ctx context.Context, orgID int) (int, error) {
+	args := m.Called(ctx, orgID)
+	return args.Int(0), args.Error(1)
+}
+
 func TestRegister(t *testing.T) {
 	mockRepo := new(MockRepository)
 	authService := NewAuthService(mockRepo, "test-secret", 24)
@@ -195,6 +238,7 @@ func TestLogin(t *testing.T) {
 
 		// Set up mock repository behavior
 		mockRepo.On("GetUserByUsername", ctx, req.Username).Return(mockUser, nil)
+		mockRepo.On("ListUserOrganizations", ctx, mockUser.ID).Return([]*models.Organization{}, nil)
 
 		// Call the service
 		response, err := authService.Login(ctx, req)
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/VitaliySynytskyi/survey-platform/auth-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/auth-service/internal/repository"
+)
+
+// Organization errors, mapped to HTTP statuses by the handlers
+var (
+	ErrOrganizationNotFound  = errors.New("organization not found")
+	ErrOrganizationForbidden = errors.New("only the organization's owners and admins can manage its members")
+	ErrInvalidOrganization   = errors.New("invalid organization")
+	ErrInvalidMember         = errors.New("invalid organization member")
+)
+
+// slugSeparators matches the runs of characters that are replaced by a dash in a derived slug
+var slugSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// OrganizationService handles organizations and their memberships
+type OrganizationService struct {
+	repo repository.Repository
+}
+
+// NewOrganizationService creates a new OrganizationService instance
+func NewOrganizationService(repo repository.Repository) *OrganizationService {
+	return &OrganizationService{
+		repo: repo,
+	}
+}
+
+// CreateOrganization creates an organization owned by the user. The slug is derived from the name if empty.
+func (s *OrganizationService) CreateOrganization(ctx context.Context, userID int, req *models.CreateOrganizationRequest) (*models.Organization, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: a name is required", ErrInvalidOrganization)
+	}
+	slug := strings.TrimSpace(req.Slug)
+	if slug == "" {
+		slug = name
+	}
+	slug = strings.Trim(slugSeparators.ReplaceAllString(strings.ToLower(slug), "-"), "-")
+	if slug == "" {
+		return nil, fmt.Errorf("%w: the slug needs letters or digits", ErrInvalidOrganization)
+	}
+
+	// Check if slug already exists
+	existing, _ := s.repo.GetOrganizationBySlug(ctx, slug)
+	if existing != nil {
+		return nil, fmt.Errorf("%w: slug %q already exists", ErrInvalidOrganization, slug)
+	}
+
+	org := &models.Organization{Name: name, Slug: slug}
+	if err := s.repo.CreateOrganization(ctx, org, userID); err != nil {
+		return nil, fmt.Errorf("error creating organization: %w", err)
+	}
+	return org, nil
+}
+
+// ListUserOrganizations lists the organizations a user belongs to with their role in each
+func (s *OrganizationService) ListUserOrganizations(ctx context.Context, userID int) ([]*models.Organization, error) {
+	orgs, err := s.repo.ListUserOrganizations(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting organizations: %w", err)
+	}
+	return orgs, nil
+}
+
+// ListMembers lists the members of an organization the user belongs to
+func (s *OrganizationService) ListMembers(ctx context.Context, orgID, userID int) ([]*models.OrganizationMember, error) {
+	if _, err := s.memberRole(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	members, err := s.repo.ListOrganizationMembers(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting organization members: %w", err)
+	}
+	return members, nil
+}
+
+// AddMember adds an active user, given by ID or else by email, to an organization or changes their role.
+// Owners and admins manage members; only owners may make or change other owners.
+func (s *OrganizationService) AddMember(ctx context.Context, orgID, userID int, req *models.AddMemberRequest) (*models.OrganizationMember, error) {
+	actingRole, err := s.memberRole(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if actingRole == models.OrgRoleMember {
+		return nil, ErrOrganizationForbidden
+	}
+	if !models.IsValidOrgRole(req.Role) {
+		return nil, fmt.Errorf("%w: role must be %q, %q or %q", ErrInvalidMember, models.OrgRoleOwner, models.OrgRoleAdmin, models.OrgRoleMember)
+	}
+
+	var user *models.User
+	switch {
+	case req.UserID > 0:
+		user, err = s.repo.GetUserByID(ctx, req.UserID)
+	case strings.TrimSpace(req.Email) != "":
+		user, err = s.repo.GetUserByEmail(ctx, strings.TrimSpace(req.Email))
+	default:
+		return nil, fmt.Errorf("%w: a user ID or email is required", ErrInvalidMember)
+	}
+	if err != nil || !user.IsActive {
+		return nil, fmt.Errorf("%w: user not found", ErrInvalidMember)
+	}
+
+	currentRole, err := s.repo.GetOrganizationRole(ctx, orgID, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting organization role: %w", err)
+	}
+	if actingRole != models.OrgRoleOwner && (req.Role == models.OrgRoleOwner || currentRole == models.OrgRoleOwner) {
+		return nil, ErrOrganizationForbidden
+	}
+	if currentRole == models.OrgRoleOwner && req.Role != models.OrgRoleOwner {
+		if err := s.checkNotLastOwner(ctx, orgID); err != nil {
+			return nil, err
+		}
+	}
+
+	member := &models.OrganizationMember{
+		OrgID:    orgID,
+		UserID:   user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		Role:     req.Role,
+	}
+	if err := s.repo.UpsertOrganizationMember(ctx, member); err != nil {
+		return nil, fmt.Errorf("error adding organization member: %w", err)
+	}
+	return member, nil
+}
+
+// RemoveMember removes a member from an organization. Owners and admins remove others, except that only owners
+// remove owners, and every member may leave. The last owner cannot leave.
+func (s *OrganizationService) RemoveMember(ctx context.Context, orgID, userID, memberID int) error {
+	actingRole, err := s.memberRole(ctx, orgID, userID)
+	if err != nil {
+		return err
+	}
+	memberRole, err := s.repo.GetOrganizationRole(ctx, orgID, memberID)
+	if err != nil {
+		return fmt.Errorf("error getting organization role: %w", err)
+	}
+	if memberRole == "" {
+		return fmt.Errorf("%w: user %d is not a member", ErrInvalidMember, memberID)
+	}
+
+	if memberID != userID {
+		if actingRole == models.OrgRoleMember || (memberRole == models.OrgRoleOwner && actingRole != models.OrgRoleOwner) {
+			return ErrOrganizationForbidden
+		}
+	}
+	if memberRole == models.OrgRoleOwner {
+		if err := s.checkNotLastOwner(ctx, orgID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.DeleteOrganizationMember(ctx, orgID, memberID); err != nil {
+		return fmt.Errorf("error removing organization member: %w", err)
+	}
+	return nil
+}
+
+// memberRole returns the user's role in an organization. Organizations are reported as not found to non-members.
+func (s *OrganizationService) memberRole(ctx context.Context, orgID, userID int) (string, error) {
+	role, err := s.repo.GetOrganizationRole(ctx, orgID, userID)
+	if err != nil {
+		return "", fmt.Errorf("error getting organization role: %w", err)
+	}
+	if role == "" {
+		return "", ErrOrganizationNotFound
+	}
+	return role, nil
+}
+
+// checkNotLastOwner keeps every organization with at least one owner
+func (s *OrganizationService) checkNotLastOwner(ctx context.Context, orgID int) error {
+	owners, err := s.repo.CountOrganizationOwners(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("error counting organization owners: %w", err)
+	}
+	if owners <= 1 {
+		return fmt.Errorf("%w: an organization needs at least one owner", ErrInvalidMember)
+	}
+	return nil
+}
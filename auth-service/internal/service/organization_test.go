@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/VitaliySynytskyi/survey-platform/auth-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateOrganization(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Slug derived from the name", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		orgService := NewOrganizationService(mockRepo)
+
+		mockRepo.On("GetOrganizationBySlug", ctx, "market-research").Return(nil, errors.New("organization not found"))
+		mockRepo.On("CreateOrganization", ctx, mock.AnythingOfType("*models.Organization"), 1).Return(nil)
+
+		org, err := orgService.CreateOrganization(ctx, 1, &models.CreateOrganizationRequest{Name: " Market Research! "})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Market Research!", org.Name)
+		assert.Equal(t, "market-research", org.Slug)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Slug already exists", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		orgService := NewOrganizationService(mockRepo)
+
+		mockRepo.On("GetOrganizationBySlug", ctx, "hr").Return(&models.Organization{ID: 3, Slug: "hr"}, nil)
+
+		org, err := orgService.CreateOrganization(ctx, 1, &models.CreateOrganizationRequest{Name: "Human Resources", Slug: "HR"})
+
+		assert.ErrorIs(t, err, ErrInvalidOrganization)
+		assert.Nil(t, org)
+		mockRepo.AssertNotCalled(t, "CreateOrganization", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestAddMember(t *testing.T) {
+	ctx := context.Background()
+	newUser := &models.User{ID: 7, Username: "newbie", Email: "newbie@example.com", IsActive: true}
+
+	t.Run("Admin adds a member by email", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		orgService := NewOrganizationService(mockRepo)
+
+		mockRepo.On("GetOrganizationRole", ctx, 5, 1).Return(models.OrgRoleAdmin, nil)
+		mockRepo.On("GetUserByEmail", ctx, newUser.Email).Return(newUser, nil)
+		mockRepo.On("GetOrganizationRole", ctx, 5, newUser.ID).Return("", nil)
+		mockRepo.On("UpsertOrganizationMember", ctx, mock.AnythingOfType("*models.OrganizationMember")).Return(nil)
+
+		member, err := orgService.AddMember(ctx, 5, 1, &models.AddMemberRequest{Email: newUser.Email, Role: models.OrgRoleMember})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 5, member.OrgID)
+		assert.Equal(t, newUser.ID, member.UserID)
+		assert.Equal(t, models.OrgRoleMember, member.Role)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Admin cannot make owners", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		orgService := NewOrganizationService(mockRepo)
+
+		mockRepo.On("GetOrganizationRole", ctx, 5, 1).Return(models.OrgRoleAdmin, nil)
+		mockRepo.On("GetUserByID", ctx, newUser.ID).Return(newUser, nil)
+		mockRepo.On("GetOrganizationRole", ctx, 5, newUser.ID).Return(models.OrgRoleMember, nil)
+
+		_, err := orgService.AddMember(ctx, 5, 1, &models.AddMemberRequest{UserID: newUser.ID, Role: models.OrgRoleOwner})
+
+		assert.ErrorIs(t, err, ErrOrganizationForbidden)
+		mockRepo.AssertNotCalled(t, "UpsertOrganizationMember", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Members cannot add members", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		orgService := NewOrganizationService(mockRepo)
+
+		mockRepo.On("GetOrganizationRole", ctx, 5, 1).Return(models.OrgRoleMember, nil)
+
+		_, err := orgService.AddMember(ctx, 5, 1, &models.AddMemberRequest{UserID: newUser.ID, Role: models.OrgRoleMember})
+
+		assert.ErrorIs(t, err, ErrOrganizationForbidden)
+	})
+
+	t.Run("Non-members do not see the organization", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		orgService := NewOrganizationService(mockRepo)
+
+		mockRepo.On("GetOrganizationRole", ctx, 5, 1).Return("", nil)
+
+		_, err := orgService.AddMember(ctx, 5, 1, &models.AddMemberRequest{UserID: newUser.ID, Role: models.OrgRoleMember})
+
+		assert.ErrorIs(t, err, ErrOrganizationNotFound)
+	})
+}
+
+func TestRemoveMember(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("The last owner cannot leave", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		orgService := NewOrganizationService(mockRepo)
+
+		mockRepo.On("GetOrganizationRole", ctx, 5, 1).Return(models.OrgRoleOwner, nil)
+		mockRepo.On("CountOrganizationOwners", ctx, 5).Return(1, nil)
+
+		err := orgService.RemoveMember(ctx, 5, 1, 1)
+
+		assert.ErrorIs(t, err, ErrInvalidMember)
+		mockRepo.AssertNotCalled(t, "DeleteOrganizationMember", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Members may leave", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		orgService := NewOrganizationService(mockRepo)
+
+		mockRepo.On("GetOrganizationRole", ctx, 5, 2).Return(models.OrgRoleMember, nil)
+		mockRepo.On("DeleteOrganizationMember", ctx, 5, 2).Return(nil)
+
+		err := orgService.RemoveMember(ctx, 5, 2, 2)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Members cannot remove others", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		orgService := NewOrganizationService(mockRepo)
+
+		mockRepo.On("GetOrganizationRole", ctx, 5, 2).Return(models.OrgRoleMember, nil)
+		mockRepo.On("GetOrganizationRole", ctx, 5, 3).Return(models.OrgRoleMember, nil)
+
+		err := orgService.RemoveMember(ctx, 5, 2, 3)
+
+		assert.ErrorIs(t, err, ErrOrganizationForbidden)
+	})
+}
+
+func TestSwitchOrganization(t *testing.T) {
+	ctx := context.Background()
+	user := &models.User{ID: 1, Username: "testuser", Roles: []string{"admin"}, IsActive: true}
+
+	t.Run("Tokens carry the organization", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		authService := NewAuthService(mockRepo, "test-secret", 24)
+
+		mockRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+		mockRepo.On("GetOrganizationRole", ctx, 5, user.ID).Return(models.OrgRoleAdmin, nil)
+
+		response, err := authService.SwitchOrganization(ctx, user.ID, 5)
+		assert.NoError(t, err)
+		assert.Equal(t, 5, *response.User.OrgID)
+
+		claims, err := authService.ValidateToken(response.Token)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(5), claims["org_id"])
+		assert.Equal(t, models.OrgRoleAdmin, claims["org_role"])
+	})
+
+	t.Run("Only into the user's organizations", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		authService := NewAuthService(mockRepo, "test-secret", 24)
+
+		mockRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+		mockRepo.On("GetOrganizationRole", ctx, 6, user.ID).Return("", nil)
+
+		response, err := authService.SwitchOrganization(ctx, user.ID, 6)
+		assert.ErrorIs(t, err, ErrOrganizationNotFound)
+		assert.Nil(t, response)
+	})
+
+	t.Run("Back to the personal workspace, across refreshes", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		authService := NewAuthService(mockRepo, "test-secret", 24)
+
+		mockRepo.On("GetUserByID", ctx, user.ID).Return(&models.User{ID: 1, Username: "testuser", IsActive: true}, nil)
+
+		response, err := authService.SwitchOrganization(ctx, user.ID, PersonalWorkspace)
+		assert.NoError(t, err)
+		assert.Nil(t, response.User.OrgID)
+		claims, err := authService.ValidateToken(response.Token)
+		assert.NoError(t, err)
+		assert.NotContains(t, claims, "org_id")
+
+		refreshed, err := authService.RefreshToken(ctx, response.RefreshToken)
+		assert.NoError(t, err)
+		assert.Nil(t, refreshed.User.OrgID, "the user still belongs to organizations, but chose none")
+		mockRepo.AssertNotCalled(t, "GetOrganizationRole", mock.Anything, mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "ListUserOrganizations", mock.Anything, mock.Anything)
+	})
+}
+
+func TestScopeToOrganization(t *testing.T) {
+	ctx := context.Background()
+	orgs := []*models.Organization{
+		{ID: 5, Role: models.OrgRoleMember},
+		{ID: 8, Role: models.OrgRoleOwner},
+	}
+
+	mockRepo := new(MockRepository)
+	authService := NewAuthService(mockRepo, "test-secret", 24)
+	mockRepo.On("ListUserOrganizations", ctx, 1).Return(orgs, nil)
+	mockRepo.On("ListUserOrganizations", ctx, 2).Return([]*models.Organization{}, nil)
+
+	user := &models.User{ID: 1}
+	assert.NoError(t, authService.scopeToOrganization(ctx, user, 8))
+	assert.Equal(t, 8, *user.OrgID)
+	assert.Equal(t, models.OrgRoleOwner, user.OrgRole)
+
+	// The organization joined first, when the requested one was left
+	assert.NoError(t, authService.scopeToOrganization(ctx, user, 9))
+	assert.Equal(t, 5, *user.OrgID)
+	assert.Equal(t, models.OrgRoleMember, user.OrgRole)
+
+	outsider := &models.User{ID: 2}
+	assert.NoError(t, authService.scopeToOrganization(ctx, outsider, 0))
+	assert.Nil(t, outsider.OrgID)
+}
@@ -25,15 +25,55 @@ CREATE TABLE IF NOT EXISTS user_roles (
     PRIMARY KEY (user_id, role_id)
 );
 
+-- Organizations let one deployment serve several departments, each seeing only its own surveys
+CREATE TABLE IF NOT EXISTS organizations (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    slug VARCHAR(100) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS organization_members (
+    org_id INT REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id INT REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(10) NOT NULL, -- 'owner', 'admin' or 'member'
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (org_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_members_user_id ON organization_members(user_id);
+
 -- Create surveys, questions, and question_options tables
 CREATE TABLE IF NOT EXISTS surveys (
     id SERIAL PRIMARY KEY,
     creator_id INT REFERENCES users(id) ON DELETE SET NULL,
+    org_id INT REFERENCES organizations(id), -- The tenant the survey belongs to, NULL for users outside any organization
     title VARCHAR(255) NOT NULL,
     description TEXT,
     is_active BOOLEAN DEFAULT TRUE,
     start_date TIMESTAMP WITH TIME ZONE,
     end_date TIMESTAMP WITH TIME ZONE,
+    max_responses INT, -- Response quota, NULL for unlimited
+    one_response_per_user BOOLEAN DEFAULT FALSE,
+    anonymous_limit VARCHAR(10) NOT NULL DEFAULT '', -- '', 'ip' or 'device'
+    allow_anonymous BOOLEAN DEFAULT FALSE, -- Respondents may answer without logging in
+    allow_response_edits BOOLEAN DEFAULT FALSE, -- Respondents may edit or withdraw submitted responses while the survey is open
+    template_visibility VARCHAR(10) NOT NULL DEFAULT '', -- '' for surveys that are not templates, else 'private', 'org' or 'global'
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_surveys_org_id ON surveys(org_id);
+
+-- Sections split a survey into pages of consecutive questions
+CREATE TABLE IF NOT EXISTS survey_sections (
+    id SERIAL PRIMARY KEY,
+    survey_id INT REFERENCES surveys(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    order_num INT NOT NULL,
+    randomize_questions BOOLEAN NOT NULL DEFAULT FALSE, -- Each respondent sees the section's questions in their own random order
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
@@ -41,10 +81,15 @@ CREATE TABLE IF NOT EXISTS surveys (
 CREATE TABLE IF NOT EXISTS questions (
     id SERIAL PRIMARY KEY,
     survey_id INT REFERENCES surveys(id) ON DELETE CASCADE,
+    section_id INT REFERENCES survey_sections(id) ON DELETE SET NULL, -- NULL for surveys without sections
     text TEXT NOT NULL,
     type VARCHAR(50) NOT NULL, -- 'text', 'single_choice', etc.
     required BOOLEAN DEFAULT FALSE,
     order_num INT NOT NULL,
+    display_rules JSONB NOT NULL DEFAULT '[]', -- Conditions on earlier answers for showing the question
+    skip_rules JSONB NOT NULL DEFAULT '[]', -- Jumps to later questions depending on this question's answer
+    config JSONB NOT NULL DEFAULT '{}', -- Type-specific settings, validated against the question type registry
+    randomize_options BOOLEAN NOT NULL DEFAULT FALSE, -- Each respondent sees the options in their own random order
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
@@ -58,6 +103,54 @@ CREATE TABLE IF NOT EXISTS question_options (
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Immutable snapshots of each published state of a survey
+CREATE TABLE IF NOT EXISTS survey_versions (
+    id SERIAL PRIMARY KEY,
+    survey_id INT REFERENCES surveys(id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    snapshot JSONB NOT NULL,
+    created_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (survey_id, version)
+);
+
+-- Transitions of a survey's effective status, manual or made by the scheduler
+CREATE TABLE IF NOT EXISTS survey_status_history (
+    id SERIAL PRIMARY KEY,
+    survey_id INT REFERENCES surveys(id) ON DELETE CASCADE,
+    from_status VARCHAR(20) NOT NULL,
+    to_status VARCHAR(20) NOT NULL,
+    reason VARCHAR(50) NOT NULL, -- 'manual', 'start_date', 'end_date', 'response_quota'
+    changed_by INT REFERENCES users(id) ON DELETE SET NULL,
+    changed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_survey_status_history_survey_id ON survey_status_history(survey_id, changed_at);
+
+-- Audit trail of surveys handed from one user to another
+CREATE TABLE IF NOT EXISTS survey_ownership_transfers (
+    id SERIAL PRIMARY KEY,
+    survey_id INT REFERENCES surveys(id) ON DELETE CASCADE,
+    from_user_id INT REFERENCES users(id) ON DELETE SET NULL,
+    to_user_id INT REFERENCES users(id) ON DELETE SET NULL,
+    transferred_by INT REFERENCES users(id) ON DELETE SET NULL, -- The previous owner or an admin
+    transferred_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_survey_ownership_transfers_survey_id ON survey_ownership_transfers(survey_id, transferred_at);
+
+-- Users invited to work on someone else's survey
+CREATE TABLE IF NOT EXISTS survey_collaborators (
+    survey_id INT REFERENCES surveys(id) ON DELETE CASCADE,
+    user_id INT REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(10) NOT NULL, -- 'viewer', 'editor' or 'analyst'
+    invited_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (survey_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_survey_collaborators_user_id ON survey_collaborators(user_id);
+
 -- Insert default roles
 INSERT INTO roles (name) VALUES ('researcher') ON CONFLICT DO NOTHING;
 INSERT INTO roles (name) VALUES ('respondent') ON CONFLICT DO NOTHING;
@@ -14,13 +14,27 @@ export const surveyApi = {
   getSurveys: () => api.get('/api/v1/surveys'),
   getAllSurveys: (params) => api.get('/api/v1/surveys/all', { params }),
   getUserSurveys: (params) => api.get('/api/v1/surveys/me', { params }),
-  getSurvey: (id) => api.get(`/api/v1/surveys/${id}`),
+  getSurvey: (id, params) => api.get(`/api/v1/surveys/${id}`, { params }),
   createSurvey: (surveyData) => api.post('/api/v1/surveys', surveyData),
   updateSurvey: (id, surveyData) => api.put(`/api/v1/surveys/${id}`, surveyData),
   deleteSurvey: (id) => api.delete(`/api/v1/surveys/${id}`),
   updateSurveyStatus: (id, isActive) => api.patch(`/api/v1/surveys/${id}/status`, { is_active: isActive }),
   getSurveyAnalytics: (id) => api.get(`/api/v1/surveys/${id}/analytics`),
   getSurveyResponses: (id, params) => api.get(`/api/v1/surveys/${id}/responses`, { params }),
+  setSurveyTemplate: (id, visibility) => api.put(`/api/v1/surveys/${id}/template`, { visibility }),
+  unsetSurveyTemplate: (id) => api.delete(`/api/v1/surveys/${id}/template`),
+  exportSurveyDefinition: (id, format = 'yaml') => api.get(`/api/v1/surveys/${id}/definition`, { params: { format }, responseType: 'blob' }),
+  importSurveyDefinition: (file) => api.post('/api/v1/surveys/import', file, {
+    headers: { 'Content-Type': /\.ya?ml$/i.test(file.name) ? 'application/yaml' : 'application/json' }
+  }),
+  importExternalSurvey: (format, file) => api.post(`/api/v1/surveys/import/${format}`, file, {
+    headers: { 'Content-Type': 'application/octet-stream' }
+  }),
+  cloneSurvey: (id, title) => api.post(`/api/v1/surveys/${id}/clone`, { title }),
+  transferSurvey: (id, newOwnerEmail) => api.post(`/api/v1/surveys/${id}/transfer`, { new_owner_email: newOwnerEmail }),
+  listCollaborators: (id) => api.get(`/api/v1/surveys/${id}/collaborators`),
+  inviteCollaborator: (id, email, role) => api.post(`/api/v1/surveys/${id}/collaborators`, { email, role }),
+  removeCollaborator: (id, userId) => api.delete(`/api/v1/surveys/${id}/collaborators/${userId}`),
   
   // Question related endpoints
   addQuestion: (surveyId, questionData) => api.post(`/api/v1/surveys/${surveyId}/questions`, questionData),
@@ -28,9 +42,41 @@ export const surveyApi = {
   deleteQuestion: (questionId) => api.delete(`/api/v1/questions/${questionId}`)
 }
 
+// Template library endpoints
+export const templateApi = {
+  getTemplates: (params) => api.get('/api/v1/templates', { params }),
+  instantiateTemplate: (id, title) => api.post(`/api/v1/templates/${id}/instantiate`, { title })
+}
+
+// Organization endpoints
+export const orgApi = {
+  getOrganizations: () => api.get('/api/v1/orgs'),
+  createOrganization: (orgData) => api.post('/api/v1/orgs', orgData),
+  getMembers: (orgId) => api.get(`/api/v1/orgs/${orgId}/members`),
+  addMember: (orgId, memberData) => api.post(`/api/v1/orgs/${orgId}/members`, memberData),
+  removeMember: (orgId, userId) => api.delete(`/api/v1/orgs/${orgId}/members/${userId}`),
+  switchOrganization: (orgId) => api.post(`/api/v1/orgs/${orgId ?? 'personal'}/switch`)
+}
+
+const draftHeaders = (draftToken) => (draftToken ? { 'X-Draft-Token': draftToken } : {})
+
 // Response endpoints
 export const responseApi = {
   submitResponse: (responseData) => api.post('/api/v1/responses', responseData),
+  saveDraft: (draftData) => api.post('/api/v1/responses/drafts', draftData),
+  // Anonymous respondents send back the draftToken they got when saving the draft
+  getDraft: (draftId, draftToken) => api.get(`/api/v1/responses/drafts/${draftId}`, { headers: draftHeaders(draftToken) }),
+  getMyDraft: (surveyId) => api.get(`/api/v1/surveys/${surveyId}/responses/draft`),
+  updateDraft: (draftId, answers, draftToken) => api.put(`/api/v1/responses/drafts/${draftId}`, { answers }, { headers: draftHeaders(draftToken) }),
+  submitDraft: (draftId, data, draftToken) => api.post(`/api/v1/responses/drafts/${draftId}/submit`, data, { headers: draftHeaders(draftToken) }),
+  updateResponse: (responseId, answers) => api.put(`/api/v1/responses/${responseId}`, { answers }),
+  withdrawResponse: (responseId) => api.delete(`/api/v1/responses/${responseId}`),
+  uploadFile: (surveyId, questionId, file) => {
+    const form = new FormData();
+    form.append('file', file);
+    return api.post(`/api/v1/surveys/${surveyId}/questions/${questionId}/uploads`, form);
+  },
+  validatePage: (surveyId, sectionId, answers) => api.post(`/api/v1/surveys/${surveyId}/sections/${sectionId}/validate`, { answers }),
   getResponses: (surveyId) => api.get('/api/v1/responses', { params: { survey_id: surveyId } }),
   getResponseSummary: (surveyId) => api.get('/api/v1/responses/summary', { params: { survey_id: surveyId } }),
   getResponsesCount: (surveyId) => api.get('/api/v1/responses/count', { params: { survey_id: surveyId } })
@@ -7,12 +7,14 @@ export const useAuthStore = defineStore('auth', {
     user: null,
     token: localStorage.getItem('token') || null,
     refreshToken: localStorage.getItem('refreshToken') || null,
+    orgId: Number(localStorage.getItem('orgId')) || null,
   }),
   
   getters: {
     isAuthenticated: (state) => !!state.token,
     getUser: (state) => state.user,
     getToken: (state) => state.token,
+    getOrgId: (state) => state.orgId,
   },
   
   actions: {
@@ -70,13 +72,32 @@ export const useAuthStore = defineStore('auth', {
       }
     },
     
+    // Switches to the organization orgId, or to the personal workspace when orgId is null
+    async switchOrganization(orgId) {
+      try {
+        const response = await axios.post(`/api/v1/orgs/${orgId ?? 'personal'}/switch`)
+        
+        this.setAuthData(response.data)
+        return response.data
+      } catch (error) {
+        throw error.response ? error.response.data : error
+      }
+    },
+    
     setAuthData(data) {
       this.token = data.token
       this.refreshToken = data.refresh_token
       this.user = data.user
+      // The organization the tokens are scoped to, if any
+      this.orgId = data.user?.org_id || null
       
       localStorage.setItem('token', data.token)
       localStorage.setItem('refreshToken', data.refresh_token)
+      if (this.orgId) {
+        localStorage.setItem('orgId', this.orgId)
+      } else {
+        localStorage.removeItem('orgId')
+      }
       
       // Set axios default headers
       axios.defaults.headers.common['Authorization'] = `Bearer ${data.token}`
@@ -86,9 +107,11 @@ export const useAuthStore = defineStore('auth', {
       this.user = null
       this.token = null
       this.refreshToken = null
+      this.orgId = null
       
       localStorage.removeItem('token')
       localStorage.removeItem('refreshToken')
+      localStorage.removeItem('orgId')
       
       // Remove axios default headers
       delete axios.defaults.headers.common['Authorization']
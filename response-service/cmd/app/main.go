@@ -12,6 +12,7 @@ import (
 	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/handlers"
 	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/repository"
 	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/service"
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/storage"
 )
 
 func main() {
@@ -39,12 +40,35 @@ func main() {
 	// Initialize service
 	responseService := service.NewResponseService(mongoRepo, cfg.SurveyServiceURL)
 
+	// Initialize storage for the files uploaded to file_upload questions
+	fileStore, err := storage.New(cfg.StorageBackend, cfg.UploadDir)
+	if err != nil {
+		log.Fatalf("Failed to initialize file storage: %v", err)
+	}
+	responseService.UseFileStorage(fileStore, cfg.MaxUploadSize)
+	responseService.LimitUploads(service.UploadQuota{
+		MaxFiles: int(cfg.UploadQuotaFiles),
+		MaxBytes: cfg.UploadQuotaBytes,
+		Window:   cfg.UploadQuotaWindow,
+	})
+
+	// Delete the uploads of responses that were never saved, so anonymous uploads cannot fill the storage
+	go responseService.RunFileCleanup(context.Background(), cfg.UploadCleanupInterval, cfg.UploadTTL)
+
 	// Initialize handler
 	responseHandler := handlers.NewResponseHandler(responseService)
 
 	// Initialize router
 	router := gin.Default()
 
+	// Client addresses in X-Forwarded-For are only trusted from the gateway, which sets them itself
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+	if cfg.ServiceToken == "" {
+		log.Println("SERVICE_TOKEN is not set; internal service routes will reject every request")
+	}
+
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
@@ -57,6 +81,22 @@ func main() {
 	api := router.Group("/api/v1")
 	{
 		api.POST("/responses", responseHandler.SubmitResponse)
+
+		// Drafts let respondents save a partially completed response and resume it later
+		api.POST("/responses/drafts", responseHandler.SaveDraft)
+		api.GET("/responses/drafts/:id", responseHandler.GetDraft)
+		api.PUT("/responses/drafts/:id", responseHandler.UpdateDraft)
+		api.POST("/responses/drafts/:id/submit", responseHandler.FinalizeDraft)
+		api.GET("/surveys/:surveyId/responses/draft", responseHandler.GetUserDraft)
+		api.POST("/surveys/:surveyId/sections/:sectionId/validate", responseHandler.ValidatePage) // Per-page validation of multi-page surveys
+
+		// Editing or withdrawing a submitted response, when the survey allows it
+		api.PUT("/responses/:id", responseHandler.UpdateResponse)
+		api.DELETE("/responses/:id", responseHandler.WithdrawResponse)
+
+		// Files answering file_upload questions: uploaded before the response is submitted, downloaded by the survey owner
+		api.POST("/surveys/:surveyId/questions/:questionId/uploads", responseHandler.UploadFile)
+		api.GET("/responses/:id/files/:questionId", responseHandler.DownloadResponseFile)
 		// This new route is added to fetch responses for a specific survey
 		// It needs to be distinct from POST /responses and likely grouped under surveys logically
 		// e.g. /api/v1/surveys/:surveyId/responses
@@ -67,6 +107,9 @@ func main() {
 		// Route for exporting survey responses as CSV
 		api.GET("/surveys/:surveyId/responses/export", responseHandler.ExportSurveyResponsesCSV)
 
+		// Route for the survey-service scheduler to check response quotas (internal, not proxied by the gateway)
+		api.GET("/surveys/:surveyId/responses/count", handlers.RequireServiceToken(cfg.ServiceToken), responseHandler.CountSurveyResponsesHandler)
+
 		// Route for survey analytics
 		api.GET("/surveys/:surveyId/analytics", responseHandler.GetSurveyAnalytics)
 
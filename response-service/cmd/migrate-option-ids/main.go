@@ -0,0 +1,57 @@
+// Command migrate-option-ids stores option IDs with the choice, checkbox and ranking answers saved before
+// answers carried them, by matching their texts to the options of the survey. It is safe to run more than once.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/joho/godotenv"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/config"
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/contextkeys"
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/repository"
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/service"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report what would change without writing to MongoDB")
+	adminID := flag.Int("admin-id", 0, "ID of the admin account the surveys are read as (required)")
+	flag.Parse()
+	if *adminID <= 0 {
+		log.Fatal("-admin-id is required")
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables from compose or K8s")
+	}
+	cfg := config.New()
+
+	mongoRepo, err := repository.NewMongoRepository(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize MongoDB repository: %v", err)
+	}
+	defer func() {
+		if err := mongoRepo.Disconnect(context.Background()); err != nil {
+			log.Printf("Error disconnecting from MongoDB: %v", err)
+		}
+	}()
+
+	responseService := service.NewResponseService(mongoRepo, cfg.SurveyServiceURL)
+
+	// Surveys are read as an admin, so inactive surveys and surveys of every owner are migrated too
+	ctx := context.WithValue(context.Background(), contextkeys.UserIDKey, *adminID)
+	ctx = context.WithValue(ctx, contextkeys.UserRolesKey, []string{"admin"})
+	report, err := responseService.MigrateOptionIDs(ctx, *dryRun)
+	if err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+
+	action := "Updated"
+	if *dryRun {
+		action = "Would update"
+	}
+	log.Printf("%s %d of %d responses in %d surveys; skipped %d unavailable surveys; %d answers match no option and were left as they are",
+		action, report.UpdatedResponses, report.Responses, report.Surveys, report.SkippedSurveys, report.UnmatchedAnswers)
+}
@@ -45,3 +45,7 @@ require (
 	google.golang.org/protobuf v1.28.1 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+require github.com/VitaliySynytskyi/survey-platform/shared v0.0.0
+
+replace github.com/VitaliySynytskyi/survey-platform/shared => ../shared
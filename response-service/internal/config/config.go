@@ -3,6 +3,9 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -13,7 +16,21 @@ type Config struct {
 	MongoDBURI         string
 	MongoDBName        string
 	ResponseCollection string
+	CounterCollection  string // Per-survey counts of submitted responses, which enforce max_responses
 	SurveyServiceURL   string
+	StorageBackend     string   // Where uploaded files are kept, see storage.New
+	UploadDir          string   // Root directory of the local storage backend
+	MaxUploadSize      int64    // Bytes; caps the size limit of every file_upload question
+	TrustedProxies     []string // Addresses of the API gateway, the only source trusted to report client addresses
+	ServiceToken       string   // Shared with survey-service; required on routes only other services call
+
+	// Per-respondent upload quota, see service.UploadQuota
+	UploadQuotaFiles  int64
+	UploadQuotaBytes  int64
+	UploadQuotaWindow time.Duration
+
+	UploadTTL             time.Duration // How long an upload no response references is kept
+	UploadCleanupInterval time.Duration // How often such uploads are looked for
 }
 
 // New creates a new Config instance
@@ -27,7 +44,20 @@ func New() *Config {
 		MongoDBURI:         getEnv("MONGO_URI", "mongodb://localhost:27017"),
 		MongoDBName:        getEnv("MONGO_DATABASE", "survey_responses_db"),
 		ResponseCollection: getEnv("MONGO_RESPONSE_COLLECTION", "responses"),
+		CounterCollection:  getEnv("MONGO_COUNTER_COLLECTION", "response_counters"),
 		SurveyServiceURL:   getEnv("SURVEY_SERVICE_URL", "http://survey-service:8082"),
+		StorageBackend:     getEnv("STORAGE_BACKEND", "local"),
+		UploadDir:          getEnv("UPLOAD_DIR", "/data/uploads"),
+		MaxUploadSize:      getEnvInt64("UPLOAD_MAX_BYTES", 50<<20),
+		TrustedProxies:     getEnvList("TRUSTED_PROXIES", "127.0.0.1,::1"),
+		ServiceToken:       getEnv("SERVICE_TOKEN", ""),
+
+		UploadQuotaFiles:  getEnvInt64("UPLOAD_QUOTA_FILES", 20),
+		UploadQuotaBytes:  getEnvInt64("UPLOAD_QUOTA_BYTES", 200<<20),
+		UploadQuotaWindow: getEnvDuration("UPLOAD_QUOTA_WINDOW", time.Hour),
+
+		UploadTTL:             getEnvDuration("UPLOAD_TTL", 24*time.Hour),
+		UploadCleanupInterval: getEnvDuration("UPLOAD_CLEANUP_INTERVAL", time.Hour),
 	}
 }
 
@@ -37,3 +67,40 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+func getEnvInt64(key string, fallback int64) int64 {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		log.Printf("Invalid %s %q, using %d: %v", key, value, fallback, err)
+		return fallback
+	}
+	return parsed
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed <= 0 {
+		log.Printf("Invalid %s %q, using %s", key, value, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+// getEnvList reads a comma-separated list
+func getEnvList(key, fallback string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(key, fallback), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
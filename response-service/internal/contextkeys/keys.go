@@ -8,6 +8,12 @@ const (
 	UserIDKey ContextKey = "userID"
 	// UserRolesKey is the context key for the user's roles.
 	UserRolesKey ContextKey = "userRoles"
+	// OrgIDKey is the context key for the ID of the organization the user works in, absent outside every organization.
+	OrgIDKey ContextKey = "orgID"
+	// DraftTokenKey is the context key for the token an anonymous respondent got when saving their draft.
+	DraftTokenKey ContextKey = "draftToken"
+	// ClientIPKey is the context key for the address of the client, as reported by the gateway.
+	ClientIPKey ContextKey = "clientIP"
 	// AuthorizationHeaderKey is the context key for the Authorization header.
 	AuthorizationHeaderKey ContextKey = "authorizationHeader"
 )
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/contextkeys"
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/repository"
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// SaveDraft handles POST requests to /responses/drafts
+func (h *ResponseHandler) SaveDraft(c *gin.Context) {
+	var req models.SaveDraftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request body: %s", err.Error())})
+		return
+	}
+
+	ctx, userID := respondentContext(c)
+	req.UserID = userID
+
+	draft, err := h.responseService.SaveDraft(ctx, &req)
+	if err != nil {
+		log.Printf("[HANDLER_ERROR] SaveDraft: Service call failed for SurveyID %d: %v", req.SurveyID, err)
+		writeRespondentError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, draft)
+}
+
+// GetDraft handles GET requests to /responses/drafts/:id
+func (h *ResponseHandler) GetDraft(c *gin.Context) {
+	ctx, _ := respondentContext(c)
+	draft, err := h.responseService.GetDraft(ctx, c.Param("id"))
+	if err != nil {
+		writeRespondentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, draft)
+}
+
+// GetUserDraft handles GET requests to /surveys/:surveyId/responses/draft
+func (h *ResponseHandler) GetUserDraft(c *gin.Context) {
+	surveyID, err := strconv.Atoi(c.Param("surveyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey_id format"})
+		return
+	}
+
+	ctx, _ := respondentContext(c)
+	draft, err := h.responseService.GetUserDraft(ctx, surveyID)
+	if err != nil {
+		writeRespondentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, draft)
+}
+
+// UpdateDraft handles PUT requests to /responses/drafts/:id
+func (h *ResponseHandler) UpdateDraft(c *gin.Context) {
+	var req models.UpdateResponseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request body: %s", err.Error())})
+		return
+	}
+
+	ctx, _ := respondentContext(c)
+	draft, err := h.responseService.UpdateDraft(ctx, c.Param("id"), &req)
+	if err != nil {
+		log.Printf("[HANDLER_ERROR] UpdateDraft: Service call failed for draft %s: %v", c.Param("id"), err)
+		writeRespondentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, draft)
+}
+
+// FinalizeDraft handles POST requests to /responses/drafts/:id/submit
+func (h *ResponseHandler) FinalizeDraft(c *gin.Context) {
+	var req models.FinalizeDraftRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request body: %s", err.Error())})
+			return
+		}
+	}
+	req.ClientIP = c.ClientIP()
+	if req.DeviceToken == "" {
+		req.DeviceToken = c.GetHeader("X-Device-Token")
+	}
+
+	ctx, _ := respondentContext(c)
+	response, err := h.responseService.FinalizeDraft(ctx, c.Param("id"), &req)
+	if err != nil {
+		log.Printf("[HANDLER_ERROR] FinalizeDraft: Service call failed for draft %s: %v", c.Param("id"), err)
+		writeRespondentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateResponse handles PUT requests to /responses/:id
+func (h *ResponseHandler) UpdateResponse(c *gin.Context) {
+	var req models.UpdateResponseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request body: %s", err.Error())})
+		return
+	}
+
+	ctx, _ := respondentContext(c)
+	response, err := h.responseService.UpdateResponse(ctx, c.Param("id"), &req)
+	if err != nil {
+		log.Printf("[HANDLER_ERROR] UpdateResponse: Service call failed for response %s: %v", c.Param("id"), err)
+		writeRespondentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// WithdrawResponse handles DELETE requests to /responses/:id
+func (h *ResponseHandler) WithdrawResponse(c *gin.Context) {
+	ctx, _ := respondentContext(c)
+	if err := h.responseService.WithdrawResponse(ctx, c.Param("id")); err != nil {
+		log.Printf("[HANDLER_ERROR] WithdrawResponse: Service call failed for response %s: %v", c.Param("id"), err)
+		writeRespondentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Response withdrawn successfully"})
+}
+
+// ValidatePage handles POST requests to /surveys/:surveyId/sections/:sectionId/validate
+func (h *ResponseHandler) ValidatePage(c *gin.Context) {
+	surveyID, err := strconv.Atoi(c.Param("surveyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey_id format"})
+		return
+	}
+	sectionID, err := strconv.Atoi(c.Param("sectionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid section_id format"})
+		return
+	}
+
+	var req models.ValidatePageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request body: %s", err.Error())})
+		return
+	}
+
+	ctx, _ := respondentContext(c)
+	if err := h.responseService.ValidatePage(ctx, surveyID, sectionID, &req); err != nil {
+		writeRespondentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"valid": true})
+}
+
+// respondentContext adds the identity forwarded by the gateway to the request context.
+// Requests without X-User-ID are anonymous, and identify their draft with X-Draft-Token.
+func respondentContext(c *gin.Context) (context.Context, *int) {
+	ctx := c.Request.Context()
+	if token := c.GetHeader("X-Draft-Token"); token != "" {
+		ctx = context.WithValue(ctx, contextkeys.DraftTokenKey, token)
+	}
+	var userID *int
+	if uid, err := strconv.Atoi(c.GetHeader("X-User-ID")); err == nil {
+		ctx = context.WithValue(ctx, contextkeys.UserIDKey, uid)
+		userID = &uid
+	}
+	if roles := parseRolesHeader(c.GetHeader("X-User-Roles")); len(roles) > 0 {
+		ctx = context.WithValue(ctx, contextkeys.UserRolesKey, roles)
+	}
+	return withOrgID(ctx, c), userID
+}
+
+// writeRespondentError maps errors of the draft and response editing operations to HTTP statuses
+func writeRespondentError(c *gin.Context, err error) {
+	var validationErr *service.AnswerValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Some answers are invalid", "details": validationErr.Errors})
+	case errors.Is(err, service.ErrDeviceTokenRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrAuthenticationRequired):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotResponseOwner), errors.Is(err, service.ErrResponseEditsNotAllowed):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrResponseNotFound), errors.Is(err, service.ErrSectionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrSurveyNotOpen), errors.Is(err, service.ErrResponseLimitReached),
+		errors.Is(err, service.ErrResponseNotDraft), errors.Is(err, service.ErrResponseIsDraft):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "not active") || strings.Contains(err.Error(), "not found"):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
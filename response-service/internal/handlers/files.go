@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/contextkeys"
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// uploadFormField is the multipart form field carrying an uploaded file
+const uploadFormField = "file"
+
+// UploadFile handles POST requests to /surveys/:surveyId/questions/:questionId/uploads.
+// The file is streamed from the multipart body into storage without being buffered.
+func (h *ResponseHandler) UploadFile(c *gin.Context) {
+	surveyID, err := strconv.Atoi(c.Param("surveyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey_id format"})
+		return
+	}
+	questionID, err := strconv.Atoi(c.Param("questionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question_id format"})
+		return
+	}
+
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Expected a multipart/form-data body"})
+		return
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No file in the \"" + uploadFormField + "\" field"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid multipart body: " + err.Error()})
+			return
+		}
+		if part.FormName() != uploadFormField || part.FileName() == "" {
+			part.Close()
+			continue
+		}
+
+		ctx, _ := respondentContext(c)
+		// Anonymous uploads count against the quota of the client's address
+		ctx = context.WithValue(ctx, contextkeys.ClientIPKey, c.ClientIP())
+		ref, err := h.responseService.UploadFile(ctx, surveyID, questionID, part.FileName(), part)
+		part.Close()
+		if err != nil {
+			log.Printf("[HANDLER_ERROR] UploadFile: Upload for SurveyID %d, QuestionID %d failed: %v", surveyID, questionID, err)
+			writeFileError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, ref)
+		return
+	}
+}
+
+// DownloadResponseFile handles GET requests to /responses/:id/files/:questionId, serving a respondent's upload to the survey owner
+func (h *ResponseHandler) DownloadResponseFile(c *gin.Context) {
+	questionID, err := strconv.Atoi(c.Param("questionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question_id format"})
+		return
+	}
+
+	ctx, _ := respondentContext(c)
+	content, ref, err := h.responseService.OpenResponseFile(ctx, c.Param("id"), questionID)
+	if err != nil {
+		log.Printf("[HANDLER_ERROR] DownloadResponseFile: Failed to open file of response %s, QuestionID %d: %v", c.Param("id"), questionID, err)
+		writeFileError(c, err)
+		return
+	}
+	defer content.Close()
+
+	// Always an attachment, and never sniffed, so uploaded HTML or SVG cannot run in the owner's browser
+	c.DataFromReader(http.StatusOK, ref.Size, ref.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": ref.Name}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// writeFileError maps errors of file uploads and downloads to HTTP statuses
+func writeFileError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrFileTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrUploadQuotaExceeded):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrFileTypeNotAllowed):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotFileQuestion):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrResponseAccessDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrFileNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrFileStorageUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		writeRespondentError(c, err)
+	}
+}
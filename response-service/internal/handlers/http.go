@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"context"
+	"crypto/subtle"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -47,10 +49,9 @@ func (h *ResponseHandler) SubmitResponse(c *gin.Context) {
 			log.Printf("[HANDLER_WARN] SubmitResponse: X-User-ID header '%s' is not a valid integer: %v", xUserIDStr, convErr)
 		}
 	} else {
-		log.Printf("[HANDLER_INFO] SubmitResponse: No X-User-ID header found. UserID from request body (if any) will be used: %v", req.UserID)
-		if req.UserID != nil {
-			ctx = context.WithValue(ctx, contextkeys.UserIDKey, *req.UserID)
-		}
+		// Only the gateway vouches for identity, so a UserID in the body of an anonymous request is ignored
+		log.Printf("[HANDLER_INFO] SubmitResponse: No X-User-ID header found. Submitting anonymously.")
+		req.UserID = nil
 	}
 
 	xUserRolesStr := c.GetHeader("X-User-Roles")
@@ -67,9 +68,24 @@ func (h *ResponseHandler) SubmitResponse(c *gin.Context) {
 		log.Printf("[HANDLER_INFO] SubmitResponse: No X-User-Roles header found.")
 	}
 
+	// The gateway sets X-Forwarded-For to the address it saw; ClientIP only honours it from TRUSTED_PROXIES
+	req.ClientIP = c.ClientIP()
+	if req.DeviceToken == "" {
+		req.DeviceToken = c.GetHeader("X-Device-Token")
+	}
+
 	if err := h.responseService.SubmitResponse(ctx, &req); err != nil {
 		log.Printf("[HANDLER_ERROR] SubmitResponse: Service call failed: %v", err)
-		if strings.Contains(err.Error(), "not active") || strings.Contains(err.Error(), "not found") {
+		var validationErr *service.AnswerValidationError
+		if errors.Is(err, service.ErrAuthenticationRequired) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		} else if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Some answers are invalid", "details": validationErr.Errors})
+		} else if errors.Is(err, service.ErrSurveyNotOpen) || errors.Is(err, service.ErrResponseLimitReached) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else if errors.Is(err, service.ErrDeviceTokenRequired) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "not active") || strings.Contains(err.Error(), "not found") {
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to submit response: %s", err.Error())})
@@ -96,6 +112,14 @@ func parseRolesHeader(headerValue string) []string {
 	return []string{}
 }
 
+// withOrgID adds the organization forwarded by the gateway in X-Org-ID to ctx. Users outside every organization have none.
+func withOrgID(ctx context.Context, c *gin.Context) context.Context {
+	if orgID, err := strconv.Atoi(c.GetHeader("X-Org-ID")); err == nil {
+		return context.WithValue(ctx, contextkeys.OrgIDKey, orgID)
+	}
+	return ctx
+}
+
 // GetSurveyResponsesHandler handles GET requests to /surveys/:surveyId/responses
 func (h *ResponseHandler) GetSurveyResponsesHandler(c *gin.Context) {
 	surveyIDStr := c.Param("surveyId")
@@ -118,8 +142,13 @@ func (h *ResponseHandler) GetSurveyResponsesHandler(c *gin.Context) {
 			ctx = context.WithValue(ctx, contextkeys.UserRolesKey, roles)
 		}
 	}
+	ctx = withOrgID(ctx, c)
 
 	responses, err := h.responseService.GetSurveyResponses(ctx, surveyID)
+	if errors.Is(err, service.ErrResponseAccessDenied) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to get survey responses: %s", err.Error())})
 		return
@@ -128,6 +157,37 @@ func (h *ResponseHandler) GetSurveyResponsesHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, responses)
 }
 
+// RequireServiceToken guards routes only other services call: requests must carry the token the services share
+// in X-Service-Token. Without a configured token every request is rejected.
+func RequireServiceToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" || subtle.ConstantTimeCompare([]byte(c.GetHeader("X-Service-Token")), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "A valid service token is required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// CountSurveyResponsesHandler handles GET requests to /surveys/:surveyId/responses/count.
+// It is called by the survey-service scheduler, with the service token, and is not exposed through the API gateway.
+func (h *ResponseHandler) CountSurveyResponsesHandler(c *gin.Context) {
+	surveyID, err := strconv.Atoi(c.Param("surveyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey_id format"})
+		return
+	}
+
+	count, err := h.responseService.CountSurveyResponses(c.Request.Context(), surveyID)
+	if err != nil {
+		log.Printf("[HANDLER_ERROR] CountSurveyResponsesHandler: Failed to count responses for SurveyID %d: %v", surveyID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to count survey responses: %s", err.Error())})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"survey_id": surveyID, "count": count})
+}
+
 // ExportSurveyResponsesCSV handles GET requests to /surveys/:surveyId/responses/export
 func (h *ResponseHandler) ExportSurveyResponsesCSV(c *gin.Context) {
 	surveyIDStr := c.Param("surveyId")
@@ -169,12 +229,15 @@ func (h *ResponseHandler) ExportSurveyResponsesCSV(c *gin.Context) {
 			ctx = context.WithValue(ctx, contextkeys.UserRolesKey, roles)
 		}
 	}
+	ctx = withOrgID(ctx, c)
 
 	csvData, filename, err := h.responseService.ExportSurveyResponsesCSV(ctx, surveyID)
 	if err != nil {
 		log.Printf("[HANDLER_ERROR] ExportSurveyResponsesCSV: Service call failed for SurveyID %d: %v", surveyID, err)
 		// Check for specific error types if needed, e.g., survey not found
-		if strings.Contains(err.Error(), "not found") { // Basic check, could be more robust
+		if errors.Is(err, service.ErrResponseAccessDenied) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "not found") { // Basic check, could be more robust
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to export responses: %s", err.Error())})
@@ -213,10 +276,13 @@ func (h *ResponseHandler) GetSurveyAnalytics(c *gin.Context) {
 			ctx = context.WithValue(ctx, contextkeys.UserRolesKey, roles)
 		}
 	}
+	ctx = withOrgID(ctx, c)
 
 	analytics, err := h.responseService.GetSurveyAnalytics(ctx, surveyID)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
+		if errors.Is(err, service.ErrResponseAccessDenied) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "not found") {
 			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Analytics not found or survey does not exist: %s", err.Error())})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to get survey analytics: %s", err.Error())})
@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/repository"
 	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/service"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -290,7 +291,7 @@ func TestGetResponseByID(t *testing.T) {
 		responseID := primitive.NewObjectID().Hex()
 
 		// Set up mock service expectation
-		mockService.On("GetResponseByID", mock.Anything, responseID).Return(nil, service.ErrResponseNotFound).Once()
+		mockService.On("GetResponseByID", mock.Anything, responseID).Return(nil, repository.ErrResponseNotFound).Once()
 
 		// Create request
 		req, _ := http.NewRequest("GET", "/api/v1/responses/"+responseID, nil)
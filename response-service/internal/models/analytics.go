@@ -15,6 +15,76 @@ type QuestionAnalytics struct {
 	QuestionType   string             `json:"question_type"`
 	OptionsSummary []OptionSummary    `json:"options_summary,omitempty"`
 	TextResponses  []TextResponseData `json:"text_responses,omitempty"`
+	RowsSummary    []MatrixRowSummary `json:"rows_summary,omitempty"`    // Matrix questions: the distribution over the columns of each row
+	RankingSummary []RankingSummary   `json:"ranking_summary,omitempty"` // Ranking questions: the standing of each option
+	NumericSummary *NumericSummary    `json:"numeric_summary,omitempty"` // Text questions in the number format: statistics of the answers
+	OtherResponses []TextResponseData `json:"other_responses,omitempty"` // Choice questions with an "Other" option: the texts written for it
+	OrderEffects   *OrderEffects      `json:"order_effects,omitempty"`   // Shuffled questions and options: the answers by the position they were shown at
+}
+
+// OrderEffects compares answers by the position respondents were shown a question or its options at, to reveal
+// whether the order influenced them. Only responses recording a layout are counted.
+type OrderEffects struct {
+	QuestionPositions []QuestionPosition `json:"question_positions,omitempty"` // Set when the question was shown at different positions
+	OptionPositions   []OptionPosition   `json:"option_positions,omitempty"`   // Set when the question's options were shuffled
+}
+
+// QuestionPosition counts the respondents shown a question at one position of the survey, and how many answered it there
+type QuestionPosition struct {
+	Position     int     `json:"position"` // 1 for the first question shown
+	Shown        int     `json:"shown"`
+	Answered     int     `json:"answered"`
+	ResponseRate float64 `json:"response_rate"` // Percentage of Shown
+}
+
+// OptionPosition counts how often the option shown at one position of a question's option list was selected
+type OptionPosition struct {
+	Position   int     `json:"position"` // 1 for the first option shown
+	Shown      int     `json:"shown"`
+	Selected   int     `json:"selected"`
+	Percentage float64 `json:"percentage"` // Percentage of Shown
+}
+
+// NumericSummary describes the distribution of the answers to a numeric question
+type NumericSummary struct {
+	Count       int               `json:"count"`
+	Mean        float64           `json:"mean"`
+	Median      float64           `json:"median"`
+	StdDev      float64           `json:"std_dev"` // Sample standard deviation, 0 for a single answer
+	Min         float64           `json:"min"`
+	Max         float64           `json:"max"`
+	Percentiles []PercentileValue `json:"percentiles"`
+	Histogram   []HistogramBucket `json:"histogram"`
+}
+
+// PercentileValue is the value below which Percentile percent of the answers fall
+type PercentileValue struct {
+	Percentile int     `json:"percentile"`
+	Value      float64 `json:"value"`
+}
+
+// HistogramBucket counts the answers from From up to To; only the last bucket includes To
+type HistogramBucket struct {
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Count int     `json:"count"`
+}
+
+// RankingSummary aggregates the ranks respondents gave one option of a ranking question
+type RankingSummary struct {
+	OptionID     *int    `json:"option_id,omitempty"`
+	OptionText   string  `json:"option_text"`
+	Ranked       int     `json:"ranked"`        // Respondents who ranked the option
+	MeanRank     float64 `json:"mean_rank"`     // Average rank among them (1 is first), 0 if nobody ranked it
+	BordaScore   int     `json:"borda_score"`   // Sum over all rankings of the options ranked below it; unranked options score 0
+	FirstChoices int     `json:"first_choices"` // Respondents who ranked the option first
+}
+
+// MatrixRowSummary summarises the answers to one row of a matrix question
+type MatrixRowSummary struct {
+	Row            string          `json:"row"`
+	Responses      int             `json:"responses"` // Respondents who answered the row; the percentages are shares of them
+	ColumnsSummary []OptionSummary `json:"columns_summary"`
 }
 
 // OptionSummary provides a summary for a single answer option
@@ -23,6 +93,7 @@ type OptionSummary struct {
 	OptionText string  `json:"option_text"`
 	Count      int     `json:"count"`
 	Percentage float64 `json:"percentage"`
+	IsOther    bool    `json:"is_other,omitempty"` // The entry counts the "Other" option, which has no ID
 }
 
 // TextResponseData holds a single text response
@@ -10,8 +10,29 @@ import (
 type Answer struct {
 	QuestionID int         `bson:"questionId" json:"questionId"`
 	Value      interface{} `bson:"value" json:"value"` // Can be string or []string for checkboxes
+	// OptionIDs lists the options a choice, checkbox or ranking answer selects, in the order of Value.
+	// Value keeps the option texts as they read when the answer was given.
+	OptionIDs []int `bson:"optionIds,omitempty" json:"optionIds,omitempty"`
+	// File is set by the service on answers to file_upload questions, whose Value is the ID of the uploaded file
+	File *FileReference `bson:"file,omitempty" json:"file,omitempty"`
+	// OtherText is what the respondent wrote for the "Other" option of a choice question, selected in Value as "__other__"
+	OtherText string `bson:"otherText,omitempty" json:"otherText,omitempty"`
 }
 
+// FileReference identifies a file uploaded in answer to a file_upload question
+type FileReference struct {
+	ID          string `bson:"id" json:"id"`
+	Name        string `bson:"name" json:"name"`
+	ContentType string `bson:"contentType" json:"contentType"`
+	Size        int64  `bson:"size" json:"size"`
+}
+
+// Response statuses. Responses stored before drafts existed have no status and count as submitted.
+const (
+	ResponseStatusDraft     = "draft"
+	ResponseStatusSubmitted = "submitted"
+)
+
 // Response represents a set of answers submitted for a survey
 type Response struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
@@ -19,6 +40,37 @@ type Response struct {
 	UserID      *int               `bson:"userId,omitempty" json:"userId,omitempty"` // Pointer for optionality
 	SubmittedAt time.Time          `bson:"submittedAt" json:"submittedAt"`
 	Answers     []Answer           `bson:"answers" json:"answers"`
+	// SurveyVersion is the published survey version the answers were given on (0 for responses predating versioning)
+	SurveyVersion int `bson:"surveyVersion,omitempty" json:"surveyVersion,omitempty"`
+	// LimitKeys identify the respondent for each per-respondent limit the survey enforces (e.g. "user:42").
+	// A unique index on (surveyId, limitKeys) rejects a second response with the same key.
+	LimitKeys []string `bson:"limitKeys,omitempty" json:"-"`
+	// Status is ResponseStatusDraft while the respondent is still answering, see IsDraft
+	Status    string    `bson:"status,omitempty" json:"status,omitempty"`
+	UpdatedAt time.Time `bson:"updatedAt,omitempty" json:"updatedAt,omitempty"`
+	// Layout is the order the respondent was shown a survey with shuffled questions or options in
+	Layout *SurveyLayout `bson:"layout,omitempty" json:"layout,omitempty"`
+	// DraftToken is given to anonymous respondents when their draft is created and must be sent back as
+	// X-Draft-Token to resume, update, submit or discard it. It is cleared on submission.
+	DraftToken string `bson:"draftToken,omitempty" json:"draftToken,omitempty"`
+}
+
+// SurveyLayout records the order a respondent was shown a survey in, as laid out by survey-service from a seed
+type SurveyLayout struct {
+	Seed          string        `bson:"seed" json:"seed"`
+	QuestionOrder []int         `bson:"questionOrder" json:"question_order"`                   // Question IDs in the order shown
+	OptionOrders  []OptionOrder `bson:"optionOrders,omitempty" json:"option_orders,omitempty"` // Questions whose options were shuffled
+}
+
+// OptionOrder is the order the options of one question were shown in
+type OptionOrder struct {
+	QuestionID int   `bson:"questionId" json:"question_id"`
+	OptionIDs  []int `bson:"optionIds" json:"option_ids"`
+}
+
+// IsDraft reports whether the response has been saved but not submitted yet
+func (r *Response) IsDraft() bool {
+	return r.Status == ResponseStatusDraft
 }
 
 // CreateResponseRequest defines the structure for submitting a new response
@@ -26,4 +78,42 @@ type CreateResponseRequest struct {
 	SurveyID int      `json:"surveyId" binding:"required"`
 	UserID   *int     `json:"userId,omitempty"` // Optional, for logged-in users
 	Answers  []Answer `json:"answers" binding:"required,dive"`
+	// DeviceToken identifies an anonymous respondent's device on surveys limited to one response per device
+	DeviceToken string `json:"deviceToken,omitempty"`
+	// ClientIP is set by the handler from the request, for surveys limited to one response per IP
+	ClientIP string `json:"-"`
+	// LayoutSeed is the seed the respondent's survey was laid out with, recorded with the response's layout
+	LayoutSeed string `json:"layoutSeed,omitempty"`
+}
+
+// SaveDraftRequest defines the structure for saving a partially completed response
+type SaveDraftRequest struct {
+	SurveyID int      `json:"surveyId" binding:"required"`
+	UserID   *int     `json:"-"` // Set by the handler from X-User-ID
+	Answers  []Answer `json:"answers"`
+	// LayoutSeed is the seed the respondent's survey was laid out with, see CreateResponseRequest
+	LayoutSeed string `json:"layoutSeed,omitempty"`
+}
+
+// ValidatePageRequest carries the answers given so far when a respondent leaves a page of the survey
+type ValidatePageRequest struct {
+	Answers []Answer `json:"answers"`
+}
+
+// UpdateResponseRequest defines the answers replacing those of a draft or a submitted response
+type UpdateResponseRequest struct {
+	Answers []Answer `json:"answers" binding:"required"`
+}
+
+// FinalizeDraftRequest carries the respondent details checked against the survey's response limits when a draft is submitted
+type FinalizeDraftRequest struct {
+	DeviceToken string `json:"deviceToken,omitempty"`
+	ClientIP    string `json:"-"`
+}
+
+// AnswerError describes why the answer to a single question was rejected
+type AnswerError struct {
+	QuestionID int    `json:"questionId"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
 }
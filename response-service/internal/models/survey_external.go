@@ -1,20 +1,75 @@
 package models
 
+import (
+	"encoding/json"
+	"time"
+)
+
 // SurveyDetailsFromService represents the survey structure fetched from survey-service
 type SurveyDetailsFromService struct {
-	ID          int                   `json:"id"`
-	Title       string                `json:"title"`
-	Description string                `json:"description,omitempty"`
-	IsActive    bool                  `json:"is_active"`
-	Questions   []QuestionFromService `json:"questions,omitempty"`
+	ID          int       `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description,omitempty"`
+	IsActive    bool      `json:"is_active"`
+	Status      string    `json:"status"` // 'scheduled', 'open' or 'closed', derived from IsActive and the dates
+	StartDate   time.Time `json:"start_date"`
+	EndDate     time.Time `json:"end_date"`
+	Version     int       `json:"version"`
+	CreatorID   int       `json:"creator_id"`
+	OrgID       *int      `json:"org_id,omitempty"`      // The organization the survey belongs to, nil outside every organization
+	AccessRole  string    `json:"access_role,omitempty"` // The current user's role on the survey: 'owner', 'editor', 'analyst' or 'viewer'
+	// Response limits, see the survey-service Survey model
+	MaxResponses       *int                  `json:"max_responses,omitempty"`
+	OneResponsePerUser bool                  `json:"one_response_per_user"`
+	AnonymousLimit     string                `json:"anonymous_limit,omitempty"`
+	AllowAnonymous     bool                  `json:"allow_anonymous"`
+	AllowResponseEdits bool                  `json:"allow_response_edits"`
+	Sections           []SectionFromService  `json:"sections,omitempty"` // Pages of the survey, ordered by OrderNum
+	Questions          []QuestionFromService `json:"questions,omitempty"`
+	// Layout is set when the survey is fetched for a respondent with a layout seed and shuffles questions or options
+	Layout *SurveyLayout `json:"layout,omitempty"`
+}
+
+// SectionFromService represents a section (page) of a survey fetched from survey-service
+type SectionFromService struct {
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	OrderNum    int    `json:"order_num"`
 }
 
 // QuestionFromService represents a question structure fetched from survey-service
 type QuestionFromService struct {
-	ID      int                         `json:"id"`
-	Text    string                      `json:"text"`
-	Type    string                      `json:"type"` // 'text', 'single_choice', etc.
-	Options []QuestionOptionFromService `json:"options,omitempty"`
+	ID           int                         `json:"id"`
+	Text         string                      `json:"text"`
+	Type         string                      `json:"type"` // 'text', 'single_choice', etc.
+	Required     bool                        `json:"required"`
+	OrderNum     int                         `json:"order_num"`
+	SectionID    *int                        `json:"section_id,omitempty"` // Page the question is shown on
+	Options      []QuestionOptionFromService `json:"options,omitempty"`
+	Config       json.RawMessage             `json:"config,omitempty"`        // Type-specific settings, e.g. the bounds of a linear scale
+	DisplayRules []DisplayRuleFromService    `json:"display_rules,omitempty"` // The question is shown only if every rule matches
+	SkipRules    []SkipRuleFromService       `json:"skip_rules,omitempty"`    // Checked once the question is answered, the first match applies
+}
+
+// DisplayRuleFromService shows a question only when the answer to an earlier question matches.
+// Rules saved before question IDs were stored name the earlier question by OrderNum in Question instead.
+type DisplayRuleFromService struct {
+	QuestionID int    `json:"question_id,omitempty"`
+	Question   int    `json:"question,omitempty"`
+	Operator   string `json:"operator"`            // 'equals', 'not_equals' or 'includes'
+	OptionID   int    `json:"option_id,omitempty"` // The option compared on choice questions, Value is compared otherwise
+	Value      string `json:"value,omitempty"`
+}
+
+// SkipRuleFromService jumps forward to the question SkipToQuestionID when its own question's answer matches.
+// Rules saved before question IDs were stored name the target by OrderNum in SkipTo instead.
+type SkipRuleFromService struct {
+	Operator         string `json:"operator"`
+	OptionID         int    `json:"option_id,omitempty"`
+	Value            string `json:"value,omitempty"`
+	SkipToQuestionID int    `json:"skip_to_question_id,omitempty"`
+	SkipTo           int    `json:"skip_to,omitempty"`
 }
 
 // QuestionOptionFromService represents a question option fetched from survey-service
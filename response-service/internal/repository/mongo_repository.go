@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
@@ -20,11 +22,13 @@ type MongoRepository struct {
 	client     *mongo.Client
 	dbName     string
 	collection *mongo.Collection
+	counters   *mongo.Collection // One document per survey: {_id: surveyID, count: submitted responses}
 }
 
 // NewMongoRepository creates a new MongoRepository instance and connects to MongoDB
 func NewMongoRepository(cfg *config.Config) (*MongoRepository, error) {
-	clientOptions := options.Client().ApplyURI(cfg.MongoDBURI)
+	// Object answers (e.g. matrix rows) decode as maps rather than ordered documents, so they encode back to JSON objects
+	clientOptions := options.Client().ApplyURI(cfg.MongoDBURI).SetBSONOptions(&options.BSONOptions{DefaultDocumentM: true})
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -42,26 +46,62 @@ func NewMongoRepository(cfg *config.Config) (*MongoRepository, error) {
 
 	collection := client.Database(cfg.MongoDBName).Collection(cfg.ResponseCollection)
 
-	return &MongoRepository{
+	repo := &MongoRepository{
 		client:     client,
 		dbName:     cfg.MongoDBName,
 		collection: collection,
-	}, nil
+		counters:   client.Database(cfg.MongoDBName).Collection(cfg.CounterCollection),
+	}
+	if err := repo.ensureIndexes(ctx); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// ensureIndexes creates the indexes backing the per-respondent response limits.
+// The unique index on (surveyId, limitKeys) is the final guard against concurrent duplicate submissions.
+func (r *MongoRepository) ensureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "surveyId", Value: 1}, {Key: "userId", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "surveyId", Value: 1}, {Key: "limitKeys", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"limitKeys": bson.M{"$exists": true}}),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create response indexes: %w", err)
+	}
+	return nil
+}
+
+// submittedFilter matches the submitted responses of a survey. Responses without a status predate drafts and count as submitted.
+func submittedFilter(surveyID int) bson.M {
+	return bson.M{"surveyId": surveyID, "status": bson.M{"$ne": models.ResponseStatusDraft}}
 }
 
 // CreateResponse inserts a new response document into MongoDB
 func (r *MongoRepository) CreateResponse(ctx context.Context, response *models.Response) error {
-	response.SubmittedAt = time.Now() // Ensure submitted time is set
+	response.UpdatedAt = time.Now()
+	if !response.IsDraft() {
+		response.SubmittedAt = response.UpdatedAt // Ensure submitted time is set
+	}
 	_, err := r.collection.InsertOne(ctx, response)
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("%w for surveyID %d", ErrDuplicateResponse, response.SurveyID)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to insert response: %w", err)
 	}
 	return nil
 }
 
-// GetResponsesBySurveyID retrieves all responses for a given surveyID
+// GetResponsesBySurveyID retrieves all submitted responses for a given surveyID
 func (r *MongoRepository) GetResponsesBySurveyID(ctx context.Context, surveyID int) ([]*models.Response, error) {
-	filter := bson.M{"surveyId": surveyID}
+	filter := submittedFilter(surveyID)
 	findOptions := options.Find()
 	// Add sorting if needed, e.g., by SubmittedAt
 	// findOptions.SetSort(bson.D{{"submittedAt", -1}} // -1 for descending
@@ -82,6 +122,207 @@ func (r *MongoRepository) GetResponsesBySurveyID(ctx context.Context, surveyID i
 	return responses, nil
 }
 
+// CountResponsesBySurveyID counts the responses submitted for a given surveyID
+func (r *MongoRepository) CountResponsesBySurveyID(ctx context.Context, surveyID int) (int64, error) {
+	count, err := r.collection.CountDocuments(ctx, submittedFilter(surveyID))
+	if err != nil {
+		return 0, fmt.Errorf("failed to count responses for surveyID %d: %w", surveyID, err)
+	}
+	return count, nil
+}
+
+// HasUserResponded reports whether the user has already submitted a response to the survey
+func (r *MongoRepository) HasUserResponded(ctx context.Context, surveyID, userID int) (bool, error) {
+	filter := submittedFilter(surveyID)
+	filter["userId"] = userID
+	return r.exists(ctx, filter)
+}
+
+// HasResponseWithLimitKey reports whether a response to the survey was already recorded with the given limit key
+func (r *MongoRepository) HasResponseWithLimitKey(ctx context.Context, surveyID int, limitKey string) (bool, error) {
+	return r.exists(ctx, bson.M{"surveyId": surveyID, "limitKeys": limitKey})
+}
+
+// ReserveResponseSlot atomically counts one more submitted response for the survey, unless maxResponses are
+// already counted. A nil maxResponses reserves without a limit, which keeps the count right should one be set later.
+// Callers release the slot with ReleaseResponseSlot when the response is not stored after all.
+func (r *MongoRepository) ReserveResponseSlot(ctx context.Context, surveyID int, maxResponses *int) (bool, error) {
+	filter := bson.M{"_id": surveyID}
+	if maxResponses != nil {
+		filter["count"] = bson.M{"$lt": *maxResponses}
+	}
+	increment := bson.M{"$inc": bson.M{"count": 1}}
+
+	result, err := r.counters.UpdateOne(ctx, filter, increment)
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve a response slot for surveyID %d: %w", surveyID, err)
+	}
+	if result.MatchedCount > 0 {
+		return true, nil
+	}
+
+	// Either the survey is full or its counter does not exist yet. Counters are created from the responses
+	// already stored; when two submissions race to create one, the second insert fails and is ignored.
+	existing, err := r.counters.CountDocuments(ctx, bson.M{"_id": surveyID})
+	if err != nil {
+		return false, fmt.Errorf("failed to look up the response counter for surveyID %d: %w", surveyID, err)
+	}
+	if existing > 0 {
+		return false, nil
+	}
+	submitted, err := r.CountResponsesBySurveyID(ctx, surveyID)
+	if err != nil {
+		return false, err
+	}
+	_, err = r.counters.InsertOne(ctx, bson.M{"_id": surveyID, "count": submitted})
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return false, fmt.Errorf("failed to create the response counter for surveyID %d: %w", surveyID, err)
+	}
+
+	result, err = r.counters.UpdateOne(ctx, filter, increment)
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve a response slot for surveyID %d: %w", surveyID, err)
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// ReleaseResponseSlot gives back a slot taken by ReserveResponseSlot, after a failed insert or when a
+// submitted response is withdrawn
+func (r *MongoRepository) ReleaseResponseSlot(ctx context.Context, surveyID int) error {
+	_, err := r.counters.UpdateOne(ctx, bson.M{"_id": surveyID, "count": bson.M{"$gt": 0}}, bson.M{"$inc": bson.M{"count": -1}})
+	if err != nil {
+		return fmt.Errorf("failed to release a response slot for surveyID %d: %w", surveyID, err)
+	}
+	return nil
+}
+
+// GetResponseByID retrieves a draft or submitted response by its ID
+func (r *MongoRepository) GetResponseByID(ctx context.Context, id primitive.ObjectID) (*models.Response, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+// GetDraftBySurveyAndUser retrieves the user's unfinished draft for a survey
+func (r *MongoRepository) GetDraftBySurveyAndUser(ctx context.Context, surveyID, userID int) (*models.Response, error) {
+	return r.findOne(ctx, bson.M{"surveyId": surveyID, "userId": userID, "status": models.ResponseStatusDraft})
+}
+
+// UpdateResponse replaces the answers and submission state of an existing response
+func (r *MongoRepository) UpdateResponse(ctx context.Context, response *models.Response) error {
+	response.UpdatedAt = time.Now()
+	set := bson.M{
+		"answers":       response.Answers,
+		"status":        response.Status,
+		"surveyVersion": response.SurveyVersion,
+		"submittedAt":   response.SubmittedAt,
+		"updatedAt":     response.UpdatedAt,
+		"layout":        response.Layout,
+	}
+	unset := bson.M{}
+	if len(response.LimitKeys) > 0 {
+		set["limitKeys"] = response.LimitKeys
+	} else {
+		// An empty array would still be indexed, so drop the field instead
+		unset["limitKeys"] = ""
+	}
+	if response.DraftToken != "" {
+		set["draftToken"] = response.DraftToken
+	} else {
+		unset["draftToken"] = ""
+	}
+	update := bson.M{"$set": set, "$unset": unset}
+
+	result, err := r.collection.UpdateByID(ctx, response.ID, update)
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("%w for surveyID %d", ErrDuplicateResponse, response.SurveyID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update response %s: %w", response.ID.Hex(), err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrResponseNotFound
+	}
+	return nil
+}
+
+// DeleteResponse removes a response, freeing any per-respondent limit it held
+func (r *MongoRepository) DeleteResponse(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return fmt.Errorf("failed to delete response %s: %w", id.Hex(), err)
+	}
+	if result.DeletedCount == 0 {
+		return ErrResponseNotFound
+	}
+	return nil
+}
+
+// GetSurveyIDs lists the surveys that have at least one draft or submitted response
+func (r *MongoRepository) GetSurveyIDs(ctx context.Context) ([]int, error) {
+	values, err := r.collection.Distinct(ctx, "surveyId", bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list surveys with responses: %w", err)
+	}
+	surveyIDs := make([]int, 0, len(values))
+	for _, value := range values {
+		switch id := value.(type) {
+		case int32:
+			surveyIDs = append(surveyIDs, int(id))
+		case int64:
+			surveyIDs = append(surveyIDs, int(id))
+		default:
+			return nil, fmt.Errorf("unexpected surveyId %v of type %T", value, value)
+		}
+	}
+	return surveyIDs, nil
+}
+
+// GetAllResponsesBySurveyID retrieves the drafts and submitted responses of a survey
+func (r *MongoRepository) GetAllResponsesBySurveyID(ctx context.Context, surveyID int) ([]*models.Response, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"surveyId": surveyID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find responses for surveyID %d: %w", surveyID, err)
+	}
+	defer cursor.Close(ctx)
+
+	var responses []*models.Response
+	if err = cursor.All(ctx, &responses); err != nil {
+		return nil, fmt.Errorf("failed to decode responses for surveyID %d: %w", surveyID, err)
+	}
+	return responses, nil
+}
+
+// SetResponseAnswers rewrites the stored answers of a response, leaving its timestamps as they are
+func (r *MongoRepository) SetResponseAnswers(ctx context.Context, id primitive.ObjectID, answers []models.Answer) error {
+	result, err := r.collection.UpdateByID(ctx, id, bson.M{"$set": bson.M{"answers": answers}})
+	if err != nil {
+		return fmt.Errorf("failed to update answers of response %s: %w", id.Hex(), err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrResponseNotFound
+	}
+	return nil
+}
+
+func (r *MongoRepository) findOne(ctx context.Context, filter bson.M) (*models.Response, error) {
+	var response models.Response
+	err := r.collection.FindOne(ctx, filter).Decode(&response)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrResponseNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find response: %w", err)
+	}
+	return &response, nil
+}
+
+func (r *MongoRepository) exists(ctx context.Context, filter bson.M) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	if err != nil {
+		return false, fmt.Errorf("failed to look up responses: %w", err)
+	}
+	return count > 0, nil
+}
+
 // Disconnect closes the MongoDB client connection
 func (r *MongoRepository) Disconnect(ctx context.Context) error {
 	if r.client != nil {
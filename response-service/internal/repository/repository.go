@@ -2,13 +2,34 @@ package repository
 
 import (
 	"context"
+	"errors"
 
 	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// ErrDuplicateResponse is returned when a response repeats a limit key already recorded for the survey
+var ErrDuplicateResponse = errors.New("duplicate response")
+
+// ErrResponseNotFound is returned when no response matches the given ID
+var ErrResponseNotFound = errors.New("response not found")
+
 // ResponseRepositoryInterface defines methods for interacting with response data
 type ResponseRepositoryInterface interface {
 	CreateResponse(ctx context.Context, response *models.Response) error
 	GetResponsesBySurveyID(ctx context.Context, surveyID int) ([]*models.Response, error)
-	// Add other methods as needed, e.g., GetResponseByID, GetResponsesByUserID, etc.
+	CountResponsesBySurveyID(ctx context.Context, surveyID int) (int64, error)
+	HasUserResponded(ctx context.Context, surveyID, userID int) (bool, error)
+	HasResponseWithLimitKey(ctx context.Context, surveyID int, limitKey string) (bool, error)
+	ReserveResponseSlot(ctx context.Context, surveyID int, maxResponses *int) (bool, error)
+	ReleaseResponseSlot(ctx context.Context, surveyID int) error
+	GetResponseByID(ctx context.Context, id primitive.ObjectID) (*models.Response, error)
+	GetDraftBySurveyAndUser(ctx context.Context, surveyID, userID int) (*models.Response, error)
+	UpdateResponse(ctx context.Context, response *models.Response) error
+	DeleteResponse(ctx context.Context, id primitive.ObjectID) error
+	// Used by one-off data migrations
+	GetSurveyIDs(ctx context.Context) ([]int, error)
+	GetAllResponsesBySurveyID(ctx context.Context, surveyID int) ([]*models.Response, error)
+	SetResponseAnswers(ctx context.Context, id primitive.ObjectID, answers []models.Answer) error
+	// Add other methods as needed, e.g., GetResponsesByUserID, etc.
 }
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/contextkeys"
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+)
+
+// ErrResponseAccessDenied is returned when someone other than a survey's owner, one of its analysts or an admin reads
+// its responses, analytics or files
+var ErrResponseAccessDenied = errors.New("only the survey's owner, its analysts and admins can access its responses")
+
+// errSurveyForbidden is returned when the survey-service does not let the current user see a survey
+var errSurveyForbidden = errors.New("survey-service denied access to the survey")
+
+// Roles on a survey, as reported by the survey-service in access_role, that may access its responses
+const (
+	accessRoleOwner   = "owner"
+	accessRoleAnalyst = "analyst"
+)
+
+// authorizeResponseAccess fetches a survey for someone reading its responses, analytics or files
+func (s *ResponseService) authorizeResponseAccess(ctx context.Context, surveyID int) (*models.SurveyDetailsFromService, error) {
+	surveyDetails, err := s.getSurveyDetails(ctx, surveyID)
+	if errors.Is(err, errSurveyForbidden) {
+		return nil, ErrResponseAccessDenied
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve survey details (ID: %d): %w", surveyID, err)
+	}
+	if !canAccessResponses(ctx, surveyDetails) {
+		return nil, ErrResponseAccessDenied
+	}
+	return surveyDetails, nil
+}
+
+// canAccessResponses reports whether the current user owns the survey, is one of its analysts or is an admin of the
+// survey's organization
+func canAccessResponses(ctx context.Context, survey *models.SurveyDetailsFromService) bool {
+	if survey.AccessRole == accessRoleOwner || survey.AccessRole == accessRoleAnalyst {
+		return true
+	}
+	if userID, ok := ctx.Value(contextkeys.UserIDKey).(int); ok && userID == survey.CreatorID {
+		return true
+	}
+	if !inUserOrg(ctx, survey) {
+		return false // Admins only manage their own organization's surveys
+	}
+	roles, _ := ctx.Value(contextkeys.UserRolesKey).([]string)
+	for _, role := range roles {
+		if role == "admin" {
+			return true
+		}
+	}
+	return false
+}
+
+// inUserOrg reports whether the survey belongs to the organization the current user works in, or to none if they
+// work outside every organization
+func inUserOrg(ctx context.Context, survey *models.SurveyDetailsFromService) bool {
+	orgID, ok := ctx.Value(contextkeys.OrgIDKey).(int)
+	if survey.OrgID == nil || !ok {
+		return survey.OrgID == nil && !ok
+	}
+	return *survey.OrgID == orgID
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/contextkeys"
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// accessRoleSurveyHandler serves a survey owned by user 9, reporting the given role on it to every caller
+func accessRoleSurveyHandler(accessRole string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id": 1, "title": "Team study", "is_active": true, "creator_id": 9, "access_role": %q,
+			"questions": [{"id": 1, "text": "How was your week?", "type": "text"}]}`, accessRole)
+	})
+}
+
+func TestResponseAccess(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contextkeys.UserIDKey, 5)
+	ctx = context.WithValue(ctx, contextkeys.UserRolesKey, []string{"user"})
+	responses := []*models.Response{{SurveyID: 1, Answers: []models.Answer{{QuestionID: 1, Value: "Busy"}}}}
+
+	t.Run("Analysts see responses, analytics and exports", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockServer, mockURL := setupMockSurveyService(t, accessRoleSurveyHandler("analyst"))
+		defer mockServer.Close()
+		mockRepo.On("GetResponsesBySurveyID", ctx, 1).Return(responses, nil)
+		service := NewResponseService(mockRepo, mockURL)
+
+		result, err := service.GetSurveyResponses(ctx, 1)
+		require.NoError(t, err)
+		assert.Len(t, result, 1)
+
+		analytics, err := service.GetSurveyAnalytics(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 1, analytics.TotalResponses)
+
+		csvData, _, err := service.ExportSurveyResponsesCSV(ctx, 1)
+		require.NoError(t, err)
+		assert.Contains(t, csvData, "Busy")
+	})
+
+	for _, accessRole := range []string{"viewer", "editor", ""} {
+		t.Run(fmt.Sprintf("Access role %q is denied", accessRole), func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			mockServer, mockURL := setupMockSurveyService(t, accessRoleSurveyHandler(accessRole))
+			defer mockServer.Close()
+			service := NewResponseService(mockRepo, mockURL)
+
+			_, err := service.GetSurveyResponses(ctx, 1)
+			assert.True(t, errors.Is(err, ErrResponseAccessDenied), "responses: %v", err)
+			_, err = service.GetSurveyAnalytics(ctx, 1)
+			assert.True(t, errors.Is(err, ErrResponseAccessDenied), "analytics: %v", err)
+			_, _, err = service.ExportSurveyResponsesCSV(ctx, 1)
+			assert.True(t, errors.Is(err, ErrResponseAccessDenied), "export: %v", err)
+			mockRepo.AssertNotCalled(t, "GetResponsesBySurveyID", ctx, 1)
+		})
+	}
+
+	t.Run("Admins see every survey's responses", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockServer, mockURL := setupMockSurveyService(t, accessRoleSurveyHandler(""))
+		defer mockServer.Close()
+		admin := context.WithValue(ctx, contextkeys.UserRolesKey, []string{"admin"})
+		mockRepo.On("GetResponsesBySurveyID", admin, 1).Return(responses, nil)
+
+		_, err := NewResponseService(mockRepo, mockURL).GetSurveyResponses(admin, 1)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Admins only see their organization's surveys", func(t *testing.T) {
+		var forwardedOrgID string
+		mockServer, mockURL := setupMockSurveyService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			forwardedOrgID = r.Header.Get("X-Org-ID")
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"id": 1, "title": "Team study", "is_active": true, "creator_id": 9, "org_id": 10}`)
+		}))
+		defer mockServer.Close()
+		admin := context.WithValue(ctx, contextkeys.UserRolesKey, []string{"admin"})
+
+		mockRepo := new(MockRepository)
+		_, err := NewResponseService(mockRepo, mockURL).GetSurveyResponses(context.WithValue(admin, contextkeys.OrgIDKey, 20), 1)
+		assert.True(t, errors.Is(err, ErrResponseAccessDenied), "another organization: %v", err)
+		assert.Equal(t, "20", forwardedOrgID)
+		_, err = NewResponseService(mockRepo, mockURL).GetSurveyResponses(admin, 1)
+		assert.True(t, errors.Is(err, ErrResponseAccessDenied), "no organization: %v", err)
+		mockRepo.AssertNotCalled(t, "GetResponsesBySurveyID", mock.Anything, 1)
+
+		sameOrg := context.WithValue(admin, contextkeys.OrgIDKey, 10)
+		mockRepo.On("GetResponsesBySurveyID", sameOrg, 1).Return(responses, nil)
+		_, err = NewResponseService(mockRepo, mockURL).GetSurveyResponses(sameOrg, 1)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Surveys the survey-service hides are denied", func(t *testing.T) {
+		mockServer, mockURL := setupMockSurveyService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer mockServer.Close()
+
+		_, err := NewResponseService(new(MockRepository), mockURL).GetSurveyResponses(ctx, 1)
+		assert.True(t, errors.Is(err, ErrResponseAccessDenied), "got %v", err)
+	})
+}
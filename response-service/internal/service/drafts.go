@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/contextkeys"
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrNotResponseOwner is returned when a respondent acts on a response given by someone else
+var ErrNotResponseOwner = errors.New("response belongs to another respondent")
+
+// ErrResponseEditsNotAllowed is returned when a submitted response is edited or withdrawn on a survey that does not allow it
+var ErrResponseEditsNotAllowed = errors.New("this survey does not allow editing or withdrawing responses")
+
+// ErrResponseNotDraft is returned when a draft operation targets a response that was already submitted
+var ErrResponseNotDraft = errors.New("response has already been submitted")
+
+// ErrResponseIsDraft is returned when a submitted-response operation targets a draft
+var ErrResponseIsDraft = errors.New("response is still a draft")
+
+// SaveDraft stores the answers given so far without requiring every question to be answered.
+// A logged-in respondent has at most one draft per survey, which is updated in place.
+func (s *ResponseService) SaveDraft(ctx context.Context, req *models.SaveDraftRequest) (*models.Response, error) {
+	surveyDetails, err := s.getRespondentSurveyDetails(ctx, req.SurveyID, req.LayoutSeed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve survey details (ID: %d): %w", req.SurveyID, err)
+	}
+	if err := checkAcceptingResponses(surveyDetails, req.UserID); err != nil {
+		return nil, err
+	}
+	if err := validateDraftAnswers(surveyDetails.Questions, req.Answers); err != nil {
+		return nil, err
+	}
+	if err := s.resolveFileAnswers(ctx, req.SurveyID, surveyDetails.Questions, req.Answers); err != nil {
+		return nil, err
+	}
+
+	if req.UserID != nil {
+		draft, err := s.repo.GetDraftBySurveyAndUser(ctx, req.SurveyID, *req.UserID)
+		if err == nil {
+			previous := draft.Answers
+			draft.Answers = req.Answers
+			draft.SurveyVersion = surveyDetails.Version
+			if surveyDetails.Layout != nil {
+				draft.Layout = surveyDetails.Layout
+			}
+			if err := s.repo.UpdateResponse(ctx, draft); err != nil {
+				return nil, fmt.Errorf("failed to save draft (SurveyID: %d): %w", req.SurveyID, err)
+			}
+			s.deleteReplacedFiles(ctx, draft.SurveyID, previous, draft.Answers)
+			logf("[SERVICE_INFO] SaveDraft: Updated draft %s for SurveyID %d", draft.ID.Hex(), req.SurveyID)
+			return draft, nil
+		}
+		if !errors.Is(err, repository.ErrResponseNotFound) {
+			return nil, fmt.Errorf("failed to look up draft (SurveyID: %d): %w", req.SurveyID, err)
+		}
+	}
+
+	draft := &models.Response{
+		SurveyID:      req.SurveyID,
+		UserID:        req.UserID,
+		Answers:       req.Answers,
+		SurveyVersion: surveyDetails.Version,
+		Status:        models.ResponseStatusDraft,
+		Layout:        surveyDetails.Layout,
+	}
+	if req.UserID == nil {
+		if draft.DraftToken, err = newDraftToken(); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.repo.CreateResponse(ctx, draft); err != nil {
+		return nil, fmt.Errorf("failed to save draft (SurveyID: %d): %w", req.SurveyID, err)
+	}
+	logf("[SERVICE_INFO] SaveDraft: Created draft %s for SurveyID %d", draft.ID.Hex(), req.SurveyID)
+	return draft, nil
+}
+
+// GetDraft resumes a draft by its ID. Anonymous drafts also need the draft token they were created with.
+func (s *ResponseService) GetDraft(ctx context.Context, id string) (*models.Response, error) {
+	return s.getOwnDraft(ctx, id)
+}
+
+// GetUserDraft resumes the current user's draft for a survey
+func (s *ResponseService) GetUserDraft(ctx context.Context, surveyID int) (*models.Response, error) {
+	userID, ok := ctx.Value(contextkeys.UserIDKey).(int)
+	if !ok {
+		return nil, ErrAuthenticationRequired
+	}
+	return s.repo.GetDraftBySurveyAndUser(ctx, surveyID, userID)
+}
+
+// UpdateDraft replaces the answers saved in a draft
+func (s *ResponseService) UpdateDraft(ctx context.Context, id string, req *models.UpdateResponseRequest) (*models.Response, error) {
+	draft, err := s.getOwnDraft(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	surveyDetails, err := s.getSurveyDetails(ctx, draft.SurveyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve survey details (ID: %d): %w", draft.SurveyID, err)
+	}
+	if err := checkAcceptingResponses(surveyDetails, draft.UserID); err != nil {
+		return nil, err
+	}
+	if err := validateDraftAnswers(surveyDetails.Questions, req.Answers); err != nil {
+		return nil, err
+	}
+	if err := s.resolveFileAnswers(ctx, draft.SurveyID, surveyDetails.Questions, req.Answers); err != nil {
+		return nil, err
+	}
+
+	previous := draft.Answers
+	draft.Answers = req.Answers
+	draft.SurveyVersion = surveyDetails.Version
+	if err := s.repo.UpdateResponse(ctx, draft); err != nil {
+		return nil, fmt.Errorf("failed to save draft %s: %w", id, err)
+	}
+	s.deleteReplacedFiles(ctx, draft.SurveyID, previous, draft.Answers)
+	return draft, nil
+}
+
+// FinalizeDraft submits a draft after validating it like a new response, including the survey's response limits
+func (s *ResponseService) FinalizeDraft(ctx context.Context, id string, req *models.FinalizeDraftRequest) (*models.Response, error) {
+	draft, err := s.getOwnDraft(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	surveyDetails, err := s.getSurveyDetails(ctx, draft.SurveyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve survey details (ID: %d): %w", draft.SurveyID, err)
+	}
+	if err := checkAcceptingResponses(surveyDetails, draft.UserID); err != nil {
+		return nil, err
+	}
+	if err := validateAnswers(surveyDetails.Questions, draft.Answers); err != nil {
+		return nil, err
+	}
+	if err := s.resolveFileAnswers(ctx, draft.SurveyID, surveyDetails.Questions, draft.Answers); err != nil {
+		return nil, err
+	}
+	limitKeys, err := s.checkResponseLimits(ctx, surveyDetails, &models.CreateResponseRequest{
+		SurveyID:    draft.SurveyID,
+		UserID:      draft.UserID,
+		Answers:     draft.Answers,
+		DeviceToken: req.DeviceToken,
+		ClientIP:    req.ClientIP,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.reserveResponseSlot(ctx, draft.SurveyID, surveyDetails); err != nil {
+		return nil, err
+	}
+
+	draft.Status = models.ResponseStatusSubmitted
+	draft.DraftToken = "" // Submitted anonymous responses cannot be edited, so the token has no further use
+	draft.SubmittedAt = time.Now()
+	draft.SurveyVersion = surveyDetails.Version
+	draft.LimitKeys = limitKeys
+	err = s.repo.UpdateResponse(ctx, draft)
+	if err != nil {
+		s.releaseResponseSlot(ctx, draft.SurveyID)
+	}
+	if errors.Is(err, repository.ErrDuplicateResponse) {
+		return nil, fmt.Errorf("%w: a response was already submitted by this respondent", ErrResponseLimitReached)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit draft %s: %w", id, err)
+	}
+	logf("[SERVICE_INFO] FinalizeDraft: Submitted draft %s for SurveyID %d", id, draft.SurveyID)
+	return draft, nil
+}
+
+// UpdateResponse lets a respondent change a submitted response while the survey is open, if its owner allows it
+func (s *ResponseService) UpdateResponse(ctx context.Context, id string, req *models.UpdateResponseRequest) (*models.Response, error) {
+	response, err := s.getOwnResponse(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	surveyDetails, err := s.checkEditable(ctx, response)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateAnswers(surveyDetails.Questions, req.Answers); err != nil {
+		return nil, err
+	}
+	if err := s.resolveFileAnswers(ctx, response.SurveyID, surveyDetails.Questions, req.Answers); err != nil {
+		return nil, err
+	}
+
+	previous := response.Answers
+	response.Answers = req.Answers
+	response.SurveyVersion = surveyDetails.Version
+	if err := s.repo.UpdateResponse(ctx, response); err != nil {
+		return nil, fmt.Errorf("failed to update response %s: %w", id, err)
+	}
+	s.deleteReplacedFiles(ctx, response.SurveyID, previous, response.Answers)
+	logf("[SERVICE_INFO] UpdateResponse: Updated response %s for SurveyID %d", id, response.SurveyID)
+	return response, nil
+}
+
+// WithdrawResponse deletes a response. Drafts can always be discarded; submitted responses
+// follow the same rules as UpdateResponse.
+func (s *ResponseService) WithdrawResponse(ctx context.Context, id string) error {
+	response, err := s.getOwnResponse(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !response.IsDraft() {
+		if _, err := s.checkEditable(ctx, response); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.DeleteResponse(ctx, response.ID); err != nil {
+		return fmt.Errorf("failed to withdraw response %s: %w", id, err)
+	}
+	if !response.IsDraft() {
+		s.releaseResponseSlot(ctx, response.SurveyID)
+	}
+	s.deleteReplacedFiles(ctx, response.SurveyID, response.Answers, nil) // Uploaded files go with the response
+	logf("[SERVICE_INFO] WithdrawResponse: Deleted response %s for SurveyID %d", id, response.SurveyID)
+	return nil
+}
+
+// getOwnDraft loads a draft the current respondent may act on
+func (s *ResponseService) getOwnDraft(ctx context.Context, id string) (*models.Response, error) {
+	draft, err := s.getOwnResponse(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !draft.IsDraft() {
+		return nil, ErrResponseNotDraft
+	}
+	return draft, nil
+}
+
+// checkEditable ensures a submitted response belongs to a user and its survey allows edits and is still open.
+// Anonymous responses cannot be edited, as nothing ties them to the respondent.
+func (s *ResponseService) checkEditable(ctx context.Context, response *models.Response) (*models.SurveyDetailsFromService, error) {
+	if response.IsDraft() {
+		return nil, ErrResponseIsDraft
+	}
+	if response.UserID == nil {
+		return nil, ErrNotResponseOwner
+	}
+
+	surveyDetails, err := s.getSurveyDetails(ctx, response.SurveyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve survey details (ID: %d): %w", response.SurveyID, err)
+	}
+	if !surveyDetails.AllowResponseEdits {
+		return nil, ErrResponseEditsNotAllowed
+	}
+	if err := checkAcceptingResponses(surveyDetails, response.UserID); err != nil {
+		return nil, err
+	}
+	return surveyDetails, nil
+}
+
+// getOwnResponse loads a response and checks it belongs to the current user.
+// Responses without a user belong to whoever holds their draft token; ObjectIDs are guessable, so the ID is not enough.
+func (s *ResponseService) getOwnResponse(ctx context.Context, id string) (*models.Response, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, repository.ErrResponseNotFound
+	}
+	response, err := s.repo.GetResponseByID(ctx, objectID)
+	if err != nil {
+		return nil, err
+	}
+	if response.UserID != nil {
+		userID, ok := ctx.Value(contextkeys.UserIDKey).(int)
+		if !ok || userID != *response.UserID {
+			return nil, ErrNotResponseOwner
+		}
+		return response, nil
+	}
+	token, _ := ctx.Value(contextkeys.DraftTokenKey).(string)
+	if response.DraftToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(response.DraftToken)) != 1 {
+		return nil, ErrNotResponseOwner
+	}
+	return response, nil
+}
+
+// newDraftToken returns a random, unguessable token that lets an anonymous respondent act on their draft
+func newDraftToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate draft token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/contextkeys"
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// draftSurveyHandler serves an open survey, which anonymous respondents may answer, with a required and an optional question
+func draftSurveyHandler(allowEdits bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(fmt.Sprintf(`{
+			"id": 1, "title": "Long survey", "is_active": true, "status": "open", "version": 3,
+			"allow_response_edits": %t, "allow_anonymous": true,
+			"questions": [
+				{"id": 1, "text": "Name?", "type": "text", "required": true},
+				{"id": 2, "text": "Comments?", "type": "text"}
+			]
+		}`, allowEdits)))
+	})
+}
+
+func userContext(userID int) context.Context {
+	return context.WithValue(context.Background(), contextkeys.UserIDKey, userID)
+}
+
+func TestDrafts(t *testing.T) {
+	ctx := userContext(5)
+	partialAnswers := []models.Answer{{QuestionID: 2, Value: "So far so good"}}
+
+	t.Run("Save a partial draft", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockServer, mockURL := setupMockSurveyService(t, draftSurveyHandler(false))
+		defer mockServer.Close()
+
+		mockRepo.On("GetDraftBySurveyAndUser", ctx, 1, 5).Return(nil, repository.ErrResponseNotFound)
+		mockRepo.On("CreateResponse", ctx, mock.MatchedBy(func(r *models.Response) bool {
+			return r.IsDraft() && r.SurveyVersion == 3 && *r.UserID == 5
+		})).Return(nil)
+
+		draft, err := NewResponseService(mockRepo, mockURL).SaveDraft(ctx, &models.SaveDraftRequest{SurveyID: 1, UserID: intPtr(5), Answers: partialAnswers})
+		require.NoError(t, err)
+		assert.Equal(t, models.ResponseStatusDraft, draft.Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Saving again updates the user's draft", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockServer, mockURL := setupMockSurveyService(t, draftSurveyHandler(false))
+		defer mockServer.Close()
+
+		existing := &models.Response{ID: primitive.NewObjectID(), SurveyID: 1, UserID: intPtr(5), Status: models.ResponseStatusDraft}
+		mockRepo.On("GetDraftBySurveyAndUser", ctx, 1, 5).Return(existing, nil)
+		mockRepo.On("UpdateResponse", ctx, existing).Return(nil)
+
+		draft, err := NewResponseService(mockRepo, mockURL).SaveDraft(ctx, &models.SaveDraftRequest{SurveyID: 1, UserID: intPtr(5), Answers: partialAnswers})
+		require.NoError(t, err)
+		assert.Equal(t, existing.ID, draft.ID)
+		assert.Equal(t, partialAnswers, draft.Answers)
+		mockRepo.AssertNotCalled(t, "CreateResponse", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Finalize requires every required answer", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockServer, mockURL := setupMockSurveyService(t, draftSurveyHandler(false))
+		defer mockServer.Close()
+		service := NewResponseService(mockRepo, mockURL)
+
+		draft := &models.Response{ID: primitive.NewObjectID(), SurveyID: 1, UserID: intPtr(5), Status: models.ResponseStatusDraft, Answers: partialAnswers}
+		mockRepo.On("GetResponseByID", ctx, draft.ID).Return(draft, nil)
+
+		_, err := service.FinalizeDraft(ctx, draft.ID.Hex(), &models.FinalizeDraftRequest{})
+		assert.True(t, errors.Is(err, ErrInvalidAnswers))
+
+		draft.Answers = append(draft.Answers, models.Answer{QuestionID: 1, Value: "Ada"})
+		mockRepo.On("ReserveResponseSlot", ctx, 1, (*int)(nil)).Return(true, nil)
+		mockRepo.On("UpdateResponse", ctx, draft).Return(nil)
+		submitted, err := service.FinalizeDraft(ctx, draft.ID.Hex(), &models.FinalizeDraftRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, models.ResponseStatusSubmitted, submitted.Status)
+		assert.False(t, submitted.SubmittedAt.IsZero())
+
+		// A submitted response is no longer a draft
+		_, err = service.GetDraft(ctx, draft.ID.Hex())
+		assert.True(t, errors.Is(err, ErrResponseNotDraft))
+	})
+
+	t.Run("Drafts of other users cannot be resumed", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		draft := &models.Response{ID: primitive.NewObjectID(), SurveyID: 1, UserID: intPtr(6), Status: models.ResponseStatusDraft}
+		mockRepo.On("GetResponseByID", ctx, draft.ID).Return(draft, nil)
+
+		_, err := NewResponseService(mockRepo, "").GetDraft(ctx, draft.ID.Hex())
+		assert.True(t, errors.Is(err, ErrNotResponseOwner))
+
+		_, err = NewResponseService(mockRepo, "").GetDraft(ctx, "not-an-id")
+		assert.True(t, errors.Is(err, repository.ErrResponseNotFound))
+	})
+
+	t.Run("Anonymous drafts need their draft token", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockServer, mockURL := setupMockSurveyService(t, draftSurveyHandler(false))
+		defer mockServer.Close()
+		service := NewResponseService(mockRepo, mockURL)
+		anonymous := context.Background()
+
+		var draft *models.Response
+		mockRepo.On("CreateResponse", anonymous, mock.MatchedBy(func(r *models.Response) bool {
+			draft = r
+			r.ID = primitive.NewObjectID()
+			return r.UserID == nil && len(r.DraftToken) == 32
+		})).Return(nil)
+		_, err := service.SaveDraft(anonymous, &models.SaveDraftRequest{SurveyID: 1, Answers: partialAnswers})
+		require.NoError(t, err)
+		mockRepo.On("GetResponseByID", mock.Anything, draft.ID).Return(draft, nil)
+
+		for name, ctx := range map[string]context.Context{
+			"no token":    anonymous,
+			"wrong token": context.WithValue(anonymous, contextkeys.DraftTokenKey, "0123456789abcdef0123456789abcdef"),
+			"a user":      ctx,
+		} {
+			_, err := service.GetDraft(ctx, draft.ID.Hex())
+			assert.True(t, errors.Is(err, ErrNotResponseOwner), "get with %s: %v", name, err)
+			_, err = service.UpdateDraft(ctx, draft.ID.Hex(), &models.UpdateResponseRequest{Answers: partialAnswers})
+			assert.True(t, errors.Is(err, ErrNotResponseOwner), "update with %s: %v", name, err)
+			_, err = service.FinalizeDraft(ctx, draft.ID.Hex(), &models.FinalizeDraftRequest{})
+			assert.True(t, errors.Is(err, ErrNotResponseOwner), "submit with %s: %v", name, err)
+			assert.True(t, errors.Is(service.WithdrawResponse(ctx, draft.ID.Hex()), ErrNotResponseOwner), "withdraw with %s", name)
+		}
+		mockRepo.AssertNotCalled(t, "UpdateResponse", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "DeleteResponse", mock.Anything, mock.Anything)
+
+		withToken := context.WithValue(anonymous, contextkeys.DraftTokenKey, draft.DraftToken)
+		resumed, err := service.GetDraft(withToken, draft.ID.Hex())
+		require.NoError(t, err)
+		assert.Equal(t, draft.ID, resumed.ID)
+
+		draft.Answers = append(draft.Answers, models.Answer{QuestionID: 1, Value: "Ada"})
+		mockRepo.On("ReserveResponseSlot", withToken, 1, (*int)(nil)).Return(true, nil)
+		mockRepo.On("UpdateResponse", withToken, draft).Return(nil)
+		submitted, err := service.FinalizeDraft(withToken, draft.ID.Hex(), &models.FinalizeDraftRequest{})
+		require.NoError(t, err)
+		assert.Empty(t, submitted.DraftToken, "the token is cleared once submitted")
+	})
+}
+
+func TestEditAndWithdrawResponse(t *testing.T) {
+	ctx := userContext(5)
+	newAnswers := &models.UpdateResponseRequest{Answers: []models.Answer{{QuestionID: 1, Value: "Grace"}}}
+
+	submitted := func() *models.Response {
+		return &models.Response{ID: primitive.NewObjectID(), SurveyID: 1, UserID: intPtr(5), Status: models.ResponseStatusSubmitted, SurveyVersion: 2}
+	}
+
+	t.Run("Survey does not allow edits", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockServer, mockURL := setupMockSurveyService(t, draftSurveyHandler(false))
+		defer mockServer.Close()
+		service := NewResponseService(mockRepo, mockURL)
+
+		response := submitted()
+		mockRepo.On("GetResponseByID", ctx, response.ID).Return(response, nil)
+
+		_, err := service.UpdateResponse(ctx, response.ID.Hex(), newAnswers)
+		assert.True(t, errors.Is(err, ErrResponseEditsNotAllowed))
+		assert.True(t, errors.Is(service.WithdrawResponse(ctx, response.ID.Hex()), ErrResponseEditsNotAllowed))
+		mockRepo.AssertNotCalled(t, "UpdateResponse", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "DeleteResponse", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Edit and withdraw while the survey is open", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockServer, mockURL := setupMockSurveyService(t, draftSurveyHandler(true))
+		defer mockServer.Close()
+		service := NewResponseService(mockRepo, mockURL)
+
+		response := submitted()
+		mockRepo.On("GetResponseByID", ctx, response.ID).Return(response, nil)
+		mockRepo.On("UpdateResponse", ctx, response).Return(nil)
+		mockRepo.On("DeleteResponse", ctx, response.ID).Return(nil)
+		mockRepo.On("ReleaseResponseSlot", ctx, 1).Return(nil)
+
+		updated, err := service.UpdateResponse(ctx, response.ID.Hex(), newAnswers)
+		require.NoError(t, err)
+		assert.Equal(t, newAnswers.Answers, updated.Answers)
+		assert.Equal(t, 3, updated.SurveyVersion, "edited answers are pinned to the current version")
+
+		assert.NoError(t, service.WithdrawResponse(ctx, response.ID.Hex()))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Other users cannot edit", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		response := submitted()
+		mockRepo.On("GetResponseByID", mock.Anything, response.ID).Return(response, nil)
+
+		_, err := NewResponseService(mockRepo, "").UpdateResponse(userContext(6), response.ID.Hex(), newAnswers)
+		assert.True(t, errors.Is(err, ErrNotResponseOwner))
+	})
+}
@@ -0,0 +1,388 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/contextkeys"
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/repository"
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/storage"
+	"github.com/VitaliySynytskyi/survey-platform/shared/questiontypes"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrFileStorageUnavailable is returned for file operations when no storage is configured
+var ErrFileStorageUnavailable = errors.New("file uploads are not available")
+
+// ErrNotFileQuestion is returned when a file is uploaded for a question that does not take one
+var ErrNotFileQuestion = errors.New("question does not accept file uploads")
+
+// ErrFileTooLarge is returned when an upload exceeds the size limit of its question
+var ErrFileTooLarge = errors.New("file is too large")
+
+// ErrFileTypeNotAllowed is returned when an upload's type is not among those its question allows
+var ErrFileTypeNotAllowed = errors.New("file type is not allowed")
+
+// ErrFileNotFound is returned when a response has no file for a question, or the file is gone
+var ErrFileNotFound = errors.New("file not found")
+
+const maxFileNameLen = 255
+
+// takesFile reports whether answers to q reference an uploaded file
+func takesFile(q models.QuestionFromService) bool {
+	def, ok := questiontypes.LookupQuestionType(q.Type)
+	return ok && def.Answer == questiontypes.AnswerFile
+}
+
+// fileUploadConfigOf returns the limits of a file_upload question, the defaults if its config is missing or unusable
+func fileUploadConfigOf(q models.QuestionFromService) *questiontypes.FileUploadConfig {
+	if config, ok := configOf(q).(*questiontypes.FileUploadConfig); ok {
+		return config
+	}
+	return &questiontypes.FileUploadConfig{MaxSize: questiontypes.DefaultMaxFileSize}
+}
+
+// UseFileStorage enables file_upload questions, keeping the uploaded files in store.
+// maxUploadSize caps the size limit of every question; 0 leaves the questions' limits as they are.
+func (s *ResponseService) UseFileStorage(store storage.BlobStore, maxUploadSize int64) {
+	s.files = store
+	s.maxUploadSize = maxUploadSize
+}
+
+// fileKey locates an upload in the blob store. Keying by survey and question keeps an upload from being
+// attached to any other question.
+func fileKey(surveyID, questionID int, fileID string) string {
+	return fmt.Sprintf("surveys/%d/questions/%d/%s", surveyID, questionID, fileID)
+}
+
+// newFileID returns a random, unguessable upload ID: holding it is what allows attaching the file to an answer
+func newFileID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate file ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func isFileID(value interface{}) bool {
+	id, ok := value.(string)
+	if !ok || len(id) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
+
+// UploadFile stores a file a respondent uploads for a file_upload question and returns the reference to submit as
+// the answer's value. The survey must be accepting responses from the current user, as for SubmitResponse.
+func (s *ResponseService) UploadFile(ctx context.Context, surveyID, questionID int, fileName string, content io.Reader) (*models.FileReference, error) {
+	if s.files == nil {
+		return nil, ErrFileStorageUnavailable
+	}
+	surveyDetails, err := s.getSurveyDetails(ctx, surveyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve survey details (ID: %d): %w", surveyID, err)
+	}
+	var userID *int
+	if uid, ok := ctx.Value(contextkeys.UserIDKey).(int); ok {
+		userID = &uid
+	}
+	if err := checkAcceptingResponses(surveyDetails, userID); err != nil {
+		return nil, err
+	}
+
+	var question *models.QuestionFromService
+	for i := range surveyDetails.Questions {
+		if surveyDetails.Questions[i].ID == questionID {
+			question = &surveyDetails.Questions[i]
+			break
+		}
+	}
+	if question == nil || !takesFile(*question) {
+		return nil, ErrNotFileQuestion
+	}
+	config := fileUploadConfigOf(*question)
+	maxSize := config.MaxSize
+	if s.maxUploadSize > 0 && maxSize > s.maxUploadSize {
+		maxSize = s.maxUploadSize
+	}
+	quotaLimited := false
+	var stored int64 // Size of the stored file, which replaces the bytes reserved from the quota
+	if s.uploadQuota != nil {
+		reservation, err := s.uploadQuota.reserve(uploaderKey(ctx), maxSize, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		defer func() { s.uploadQuota.settle(reservation, stored) }()
+		if s.uploadQuota.quota.MaxBytes > 0 && reservation.bytes < maxSize {
+			maxSize, quotaLimited = reservation.bytes, true
+		}
+	}
+
+	buffered := bufio.NewReaderSize(content, 512)
+	head, err := buffered.Peek(512)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read uploaded file: %w", err)
+	}
+	contentType := detectContentType(head)
+	if !config.Allows(contentType) {
+		return nil, fmt.Errorf("%w: %s", ErrFileTypeNotAllowed, contentType)
+	}
+
+	fileID, err := newFileID()
+	if err != nil {
+		return nil, err
+	}
+	info, err := s.files.Put(ctx, fileKey(surveyID, questionID, fileID), &sizeLimitedReader{r: buffered, remaining: maxSize},
+		storage.BlobInfo{Name: cleanFileName(fileName), ContentType: contentType})
+	if errors.Is(err, ErrFileTooLarge) && quotaLimited {
+		return nil, fmt.Errorf("%w: %d bytes left", ErrUploadQuotaExceeded, maxSize)
+	}
+	if errors.Is(err, ErrFileTooLarge) {
+		return nil, fmt.Errorf("%w: the limit is %d bytes", ErrFileTooLarge, maxSize)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to store uploaded file: %w", err)
+	}
+	stored = info.Size
+	logf("[SERVICE_INFO] UploadFile: Stored file %s (%d bytes) for SurveyID %d, QuestionID %d", fileID, info.Size, surveyID, questionID)
+	return &models.FileReference{ID: fileID, Name: info.Name, ContentType: info.ContentType, Size: info.Size}, nil
+}
+
+// detectContentType determines the MIME type of an upload from its first bytes. The file name is not trusted, so
+// renaming a file cannot get it past a question's allowed types; formats built on ZIP, such as Office documents,
+// are detected as application/zip. Files are always downloaded as attachments, so none gets rendered.
+func detectContentType(head []byte) string {
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		return "application/octet-stream"
+	}
+	return mediaType
+}
+
+// cleanFileName keeps the base name of an uploaded file; browsers on Windows may send a full path
+func cleanFileName(fileName string) string {
+	if i := strings.LastIndexAny(fileName, `/\`); i >= 0 {
+		fileName = fileName[i+1:]
+	}
+	fileName = strings.TrimSpace(fileName)
+	if len(fileName) > maxFileNameLen {
+		fileName = fileName[len(fileName)-maxFileNameLen:] // Keep the extension
+	}
+	if fileName == "" || fileName == "." || fileName == ".." {
+		return "file"
+	}
+	return fileName
+}
+
+// sizeLimitedReader fails with ErrFileTooLarge once more than remaining bytes have been read
+type sizeLimitedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (l *sizeLimitedReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, ErrFileTooLarge
+	}
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1] // One byte past the limit tells a file of exactly the limit from a larger one
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, ErrFileTooLarge
+	}
+	return n, err
+}
+
+// resolveFileAnswers checks that the answers to file_upload questions reference files uploaded for them, and sets
+// the answers' file references. File references sent by the client are discarded.
+func (s *ResponseService) resolveFileAnswers(ctx context.Context, surveyID int, questions []models.QuestionFromService, answers []models.Answer) error {
+	questionsByID := make(map[int]models.QuestionFromService, len(questions))
+	for _, q := range questions {
+		questionsByID[q.ID] = q
+	}
+
+	verr := &AnswerValidationError{}
+	for i := range answers {
+		ans := &answers[i]
+		ans.File = nil
+		q, ok := questionsByID[ans.QuestionID]
+		if !ok || !takesFile(q) || isEmptyAnswer(ans.Value) {
+			continue
+		}
+		if s.files == nil {
+			return ErrFileStorageUnavailable
+		}
+		fileID, _ := ans.Value.(string) // The answer was validated to be a file ID
+		info, err := s.files.Stat(ctx, fileKey(surveyID, q.ID, fileID))
+		if errors.Is(err, storage.ErrBlobNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+			verr.add(q.ID, AnswerErrInvalidFile, "file %q was not uploaded for question %d", fileID, q.ID)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to look up file %s: %w", fileID, err)
+		}
+		ans.File = &models.FileReference{ID: fileID, Name: info.Name, ContentType: info.ContentType, Size: info.Size}
+	}
+
+	if len(verr.Errors) > 0 {
+		return verr
+	}
+	return nil
+}
+
+// deleteReplacedFiles removes the files referenced by the answers before an update that the answers after it no
+// longer reference. Failures are logged; they leave an unreferenced file behind but do not fail the update.
+func (s *ResponseService) deleteReplacedFiles(ctx context.Context, surveyID int, before, after []models.Answer) {
+	kept := make(map[string]bool)
+	for _, ans := range after {
+		if ans.File != nil {
+			kept[fileKey(surveyID, ans.QuestionID, ans.File.ID)] = true
+		}
+	}
+	for _, ans := range before {
+		if ans.File == nil || s.files == nil {
+			continue
+		}
+		key := fileKey(surveyID, ans.QuestionID, ans.File.ID)
+		if kept[key] {
+			continue
+		}
+		if err := s.files.Delete(ctx, key); err != nil {
+			logf("[SERVICE_WARN] deleteReplacedFiles: Failed to delete file %s: %v", key, err)
+		}
+	}
+}
+
+// OpenResponseFile opens the file a response gave in answer to a file_upload question, for the survey's owner,
+// its analysts or an admin.
+// The caller closes the returned content.
+func (s *ResponseService) OpenResponseFile(ctx context.Context, responseID string, questionID int) (io.ReadCloser, *models.FileReference, error) {
+	if s.files == nil {
+		return nil, nil, ErrFileStorageUnavailable
+	}
+	objectID, err := primitive.ObjectIDFromHex(responseID)
+	if err != nil {
+		return nil, nil, repository.ErrResponseNotFound
+	}
+	response, err := s.repo.GetResponseByID(ctx, objectID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := s.authorizeResponseAccess(ctx, response.SurveyID); err != nil {
+		return nil, nil, err
+	}
+
+	for _, ans := range response.Answers {
+		if ans.QuestionID != questionID || ans.File == nil {
+			continue
+		}
+		content, _, err := s.files.Open(ctx, fileKey(response.SurveyID, questionID, ans.File.ID))
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			return nil, nil, ErrFileNotFound
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open file %s: %w", ans.File.ID, err)
+		}
+		return content, ans.File, nil
+	}
+	return nil, nil, ErrFileNotFound
+}
+
+// DeleteUnattachedFiles deletes the uploads older than maxAge that no response references: files uploaded for
+// responses that were never submitted or saved as a draft, or that were replaced. It returns how many it deleted.
+func (s *ResponseService) DeleteUnattachedFiles(ctx context.Context, maxAge time.Duration) (int, error) {
+	if s.files == nil {
+		return 0, nil
+	}
+	keys, err := s.files.List(ctx, "surveys")
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	staleBySurvey := make(map[int][]string)
+	for _, key := range keys {
+		// Keys are laid out by fileKey: surveys/<survey>/questions/<question>/<file>
+		parts := strings.Split(key, "/")
+		if len(parts) != 5 {
+			continue
+		}
+		surveyID, err := strconv.Atoi(parts[1])
+		if err != nil {
+			continue
+		}
+		info, err := s.files.Stat(ctx, key)
+		if err != nil {
+			logf("[SERVICE_WARN] DeleteUnattachedFiles: Skipping file %s: %v", key, err)
+			continue
+		}
+		if info.UploadedAt.Before(cutoff) {
+			staleBySurvey[surveyID] = append(staleBySurvey[surveyID], key)
+		}
+	}
+
+	deleted := 0
+	for surveyID, stale := range staleBySurvey {
+		// Drafts count too, so respondents resuming one keep their files
+		responses, err := s.repo.GetAllResponsesBySurveyID(ctx, surveyID)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to list responses of survey %d: %w", surveyID, err)
+		}
+		attached := make(map[string]bool)
+		for _, response := range responses {
+			for _, ans := range response.Answers {
+				if ans.File != nil {
+					attached[fileKey(surveyID, ans.QuestionID, ans.File.ID)] = true
+				}
+			}
+		}
+		for _, key := range stale {
+			if attached[key] {
+				continue
+			}
+			if err := s.files.Delete(ctx, key); err != nil {
+				logf("[SERVICE_WARN] DeleteUnattachedFiles: Failed to delete file %s: %v", key, err)
+				continue
+			}
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// RunFileCleanup deletes unattached uploads older than maxAge immediately and then on every interval until ctx is
+// cancelled. Replicas may run it at the same time; deleting a file twice is harmless.
+func (s *ResponseService) RunFileCleanup(ctx context.Context, interval, maxAge time.Duration) {
+	logf("[CLEANUP_INFO] Deleting unattached uploads older than %s every %s", maxAge, interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := s.DeleteUnattachedFiles(ctx, maxAge)
+		if err != nil {
+			logf("[CLEANUP_ERROR] %v", err)
+		} else if deleted > 0 {
+			logf("[CLEANUP_INFO] Deleted %d unattached uploads", deleted)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/contextkeys"
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/storage"
+	"github.com/VitaliySynytskyi/survey-platform/shared/questiontypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func uploadSurveyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"id": 1, "title": "Applications", "is_active": true, "status": "open", "version": 1,
+			"creator_id": 9, "allow_response_edits": true, "allow_anonymous": true,
+			"questions": [
+				{"id": 1, "text": "Name?", "type": "text"},
+				{"id": 2, "text": "CV", "type": "file_upload", "config": {"max_size": 10, "allowed_types": ["text/plain"]}}
+			]
+		}`))
+	})
+}
+
+func TestFileUploads(t *testing.T) {
+	ctx := userContext(5)
+
+	newService := func(t *testing.T, mockRepo *MockRepository) (*ResponseService, storage.BlobStore) {
+		mockServer, mockURL := setupMockSurveyService(t, uploadSurveyHandler())
+		t.Cleanup(mockServer.Close)
+		store, err := storage.NewLocalStore(t.TempDir())
+		require.NoError(t, err)
+		service := NewResponseService(mockRepo, mockURL)
+		service.UseFileStorage(store, 0)
+		return service, store
+	}
+
+	t.Run("Upload within the limits", func(t *testing.T) {
+		service, store := newService(t, new(MockRepository))
+		ref, err := service.UploadFile(ctx, 1, 2, `C:\Users\ann\cv.txt`, strings.NewReader("0123456789"))
+		require.NoError(t, err)
+		assert.True(t, isFileID(ref.ID))
+		assert.Equal(t, "cv.txt", ref.Name)
+		assert.Equal(t, "text/plain", ref.ContentType)
+		assert.Equal(t, int64(10), ref.Size)
+
+		_, err = store.Stat(ctx, fileKey(1, 2, ref.ID))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Rejected uploads", func(t *testing.T) {
+		service, _ := newService(t, new(MockRepository))
+		_, err := service.UploadFile(ctx, 1, 2, "cv.txt", strings.NewReader("01234567890"))
+		assert.True(t, errors.Is(err, ErrFileTooLarge))
+		_, err = service.UploadFile(ctx, 1, 2, "cv.pdf", strings.NewReader("%PDF-1.4"))
+		assert.True(t, errors.Is(err, ErrFileTypeNotAllowed))
+		_, err = service.UploadFile(ctx, 1, 1, "cv.txt", strings.NewReader("hi"))
+		assert.True(t, errors.Is(err, ErrNotFileQuestion))
+	})
+
+	t.Run("Types are detected from the content, not the name", func(t *testing.T) {
+		service, _ := newService(t, new(MockRepository))
+		_, err := service.UploadFile(ctx, 1, 2, "cv.txt", strings.NewReader("%PDF-1.4"))
+		assert.True(t, errors.Is(err, ErrFileTypeNotAllowed), "a PDF renamed to .txt")
+		ref, err := service.UploadFile(ctx, 1, 2, "cv.pdf", strings.NewReader("plain text"))
+		require.NoError(t, err)
+		assert.Equal(t, "text/plain", ref.ContentType)
+	})
+
+	t.Run("Upload quota", func(t *testing.T) {
+		service, _ := newService(t, new(MockRepository))
+		service.LimitUploads(UploadQuota{MaxFiles: 2, MaxBytes: 15, Window: time.Hour})
+		anonymous := func(ip string) context.Context {
+			return context.WithValue(context.Background(), contextkeys.ClientIPKey, ip)
+		}
+
+		_, err := service.UploadFile(anonymous("203.0.113.7"), 1, 2, "a.txt", strings.NewReader("0123456789"))
+		require.NoError(t, err)
+		_, err = service.UploadFile(anonymous("203.0.113.7"), 1, 2, "b.txt", strings.NewReader("0123456789"))
+		assert.True(t, errors.Is(err, ErrUploadQuotaExceeded), "only 5 bytes are left")
+		_, err = service.UploadFile(anonymous("203.0.113.7"), 1, 2, "c.txt", strings.NewReader("01234"))
+		assert.True(t, errors.Is(err, ErrUploadQuotaExceeded), "both files were used up")
+
+		_, err = service.UploadFile(anonymous("198.51.100.1"), 1, 2, "a.txt", strings.NewReader("0123456789"))
+		assert.NoError(t, err, "other addresses have their own quota")
+		_, err = service.UploadFile(ctx, 1, 2, "a.txt", strings.NewReader("0123456789"))
+		assert.NoError(t, err, "signed-in respondents have their own quota")
+	})
+
+	t.Run("Unattached uploads are deleted", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service, store := newService(t, mockRepo)
+		attached, err := service.UploadFile(ctx, 1, 2, "cv.txt", strings.NewReader("hello"))
+		require.NoError(t, err)
+		abandoned, err := service.UploadFile(ctx, 1, 2, "cv.txt", strings.NewReader("hello"))
+		require.NoError(t, err)
+		mockRepo.On("GetAllResponsesBySurveyID", mock.Anything, 1).Return([]*models.Response{
+			{ID: primitive.NewObjectID(), SurveyID: 1, Status: models.ResponseStatusDraft, Answers: []models.Answer{{QuestionID: 2, Value: attached.ID, File: attached}}},
+		}, nil)
+
+		deleted, err := service.DeleteUnattachedFiles(ctx, time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, 0, deleted, "recent uploads are kept")
+
+		deleted, err = service.DeleteUnattachedFiles(ctx, 0)
+		require.NoError(t, err)
+		assert.Equal(t, 1, deleted)
+		_, err = store.Stat(ctx, fileKey(1, 2, attached.ID))
+		assert.NoError(t, err)
+		_, err = store.Stat(ctx, fileKey(1, 2, abandoned.ID))
+		assert.True(t, errors.Is(err, storage.ErrBlobNotFound))
+	})
+
+	t.Run("Uploads need storage", func(t *testing.T) {
+		_, err := NewResponseService(new(MockRepository), "").UploadFile(ctx, 1, 2, "cv.txt", strings.NewReader("hi"))
+		assert.True(t, errors.Is(err, ErrFileStorageUnavailable))
+	})
+
+	t.Run("Submitting an upload attaches the file", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service, _ := newService(t, mockRepo)
+		ref, err := service.UploadFile(ctx, 1, 2, "cv.txt", strings.NewReader("hello"))
+		require.NoError(t, err)
+
+		mockRepo.On("ReserveResponseSlot", ctx, 1, (*int)(nil)).Return(true, nil)
+		mockRepo.On("CreateResponse", ctx, mock.MatchedBy(func(r *models.Response) bool {
+			return r.Answers[0].File != nil && *r.Answers[0].File == *ref
+		})).Return(nil)
+		err = service.SubmitResponse(ctx, &models.CreateResponseRequest{SurveyID: 1, UserID: intPtr(5), Answers: []models.Answer{
+			{QuestionID: 2, Value: ref.ID, File: &models.FileReference{ID: ref.ID, Name: "forged.exe"}},
+		}})
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unknown upload", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service, _ := newService(t, mockRepo)
+		otherQuestion, err := service.UploadFile(ctx, 1, 2, "cv.txt", strings.NewReader("hello"))
+		require.NoError(t, err)
+
+		for _, value := range []interface{}{"0123456789abcdef0123456789abcdef", "../../../etc/passwd"} {
+			err := service.SubmitResponse(ctx, &models.CreateResponseRequest{SurveyID: 1, UserID: intPtr(5), Answers: []models.Answer{{QuestionID: 2, Value: value}}})
+			var verr *AnswerValidationError
+			require.True(t, errors.As(err, &verr), "value %v", value)
+		}
+		// An upload for question 2 cannot answer another file question
+		questions := []models.QuestionFromService{{ID: 3, Type: questiontypes.QuestionTypeFileUpload}}
+		var verr *AnswerValidationError
+		err = service.resolveFileAnswers(ctx, 1, questions, []models.Answer{{QuestionID: 3, Value: otherQuestion.ID}})
+		require.True(t, errors.As(err, &verr))
+		assert.Equal(t, AnswerErrInvalidFile, verr.Errors[0].Code)
+		mockRepo.AssertNotCalled(t, "CreateResponse", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Only the survey owner downloads files, and withdrawing deletes them", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		service, store := newService(t, mockRepo)
+		ref, err := service.UploadFile(ctx, 1, 2, "cv.txt", strings.NewReader("hello"))
+		require.NoError(t, err)
+
+		response := &models.Response{ID: primitive.NewObjectID(), SurveyID: 1, UserID: intPtr(5), Status: models.ResponseStatusSubmitted, SurveyVersion: 1,
+			Answers: []models.Answer{{QuestionID: 2, Value: ref.ID, File: ref}}}
+		mockRepo.On("GetResponseByID", mock.Anything, response.ID).Return(response, nil)
+		mockRepo.On("DeleteResponse", ctx, response.ID).Return(nil)
+		mockRepo.On("ReleaseResponseSlot", ctx, 1).Return(nil)
+
+		_, _, err = service.OpenResponseFile(ctx, response.ID.Hex(), 2)
+		assert.True(t, errors.Is(err, ErrResponseAccessDenied), "the respondent is not the owner")
+
+		content, opened, err := service.OpenResponseFile(userContext(9), response.ID.Hex(), 2)
+		require.NoError(t, err)
+		data, _ := io.ReadAll(content)
+		content.Close()
+		assert.Equal(t, "hello", string(data))
+		assert.Equal(t, ref, opened)
+
+		admin := context.WithValue(userContext(1), contextkeys.UserRolesKey, []string{"admin"})
+		_, _, err = service.OpenResponseFile(admin, response.ID.Hex(), 1)
+		assert.True(t, errors.Is(err, ErrFileNotFound), "question 1 has no file")
+
+		require.NoError(t, service.WithdrawResponse(ctx, response.ID.Hex()))
+		_, err = store.Stat(ctx, fileKey(1, 2, ref.ID))
+		assert.True(t, errors.Is(err, storage.ErrBlobNotFound))
+	})
+}
+
+func TestUploadQuotaReservesBytesOfUploadsInFlight(t *testing.T) {
+	tracker := newUploadQuotaTracker(UploadQuota{MaxBytes: 15, Window: time.Hour})
+	now := time.Now()
+
+	first, err := tracker.reserve("ip:203.0.113.7", 10, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), first.bytes)
+	second, err := tracker.reserve("ip:203.0.113.7", 10, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), second.bytes, "the first upload holds 10 bytes while it is stored")
+	_, err = tracker.reserve("ip:203.0.113.7", 10, now)
+	assert.True(t, errors.Is(err, ErrUploadQuotaExceeded), "uploads in flight hold the whole quota")
+
+	tracker.settle(first, 4)
+	tracker.settle(second, 0)
+	third, err := tracker.reserve("ip:203.0.113.7", 20, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), third.bytes, "only the stored 4 bytes are still used")
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+)
+
+// getRespondentSurveyDetails fetches a survey laid out for the respondent with the given seed, so surveys with
+// shuffled questions or options come with the layout the respondent was shown. Without a seed it is getSurveyDetails.
+func (s *ResponseService) getRespondentSurveyDetails(ctx context.Context, surveyID int, seed string) (*models.SurveyDetailsFromService, error) {
+	if seed == "" {
+		return s.getSurveyDetails(ctx, surveyID)
+	}
+	var surveyDetails models.SurveyDetailsFromService
+	path := fmt.Sprintf("/api/v1/surveys/%d?seed=%s", surveyID, url.QueryEscape(seed))
+	if err := s.getFromSurveyService(ctx, path, &surveyDetails); err != nil {
+		logf("[SERVICE_ERROR] getRespondentSurveyDetails: Failed to fetch SurveyID %d: %v", surveyID, err)
+		return nil, err
+	}
+	return &surveyDetails, nil
+}
+
+// orderEffects compares the answers to a question by the position it and its options were shown at, nil when no
+// response records the question being shuffled
+func orderEffects(q models.QuestionFromService, responses []*models.Response) *models.OrderEffects {
+	questionPositions := make(map[int]*models.QuestionPosition)
+	optionPositions := make(map[int]*models.OptionPosition)
+	for _, resp := range responses {
+		if resp.Layout == nil {
+			continue
+		}
+		var answer models.Answer
+		for _, ans := range resp.Answers {
+			if ans.QuestionID == q.ID {
+				answer = ans
+				break
+			}
+		}
+
+		for i, id := range resp.Layout.QuestionOrder {
+			if id != q.ID {
+				continue
+			}
+			position, ok := questionPositions[i+1]
+			if !ok {
+				position = &models.QuestionPosition{Position: i + 1}
+				questionPositions[i+1] = position
+			}
+			position.Shown++
+			if !isEmptyAnswer(answer.Value) {
+				position.Answered++
+			}
+			break
+		}
+
+		for _, order := range resp.Layout.OptionOrders {
+			if order.QuestionID != q.ID {
+				continue
+			}
+			selected := make(map[int]bool, len(answer.OptionIDs))
+			for _, id := range answer.OptionIDs {
+				selected[id] = true
+			}
+			for i, id := range order.OptionIDs {
+				position, ok := optionPositions[i+1]
+				if !ok {
+					position = &models.OptionPosition{Position: i + 1}
+					optionPositions[i+1] = position
+				}
+				position.Shown++
+				if selected[id] {
+					position.Selected++
+				}
+			}
+			break
+		}
+	}
+
+	effects := &models.OrderEffects{}
+	// A question always shown at the same position has no order effect to compare
+	if len(questionPositions) > 1 {
+		for _, position := range questionPositions {
+			position.ResponseRate = percentageOf(position.Answered, position.Shown)
+			effects.QuestionPositions = append(effects.QuestionPositions, *position)
+		}
+		sort.Slice(effects.QuestionPositions, func(i, j int) bool {
+			return effects.QuestionPositions[i].Position < effects.QuestionPositions[j].Position
+		})
+	}
+	for position := 1; position <= len(optionPositions); position++ {
+		if counts, ok := optionPositions[position]; ok {
+			counts.Percentage = percentageOf(counts.Selected, counts.Shown)
+			effects.OptionPositions = append(effects.OptionPositions, *counts)
+		}
+	}
+	if effects.QuestionPositions == nil && effects.OptionPositions == nil {
+		return nil
+	}
+	return effects
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const layoutTestSurvey = `{"id": 1, "title": "Snacks", "is_active": true, "access_role": "owner", "questions": [
+	{"id": 1, "order_num": 1, "text": "Fruit", "type": "single_choice", "options": [{"id": 11, "text": "Apple"}, {"id": 12, "text": "Pear"}]},
+	{"id": 2, "order_num": 2, "text": "Why?", "type": "text"},
+	{"id": 3, "order_num": 3, "text": "Anything else?", "type": "text"}
+]`
+
+func TestRespondentLayout(t *testing.T) {
+	ctx := userContext(5)
+
+	t.Run("Submitted responses record the layout of their seed", func(t *testing.T) {
+		mockServer, mockURL := setupMockSurveyService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if seed := r.URL.Query().Get("seed"); seed != "" {
+				assert.Equal(t, "respondent 5", seed)
+				w.Write([]byte(layoutTestSurvey + `, "layout": {"seed": "respondent 5", "question_order": [2, 1, 3],
+					"option_orders": [{"question_id": 1, "option_ids": [12, 11]}]}}`))
+				return
+			}
+			w.Write([]byte(layoutTestSurvey + `}`))
+		}))
+		defer mockServer.Close()
+
+		mockRepo := new(MockRepository)
+		want := &models.SurveyLayout{Seed: "respondent 5", QuestionOrder: []int{2, 1, 3}, OptionOrders: []models.OptionOrder{{QuestionID: 1, OptionIDs: []int{12, 11}}}}
+		mockRepo.On("ReserveResponseSlot", ctx, 1, (*int)(nil)).Return(true, nil)
+		mockRepo.On("CreateResponse", ctx, mock.MatchedBy(func(r *models.Response) bool {
+			return assert.ObjectsAreEqual(want, r.Layout)
+		})).Return(nil).Once()
+		mockRepo.On("CreateResponse", ctx, mock.MatchedBy(func(r *models.Response) bool {
+			return r.Layout == nil
+		})).Return(nil).Once()
+		service := NewResponseService(mockRepo, mockURL)
+
+		answers := []models.Answer{{QuestionID: 1, Value: "Pear"}}
+		require.NoError(t, service.SubmitResponse(ctx, &models.CreateResponseRequest{SurveyID: 1, UserID: intPtr(5), Answers: answers, LayoutSeed: "respondent 5"}))
+		require.NoError(t, service.SubmitResponse(ctx, &models.CreateResponseRequest{SurveyID: 1, UserID: intPtr(5), Answers: answers}))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Analytics compare answers by position", func(t *testing.T) {
+		mockServer, mockURL := setupMockSurveyService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(layoutTestSurvey + `}`))
+		}))
+		defer mockServer.Close()
+
+		responses := []*models.Response{
+			{ID: primitive.NewObjectID(), SurveyID: 1,
+				Layout:  &models.SurveyLayout{QuestionOrder: []int{1, 2, 3}, OptionOrders: []models.OptionOrder{{QuestionID: 1, OptionIDs: []int{11, 12}}}},
+				Answers: []models.Answer{{QuestionID: 1, Value: "Apple", OptionIDs: []int{11}}, {QuestionID: 2, Value: "Crunchy"}}},
+			{ID: primitive.NewObjectID(), SurveyID: 1,
+				Layout:  &models.SurveyLayout{QuestionOrder: []int{2, 1, 3}, OptionOrders: []models.OptionOrder{{QuestionID: 1, OptionIDs: []int{12, 11}}}},
+				Answers: []models.Answer{{QuestionID: 1, Value: "Apple", OptionIDs: []int{11}}}},
+			{ID: primitive.NewObjectID(), SurveyID: 1, Answers: []models.Answer{{QuestionID: 1, Value: "Pear", OptionIDs: []int{12}}}},
+		}
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetResponsesBySurveyID", ctx, 1).Return(responses, nil)
+		service := NewResponseService(mockRepo, mockURL)
+
+		analytics, err := service.GetSurveyAnalytics(ctx, 1)
+		require.NoError(t, err)
+
+		fruit := analytics.QuestionAnalytics[0].OrderEffects
+		require.NotNil(t, fruit)
+		assert.Equal(t, []models.QuestionPosition{
+			{Position: 1, Shown: 1, Answered: 1, ResponseRate: 100},
+			{Position: 2, Shown: 1, Answered: 1, ResponseRate: 100},
+		}, fruit.QuestionPositions)
+		assert.Equal(t, []models.OptionPosition{
+			{Position: 1, Shown: 2, Selected: 1, Percentage: 50},
+			{Position: 2, Shown: 2, Selected: 1, Percentage: 50},
+		}, fruit.OptionPositions)
+
+		why := analytics.QuestionAnalytics[1].OrderEffects
+		require.NotNil(t, why)
+		assert.Equal(t, []models.QuestionPosition{
+			{Position: 1, Shown: 1, Answered: 0, ResponseRate: 0},
+			{Position: 2, Shown: 1, Answered: 1, ResponseRate: 100},
+		}, why.QuestionPositions)
+		assert.Nil(t, why.OptionPositions)
+
+		assert.Nil(t, analytics.QuestionAnalytics[2].OrderEffects, "a question always shown third has no order effects")
+	})
+}
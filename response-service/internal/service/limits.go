@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+)
+
+// ErrResponseLimitReached is returned when a survey's response quota or a per-respondent limit rejects a submission
+var ErrResponseLimitReached = errors.New("response limit reached")
+
+// ErrDeviceTokenRequired is returned when an anonymous response to a survey limited per device carries no device token
+var ErrDeviceTokenRequired = errors.New("a device token is required to respond to this survey")
+
+// Anonymous respondent limits configured on a survey
+const (
+	anonymousLimitIP     = "ip"
+	anonymousLimitDevice = "device"
+)
+
+// checkResponseLimits enforces the survey's per-respondent limits; the response quota is enforced by reserveResponseSlot.
+// It returns the limit keys to store on the response, so the unique index also rejects concurrent duplicates.
+func (s *ResponseService) checkResponseLimits(ctx context.Context, survey *models.SurveyDetailsFromService, req *models.CreateResponseRequest) ([]string, error) {
+	if req.UserID != nil {
+		if !survey.OneResponsePerUser {
+			return nil, nil
+		}
+		responded, err := s.repo.HasUserResponded(ctx, req.SurveyID, *req.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up previous responses (SurveyID: %d): %w", req.SurveyID, err)
+		}
+		if responded {
+			return nil, fmt.Errorf("%w: you have already responded to this survey", ErrResponseLimitReached)
+		}
+		return []string{fmt.Sprintf("user:%d", *req.UserID)}, nil
+	}
+
+	var limitKey, source string
+	switch survey.AnonymousLimit {
+	case anonymousLimitIP:
+		if req.ClientIP == "" {
+			return nil, nil
+		}
+		limitKey, source = hashedLimitKey(anonymousLimitIP, req.ClientIP), "from this network"
+	case anonymousLimitDevice:
+		if req.DeviceToken == "" {
+			return nil, ErrDeviceTokenRequired
+		}
+		limitKey, source = hashedLimitKey(anonymousLimitDevice, req.DeviceToken), "from this device"
+	default:
+		return nil, nil
+	}
+
+	exists, err := s.repo.HasResponseWithLimitKey(ctx, req.SurveyID, limitKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up previous responses (SurveyID: %d): %w", req.SurveyID, err)
+	}
+	if exists {
+		return nil, fmt.Errorf("%w: a response was already submitted %s", ErrResponseLimitReached, source)
+	}
+	return []string{limitKey}, nil
+}
+
+// reserveResponseSlot counts a submission against the survey's response quota before it is stored. The counter
+// is updated atomically, so concurrent submissions cannot exceed max_responses. Callers must release the slot
+// if the response is not stored.
+func (s *ResponseService) reserveResponseSlot(ctx context.Context, surveyID int, survey *models.SurveyDetailsFromService) error {
+	reserved, err := s.repo.ReserveResponseSlot(ctx, surveyID, survey.MaxResponses)
+	if err != nil {
+		return fmt.Errorf("failed to reserve a response slot (SurveyID: %d): %w", surveyID, err)
+	}
+	if !reserved {
+		return fmt.Errorf("%w: the survey accepts at most %d responses", ErrResponseLimitReached, *survey.MaxResponses)
+	}
+	return nil
+}
+
+// releaseResponseSlot gives back a slot reserved for a response that was not stored or has been withdrawn
+func (s *ResponseService) releaseResponseSlot(ctx context.Context, surveyID int) {
+	if err := s.repo.ReleaseResponseSlot(ctx, surveyID); err != nil {
+		logf("[SERVICE_ERROR] Failed to release a response slot for SurveyID %d: %v", surveyID, err)
+	}
+}
+
+// hashedLimitKey builds a limit key without storing the raw IP address or device token
+func hashedLimitKey(kind, value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return kind + ":" + hex.EncodeToString(sum[:])
+}
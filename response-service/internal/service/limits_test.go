@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// limitedSurveyHandler serves an open single-question survey with the given response limit settings
+func limitedSurveyHandler(limits string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(fmt.Sprintf(`{
+			"id": 1, "title": "Limited", "is_active": true, "status": "open", "allow_anonymous": true, %s
+			"questions": [{"id": 1, "text": "Question 1", "type": "text"}]
+		}`, limits)))
+	})
+}
+
+func limitedRequest(userID *int) *models.CreateResponseRequest {
+	return &models.CreateResponseRequest{
+		SurveyID: 1,
+		UserID:   userID,
+		Answers:  []models.Answer{{QuestionID: 1, Value: "Answer"}},
+		ClientIP: "203.0.113.7",
+	}
+}
+
+func TestSubmitResponseLimits(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Quota reached", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockServer, mockURL := setupMockSurveyService(t, limitedSurveyHandler(`"max_responses": 2,`))
+		defer mockServer.Close()
+
+		mockRepo.On("ReserveResponseSlot", ctx, 1, intPtr(2)).Return(false, nil)
+
+		err := NewResponseService(mockRepo, mockURL).SubmitResponse(ctx, limitedRequest(nil))
+		assert.True(t, errors.Is(err, ErrResponseLimitReached))
+		mockRepo.AssertNotCalled(t, "CreateResponse", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Quota not reached", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockServer, mockURL := setupMockSurveyService(t, limitedSurveyHandler(`"max_responses": 2,`))
+		defer mockServer.Close()
+
+		mockRepo.On("ReserveResponseSlot", ctx, 1, intPtr(2)).Return(true, nil)
+		mockRepo.On("CreateResponse", ctx, mock.MatchedBy(func(r *models.Response) bool {
+			return len(r.LimitKeys) == 0
+		})).Return(nil)
+
+		err := NewResponseService(mockRepo, mockURL).SubmitResponse(ctx, limitedRequest(nil))
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("One response per user", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockServer, mockURL := setupMockSurveyService(t, limitedSurveyHandler(`"one_response_per_user": true,`))
+		defer mockServer.Close()
+		service := NewResponseService(mockRepo, mockURL)
+
+		mockRepo.On("HasUserResponded", ctx, 1, 5).Return(false, nil).Once()
+		mockRepo.On("ReserveResponseSlot", ctx, 1, (*int)(nil)).Return(true, nil).Once()
+		mockRepo.On("CreateResponse", ctx, mock.MatchedBy(func(r *models.Response) bool {
+			return assert.ObjectsAreEqual([]string{"user:5"}, r.LimitKeys)
+		})).Return(nil).Once()
+		assert.NoError(t, service.SubmitResponse(ctx, limitedRequest(intPtr(5))))
+
+		mockRepo.On("HasUserResponded", ctx, 1, 5).Return(true, nil).Once()
+		err := service.SubmitResponse(ctx, limitedRequest(intPtr(5)))
+		assert.True(t, errors.Is(err, ErrResponseLimitReached))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Concurrent duplicate rejected by the index", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockServer, mockURL := setupMockSurveyService(t, limitedSurveyHandler(`"one_response_per_user": true,`))
+		defer mockServer.Close()
+
+		mockRepo.On("HasUserResponded", ctx, 1, 5).Return(false, nil)
+		mockRepo.On("ReserveResponseSlot", ctx, 1, (*int)(nil)).Return(true, nil)
+		mockRepo.On("CreateResponse", ctx, mock.Anything).Return(fmt.Errorf("%w for surveyID 1", repository.ErrDuplicateResponse))
+		mockRepo.On("ReleaseResponseSlot", ctx, 1).Return(nil)
+
+		err := NewResponseService(mockRepo, mockURL).SubmitResponse(ctx, limitedRequest(intPtr(5)))
+		assert.True(t, errors.Is(err, ErrResponseLimitReached))
+		mockRepo.AssertExpectations(t) // The reserved quota slot is given back
+	})
+
+	t.Run("One anonymous response per IP", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockServer, mockURL := setupMockSurveyService(t, limitedSurveyHandler(`"anonymous_limit": "ip",`))
+		defer mockServer.Close()
+
+		ipKey := mock.MatchedBy(func(key string) bool {
+			return strings.HasPrefix(key, "ip:") && !strings.Contains(key, "203.0.113.7")
+		})
+		mockRepo.On("HasResponseWithLimitKey", ctx, 1, ipKey).Return(true, nil)
+
+		err := NewResponseService(mockRepo, mockURL).SubmitResponse(ctx, limitedRequest(nil))
+		assert.True(t, errors.Is(err, ErrResponseLimitReached))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Device limit requires a token", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockServer, mockURL := setupMockSurveyService(t, limitedSurveyHandler(`"anonymous_limit": "device",`))
+		defer mockServer.Close()
+		service := NewResponseService(mockRepo, mockURL)
+
+		err := service.SubmitResponse(ctx, limitedRequest(nil))
+		assert.True(t, errors.Is(err, ErrDeviceTokenRequired))
+
+		req := limitedRequest(nil)
+		req.DeviceToken = "device-abc"
+		mockRepo.On("HasResponseWithLimitKey", ctx, 1, hashedLimitKey(anonymousLimitDevice, "device-abc")).Return(false, nil)
+		mockRepo.On("ReserveResponseSlot", ctx, 1, (*int)(nil)).Return(true, nil)
+		mockRepo.On("CreateResponse", ctx, mock.MatchedBy(func(r *models.Response) bool {
+			return len(r.LimitKeys) == 1 && r.LimitKeys[0] == hashedLimitKey(anonymousLimitDevice, "device-abc")
+		})).Return(nil)
+		assert.NoError(t, service.SubmitResponse(ctx, req))
+		mockRepo.AssertExpectations(t)
+	})
+}
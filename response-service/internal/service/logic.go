@@ -0,0 +1,136 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+)
+
+// Operators of display and skip rules, as defined by survey-service
+const (
+	logicOperatorEquals    = "equals"
+	logicOperatorNotEquals = "not_equals"
+	logicOperatorIncludes  = "includes"
+)
+
+// hiddenQuestions walks the survey in order, applying its display and skip rules to the submitted answers,
+// and returns the IDs of the questions the respondent never reached.
+// Answers to hidden questions are ignored while evaluating later rules.
+func hiddenQuestions(questions []models.QuestionFromService, answers []models.Answer) map[int]bool {
+	answersByID := make(map[int]*models.Answer, len(answers))
+	for i := range answers {
+		if _, seen := answersByID[answers[i].QuestionID]; !seen {
+			answersByID[answers[i].QuestionID] = &answers[i]
+		}
+	}
+
+	ordered := make([]models.QuestionFromService, len(questions))
+	copy(ordered, questions)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].OrderNum < ordered[j].OrderNum })
+
+	// Rules refer to questions by ID, or by OrderNum when they were saved before IDs were stored
+	idsByOrder := make(map[int]int, len(ordered))
+	orderByID := make(map[int]int, len(ordered))
+	for _, q := range ordered {
+		idsByOrder[q.OrderNum] = q.ID
+		orderByID[q.ID] = q.OrderNum
+	}
+
+	hidden := make(map[int]bool)
+	visibleAnswers := make(map[int]*models.Answer, len(answers)) // Keyed by question ID
+	skipUntil := 0
+	for _, q := range ordered {
+		if q.OrderNum < skipUntil || !displayRulesMatch(q.DisplayRules, visibleAnswers, idsByOrder) {
+			hidden[q.ID] = true
+			continue
+		}
+
+		answer := answersByID[q.ID]
+		if answer == nil {
+			continue
+		}
+		visibleAnswers[q.ID] = answer
+		if isEmptyAnswer(answer.Value) {
+			continue
+		}
+		for _, rule := range q.SkipRules {
+			if matchesLogicRule(rule.Operator, rule.OptionID, rule.Value, answer) {
+				skipTo := rule.SkipTo
+				if order, ok := orderByID[rule.SkipToQuestionID]; ok {
+					skipTo = order
+				}
+				if skipTo > skipUntil {
+					skipUntil = skipTo
+				}
+				break
+			}
+		}
+	}
+	return hidden
+}
+
+func displayRulesMatch(rules []models.DisplayRuleFromService, visibleAnswers map[int]*models.Answer, idsByOrder map[int]int) bool {
+	for _, rule := range rules {
+		questionID := rule.QuestionID
+		if questionID == 0 {
+			questionID = idsByOrder[rule.Question]
+		}
+		if !matchesLogicRule(rule.Operator, rule.OptionID, rule.Value, visibleAnswers[questionID]) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesLogicRule compares an answer with a rule. Rules on choice questions compare the selected option IDs with
+// optionID, other rules compare the answer's text with expected. An unanswered question only matches not_equals.
+func matchesLogicRule(operator string, optionID int, expected string, answer *models.Answer) bool {
+	var selected []string
+	if answer != nil {
+		if optionID != 0 {
+			for _, id := range answer.OptionIDs {
+				selected = append(selected, strconv.Itoa(id))
+			}
+			expected = strconv.Itoa(optionID)
+		} else {
+			selected = answerValues(answer.Value)
+		}
+	} else if optionID != 0 {
+		expected = strconv.Itoa(optionID)
+	}
+
+	equals := len(selected) == 1 && selected[0] == expected
+	switch operator {
+	case logicOperatorEquals:
+		return equals
+	case logicOperatorNotEquals:
+		return !equals
+	case logicOperatorIncludes:
+		for _, v := range selected {
+			if v == expected {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// answerValues lists the selected values of an answer as text, empty when unanswered
+func answerValues(answer interface{}) []string {
+	if isEmptyAnswer(answer) {
+		return nil
+	}
+	if list, ok := toStringSlice(answer); ok {
+		return list
+	}
+	switch v := answer.(type) {
+	case string:
+		return []string{v}
+	case float64:
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
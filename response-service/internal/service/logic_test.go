@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// logicTestQuestions models "if Q1 = 'No', skip to Q3" and "show Q4 only if Q3 includes 'Other'"
+func logicTestQuestions() []models.QuestionFromService {
+	return []models.QuestionFromService{
+		{ID: 11, OrderNum: 1, Text: "Do you own a car?", Type: "multiple_choice", Required: true,
+			Options:   []models.QuestionOptionFromService{{ID: 101, Text: "Yes"}, {ID: 102, Text: "No"}},
+			SkipRules: []models.SkipRuleFromService{{Operator: "equals", OptionID: 102, SkipToQuestionID: 13}}},
+		{ID: 12, OrderNum: 2, Text: "Which brand?", Type: "text", Required: true},
+		{ID: 13, OrderNum: 3, Text: "How do you commute?", Type: "checkbox",
+			Options: []models.QuestionOptionFromService{{ID: 131, Text: "Bus"}, {ID: 132, Text: "Other"}}},
+		{ID: 14, OrderNum: 4, Text: "Describe other", Type: "text", Required: true,
+			DisplayRules: []models.DisplayRuleFromService{{QuestionID: 13, Operator: "includes", OptionID: 132}}},
+	}
+}
+
+// legacyLogicTestQuestions are logicTestQuestions with rules saved before question and option IDs were stored
+func legacyLogicTestQuestions() []models.QuestionFromService {
+	questions := logicTestQuestions()
+	questions[0].SkipRules = []models.SkipRuleFromService{{Operator: "equals", Value: "No", SkipTo: 3}}
+	questions[3].DisplayRules = []models.DisplayRuleFromService{{Question: 3, Operator: "includes", Value: "Other"}}
+	return questions
+}
+
+func TestValidateAnswersWithLogic(t *testing.T) {
+	tests := []struct {
+		name      string
+		answers   []models.Answer
+		wantCodes map[int]string
+	}{
+		{
+			name: "Skipped required question is not missing",
+			answers: []models.Answer{
+				{QuestionID: 11, Value: "No"},
+				{QuestionID: 13, Value: []interface{}{"Bus"}},
+			},
+		},
+		{
+			name: "Required question is enforced when not skipped",
+			answers: []models.Answer{
+				{QuestionID: 11, Value: "Yes"},
+			},
+			wantCodes: map[int]string{12: AnswerErrRequired},
+		},
+		{
+			name: "Answer to a skipped question is rejected",
+			answers: []models.Answer{
+				{QuestionID: 11, Value: "No"},
+				{QuestionID: 12, Value: "Volvo"},
+			},
+			wantCodes: map[int]string{12: AnswerErrHiddenQuestion},
+		},
+		{
+			name: "Displayed question becomes required",
+			answers: []models.Answer{
+				{QuestionID: 11, Value: "No"},
+				{QuestionID: 13, Value: []interface{}{"Bus", "Other"}},
+			},
+			wantCodes: map[int]string{14: AnswerErrRequired},
+		},
+		{
+			name: "Answer to a hidden question is rejected",
+			answers: []models.Answer{
+				{QuestionID: 11, Value: "Yes"},
+				{QuestionID: 12, Value: "Volvo"},
+				{QuestionID: 14, Value: "Scooter"},
+			},
+			wantCodes: map[int]string{14: AnswerErrHiddenQuestion},
+		},
+	}
+
+	for _, tt := range tests {
+		for name, questions := range map[string]func() []models.QuestionFromService{
+			"ids":    logicTestQuestions,
+			"legacy": legacyLogicTestQuestions,
+		} {
+			t.Run(tt.name+"/"+name, func(t *testing.T) {
+				answers := make([]models.Answer, len(tt.answers))
+				copy(answers, tt.answers)
+				err := validateAnswers(questions(), answers)
+				if tt.wantCodes == nil {
+					assert.NoError(t, err)
+					return
+				}
+
+				var verr *AnswerValidationError
+				if assert.True(t, errors.As(err, &verr)) {
+					gotCodes := make(map[int]string)
+					for _, e := range verr.Errors {
+						gotCodes[e.QuestionID] = e.Code
+					}
+					assert.Equal(t, tt.wantCodes, gotCodes)
+				}
+			})
+		}
+	}
+}
+
+func TestQuestionLogicFollowsQuestionsAndOptions(t *testing.T) {
+	// Q3 moved before Q2 and its "Other" option renamed: the rules still target the same question and option
+	questions := logicTestQuestions()
+	questions[1].OrderNum, questions[2].OrderNum = 3, 2
+	questions[2].Options[1].Text = "Something else"
+
+	err := validateAnswers(questions, []models.Answer{
+		{QuestionID: 11, Value: "No"},
+		{QuestionID: 13, Value: []interface{}{"Something else"}},
+		{QuestionID: 12, Value: "Volvo"},
+	})
+	var verr *AnswerValidationError
+	if assert.True(t, errors.As(err, &verr)) && assert.Len(t, verr.Errors, 1) {
+		assert.Equal(t, 14, verr.Errors[0].QuestionID)
+		assert.Equal(t, AnswerErrRequired, verr.Errors[0].Code)
+	}
+
+	hidden := hiddenQuestions(questions, []models.Answer{{QuestionID: 11, Value: "No", OptionIDs: []int{102}}})
+	assert.Equal(t, map[int]bool{14: true}, hidden, "Q2 now comes before the skip target and is not skipped")
+}
+
+func TestSubmitResponseAppliesQuestionLogic(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	mockServer, mockURL := setupMockSurveyService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"id": 1, "title": "Commute", "is_active": true, "status": "open", "allow_anonymous": true,
+			"questions": [
+				{"id": 11, "order_num": 1, "text": "Own a car?", "type": "multiple_choice", "required": true,
+				 "options": [{"id": 101, "text": "Yes"}, {"id": 102, "text": "No"}],
+				 "skip_rules": [{"operator": "equals", "option_id": 102, "skip_to_question_id": 13}]},
+				{"id": 12, "order_num": 2, "text": "Which brand?", "type": "text", "required": true},
+				{"id": 13, "order_num": 3, "text": "Anything else?", "type": "text"}
+			]
+		}`))
+	}))
+	defer mockServer.Close()
+	service := NewResponseService(mockRepo, mockURL)
+
+	mockRepo.On("ReserveResponseSlot", ctx, 1, (*int)(nil)).Return(true, nil).Once()
+	mockRepo.On("CreateResponse", ctx, mock.Anything).Return(nil).Once()
+	err := service.SubmitResponse(ctx, &models.CreateResponseRequest{SurveyID: 1, Answers: []models.Answer{{QuestionID: 11, Value: "No"}}})
+	assert.NoError(t, err)
+
+	err = service.SubmitResponse(ctx, &models.CreateResponseRequest{SurveyID: 1, Answers: []models.Answer{
+		{QuestionID: 11, Value: "No"},
+		{QuestionID: 12, Value: "Volvo"},
+	}})
+	assert.True(t, errors.Is(err, ErrInvalidAnswers))
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/contextkeys"
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const matrixTestConfig = `{"rows": ["Fast", "Easy"], "columns": ["Disagree", "Neutral", "Agree"]}`
+
+func matrixTestQuestion() models.QuestionFromService {
+	return models.QuestionFromService{ID: 1, OrderNum: 1, Text: "The app is", Type: "matrix", Required: true, Config: []byte(matrixTestConfig)}
+}
+
+func TestMatrixAnswerValidation(t *testing.T) {
+	questions := []models.QuestionFromService{matrixTestQuestion()}
+
+	tests := []struct {
+		name      string
+		value     interface{}
+		wantCodes []string
+	}{
+		{name: "Every row answered", value: map[string]interface{}{"Fast": "Agree", "Easy": "Neutral"}},
+		{name: "Stored BSON document", value: primitive.D{{Key: "Fast", Value: "Agree"}, {Key: "Easy", Value: "Agree"}}},
+		{name: "Not an object", value: "Agree", wantCodes: []string{AnswerErrInvalidType}},
+		{name: "Unknown row and column", value: map[string]interface{}{"Fast": "Maybe", "Easy": "Agree", "Cheap": "Agree"}, wantCodes: []string{AnswerErrInvalidOption, AnswerErrInvalidOption}},
+		{name: "Row missing from a required matrix", value: map[string]interface{}{"Fast": "Agree"}, wantCodes: []string{AnswerErrRequired}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAnswers(questions, []models.Answer{{QuestionID: 1, Value: tt.value}})
+			if tt.wantCodes == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var verr *AnswerValidationError
+			if assert.True(t, errors.As(err, &verr)) {
+				var codes []string
+				for _, e := range verr.Errors {
+					codes = append(codes, e.Code)
+				}
+				assert.Equal(t, tt.wantCodes, codes)
+			}
+		})
+	}
+
+	t.Run("Drafts may leave rows unanswered", func(t *testing.T) {
+		assert.NoError(t, validateDraftAnswers(questions, []models.Answer{{QuestionID: 1, Value: map[string]interface{}{"Fast": "Agree"}}}))
+	})
+}
+
+func TestMatrixAnalyticsAndExport(t *testing.T) {
+	mockRepo := new(MockRepository)
+	ctx := context.WithValue(context.Background(), contextkeys.UserIDKey, 1)
+	mockServer, mockURL := setupMockSurveyService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1, "title": "App", "is_active": true, "access_role": "owner", "questions": [
+			{"id": 1, "order_num": 1, "text": "The app is", "type": "matrix", "config": ` + matrixTestConfig + `}
+		]}`))
+	}))
+	defer mockServer.Close()
+
+	responses := []*models.Response{
+		{ID: primitive.NewObjectID(), SurveyID: 1, Answers: []models.Answer{{QuestionID: 1, Value: primitive.M{"Fast": "Agree", "Easy": "Neutral"}}}},
+		{ID: primitive.NewObjectID(), SurveyID: 1, Answers: []models.Answer{{QuestionID: 1, Value: primitive.M{"Fast": "Disagree"}}}},
+	}
+	mockRepo.On("GetResponsesBySurveyID", ctx, 1).Return(responses, nil)
+	service := NewResponseService(mockRepo, mockURL)
+
+	analytics, err := service.GetSurveyAnalytics(ctx, 1)
+	require.NoError(t, err)
+	rows := analytics.QuestionAnalytics[0].RowsSummary
+	require.Len(t, rows, 2)
+	assert.Equal(t, "Fast", rows[0].Row)
+	assert.Equal(t, 2, rows[0].Responses)
+	assert.Equal(t, []int{1, 0, 1}, []int{rows[0].ColumnsSummary[0].Count, rows[0].ColumnsSummary[1].Count, rows[0].ColumnsSummary[2].Count})
+	assert.Equal(t, 50.0, rows[0].ColumnsSummary[2].Percentage)
+	assert.Equal(t, 1, rows[1].Responses)
+	assert.Equal(t, 100.0, rows[1].ColumnsSummary[1].Percentage)
+
+	csvData, _, err := service.ExportSurveyResponsesCSV(ctx, 1)
+	require.NoError(t, err)
+	records, err := csv.NewReader(strings.NewReader(csvData)).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, []string{"The app is [Fast]", "The app is [Easy]"}, records[0][4:])
+	assert.Equal(t, []string{"Agree", "Neutral"}, records[1][4:])
+	assert.Equal(t, []string{"Disagree", ""}, records[2][4:])
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/shared/questiontypes"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// optionSelection tells how answers to a question type select the question's options. Such answers are stored
+// with the IDs of the selected options, so renaming an option keeps its answers.
+type optionSelection int
+
+const (
+	selectsNoOption   optionSelection = iota
+	selectsOneOption                  // The value is the text of one option
+	selectsOptionList                 // The value lists option texts, e.g. ticked checkboxes or a ranking
+)
+
+// normalizeOptionAnswers makes answers to questions selecting options carry both the options' IDs and their texts.
+// Answers sent with OptionIDs get their value from the current texts of those options; answers sent with texts
+// get the IDs of the options they name. Texts matching no option are left for validation to report.
+// OptionIDs sent for other questions are discarded.
+func normalizeOptionAnswers(verr *AnswerValidationError, questionsByID map[int]models.QuestionFromService, answers []models.Answer) {
+	for i := range answers {
+		ans := &answers[i]
+		q, ok := questionsByID[ans.QuestionID]
+		selection := optionSelectionOf(q)
+		if !ok || selection == selectsNoOption {
+			ans.OptionIDs = nil
+			continue
+		}
+		if len(ans.OptionIDs) > 0 {
+			value, ok := valueOfOptionIDs(verr, q, selection, ans)
+			if !ok {
+				continue
+			}
+			ans.Value = value
+		}
+		ans.OptionIDs = optionIDsOf(q, ans.Value)
+	}
+}
+
+func optionSelectionOf(q models.QuestionFromService) optionSelection {
+	kind, _ := answerKindOf(q.Type)
+	return kind.optionSelection
+}
+
+// valueOfOptionIDs builds the value of an answer sent with option IDs. An "Other" selection in the value is kept.
+func valueOfOptionIDs(verr *AnswerValidationError, q models.QuestionFromService, selection optionSelection, ans *models.Answer) (interface{}, bool) {
+	textsByID := make(map[int]string, len(q.Options))
+	for _, opt := range q.Options {
+		textsByID[opt.ID] = opt.Text
+	}
+
+	texts := make([]interface{}, 0, len(ans.OptionIDs)+1)
+	for _, id := range ans.OptionIDs {
+		text, ok := textsByID[id]
+		if !ok {
+			verr.add(q.ID, AnswerErrInvalidOption, "option %d is not an option of question %d", id, q.ID)
+			return nil, false
+		}
+		texts = append(texts, text)
+	}
+
+	if selection == selectsOneOption {
+		if len(texts) != 1 {
+			verr.add(q.ID, AnswerErrInvalidOption, "question %d takes a single option, got %d", q.ID, len(texts))
+			return nil, false
+		}
+		return texts[0], true
+	}
+	if selectsOther(ans.Value) {
+		texts = append(texts, questiontypes.OtherOptionValue)
+	}
+	return texts, true
+}
+
+// optionIDsOf returns the IDs of the options an answer value names, in the order of the value.
+// "Other" and texts matching no option have no ID and are skipped.
+func optionIDsOf(q models.QuestionFromService, value interface{}) []int {
+	var ids []int
+	mapOptionTexts(value, func(text string) string {
+		for _, opt := range q.Options {
+			if opt.Text == text {
+				ids = append(ids, opt.ID)
+				break
+			}
+		}
+		return text
+	})
+	return ids
+}
+
+// mapOptionTexts applies rename to each option text of a single-option or option-list value
+func mapOptionTexts(value interface{}, rename func(text string) string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return rename(v)
+	case primitive.A:
+		result := make(primitive.A, len(v))
+		for i, item := range v {
+			if text, ok := item.(string); ok {
+				result[i] = rename(text)
+			} else {
+				result[i] = item
+			}
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			if text, ok := item.(string); ok {
+				result[i] = rename(text)
+			} else {
+				result[i] = item
+			}
+		}
+		return result
+	case []string:
+		result := make([]string, len(v))
+		for i, text := range v {
+			result[i] = rename(text)
+		}
+		return result
+	}
+	return value
+}
+
+// currentOptionValue rewrites an answer's option texts to the current texts of its OptionIDs, which list the
+// selected options in the order of the value. Options removed since keep the text they were answered with.
+func currentOptionValue(ans models.Answer, currentOptionTexts map[int]string) interface{} {
+	next := 0
+	return mapOptionTexts(ans.Value, func(text string) string {
+		if text == questiontypes.OtherOptionValue || next >= len(ans.OptionIDs) {
+			return text
+		}
+		id := ans.OptionIDs[next]
+		next++
+		if current, ok := currentOptionTexts[id]; ok {
+			return current
+		}
+		return text
+	})
+}
+
+// OptionIDMigrationReport summarises a run of MigrateOptionIDs
+type OptionIDMigrationReport struct {
+	Surveys          int // Surveys whose responses were examined
+	SkippedSurveys   int // Surveys the survey-service could not return, e.g. because they were deleted
+	Responses        int
+	UpdatedResponses int
+	UnmatchedAnswers int // Answers naming a text that no option of their question has, left without IDs
+}
+
+// MigrateOptionIDs stores option IDs with the choice answers saved before answers carried them. Texts are matched
+// to the options of the survey version each response was given on, or to the current options when that version
+// is unavailable. Answers are only given IDs when all their texts match. With dryRun nothing is written.
+func (s *ResponseService) MigrateOptionIDs(ctx context.Context, dryRun bool) (*OptionIDMigrationReport, error) {
+	surveyIDs, err := s.repo.GetSurveyIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &OptionIDMigrationReport{}
+	for _, surveyID := range surveyIDs {
+		current, err := s.getSurveyDetails(ctx, surveyID)
+		if err != nil {
+			logf("[SERVICE_WARN] MigrateOptionIDs: Skipping SurveyID %d: %v", surveyID, err)
+			report.SkippedSurveys++
+			continue
+		}
+		responses, err := s.repo.GetAllResponsesBySurveyID(ctx, surveyID)
+		if err != nil {
+			return report, err
+		}
+		report.Surveys++
+		report.Responses += len(responses)
+
+		versions := s.getAnsweredVersions(ctx, current, responses)
+		for _, resp := range responses {
+			answeredOn := current
+			if version := versions[resp.SurveyVersion]; version != nil {
+				answeredOn = version
+			}
+			answers, unmatched := withOptionIDs(answeredOn.Questions, resp.Answers)
+			report.UnmatchedAnswers += unmatched
+			if answers == nil {
+				continue
+			}
+			report.UpdatedResponses++
+			if dryRun {
+				continue
+			}
+			if err := s.repo.SetResponseAnswers(ctx, resp.ID, answers); err != nil {
+				return report, err
+			}
+		}
+	}
+	return report, nil
+}
+
+// withOptionIDs returns a copy of answers with option IDs added where they are missing, or nil if none could be
+// added. It also counts the answers left without IDs because some of their texts match no option.
+func withOptionIDs(questions []models.QuestionFromService, answers []models.Answer) ([]models.Answer, int) {
+	questionsByID := make(map[int]models.QuestionFromService, len(questions))
+	for _, q := range questions {
+		questionsByID[q.ID] = q
+	}
+
+	var updated []models.Answer
+	unmatched := 0
+	for i, ans := range answers {
+		q, ok := questionsByID[ans.QuestionID]
+		if !ok || optionSelectionOf(q) == selectsNoOption || len(ans.OptionIDs) > 0 || isEmptyAnswer(ans.Value) {
+			continue
+		}
+		texts := 0
+		mapOptionTexts(ans.Value, func(text string) string {
+			if text != questiontypes.OtherOptionValue {
+				texts++
+			}
+			return text
+		})
+		ids := optionIDsOf(q, ans.Value)
+		if len(ids) != texts {
+			unmatched++
+			continue
+		}
+		if len(ids) == 0 {
+			continue // Only "Other" was selected
+		}
+		if updated == nil {
+			updated = append([]models.Answer(nil), answers...)
+		}
+		updated[i].OptionIDs = ids
+	}
+	return updated, unmatched
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/shared/questiontypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const optionIDTestQuestions = `[
+	{"id": 1, "order_num": 1, "text": "Fruit", "type": "single_choice",
+		"options": [{"id": 11, "text": "Apple"}, {"id": 12, "text": "Pears"}]},
+	{"id": 2, "order_num": 2, "text": "Heard of us via", "type": "checkbox", "config": {"allow_other": true},
+		"options": [{"id": 21, "text": "Friends"}, {"id": 22, "text": "Search"}]},
+	{"id": 3, "order_num": 3, "text": "Comments", "type": "text"}
+]`
+
+func optionIDSurveyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/surveys/1" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1, "title": "Fruit", "is_active": true, "access_role": "owner", "questions": ` + optionIDTestQuestions + `}`))
+	})
+}
+
+func TestNormalizeOptionAnswers(t *testing.T) {
+	var questions []models.QuestionFromService
+	require.NoError(t, json.Unmarshal([]byte(optionIDTestQuestions), &questions))
+
+	tests := []struct {
+		name     string
+		answer   models.Answer
+		want     models.Answer
+		wantCode string
+	}{
+		{
+			name:   "IDs give the current texts",
+			answer: models.Answer{QuestionID: 1, Value: "Pear", OptionIDs: []int{12}},
+			want:   models.Answer{QuestionID: 1, Value: "Pears", OptionIDs: []int{12}},
+		},
+		{
+			name:   "Texts give the IDs",
+			answer: models.Answer{QuestionID: 2, Value: []interface{}{"Search", "Friends"}},
+			want:   models.Answer{QuestionID: 2, Value: []interface{}{"Search", "Friends"}, OptionIDs: []int{22, 21}},
+		},
+		{
+			name:   "Other is kept alongside IDs",
+			answer: models.Answer{QuestionID: 2, Value: []interface{}{questiontypes.OtherOptionValue}, OptionIDs: []int{21}, OtherText: "A podcast"},
+			want:   models.Answer{QuestionID: 2, Value: []interface{}{"Friends", questiontypes.OtherOptionValue}, OptionIDs: []int{21}, OtherText: "A podcast"},
+		},
+		{
+			name:   "IDs of questions without options are dropped",
+			answer: models.Answer{QuestionID: 3, Value: "Nice", OptionIDs: []int{11}},
+			want:   models.Answer{QuestionID: 3, Value: "Nice"},
+		},
+		{
+			name:     "Unknown ID",
+			answer:   models.Answer{QuestionID: 1, Value: "Apple", OptionIDs: []int{21}},
+			wantCode: AnswerErrInvalidOption,
+		},
+		{
+			name:     "Several IDs for a single choice",
+			answer:   models.Answer{QuestionID: 1, OptionIDs: []int{11, 12}},
+			wantCode: AnswerErrInvalidOption,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			answers := []models.Answer{tt.answer}
+			err := validateAnswers(questions, answers)
+			if tt.wantCode == "" {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, answers[0])
+				return
+			}
+			var verr *AnswerValidationError
+			if assert.True(t, errors.As(err, &verr)) {
+				assert.Equal(t, tt.wantCode, verr.Errors[0].Code)
+			}
+		})
+	}
+}
+
+func TestRenamedOptionsKeepTheirAnswers(t *testing.T) {
+	mockRepo := new(MockRepository)
+	ctx := userContext(1)
+	mockServer, mockURL := setupMockSurveyService(t, optionIDSurveyHandler())
+	defer mockServer.Close()
+
+	// "Pears" was called "Pear" when these answers were given
+	responses := []*models.Response{
+		{ID: primitive.NewObjectID(), SurveyID: 1, Answers: []models.Answer{{QuestionID: 1, Value: "Pear", OptionIDs: []int{12}}}},
+		{ID: primitive.NewObjectID(), SurveyID: 1, Answers: []models.Answer{{QuestionID: 1, Value: "Pears", OptionIDs: []int{12}}}},
+		{ID: primitive.NewObjectID(), SurveyID: 1, Answers: []models.Answer{{QuestionID: 1, Value: "Apple", OptionIDs: []int{11}}}},
+	}
+	mockRepo.On("GetResponsesBySurveyID", ctx, 1).Return(responses, nil)
+	service := NewResponseService(mockRepo, mockURL)
+
+	analytics, err := service.GetSurveyAnalytics(ctx, 1)
+	require.NoError(t, err)
+	summary := analytics.QuestionAnalytics[0].OptionsSummary
+	require.Len(t, summary, 2)
+	assert.Equal(t, "Pears", summary[1].OptionText)
+	assert.Equal(t, 2, summary[1].Count)
+	assert.Equal(t, "Pear", responses[0].Answers[0].Value, "stored responses are not modified")
+}
+
+func TestMigrateOptionIDs(t *testing.T) {
+	ctx := userContext(1)
+	mockServer, mockURL := setupMockSurveyService(t, optionIDSurveyHandler())
+	defer mockServer.Close()
+
+	newResponses := func() []*models.Response {
+		return []*models.Response{
+			{ID: primitive.ObjectID{1}, SurveyID: 1, Answers: []models.Answer{
+				{QuestionID: 1, Value: "Apple"},
+				{QuestionID: 2, Value: primitive.A{"Search", questiontypes.OtherOptionValue}, OtherText: "A podcast"},
+				{QuestionID: 3, Value: "Tasty"},
+			}},
+			{ID: primitive.ObjectID{2}, SurveyID: 1, Answers: []models.Answer{{QuestionID: 1, Value: "Apple", OptionIDs: []int{11}}}},
+			{ID: primitive.ObjectID{3}, SurveyID: 1, Answers: []models.Answer{{QuestionID: 1, Value: "Banana"}}},
+			{ID: primitive.ObjectID{4}, SurveyID: 1, Answers: []models.Answer{{QuestionID: 2, Value: primitive.A{questiontypes.OtherOptionValue}, OtherText: "Radio"}}},
+		}
+	}
+
+	t.Run("Dry run", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRepo.On("GetSurveyIDs", ctx).Return([]int{1, 2}, nil)
+		mockRepo.On("GetAllResponsesBySurveyID", ctx, 1).Return(newResponses(), nil)
+		service := NewResponseService(mockRepo, mockURL)
+
+		report, err := service.MigrateOptionIDs(ctx, true)
+		require.NoError(t, err)
+		assert.Equal(t, OptionIDMigrationReport{Surveys: 1, SkippedSurveys: 1, Responses: 4, UpdatedResponses: 1, UnmatchedAnswers: 1}, *report)
+		mockRepo.AssertNotCalled(t, "SetResponseAnswers", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Matched answers get their IDs", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		responses := newResponses()
+		mockRepo.On("GetSurveyIDs", ctx).Return([]int{1}, nil)
+		mockRepo.On("GetAllResponsesBySurveyID", ctx, 1).Return(responses, nil)
+		mockRepo.On("SetResponseAnswers", ctx, primitive.ObjectID{1}, []models.Answer{
+			{QuestionID: 1, Value: "Apple", OptionIDs: []int{11}},
+			{QuestionID: 2, Value: primitive.A{"Search", questiontypes.OtherOptionValue}, OptionIDs: []int{22}, OtherText: "A podcast"},
+			{QuestionID: 3, Value: "Tasty"},
+		}).Return(nil)
+		service := NewResponseService(mockRepo, mockURL)
+
+		report, err := service.MigrateOptionIDs(ctx, false)
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.UpdatedResponses)
+		mockRepo.AssertExpectations(t)
+		assert.Nil(t, responses[0].Answers[0].OptionIDs, "loaded responses are not modified")
+	})
+}
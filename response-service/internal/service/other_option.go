@@ -0,0 +1,99 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/shared/questiontypes"
+)
+
+// Respondents selecting the "Other" option of a choice question send questiontypes.OtherOptionValue and write
+// their own answer in Answer.OtherText.
+const maxOtherTextLen = 500
+
+// choiceConfigOf returns the config of a choice question, nil for questions of other types
+func choiceConfigOf(q models.QuestionFromService) *questiontypes.ChoiceConfig {
+	config, _ := configOf(q).(*questiontypes.ChoiceConfig)
+	return config
+}
+
+// allowsOther reports whether q is a choice question offering an "Other" option
+func allowsOther(q models.QuestionFromService) bool {
+	config := choiceConfigOf(q)
+	return config != nil && config.AllowOther
+}
+
+// otherLabel returns the label the "Other" option of q is shown with
+func otherLabel(q models.QuestionFromService) string {
+	if config := choiceConfigOf(q); config != nil && config.OtherLabel != "" {
+		return config.OtherLabel
+	}
+	return questiontypes.DefaultOtherLabel
+}
+
+// isChoice reports whether text is an option of q, or its "Other" option when q offers one
+func isChoice(q models.QuestionFromService, text string) bool {
+	return hasOption(q, text) || (text == questiontypes.OtherOptionValue && allowsOther(q))
+}
+
+// selectsOther reports whether an answer value selects the "Other" option
+func selectsOther(value interface{}) bool {
+	if selected, ok := value.(string); ok {
+		return selected == questiontypes.OtherOptionValue
+	}
+	selected, _ := toStringSlice(value)
+	for _, opt := range selected {
+		if opt == questiontypes.OtherOptionValue {
+			return true
+		}
+	}
+	return false
+}
+
+// validateOtherText checks that an answer carries written text exactly when it selects the "Other" option
+func validateOtherText(verr *AnswerValidationError, q models.QuestionFromService, ans models.Answer) {
+	text := strings.TrimSpace(ans.OtherText)
+	switch {
+	case !selectsOther(ans.Value) || !allowsOther(q):
+		if text != "" {
+			verr.add(q.ID, AnswerErrInvalidOption, "question %d has text for %q, which was not selected", q.ID, otherLabel(q))
+		}
+	case text == "":
+		verr.add(q.ID, AnswerErrRequired, "question %d needs the text of its %q option", q.ID, otherLabel(q))
+	case len([]rune(text)) > maxOtherTextLen:
+		verr.add(q.ID, AnswerErrOutOfRange, "the %q text of question %d must be at most %d characters", otherLabel(q), q.ID, maxOtherTextLen)
+	}
+}
+
+// otherTexts lists the texts respondents wrote for the "Other" option of a question
+func otherTexts(responses []*models.Response, questionID int) []models.TextResponseData {
+	texts := make([]models.TextResponseData, 0)
+	for _, resp := range responses {
+		for _, ans := range resp.Answers {
+			if ans.QuestionID == questionID {
+				if ans.OtherText != "" && selectsOther(ans.Value) {
+					texts = append(texts, models.TextResponseData{Response: ans.OtherText})
+				}
+				break
+			}
+		}
+	}
+	return texts
+}
+
+// choiceText returns how a selected value is shown in exports: the label of the "Other" option, or the option itself
+func choiceText(q models.QuestionFromService, selected string) string {
+	if selected == questiontypes.OtherOptionValue && allowsOther(q) {
+		return otherLabel(q)
+	}
+	return selected
+}
+
+// formatOptionAnswer formats the option of a single-choice answer
+func formatOptionAnswer(q models.QuestionFromService, value interface{}) []string {
+	if selected, ok := value.(string); ok {
+		return []string{choiceText(q, selected)}
+	}
+	return []string{fmt.Sprintf("%v", value)}
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/contextkeys"
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/shared/questiontypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const otherTestQuestions = `[
+	{"id": 1, "order_num": 1, "text": "Fruit", "type": "single_choice", "config": {"allow_other": true},
+		"options": [{"id": 11, "text": "Apple"}, {"id": 12, "text": "Pear"}]},
+	{"id": 2, "order_num": 2, "text": "Heard of us via", "type": "checkbox", "config": {"allow_other": true, "other_label": "Elsewhere"},
+		"options": [{"id": 21, "text": "Friends"}, {"id": 22, "text": "Search"}]},
+	{"id": 3, "order_num": 3, "text": "Colour", "type": "dropdown",
+		"options": [{"id": 31, "text": "Red"}]}
+]`
+
+func otherTestQuestionList(t *testing.T) []models.QuestionFromService {
+	var questions []models.QuestionFromService
+	require.NoError(t, json.Unmarshal([]byte(otherTestQuestions), &questions))
+	return questions
+}
+
+func TestOtherOptionValidation(t *testing.T) {
+	questions := otherTestQuestionList(t)
+
+	tests := []struct {
+		name      string
+		answer    models.Answer
+		wantCodes []string
+	}{
+		{name: "Other with text", answer: models.Answer{QuestionID: 1, Value: questiontypes.OtherOptionValue, OtherText: "Mango"}},
+		{name: "Other among ticked options", answer: models.Answer{QuestionID: 2, Value: []interface{}{"Friends", questiontypes.OtherOptionValue}, OtherText: "A podcast"}},
+		{name: "Other without text", answer: models.Answer{QuestionID: 1, Value: questiontypes.OtherOptionValue, OtherText: "  "}, wantCodes: []string{AnswerErrRequired}},
+		{name: "Text without selecting Other", answer: models.Answer{QuestionID: 2, Value: []interface{}{"Friends"}, OtherText: "A podcast"}, wantCodes: []string{AnswerErrInvalidOption}},
+		{name: "Text too long", answer: models.Answer{QuestionID: 1, Value: questiontypes.OtherOptionValue, OtherText: strings.Repeat("a", maxOtherTextLen+1)}, wantCodes: []string{AnswerErrOutOfRange}},
+		{name: "Question without Other", answer: models.Answer{QuestionID: 3, Value: questiontypes.OtherOptionValue, OtherText: "Teal"}, wantCodes: []string{AnswerErrInvalidOption, AnswerErrInvalidOption}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAnswers(questions, []models.Answer{tt.answer})
+			if tt.wantCodes == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var verr *AnswerValidationError
+			if assert.True(t, errors.As(err, &verr)) {
+				var codes []string
+				for _, e := range verr.Errors {
+					codes = append(codes, e.Code)
+				}
+				assert.Equal(t, tt.wantCodes, codes)
+			}
+		})
+	}
+}
+
+func TestOtherOptionAnalyticsAndExport(t *testing.T) {
+	mockRepo := new(MockRepository)
+	ctx := context.WithValue(context.Background(), contextkeys.UserIDKey, 1)
+	mockServer, mockURL := setupMockSurveyService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1, "title": "Fruit", "is_active": true, "access_role": "owner", "questions": ` + otherTestQuestions + `}`))
+	}))
+	defer mockServer.Close()
+
+	responses := []*models.Response{
+		{ID: primitive.NewObjectID(), SurveyID: 1, Answers: []models.Answer{
+			{QuestionID: 1, Value: "Apple"},
+			{QuestionID: 2, Value: primitive.A{"Friends", questiontypes.OtherOptionValue}, OtherText: "A podcast"},
+		}},
+		{ID: primitive.NewObjectID(), SurveyID: 1, Answers: []models.Answer{{QuestionID: 1, Value: questiontypes.OtherOptionValue, OtherText: "Mango"}}},
+		{ID: primitive.NewObjectID(), SurveyID: 1, Answers: []models.Answer{{QuestionID: 1, Value: questiontypes.OtherOptionValue, OtherText: "Kiwi"}}},
+	}
+	mockRepo.On("GetResponsesBySurveyID", ctx, 1).Return(responses, nil)
+	service := NewResponseService(mockRepo, mockURL)
+
+	analytics, err := service.GetSurveyAnalytics(ctx, 1)
+	require.NoError(t, err)
+	fruit := analytics.QuestionAnalytics[0]
+	require.Len(t, fruit.OptionsSummary, 3)
+	other := fruit.OptionsSummary[2]
+	assert.True(t, other.IsOther)
+	assert.Nil(t, other.OptionID)
+	assert.Equal(t, "Other", other.OptionText)
+	assert.Equal(t, 2, other.Count)
+	assert.InDelta(t, 66.67, other.Percentage, 0.01)
+	assert.Equal(t, []models.TextResponseData{{Response: "Mango"}, {Response: "Kiwi"}}, fruit.OtherResponses)
+
+	heard := analytics.QuestionAnalytics[1]
+	assert.Equal(t, "Elsewhere", heard.OptionsSummary[2].OptionText)
+	assert.Equal(t, 1, heard.OptionsSummary[2].Count)
+	assert.Len(t, analytics.QuestionAnalytics[2].OptionsSummary, 1, "questions without Other have no extra bucket")
+
+	csvData, _, err := service.ExportSurveyResponsesCSV(ctx, 1)
+	require.NoError(t, err)
+	records, err := csv.NewReader(strings.NewReader(csvData)).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	assert.Equal(t, []string{"Fruit", "Fruit [Other]", "Heard of us via", "Heard of us via [Elsewhere]", "Colour"}, records[0][4:])
+	assert.Equal(t, []string{"Apple", "", "Friends; Elsewhere", "A podcast", ""}, records[1][4:])
+	assert.Equal(t, []string{"Other", "Mango", "", "", ""}, records[2][4:])
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/contextkeys"
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+)
+
+// ErrSectionNotFound is returned when a page is validated that does not belong to the survey
+var ErrSectionNotFound = errors.New("section not found")
+
+// ValidatePage checks the answers given so far when a respondent leaves a section (page) of a survey,
+// so problems are reported on the page they occur instead of on final submission.
+func (s *ResponseService) ValidatePage(ctx context.Context, surveyID, sectionID int, req *models.ValidatePageRequest) error {
+	surveyDetails, err := s.getSurveyDetails(ctx, surveyID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve survey details (ID: %d): %w", surveyID, err)
+	}
+
+	var userID *int
+	if uid, ok := ctx.Value(contextkeys.UserIDKey).(int); ok {
+		userID = &uid
+	}
+	if err := checkAcceptingResponses(surveyDetails, userID); err != nil {
+		return err
+	}
+
+	for _, section := range surveyDetails.Sections {
+		if section.ID == sectionID {
+			return validatePageAnswers(surveyDetails.Questions, sectionID, req.Answers)
+		}
+	}
+	return fmt.Errorf("%w: survey %d has no section %d", ErrSectionNotFound, surveyID, sectionID)
+}
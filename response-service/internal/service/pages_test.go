@@ -0,0 +1,76 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// pagedSurveyHandler serves a two-page survey; the second page's required question is skipped when Q1 is "No"
+func pagedSurveyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"id": 1, "title": "Paged", "is_active": true, "status": "open",
+			"sections": [{"id": 7, "title": "Basics", "order_num": 1}, {"id": 8, "title": "Details", "order_num": 2}],
+			"questions": [
+				{"id": 1, "order_num": 1, "section_id": 7, "text": "Employed?", "type": "multiple_choice", "required": true,
+				 "options": [{"text": "Yes"}, {"text": "No"}],
+				 "skip_rules": [{"operator": "equals", "value": "No", "skip_to": 3}]},
+				{"id": 2, "order_num": 2, "section_id": 8, "text": "Employer", "type": "text", "required": true},
+				{"id": 3, "order_num": 3, "section_id": 8, "text": "Comments", "type": "text"}
+			]
+		}`))
+	})
+}
+
+func TestValidatePage(t *testing.T) {
+	ctx := userContext(5)
+	mockServer, mockURL := setupMockSurveyService(t, pagedSurveyHandler())
+	defer mockServer.Close()
+	service := NewResponseService(new(MockRepository), mockURL)
+
+	pageCodes := func(err error) map[int]string {
+		var verr *AnswerValidationError
+		if !assert.True(t, errors.As(err, &verr)) {
+			return nil
+		}
+		codes := make(map[int]string)
+		for _, e := range verr.Errors {
+			codes[e.QuestionID] = e.Code
+		}
+		return codes
+	}
+
+	t.Run("First page requires its own questions only", func(t *testing.T) {
+		err := service.ValidatePage(ctx, 1, 7, &models.ValidatePageRequest{})
+		assert.Equal(t, map[int]string{1: AnswerErrRequired}, pageCodes(err))
+
+		err = service.ValidatePage(ctx, 1, 7, &models.ValidatePageRequest{Answers: []models.Answer{{QuestionID: 1, Value: "Yes"}}})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Second page applies the answers of the first", func(t *testing.T) {
+		err := service.ValidatePage(ctx, 1, 8, &models.ValidatePageRequest{Answers: []models.Answer{{QuestionID: 1, Value: "Yes"}}})
+		assert.Equal(t, map[int]string{2: AnswerErrRequired}, pageCodes(err))
+
+		err = service.ValidatePage(ctx, 1, 8, &models.ValidatePageRequest{Answers: []models.Answer{{QuestionID: 1, Value: "No"}}})
+		assert.NoError(t, err, "the skipped required question is not missing")
+	})
+
+	t.Run("Problems on other pages are not reported", func(t *testing.T) {
+		err := service.ValidatePage(ctx, 1, 8, &models.ValidatePageRequest{Answers: []models.Answer{
+			{QuestionID: 1, Value: "Maybe"},
+			{QuestionID: 2, Value: "Acme"},
+		}})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Unknown section", func(t *testing.T) {
+		err := service.ValidatePage(ctx, 1, 99, &models.ValidatePageRequest{})
+		assert.True(t, errors.Is(err, ErrSectionNotFound))
+	})
+}
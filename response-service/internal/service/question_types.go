@@ -0,0 +1,505 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/shared/questiontypes"
+)
+
+// configOf decodes the config of q with the shared question type registry. A missing or unusable config gives the
+// type's defaults, nil for types unknown to the registry or taking no config.
+func configOf(q models.QuestionFromService) questiontypes.QuestionConfig {
+	def, ok := questiontypes.LookupQuestionType(q.Type)
+	if !ok || def.NewConfig == nil {
+		return nil
+	}
+	config, err := def.DecodeConfig(q.Config)
+	if err != nil {
+		logf("[SERVICE_WARN] Invalid config of question %d, using the defaults: %v", q.ID, err)
+		return def.NewConfig()
+	}
+	return config
+}
+
+// scaleConfigOf returns the scale of a linear_scale question, falling back to the defaults for a missing or unusable config
+func scaleConfigOf(q models.QuestionFromService) *questiontypes.LinearScaleConfig {
+	if config, ok := configOf(q).(*questiontypes.LinearScaleConfig); ok {
+		return config
+	}
+	return questiontypes.DefaultLinearScaleConfig()
+}
+
+// answerShape describes the JSON value an answer to a question type must have
+type answerShape struct {
+	description string // Completes "question N expects ..." in validation errors
+	matches     func(value interface{}) bool
+}
+
+var (
+	textShape        = answerShape{description: "a text answer", matches: isString}
+	optionShape      = answerShape{description: "a single option", matches: isString}
+	optionListShape  = answerShape{description: "a list of options", matches: isStringList}
+	wholeNumberShape = answerShape{description: "a whole number", matches: isWholeNumber}
+	dateShape        = answerShape{description: "a date", matches: isString}
+	rowMapShape      = answerShape{description: "an object mapping rows to columns", matches: isStringMap}
+	rankingShape     = answerShape{description: "a list of options in order of preference", matches: isStringList}
+	fileShape        = answerShape{description: "the ID of an uploaded file", matches: isFileID}
+)
+
+// answerKind defines how answers of one kind are validated, aggregated and exported. Question types are
+// registered in the shared questiontypes registry with the kind of answer they take. Answers to types
+// unknown there are stored as submitted, left out of analytics and exported as formatted by fmt.
+type answerKind struct {
+	shape answerShape
+	// validate checks a value of the right shape, nil if any is accepted
+	validate func(verr *AnswerValidationError, q models.QuestionFromService, value interface{})
+	// aggregate summarises the non-empty answers of all responses
+	aggregate func(qa *models.QuestionAnalytics, q models.QuestionFromService, values []interface{})
+	// unansweredParts lists the parts of a required question an answer leaves out, nil if any answer is complete
+	unansweredParts func(q models.QuestionFromService, value interface{}) []string
+	// csvColumns labels the CSV columns of a question spanning several, nil for a single column
+	csvColumns func(q models.QuestionFromService) []string
+	// formatCSV formats a non-nil answer into one cell per column, nil for fmt's default
+	formatCSV func(q models.QuestionFromService, value interface{}) []string
+	// optionSelection tells whether answers select options of the question, stored with their IDs
+	optionSelection optionSelection
+}
+
+var answerKinds = make(map[questiontypes.AnswerKind]answerKind)
+
+// registerAnswerKind defines how answers of a kind are handled. It panics if the kind is already registered.
+func registerAnswerKind(kind questiontypes.AnswerKind, k answerKind) {
+	if _, exists := answerKinds[kind]; exists {
+		panic(fmt.Sprintf("answer kind %q registered twice", kind))
+	}
+	answerKinds[kind] = k
+}
+
+// answerKindOf returns how answers to questions of the named type are handled
+func answerKindOf(typeName string) (answerKind, bool) {
+	def, ok := questiontypes.LookupQuestionType(typeName)
+	if !ok {
+		return answerKind{}, false
+	}
+	k, ok := answerKinds[def.Answer]
+	return k, ok
+}
+
+func init() {
+	registerAnswerKind(questiontypes.AnswerText, answerKind{shape: textShape, validate: validateTextAnswer, aggregate: aggregateFormattedTextAnswers})
+	registerAnswerKind(questiontypes.AnswerDate, answerKind{shape: dateShape, validate: validateDateAnswer, aggregate: aggregateTextAnswers})
+	registerAnswerKind(questiontypes.AnswerOption, answerKind{
+		shape:           optionShape,
+		validate:        validateOptionAnswer,
+		aggregate:       aggregateOptionAnswers,
+		formatCSV:       formatOptionAnswer,
+		optionSelection: selectsOneOption,
+	})
+	registerAnswerKind(questiontypes.AnswerOptionList, answerKind{
+		shape:           optionListShape,
+		validate:        validateOptionListAnswer,
+		aggregate:       aggregateOptionListAnswers,
+		formatCSV:       formatListAnswer,
+		optionSelection: selectsOptionList,
+	})
+	registerAnswerKind(questiontypes.AnswerRowMap, answerKind{
+		shape:           rowMapShape,
+		validate:        validateMatrixAnswer,
+		aggregate:       aggregateMatrixAnswers,
+		unansweredParts: unansweredMatrixRows,
+		csvColumns:      func(q models.QuestionFromService) []string { return matrixConfigOf(q).Rows },
+		formatCSV:       formatMatrixAnswer,
+	})
+	registerAnswerKind(questiontypes.AnswerRanking, answerKind{
+		shape:           rankingShape,
+		validate:        validateRankingAnswer,
+		aggregate:       aggregateRankingAnswers,
+		csvColumns:      optionTexts,
+		formatCSV:       formatRankingAnswer,
+		optionSelection: selectsOptionList,
+	})
+	// Uploaded files are checked against the blob store when the response is saved, see resolveFileAnswers
+	registerAnswerKind(questiontypes.AnswerFile, answerKind{shape: fileShape})
+	registerAnswerKind(questiontypes.AnswerScale, answerKind{shape: wholeNumberShape, validate: validateScaleAnswer, aggregate: aggregateScaleAnswers})
+}
+
+func isString(value interface{}) bool {
+	_, ok := value.(string)
+	return ok
+}
+
+func isStringList(value interface{}) bool {
+	_, ok := toStringSlice(value)
+	return ok
+}
+
+func isStringMap(value interface{}) bool {
+	_, ok := toStringMap(value)
+	return ok
+}
+
+func isWholeNumber(value interface{}) bool {
+	num, ok := value.(float64)
+	return ok && num == math.Trunc(num)
+}
+
+func validateOptionAnswer(verr *AnswerValidationError, q models.QuestionFromService, value interface{}) {
+	if selected := value.(string); !isChoice(q, selected) {
+		verr.add(q.ID, AnswerErrInvalidOption, "%q is not an option of question %d", selected, q.ID)
+	}
+}
+
+func validateOptionListAnswer(verr *AnswerValidationError, q models.QuestionFromService, value interface{}) {
+	selected, _ := toStringSlice(value)
+	seen := make(map[string]bool, len(selected))
+	for _, opt := range selected {
+		if seen[opt] {
+			verr.add(q.ID, AnswerErrInvalidOption, "%q was selected more than once for question %d", opt, q.ID)
+			continue
+		}
+		seen[opt] = true
+		if !isChoice(q, opt) {
+			verr.add(q.ID, AnswerErrInvalidOption, "%q is not an option of question %d", opt, q.ID)
+		}
+	}
+}
+
+func validateScaleAnswer(verr *AnswerValidationError, q models.QuestionFromService, value interface{}) {
+	scale := scaleConfigOf(q)
+	if !scale.Includes(int(value.(float64))) {
+		if scale.Step == 1 {
+			verr.add(q.ID, AnswerErrOutOfRange, "question %d expects a value between %d and %d", q.ID, scale.Min, scale.Max)
+		} else {
+			verr.add(q.ID, AnswerErrOutOfRange, "question %d expects a value between %d and %d in steps of %d", q.ID, scale.Min, scale.Max, scale.Step)
+		}
+	}
+}
+
+// aggregateOptionAnswers counts how often each option, or "Other", was chosen; answers that are not an option are ignored
+func aggregateOptionAnswers(qa *models.QuestionAnalytics, q models.QuestionFromService, values []interface{}) {
+	counts := make(map[string]int, len(q.Options))
+	responders := 0
+	for _, value := range values {
+		if selected, ok := value.(string); ok && isChoice(q, selected) {
+			counts[selected]++
+			responders++
+		}
+	}
+	qa.OptionsSummary = optionSummaries(q, counts, responders)
+}
+
+// aggregateOptionListAnswers counts how often each option was ticked, as a share of the respondents who ticked any
+func aggregateOptionListAnswers(qa *models.QuestionAnalytics, q models.QuestionFromService, values []interface{}) {
+	counts := make(map[string]int, len(q.Options))
+	responders := 0
+	for _, value := range values {
+		selected, ok := toStringSlice(value)
+		if !ok || len(selected) == 0 {
+			continue
+		}
+		responders++
+		for _, opt := range selected {
+			counts[opt]++
+		}
+	}
+	qa.OptionsSummary = optionSummaries(q, counts, responders)
+}
+
+// aggregateScaleAnswers counts the answers on each point of the question's scale
+func aggregateScaleAnswers(qa *models.QuestionAnalytics, q models.QuestionFromService, values []interface{}) {
+	scale := scaleConfigOf(q)
+	counts := make(map[int]int)
+	responders := 0
+	for _, value := range values {
+		if num, ok := value.(float64); ok && num == math.Trunc(num) && scale.Includes(int(num)) {
+			counts[int(num)]++
+			responders++
+		}
+	}
+
+	points := scale.Points()
+	qa.OptionsSummary = make([]models.OptionSummary, 0, len(points))
+	for _, point := range points {
+		val := point
+		qa.OptionsSummary = append(qa.OptionsSummary, models.OptionSummary{OptionID: &val, OptionText: fmt.Sprintf("%d", point), Count: counts[point], Percentage: percentageOf(counts[point], responders)})
+	}
+}
+
+// aggregateTextAnswers lists the written answers
+func aggregateTextAnswers(qa *models.QuestionAnalytics, q models.QuestionFromService, values []interface{}) {
+	qa.TextResponses = make([]models.TextResponseData, 0, len(values))
+	for _, value := range values {
+		if text, ok := value.(string); ok {
+			qa.TextResponses = append(qa.TextResponses, models.TextResponseData{Response: text})
+		}
+	}
+}
+
+// matrixConfigOf returns the rows and columns of a matrix question, none if its config is missing or unusable
+func matrixConfigOf(q models.QuestionFromService) *questiontypes.MatrixConfig {
+	if config, ok := configOf(q).(*questiontypes.MatrixConfig); ok {
+		return config
+	}
+	return &questiontypes.MatrixConfig{}
+}
+
+func containsText(texts []string, text string) bool {
+	for _, t := range texts {
+		if t == text {
+			return true
+		}
+	}
+	return false
+}
+
+// validateMatrixAnswer checks that the answer picks one of the columns for rows of the question
+func validateMatrixAnswer(verr *AnswerValidationError, q models.QuestionFromService, value interface{}) {
+	matrix := matrixConfigOf(q)
+	answers, _ := toStringMap(value)
+	for row, column := range answers {
+		if !containsText(matrix.Rows, row) {
+			verr.add(q.ID, AnswerErrInvalidOption, "%q is not a row of question %d", row, q.ID)
+			continue
+		}
+		if !containsText(matrix.Columns, column) {
+			verr.add(q.ID, AnswerErrInvalidOption, "%q is not a column of question %d", column, q.ID)
+		}
+	}
+}
+
+// unansweredMatrixRows lists the rows a matrix answer leaves out
+func unansweredMatrixRows(q models.QuestionFromService, value interface{}) []string {
+	answers, ok := toStringMap(value)
+	if !ok {
+		return nil // Reported as an invalid answer
+	}
+	var missing []string
+	for _, row := range matrixConfigOf(q).Rows {
+		if _, answered := answers[row]; !answered {
+			missing = append(missing, fmt.Sprintf("row %q", row))
+		}
+	}
+	return missing
+}
+
+// aggregateMatrixAnswers counts the columns chosen for each row, as a share of the respondents who answered the row
+func aggregateMatrixAnswers(qa *models.QuestionAnalytics, q models.QuestionFromService, values []interface{}) {
+	matrix := matrixConfigOf(q)
+	counts := make(map[string]map[string]int, len(matrix.Rows))
+	for _, row := range matrix.Rows {
+		counts[row] = make(map[string]int, len(matrix.Columns))
+	}
+	for _, value := range values {
+		answers, ok := toStringMap(value)
+		if !ok {
+			continue
+		}
+		for row, column := range answers {
+			if rowCounts, known := counts[row]; known && containsText(matrix.Columns, column) {
+				rowCounts[column]++
+			}
+		}
+	}
+
+	qa.RowsSummary = make([]models.MatrixRowSummary, 0, len(matrix.Rows))
+	for _, row := range matrix.Rows {
+		responders := 0
+		for _, count := range counts[row] {
+			responders += count
+		}
+		summary := models.MatrixRowSummary{Row: row, Responses: responders, ColumnsSummary: make([]models.OptionSummary, 0, len(matrix.Columns))}
+		for _, column := range matrix.Columns {
+			count := counts[row][column]
+			summary.ColumnsSummary = append(summary.ColumnsSummary, models.OptionSummary{OptionText: column, Count: count, Percentage: percentageOf(count, responders)})
+		}
+		qa.RowsSummary = append(qa.RowsSummary, summary)
+	}
+}
+
+// formatMatrixAnswer writes the column chosen for each row into the row's CSV column
+func formatMatrixAnswer(q models.QuestionFromService, value interface{}) []string {
+	answers, ok := toStringMap(value)
+	if !ok {
+		return []string{fmt.Sprintf("%v", value)}
+	}
+	rows := matrixConfigOf(q).Rows
+	cells := make([]string, len(rows))
+	for i, row := range rows {
+		cells[i] = answers[row]
+	}
+	return cells
+}
+
+// rankedCount returns how many options an answer to ranking question q must rank
+func rankedCount(q models.QuestionFromService) int {
+	config, ok := configOf(q).(*questiontypes.RankingConfig)
+	if !ok || config.TopN <= 0 || config.TopN > len(q.Options) {
+		return len(q.Options)
+	}
+	return config.TopN
+}
+
+// validateRankingAnswer checks that the answer orders all of the question's options, or its top N when configured
+func validateRankingAnswer(verr *AnswerValidationError, q models.QuestionFromService, value interface{}) {
+	before := len(verr.Errors)
+	validateOptionListAnswer(verr, q, value)
+	if len(verr.Errors) > before {
+		return
+	}
+	ranked, _ := toStringSlice(value)
+	if want := rankedCount(q); len(ranked) != want {
+		if want == len(q.Options) {
+			verr.add(q.ID, AnswerErrOutOfRange, "question %d expects a ranking of all %d options, got %d", q.ID, want, len(ranked))
+		} else {
+			verr.add(q.ID, AnswerErrOutOfRange, "question %d expects a ranking of the top %d options, got %d", q.ID, want, len(ranked))
+		}
+	}
+}
+
+// rankOf maps the options of a ranking answer to their rank, starting at 1. Unknown and repeated options are skipped.
+func rankOf(q models.QuestionFromService, value interface{}) map[string]int {
+	ranked, ok := toStringSlice(value)
+	if !ok {
+		return nil
+	}
+	ranks := make(map[string]int, len(ranked))
+	for _, opt := range ranked {
+		if _, seen := ranks[opt]; !seen && hasOption(q, opt) {
+			ranks[opt] = len(ranks) + 1
+		}
+	}
+	return ranks
+}
+
+// aggregateRankingAnswers reports the mean rank, Borda score and first-choice count of each option.
+// An option ranked r-th of n options scores n-r Borda points.
+func aggregateRankingAnswers(qa *models.QuestionAnalytics, q models.QuestionFromService, values []interface{}) {
+	n := len(q.Options)
+	rankSums := make(map[string]int, n)
+	summaries := make(map[string]*models.RankingSummary, n)
+	for _, opt := range q.Options {
+		summaries[opt.Text] = &models.RankingSummary{}
+	}
+	for _, value := range values {
+		for opt, rank := range rankOf(q, value) {
+			summary := summaries[opt]
+			summary.Ranked++
+			summary.BordaScore += n - rank
+			if rank == 1 {
+				summary.FirstChoices++
+			}
+			rankSums[opt] += rank
+		}
+	}
+
+	qa.RankingSummary = make([]models.RankingSummary, 0, n)
+	for _, opt := range q.Options {
+		optID := opt.ID
+		summary := summaries[opt.Text]
+		summary.OptionID = &optID
+		summary.OptionText = opt.Text
+		if summary.Ranked > 0 {
+			summary.MeanRank = float64(rankSums[opt.Text]) / float64(summary.Ranked)
+		}
+		qa.RankingSummary = append(qa.RankingSummary, *summary)
+	}
+}
+
+// formatRankingAnswer writes the rank given to each option into the option's CSV column
+func formatRankingAnswer(q models.QuestionFromService, value interface{}) []string {
+	ranks := rankOf(q, value)
+	if ranks == nil {
+		return []string{fmt.Sprintf("%v", value)}
+	}
+	cells := make([]string, len(q.Options))
+	for i, opt := range q.Options {
+		if rank, ok := ranks[opt.Text]; ok {
+			cells[i] = fmt.Sprintf("%d", rank)
+		}
+	}
+	return cells
+}
+
+// optionTexts labels one CSV column per option of the question
+func optionTexts(q models.QuestionFromService) []string {
+	texts := make([]string, 0, len(q.Options))
+	for _, opt := range q.Options {
+		texts = append(texts, opt.Text)
+	}
+	return texts
+}
+
+// optionSummaries lists the counts of a question's options in order, as percentages of the respondents.
+// Questions offering an "Other" option get a last entry counting it.
+func optionSummaries(q models.QuestionFromService, countsByText map[string]int, responders int) []models.OptionSummary {
+	summaries := make([]models.OptionSummary, 0, len(q.Options)+1)
+	for _, opt := range q.Options {
+		optID := opt.ID
+		count := countsByText[opt.Text]
+		summaries = append(summaries, models.OptionSummary{OptionID: &optID, OptionText: opt.Text, Count: count, Percentage: percentageOf(count, responders)})
+	}
+	if allowsOther(q) {
+		count := countsByText[questiontypes.OtherOptionValue]
+		summaries = append(summaries, models.OptionSummary{OptionText: otherLabel(q), Count: count, Percentage: percentageOf(count, responders), IsOther: true})
+	}
+	return summaries
+}
+
+func percentageOf(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return (float64(count) / float64(total)) * 100
+}
+
+// formatListAnswer joins the selected options of a list answer
+func formatListAnswer(q models.QuestionFromService, value interface{}) []string {
+	if selected, ok := toStringSlice(value); ok {
+		texts := make([]string, len(selected))
+		for i, opt := range selected {
+			texts[i] = choiceText(q, opt)
+		}
+		return []string{strings.Join(texts, "; ")}
+	}
+	return []string{fmt.Sprintf("%v", value)}
+}
+
+// csvHeaders returns the headers of a question's CSV columns, the first being title
+func csvHeaders(q models.QuestionFromService, title string) []string {
+	headers := []string{title}
+	if kind, ok := answerKindOf(q.Type); ok && kind.csvColumns != nil {
+		if labels := kind.csvColumns(q); len(labels) > 0 {
+			headers = make([]string, 0, len(labels)+1)
+			for _, label := range labels {
+				headers = append(headers, fmt.Sprintf("%s [%s]", title, label))
+			}
+		}
+	}
+	// The text written for "Other" gets a column of its own, after the selection
+	if allowsOther(q) {
+		headers = append(headers, fmt.Sprintf("%s [%s]", title, otherLabel(q)))
+	}
+	return headers
+}
+
+// csvCells formats an answer into the columns of question q, given on answeredAs: the question as it was in the
+// survey version of the response. Answers given while the question had another type are written to its first column.
+func csvCells(q, answeredAs models.QuestionFromService, ans models.Answer) []string {
+	cells := make([]string, len(csvHeaders(q, "")))
+	kind, ok := answerKindOf(answeredAs.Type)
+	switch {
+	case !ok || kind.formatCSV == nil:
+		cells[0] = fmt.Sprintf("%v", ans.Value)
+	case answeredAs.Type == q.Type:
+		copy(cells, kind.formatCSV(q, ans.Value))
+	default:
+		cells[0] = strings.Join(kind.formatCSV(answeredAs, ans.Value), "; ")
+	}
+	if allowsOther(q) && selectsOther(ans.Value) {
+		cells[len(cells)-1] = ans.OtherText
+	}
+	return cells
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/shared/questiontypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// answerKindOfType returns how answers to the named question type are handled, failing the test if they are not
+func answerKindOfType(t *testing.T, name string) answerKind {
+	kind, ok := answerKindOf(name)
+	require.True(t, ok, "answers to type %q are not handled", name)
+	return kind
+}
+
+func TestQuestionTypeRegistry(t *testing.T) {
+	t.Run("Every registered type takes a handled kind of answer", func(t *testing.T) {
+		for _, name := range questiontypes.QuestionTypeNames() {
+			kind, ok := answerKindOf(name)
+			if assert.True(t, ok, "answers to type %q are not handled", name) {
+				assert.NotNil(t, kind.shape.matches, "type %q has no answer shape", name)
+			}
+		}
+		for _, name := range []string{"text", "short_answer", "paragraph", "single_choice", "multiple_choice", "dropdown", "checkbox", "linear_scale", "date", "matrix", "ranking"} {
+			kind := answerKindOfType(t, name)
+			assert.NotNil(t, kind.aggregate, "type %q has no aggregator", name)
+		}
+	})
+
+	t.Run("Checkbox aggregation accepts JSON and BSON lists", func(t *testing.T) {
+		q := models.QuestionFromService{ID: 3, Type: "checkbox", Options: []models.QuestionOptionFromService{
+			{ID: 1, Text: "Reading"}, {ID: 2, Text: "Cycling"},
+		}}
+		values := []interface{}{
+			primitive.A{"Reading", "Cycling"},
+			[]interface{}{"Reading"},
+		}
+
+		var qa models.QuestionAnalytics
+		answerKindOfType(t, "checkbox").aggregate(&qa, q, values)
+		if assert.Len(t, qa.OptionsSummary, 2) {
+			assert.Equal(t, 2, qa.OptionsSummary[0].Count)
+			assert.Equal(t, 100.0, qa.OptionsSummary[0].Percentage)
+			assert.Equal(t, 1, qa.OptionsSummary[1].Count)
+			assert.Equal(t, 50.0, qa.OptionsSummary[1].Percentage)
+		}
+		assert.Equal(t, []string{"Reading; Cycling"}, answerKindOfType(t, "checkbox").formatCSV(q, values[0]))
+	})
+
+	t.Run("Choice aggregation reports zero counts without answers", func(t *testing.T) {
+		q := models.QuestionFromService{ID: 1, Type: "single_choice", Options: []models.QuestionOptionFromService{
+			{ID: 1, Text: "Yes"}, {ID: 2, Text: "No"},
+		}}
+
+		var qa models.QuestionAnalytics
+		answerKindOfType(t, "single_choice").aggregate(&qa, q, nil)
+		assert.Equal(t, []models.OptionSummary{
+			{OptionID: intPtr(1), OptionText: "Yes"},
+			{OptionID: intPtr(2), OptionText: "No"},
+		}, qa.OptionsSummary)
+	})
+}
+
+func TestLinearScaleConfig(t *testing.T) {
+	nps := models.QuestionFromService{ID: 1, Type: "linear_scale", Config: []byte(`{"min": 0, "max": 10, "step": 1}`)}
+	stepped := models.QuestionFromService{ID: 2, Type: "linear_scale", Config: []byte(`{"min": 1, "max": 9, "step": 2}`)}
+	legacy := models.QuestionFromService{ID: 3, Type: "linear_scale"}
+	questions := []models.QuestionFromService{nps, stepped, legacy}
+
+	t.Run("Answers are validated against the scale", func(t *testing.T) {
+		assert.NoError(t, validateAnswers(questions, []models.Answer{
+			{QuestionID: 1, Value: float64(0)},
+			{QuestionID: 2, Value: float64(9)},
+			{QuestionID: 3, Value: float64(5)},
+		}))
+
+		err := validateAnswers(questions, []models.Answer{
+			{QuestionID: 1, Value: float64(11)},
+			{QuestionID: 2, Value: float64(4)},
+			{QuestionID: 3, Value: float64(0)},
+		})
+		var verr *AnswerValidationError
+		if assert.ErrorAs(t, err, &verr) && assert.Len(t, verr.Errors, 3) {
+			for _, e := range verr.Errors {
+				assert.Equal(t, AnswerErrOutOfRange, e.Code)
+			}
+		}
+	})
+
+	t.Run("Analytics has a bucket per point of the scale", func(t *testing.T) {
+		var qa models.QuestionAnalytics
+		answerKindOfType(t, "linear_scale").aggregate(&qa, nps, []interface{}{float64(0), float64(10), float64(10), float64(12)})
+		if assert.Len(t, qa.OptionsSummary, 11) {
+			assert.Equal(t, "0", qa.OptionsSummary[0].OptionText)
+			assert.Equal(t, 1, qa.OptionsSummary[0].Count)
+			assert.Equal(t, "10", qa.OptionsSummary[10].OptionText)
+			assert.Equal(t, 2, qa.OptionsSummary[10].Count)
+			assert.InDelta(t, 66.67, qa.OptionsSummary[10].Percentage, 0.01)
+		}
+
+		qa = models.QuestionAnalytics{}
+		answerKindOfType(t, "linear_scale").aggregate(&qa, stepped, nil)
+		var points []string
+		for _, summary := range qa.OptionsSummary {
+			points = append(points, summary.OptionText)
+		}
+		assert.Equal(t, []string{"1", "3", "5", "7", "9"}, points)
+	})
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/contextkeys"
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func rankingTestQuestion(config string) models.QuestionFromService {
+	return models.QuestionFromService{ID: 1, OrderNum: 1, Text: "Priorities", Type: "ranking", Config: []byte(config), Options: []models.QuestionOptionFromService{
+		{ID: 1, Text: "Price"}, {ID: 2, Text: "Speed"}, {ID: 3, Text: "Support"},
+	}}
+}
+
+func TestRankingAnswerValidation(t *testing.T) {
+	full := rankingTestQuestion(`{}`)
+	topTwo := rankingTestQuestion(`{"top_n": 2}`)
+
+	tests := []struct {
+		name      string
+		question  models.QuestionFromService
+		value     interface{}
+		wantCodes []string
+	}{
+		{name: "Full permutation", question: full, value: []interface{}{"Speed", "Price", "Support"}},
+		{name: "Stored BSON list", question: full, value: primitive.A{"Support", "Speed", "Price"}},
+		{name: "Top N subset", question: topTwo, value: []interface{}{"Support", "Price"}},
+		{name: "Not a list", question: full, value: "Speed", wantCodes: []string{AnswerErrInvalidType}},
+		{name: "Unknown option", question: full, value: []interface{}{"Speed", "Price", "Colour"}, wantCodes: []string{AnswerErrInvalidOption}},
+		{name: "Option ranked twice", question: full, value: []interface{}{"Speed", "Speed", "Price"}, wantCodes: []string{AnswerErrInvalidOption}},
+		{name: "Incomplete permutation", question: full, value: []interface{}{"Speed", "Price"}, wantCodes: []string{AnswerErrOutOfRange}},
+		{name: "More than the top N", question: topTwo, value: []interface{}{"Speed", "Price", "Support"}, wantCodes: []string{AnswerErrOutOfRange}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAnswers([]models.QuestionFromService{tt.question}, []models.Answer{{QuestionID: 1, Value: tt.value}})
+			if tt.wantCodes == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var verr *AnswerValidationError
+			if assert.True(t, errors.As(err, &verr)) {
+				var codes []string
+				for _, e := range verr.Errors {
+					codes = append(codes, e.Code)
+				}
+				assert.Equal(t, tt.wantCodes, codes)
+			}
+		})
+	}
+}
+
+func TestRankingAnalyticsAndExport(t *testing.T) {
+	mockRepo := new(MockRepository)
+	ctx := context.WithValue(context.Background(), contextkeys.UserIDKey, 1)
+	mockServer, mockURL := setupMockSurveyService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1, "title": "Priorities", "is_active": true, "access_role": "owner", "questions": [
+			{"id": 1, "order_num": 1, "text": "Priorities", "type": "ranking", "config": {"top_n": 2},
+			 "options": [{"id": 1, "text": "Price"}, {"id": 2, "text": "Speed"}, {"id": 3, "text": "Support"}]}
+		]}`))
+	}))
+	defer mockServer.Close()
+
+	responses := []*models.Response{
+		{ID: primitive.NewObjectID(), SurveyID: 1, Answers: []models.Answer{{QuestionID: 1, Value: primitive.A{"Speed", "Price"}}}},
+		{ID: primitive.NewObjectID(), SurveyID: 1, Answers: []models.Answer{{QuestionID: 1, Value: primitive.A{"Speed", "Support"}}}},
+		{ID: primitive.NewObjectID(), SurveyID: 1, Answers: []models.Answer{{QuestionID: 1, Value: primitive.A{"Price", "Speed"}}}},
+	}
+	mockRepo.On("GetResponsesBySurveyID", ctx, 1).Return(responses, nil)
+	service := NewResponseService(mockRepo, mockURL)
+
+	analytics, err := service.GetSurveyAnalytics(ctx, 1)
+	require.NoError(t, err)
+	summary := analytics.QuestionAnalytics[0].RankingSummary
+	require.Len(t, summary, 3)
+	assert.Equal(t, models.RankingSummary{OptionID: intPtr(1), OptionText: "Price", Ranked: 2, MeanRank: 1.5, BordaScore: 3, FirstChoices: 1}, summary[0])
+	assert.Equal(t, models.RankingSummary{OptionID: intPtr(2), OptionText: "Speed", Ranked: 3, MeanRank: 4.0 / 3, BordaScore: 5, FirstChoices: 2}, summary[1])
+	assert.Equal(t, models.RankingSummary{OptionID: intPtr(3), OptionText: "Support", Ranked: 1, MeanRank: 2, BordaScore: 1}, summary[2])
+
+	csvData, _, err := service.ExportSurveyResponsesCSV(ctx, 1)
+	require.NoError(t, err)
+	records, err := csv.NewReader(strings.NewReader(csvData)).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	assert.Equal(t, []string{"Priorities [Price]", "Priorities [Speed]", "Priorities [Support]"}, records[0][4:])
+	assert.Equal(t, []string{"2", "1", ""}, records[1][4:])
+	assert.Equal(t, []string{"", "1", "2"}, records[2][4:])
+	assert.Equal(t, []string{"1", "2", ""}, records[3][4:])
+}
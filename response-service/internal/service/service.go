@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"strconv"
@@ -15,7 +16,7 @@ import (
 	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/contextkeys"
 	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
 	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/repository"
-	"go.mongodb.org/mongo-driver/bson/primitive"
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/storage"
 )
 
 // Set this to true to disable logging in tests
@@ -33,12 +34,35 @@ func logf(format string, v ...interface{}) {
 	}
 }
 
+// ErrSurveyNotOpen is returned when a response is submitted before a survey's start date or after its end date
+var ErrSurveyNotOpen = errors.New("survey is not open for responses")
+
+// ErrAuthenticationRequired is returned when an anonymous respondent submits to a survey that requires login
+var ErrAuthenticationRequired = errors.New("authentication required to respond to this survey")
+
+// Effective survey statuses reported by the survey-service
+const (
+	surveyStatusScheduled = "scheduled"
+	surveyStatusClosed    = "closed"
+)
+
 // ResponseServiceInterface defines methods for response-related business logic
 type ResponseServiceInterface interface {
 	SubmitResponse(ctx context.Context, req *models.CreateResponseRequest) error
 	GetSurveyResponses(ctx context.Context, surveyID int) ([]*models.Response, error)
+	CountSurveyResponses(ctx context.Context, surveyID int) (int64, error)
+	SaveDraft(ctx context.Context, req *models.SaveDraftRequest) (*models.Response, error)
+	GetDraft(ctx context.Context, id string) (*models.Response, error)
+	GetUserDraft(ctx context.Context, surveyID int) (*models.Response, error)
+	UpdateDraft(ctx context.Context, id string, req *models.UpdateResponseRequest) (*models.Response, error)
+	FinalizeDraft(ctx context.Context, id string, req *models.FinalizeDraftRequest) (*models.Response, error)
+	UpdateResponse(ctx context.Context, id string, req *models.UpdateResponseRequest) (*models.Response, error)
+	WithdrawResponse(ctx context.Context, id string) error
+	ValidatePage(ctx context.Context, surveyID, sectionID int, req *models.ValidatePageRequest) error
 	GetSurveyAnalytics(ctx context.Context, surveyID int) (*models.SurveyAnalyticsResponse, error)
 	ExportSurveyResponsesCSV(ctx context.Context, surveyID int) (csvData string, filename string, err error)
+	UploadFile(ctx context.Context, surveyID, questionID int, fileName string, content io.Reader) (*models.FileReference, error)
+	OpenResponseFile(ctx context.Context, responseID string, questionID int) (io.ReadCloser, *models.FileReference, error)
 }
 
 // ResponseService implements ResponseServiceInterface
@@ -46,6 +70,9 @@ type ResponseService struct {
 	repo             repository.ResponseRepositoryInterface
 	surveyServiceURL string
 	httpClient       *http.Client
+	files            storage.BlobStore // Uploads of file_upload questions, nil until UseFileStorage is called
+	maxUploadSize    int64
+	uploadQuota      *uploadQuotaTracker // nil until LimitUploads is called
 }
 
 // NewResponseService creates a new ResponseService
@@ -61,20 +88,29 @@ func NewResponseService(repo repository.ResponseRepositoryInterface, surveyServi
 
 // getSurveyDetails fetches full survey details from the survey-service
 func (s *ResponseService) getSurveyDetails(ctx context.Context, surveyID int) (*models.SurveyDetailsFromService, error) {
-	surveyURL := fmt.Sprintf("%s/api/v1/surveys/%d", s.surveyServiceURL, surveyID)
-	logf("[SERVICE_INFO] getSurveyDetails: Calling Survey Service at URL: %s", surveyURL)
+	var surveyDetails models.SurveyDetailsFromService
+	if err := s.getFromSurveyService(ctx, fmt.Sprintf("/api/v1/surveys/%d", surveyID), &surveyDetails); err != nil {
+		logf("[SERVICE_ERROR] getSurveyDetails: Failed to fetch SurveyID %d: %v", surveyID, err)
+		return nil, err
+	}
+	return &surveyDetails, nil
+}
+
+// getFromSurveyService performs a GET against the survey-service on behalf of the current user and decodes the JSON body into out
+func (s *ResponseService) getFromSurveyService(ctx context.Context, path string, out interface{}) error {
+	surveyURL := s.surveyServiceURL + path
+	logf("[SERVICE_INFO] getFromSurveyService: Calling Survey Service at URL: %s", surveyURL)
 
 	httpReq, err := http.NewRequestWithContext(ctx, "GET", surveyURL, nil)
 	if err != nil {
-		logf("[SERVICE_ERROR] getSurveyDetails: Failed to create request to survey-service for SurveyID %d: %v", surveyID, err)
-		return nil, fmt.Errorf("failed to create request to survey-service: %w", err)
+		return fmt.Errorf("failed to create request to survey-service: %w", err)
 	}
 
 	// Forward Authorization header if present in context
 	if authHeaderVal := ctx.Value(contextkeys.AuthorizationHeaderKey); authHeaderVal != nil {
 		if authHeader, ok := authHeaderVal.(string); ok && authHeader != "" {
 			httpReq.Header.Set("Authorization", authHeader)
-			logf("[SERVICE_INFO] getSurveyDetails: Forwarding Authorization header to survey-service.")
+			logf("[SERVICE_INFO] getFromSurveyService: Forwarding Authorization header to survey-service.")
 		}
 	}
 
@@ -82,39 +118,46 @@ func (s *ResponseService) getSurveyDetails(ctx context.Context, surveyID int) (*
 	if userIDVal := ctx.Value(contextkeys.UserIDKey); userIDVal != nil {
 		if userID, ok := userIDVal.(int); ok {
 			httpReq.Header.Set("X-User-ID", strconv.Itoa(userID))
-			logf("[SERVICE_INFO] getSurveyDetails: Forwarding X-User-ID: %d to survey-service", userID)
+			logf("[SERVICE_INFO] getFromSurveyService: Forwarding X-User-ID: %d to survey-service", userID)
 		}
 	}
 	if userRolesVal := ctx.Value(contextkeys.UserRolesKey); userRolesVal != nil {
 		if roles, ok := userRolesVal.([]string); ok {
 			rolesStr := fmt.Sprintf("%v", roles) // Produces "[role1 role2 ...]"
 			httpReq.Header.Set("X-User-Roles", rolesStr)
-			logf("[SERVICE_INFO] getSurveyDetails: Forwarding X-User-Roles: %s to survey-service", rolesStr)
+			logf("[SERVICE_INFO] getFromSurveyService: Forwarding X-User-Roles: %s to survey-service", rolesStr)
 		}
 	}
+	// The survey-service only lets users manage surveys of the organization they work in
+	if orgID, ok := ctx.Value(contextkeys.OrgIDKey).(int); ok {
+		httpReq.Header.Set("X-Org-ID", strconv.Itoa(orgID))
+	}
 
 	httpResp, err := s.httpClient.Do(httpReq)
 	if err != nil {
-		logf("[SERVICE_ERROR] getSurveyDetails: Failed to call survey-service for SurveyID %d: %v", surveyID, err)
-		return nil, fmt.Errorf("failed to call survey-service: %w", err)
+		return fmt.Errorf("failed to call survey-service: %w", err)
 	}
 	defer httpResp.Body.Close()
 
-	logf("[SERVICE_INFO] getSurveyDetails: Response status from survey-service for SurveyID %d: %d", surveyID, httpResp.StatusCode)
+	logf("[SERVICE_INFO] getFromSurveyService: Response status from survey-service for %s: %d", path, httpResp.StatusCode)
 	if httpResp.StatusCode == http.StatusNotFound {
-		return nil, errors.New("survey not found in survey-service")
+		return errors.New("survey not found in survey-service")
+	}
+	if httpResp.StatusCode == http.StatusUnauthorized {
+		return ErrAuthenticationRequired
+	}
+	if httpResp.StatusCode == http.StatusForbidden {
+		return errSurveyForbidden
 	}
 	if httpResp.StatusCode != http.StatusOK {
 		// Consider logging the body for more context on non-OK responses
-		return nil, fmt.Errorf("survey-service returned status %d", httpResp.StatusCode)
+		return fmt.Errorf("survey-service returned status %d", httpResp.StatusCode)
 	}
 
-	var surveyDetails models.SurveyDetailsFromService
-	if err := json.NewDecoder(httpResp.Body).Decode(&surveyDetails); err != nil {
-		logf("[SERVICE_ERROR] getSurveyDetails: Failed to decode response from survey-service for SurveyID %d: %v", surveyID, err)
-		return nil, fmt.Errorf("failed to decode response from survey-service: %w", err)
+	if err := json.NewDecoder(httpResp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response from survey-service: %w", err)
 	}
-	return &surveyDetails, nil
+	return nil
 }
 
 // SubmitResponse handles the business logic for submitting a new survey response
@@ -122,32 +165,59 @@ func (s *ResponseService) SubmitResponse(ctx context.Context, req *models.Create
 	logf("[SERVICE_INFO] SubmitResponse: Attempting to submit response for SurveyID: %d, UserID: %v", req.SurveyID, req.UserID)
 	logf("[SERVICE_INFO] SubmitResponse: Request Answers: %+v", req.Answers)
 
-	surveyDetails, err := s.getSurveyDetails(ctx, req.SurveyID)
+	surveyDetails, err := s.getRespondentSurveyDetails(ctx, req.SurveyID, req.LayoutSeed)
 	if err != nil {
 		logf("[SERVICE_ERROR] SubmitResponse: Failed to get survey details for SurveyID %d: %v", req.SurveyID, err)
 		return fmt.Errorf("failed to retrieve survey details (ID: %d): %w", req.SurveyID, err)
 	}
 	logf("[SERVICE_INFO] SubmitResponse: Successfully fetched survey details for SurveyID %d: %+v", req.SurveyID, surveyDetails)
 
-	if !surveyDetails.IsActive {
-		logf("[SERVICE_WARN] SubmitResponse: SurveyID %d is not active. Aborting submission.", req.SurveyID)
-		return errors.New("survey is not active and cannot accept new responses")
+	if err := checkAcceptingResponses(surveyDetails, req.UserID); err != nil {
+		logf("[SERVICE_WARN] SubmitResponse: SurveyID %d does not accept this response: %v. Aborting submission.", req.SurveyID, err)
+		return err
 	}
 
-	// TODO: Validate req.Answers against surveyDetails.Questions
-	// - Check if question IDs in answers are valid for the survey.
-	// - Check if answer values are consistent with question types (e.g., selected option ID is valid for single_choice).
-	logf("[SERVICE_INFO] SubmitResponse: SurveyID %d is active. Proceeding with response creation.", req.SurveyID)
+	if err := validateAnswers(surveyDetails.Questions, req.Answers); err != nil {
+		logf("[SERVICE_WARN] SubmitResponse: Answers for SurveyID %d failed validation: %v", req.SurveyID, err)
+		return err
+	}
+	if err := s.resolveFileAnswers(ctx, req.SurveyID, surveyDetails.Questions, req.Answers); err != nil {
+		logf("[SERVICE_WARN] SubmitResponse: File answers for SurveyID %d failed validation: %v", req.SurveyID, err)
+		return err
+	}
+	limitKeys, err := s.checkResponseLimits(ctx, surveyDetails, req)
+	if err != nil {
+		logf("[SERVICE_WARN] SubmitResponse: Response limits rejected submission for SurveyID %d: %v", req.SurveyID, err)
+		return err
+	}
+	if err := s.reserveResponseSlot(ctx, req.SurveyID, surveyDetails); err != nil {
+		logf("[SERVICE_WARN] SubmitResponse: Response quota rejected submission for SurveyID %d: %v", req.SurveyID, err)
+		return err
+	}
+	logf("[SERVICE_INFO] SubmitResponse: SurveyID %d is active and answers are valid. Proceeding with response creation.", req.SurveyID)
 
 	response := &models.Response{
 		SurveyID: req.SurveyID,
 		UserID:   req.UserID, // UserID is now reliably set by the handler from X-User-ID or original req
 		Answers:  req.Answers,
+		// Pin the response to the version the answers were validated against
+		SurveyVersion: surveyDetails.Version,
+		LimitKeys:     limitKeys,
+		Status:        models.ResponseStatusSubmitted,
+		Layout:        surveyDetails.Layout,
 		// SubmittedAt will be set by the repository
 	}
 
 	logf("[SERVICE_INFO] SubmitResponse: Attempting to create response in repository for SurveyID: %d", req.SurveyID)
 	err = s.repo.CreateResponse(ctx, response)
+	if err != nil {
+		s.releaseResponseSlot(ctx, req.SurveyID)
+	}
+	if errors.Is(err, repository.ErrDuplicateResponse) {
+		// A concurrent submission with the same limit key won the race
+		logf("[SERVICE_WARN] SubmitResponse: Duplicate response for SurveyID %d rejected by the database", req.SurveyID)
+		return fmt.Errorf("%w: a response was already submitted by this respondent", ErrResponseLimitReached)
+	}
 	if err != nil {
 		logf("[SERVICE_ERROR] SubmitResponse: Failed to create response in repository for SurveyID %d: %v", req.SurveyID, err)
 		return fmt.Errorf("failed to save response to database (SurveyID: %d): %w", req.SurveyID, err)
@@ -157,16 +227,42 @@ func (s *ResponseService) SubmitResponse(ctx context.Context, req *models.Create
 	return nil
 }
 
-// GetSurveyResponses retrieves all responses for a specific survey
+// checkAcceptingResponses rejects respondents while the survey is inactive or outside its schedule,
+// and anonymous respondents on surveys that require login
+func checkAcceptingResponses(survey *models.SurveyDetailsFromService, userID *int) error {
+	if !survey.IsActive {
+		return errors.New("survey is not active and cannot accept new responses")
+	}
+	switch survey.Status {
+	case surveyStatusScheduled:
+		return fmt.Errorf("%w: it opens at %s", ErrSurveyNotOpen, survey.StartDate.Format(time.RFC3339))
+	case surveyStatusClosed:
+		return fmt.Errorf("%w: it closed at %s", ErrSurveyNotOpen, survey.EndDate.Format(time.RFC3339))
+	}
+	if userID == nil && !survey.AllowAnonymous {
+		return ErrAuthenticationRequired
+	}
+	return nil
+}
+
+// GetSurveyResponses retrieves all responses for a specific survey, for its owner, its analysts or an admin
 func (s *ResponseService) GetSurveyResponses(ctx context.Context, surveyID int) ([]*models.Response, error) {
-	// TODO: Add any transformation or additional logic if needed
+	if _, err := s.authorizeResponseAccess(ctx, surveyID); err != nil {
+		return nil, err
+	}
 	return s.repo.GetResponsesBySurveyID(ctx, surveyID)
 }
 
-// GetSurveyAnalytics retrieves and processes survey responses to generate analytics
+// CountSurveyResponses returns how many responses a survey has received
+func (s *ResponseService) CountSurveyResponses(ctx context.Context, surveyID int) (int64, error) {
+	return s.repo.CountResponsesBySurveyID(ctx, surveyID)
+}
+
+// GetSurveyAnalytics retrieves and processes survey responses to generate analytics, for the survey's owner,
+// its analysts or an admin
 func (s *ResponseService) GetSurveyAnalytics(ctx context.Context, surveyID int) (*models.SurveyAnalyticsResponse, error) {
 	// 1. Fetch survey details
-	surveyDetails, err := s.getSurveyDetails(ctx, surveyID)
+	surveyDetails, err := s.authorizeResponseAccess(ctx, surveyID)
 	if err != nil {
 		logf("Error fetching survey details for analytics (surveyID: %d): %v", surveyID, err) // Keep error log
 		return nil, fmt.Errorf("failed to get survey details for analytics: %w", err)
@@ -178,6 +274,8 @@ func (s *ResponseService) GetSurveyAnalytics(ctx context.Context, surveyID int)
 		logf("Error fetching responses for analytics (surveyID: %d): %v", surveyID, err) // Keep error log
 		return nil, fmt.Errorf("failed to get survey responses for analytics: %w", err)
 	}
+	// Responses given on older versions are counted against the current options
+	responses = s.translateResponsesToCurrentVersion(ctx, surveyDetails, responses)
 
 	totalResponses := len(responses)
 	analyticsResp := &models.SurveyAnalyticsResponse{
@@ -187,187 +285,49 @@ func (s *ResponseService) GetSurveyAnalytics(ctx context.Context, surveyID int)
 		QuestionAnalytics: make([]models.QuestionAnalytics, 0, len(surveyDetails.Questions)),
 	}
 
-	optionTextToIDMap := make(map[int]map[string]int)
-	for _, q := range surveyDetails.Questions {
-		if q.Type == "single_choice" || q.Type == "multiple_choice" || q.Type == "dropdown" || q.Type == "checkbox" {
-			optionTextToIDMap[q.ID] = make(map[string]int)
-			for _, opt := range q.Options {
-				optionTextToIDMap[q.ID][opt.Text] = opt.ID
-			}
-		}
-	}
-
-	if totalResponses == 0 {
-		for _, q := range surveyDetails.Questions {
-			qa := models.QuestionAnalytics{
-				QuestionID:   q.ID,
-				QuestionText: q.Text,
-				QuestionType: q.Type,
-			}
-			if q.Type == "single_choice" || q.Type == "multiple_choice" || q.Type == "dropdown" || q.Type == "checkbox" || q.Type == "linear_scale" {
-				qa.OptionsSummary = make([]models.OptionSummary, 0)
-				if q.Type == "linear_scale" {
-					for i := 1; i <= 5; i++ { // Assuming 1-5 scale
-						val := i
-						qa.OptionsSummary = append(qa.OptionsSummary, models.OptionSummary{OptionID: &val, OptionText: fmt.Sprintf("%d", val), Count: 0, Percentage: 0})
-					}
-				} else {
-					for _, opt := range q.Options {
-						optID := opt.ID
-						qa.OptionsSummary = append(qa.OptionsSummary, models.OptionSummary{OptionID: &optID, OptionText: opt.Text, Count: 0, Percentage: 0})
-					}
-				}
-			}
-			analyticsResp.QuestionAnalytics = append(analyticsResp.QuestionAnalytics, qa)
-		}
-		return analyticsResp, nil
-	}
-
 	for _, q := range surveyDetails.Questions {
 		qa := models.QuestionAnalytics{
 			QuestionID:   q.ID,
 			QuestionText: q.Text,
 			QuestionType: q.Type,
 		}
-		actualRespondersToThisQuestion := 0
-
-		switch q.Type {
-		case "single_choice", "multiple_choice", "dropdown":
-			optionCounts := make(map[int]int)
-			for _, opt := range q.Options {
-				optionCounts[opt.ID] = 0
-			}
-			for _, resp := range responses {
-				foundAnswerToThisQuestionInResp := false
-				for _, ans := range resp.Answers {
-					if ans.QuestionID == q.ID {
-						if selectedOptionText, ok := ans.Value.(string); ok {
-							if optID, found := optionTextToIDMap[q.ID][selectedOptionText]; found {
-								optionCounts[optID]++
-								if !foundAnswerToThisQuestionInResp {
-									actualRespondersToThisQuestion++
-									foundAnswerToThisQuestionInResp = true
-								}
-							}
-						}
-						break
-					}
-				}
-			}
-			qa.OptionsSummary = make([]models.OptionSummary, 0, len(q.Options))
-			for _, opt := range q.Options {
-				count := optionCounts[opt.ID]
-				percentage := 0.0
-				if actualRespondersToThisQuestion > 0 {
-					percentage = (float64(count) / float64(actualRespondersToThisQuestion)) * 100
-				}
-				optID := opt.ID
-				qa.OptionsSummary = append(qa.OptionsSummary, models.OptionSummary{OptionID: &optID, OptionText: opt.Text, Count: count, Percentage: percentage})
-			}
-
-		case "checkbox":
-			optionCounts := make(map[string]int)
-			optionTextToIDMap := make(map[string]int)
-			for _, opt := range q.Options {
-				optionCounts[opt.Text] = 0
-				optionTextToIDMap[opt.Text] = opt.ID
-			}
-			for _, resp := range responses {
-				foundAnswerToThisQuestionInResp := false
-				for _, ans := range resp.Answers {
-					if ans.QuestionID == q.ID {
-						if selectedOptionValues, ok := ans.Value.(primitive.A); ok {
-							if len(selectedOptionValues) > 0 && !foundAnswerToThisQuestionInResp {
-								actualRespondersToThisQuestion++
-								foundAnswerToThisQuestionInResp = true
-							}
-							for _, valInterface := range selectedOptionValues {
-								if optionValueStr, isString := valInterface.(string); isString {
-									if _, knownOption := optionCounts[optionValueStr]; knownOption {
-										optionCounts[optionValueStr]++
-									}
-								}
-							}
-						}
-						break
-					}
-				}
-			}
-			qa.OptionsSummary = make([]models.OptionSummary, 0, len(q.Options))
-			for _, opt := range q.Options {
-				count := optionCounts[opt.Text]
-				percentage := 0.0
-				if actualRespondersToThisQuestion > 0 {
-					percentage = (float64(count) / float64(actualRespondersToThisQuestion)) * 100
-				}
-				optID := opt.ID
-				qa.OptionsSummary = append(qa.OptionsSummary, models.OptionSummary{OptionID: &optID, OptionText: opt.Text, Count: count, Percentage: percentage})
-			}
+		// Question types without an aggregator are listed without figures
+		if kind, ok := answerKindOf(q.Type); ok && kind.aggregate != nil {
+			kind.aggregate(&qa, q, answeredValues(responses, q.ID))
+		}
+		if allowsOther(q) {
+			qa.OtherResponses = otherTexts(responses, q.ID)
+		}
+		qa.OrderEffects = orderEffects(q, responses)
+		analyticsResp.QuestionAnalytics = append(analyticsResp.QuestionAnalytics, qa)
+	}
 
-		case "linear_scale":
-			valueCounts := make(map[int]int)
-			minScale, maxScale := 1, 5 // Assuming 1-5 scale
-			for i := minScale; i <= maxScale; i++ {
-				valueCounts[i] = 0
-			}
-			for _, resp := range responses {
-				foundAnswerToThisQuestionInResp := false
-				for _, ans := range resp.Answers {
-					if ans.QuestionID == q.ID {
-						if selectedValueFloat, ok := ans.Value.(float64); ok {
-							selectedValueInt := int(selectedValueFloat)
-							if selectedValueInt >= minScale && selectedValueInt <= maxScale {
-								valueCounts[selectedValueInt]++
-								if !foundAnswerToThisQuestionInResp {
-									actualRespondersToThisQuestion++
-									foundAnswerToThisQuestionInResp = true
-								}
-							}
-						}
-						break
-					}
-				}
-			}
-			qa.OptionsSummary = make([]models.OptionSummary, 0, maxScale-minScale+1)
-			for i := minScale; i <= maxScale; i++ {
-				count := valueCounts[i]
-				percentage := 0.0
-				if actualRespondersToThisQuestion > 0 {
-					percentage = (float64(count) / float64(actualRespondersToThisQuestion)) * 100
-				}
-				val := i
-				qa.OptionsSummary = append(qa.OptionsSummary, models.OptionSummary{OptionID: &val, OptionText: fmt.Sprintf("%d", i), Count: count, Percentage: percentage})
-			}
+	return analyticsResp, nil
+}
 
-		case "text", "paragraph", "short_answer", "date":
-			qa.TextResponses = make([]models.TextResponseData, 0)
-			for _, resp := range responses {
-				for _, ans := range resp.Answers {
-					if ans.QuestionID == q.ID {
-						if textValue, ok := ans.Value.(string); ok && textValue != "" {
-							qa.TextResponses = append(qa.TextResponses, models.TextResponseData{Response: textValue})
-						}
-						break
-					}
+// answeredValues collects the non-empty answer to a question from each response
+func answeredValues(responses []*models.Response, questionID int) []interface{} {
+	values := make([]interface{}, 0, len(responses))
+	for _, resp := range responses {
+		for _, ans := range resp.Answers {
+			if ans.QuestionID == questionID {
+				if !isEmptyAnswer(ans.Value) {
+					values = append(values, ans.Value)
 				}
+				break
 			}
-
-		default:
-			// Handle unknown or non-analyzable question type
 		}
-		analyticsResp.QuestionAnalytics = append(analyticsResp.QuestionAnalytics, qa)
 	}
-
-	return analyticsResp, nil
+	return values
 }
 
-// ExportSurveyResponsesCSV generates a CSV string of all responses for a given survey.
-// It fetches survey details for question text (headers) and all responses.
+// ExportSurveyResponsesCSV generates a CSV string of all responses for a given survey, for its owner, its analysts
+// or an admin. It fetches survey details for question text (headers) and all responses.
 func (s *ResponseService) ExportSurveyResponsesCSV(ctx context.Context, surveyID int) (csvData string, filename string, err error) {
 	logf("[SERVICE_INFO] ExportSurveyResponsesCSV: Starting export for SurveyID %d", surveyID)
 
 	// 1. Fetch Survey Details (for question texts as headers)
-	surveyDetails, err := s.getSurveyDetails(ctx, surveyID)
+	surveyDetails, err := s.authorizeResponseAccess(ctx, surveyID)
 	if err != nil {
 		logf("[SERVICE_ERROR] ExportSurveyResponsesCSV: Failed to get survey details for SurveyID %d: %v", surveyID, err)
 		return "", "", fmt.Errorf("failed to retrieve survey details (ID: %d): %w", surveyID, err)
@@ -375,7 +335,7 @@ func (s *ResponseService) ExportSurveyResponsesCSV(ctx context.Context, surveyID
 	logf("[SERVICE_INFO] ExportSurveyResponsesCSV: Successfully fetched survey details for SurveyID %d. Number of questions: %d", surveyID, len(surveyDetails.Questions))
 
 	// 2. Fetch All Responses for this Survey
-	responses, err := s.GetSurveyResponses(ctx, surveyID) // This already calls s.repo.GetResponsesBySurveyID
+	responses, err := s.repo.GetResponsesBySurveyID(ctx, surveyID)
 	if err != nil {
 		logf("[SERVICE_ERROR] ExportSurveyResponsesCSV: Failed to get responses for SurveyID %d: %v", surveyID, err)
 		return "", "", fmt.Errorf("failed to retrieve responses (ID: %d): %w", surveyID, err)
@@ -386,15 +346,32 @@ func (s *ResponseService) ExportSurveyResponsesCSV(ctx context.Context, surveyID
 	var csvBuffer strings.Builder
 	csvWriter := csv.NewWriter(&csvBuffer)
 
-	// 3a. Write Headers
-	headers := []string{"ResponseID", "SubmittedAt", "UserID"}
-	questionIDToHeaderIndex := make(map[int]int) // Map question ID to its column index in the CSV
-	questionIDToType := make(map[int]string)     // Map question ID to its type for answer formatting
+	// Older versions the responses were given on, for questions that have since changed or been removed
+	answeredVersions := s.getAnsweredVersions(ctx, surveyDetails, responses)
+
+	// 3a. Write Headers. Most questions get one column, some types (e.g. matrix) one per part of the question.
+	headers := []string{"ResponseID", "SubmittedAt", "UserID", "SurveyVersion"}
+	questionIDToHeaderIndex := make(map[int]int)              // Map question ID to its first column index in the CSV
+	questionsByID := make(map[int]models.QuestionFromService) // Question defining each question's columns
+	addQuestionColumns := func(q models.QuestionFromService, title string) {
+		questionIDToHeaderIndex[q.ID] = len(headers)
+		questionsByID[q.ID] = q
+		headers = append(headers, csvHeaders(q, title)...)
+	}
 
 	for _, q := range surveyDetails.Questions {
-		headers = append(headers, q.Text) // Use question text as header
-		questionIDToHeaderIndex[q.ID] = len(headers) - 1
-		questionIDToType[q.ID] = q.Type
+		addQuestionColumns(q, q.Text) // Use question text as header
+	}
+	// Questions removed since an older version still get columns so their answers are not lost
+	for _, version := range sortedVersionNumbers(answeredVersions) {
+		if answeredVersions[version] == nil {
+			continue
+		}
+		for _, q := range answeredVersions[version].Questions {
+			if _, ok := questionIDToHeaderIndex[q.ID]; !ok {
+				addQuestionColumns(q, fmt.Sprintf("%s (v%d)", q.Text, version))
+			}
+		}
 	}
 
 	if err := csvWriter.Write(headers); err != nil {
@@ -414,37 +391,35 @@ func (s *ResponseService) ExportSurveyResponsesCSV(ctx context.Context, surveyID
 		} else {
 			row[2] = "Anonymous"
 		}
+		if resp.SurveyVersion > 0 {
+			row[3] = strconv.Itoa(resp.SurveyVersion)
+		}
+
+		// Interpret answers with the questions of the version they were given on
+		answeredQuestions := questionsByID
+		if old := answeredVersions[resp.SurveyVersion]; old != nil {
+			answeredQuestions = make(map[int]models.QuestionFromService, len(old.Questions))
+			for _, q := range old.Questions {
+				answeredQuestions[q.ID] = q
+			}
+		}
 
-		// Answer columns - map answers to the correct question column
+		// Answer columns - map answers to the correct question columns
 		for _, ans := range resp.Answers {
-			if headerIndex, ok := questionIDToHeaderIndex[ans.QuestionID]; ok {
-				// Format answer value based on its type and question type
-				var formattedValue string
-				if ans.Value == nil {
-					formattedValue = ""
-				} else {
-					qType := questionIDToType[ans.QuestionID]
-					switch qType {
-					case "checkbox": // Checkbox answers are []interface{} or []string
-						if valSlice, ok := ans.Value.([]interface{}); ok {
-							var strVals []string
-							for _, item := range valSlice {
-								strVals = append(strVals, fmt.Sprintf("%v", item))
-							}
-							formattedValue = strings.Join(strVals, "; ") // Join multiple checkbox values
-						} else if valStrSlice, ok := ans.Value.([]string); ok {
-							formattedValue = strings.Join(valStrSlice, "; ")
-						} else {
-							formattedValue = fmt.Sprintf("%v", ans.Value) // Fallback
-						}
-					default:
-						formattedValue = fmt.Sprintf("%v", ans.Value)
-					}
-				}
-				row[headerIndex] = formattedValue
-			} else {
+			headerIndex, ok := questionIDToHeaderIndex[ans.QuestionID]
+			if !ok {
 				logf("[SERVICE_WARN] ExportSurveyResponsesCSV: Answer for QuestionID %d found in response %s, but this QuestionID is not in the survey's question list.", ans.QuestionID, resp.ID.Hex())
+				continue
+			}
+			if ans.Value == nil {
+				continue
+			}
+			q := questionsByID[ans.QuestionID]
+			answeredAs, ok := answeredQuestions[ans.QuestionID]
+			if !ok {
+				answeredAs = q
 			}
+			copy(row[headerIndex:], csvCells(q, answeredAs, ans))
 		}
 		if err := csvWriter.Write(row); err != nil {
 			logf("[SERVICE_ERROR] ExportSurveyResponsesCSV: Error writing CSV row for response %s, SurveyID %d: %v", resp.ID.Hex(), surveyID, err)
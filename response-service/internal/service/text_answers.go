@@ -0,0 +1,224 @@
+package service
+
+import (
+	"math"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/shared/questiontypes"
+)
+
+// Numeric answers are plain decimals such as "42", "-3" or "2.50"; exponents and thousands separators are not accepted
+var numberAnswerPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// Percentiles reported in numeric analytics, and the most histogram buckets drawn
+var reportedPercentiles = []int{10, 25, 50, 75, 90}
+
+const maxHistogramBuckets = 20
+
+// textConfigOf returns the constraints on a text question's answers, none if its config is missing or unusable
+func textConfigOf(q models.QuestionFromService) *questiontypes.TextConfig {
+	if config, ok := configOf(q).(*questiontypes.TextConfig); ok {
+		return config
+	}
+	return &questiontypes.TextConfig{}
+}
+
+// dateConfigOf returns the range of a date question, none if its config is missing or unusable
+func dateConfigOf(q models.QuestionFromService) *questiontypes.DateConfig {
+	if config, ok := configOf(q).(*questiontypes.DateConfig); ok {
+		return config
+	}
+	return &questiontypes.DateConfig{}
+}
+
+// parseNumberAnswer parses a numeric answer and counts its decimal places
+func parseNumberAnswer(answer string) (num float64, decimals int, ok bool) {
+	answer = strings.TrimSpace(answer)
+	if !numberAnswerPattern.MatchString(answer) {
+		return 0, 0, false
+	}
+	num, err := strconv.ParseFloat(answer, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	if dot := strings.IndexByte(answer, '.'); dot >= 0 {
+		decimals = len(answer) - dot - 1
+	}
+	return num, decimals, true
+}
+
+// validateTextAnswer checks a text answer against the format, length and pattern its question's config asks for
+func validateTextAnswer(verr *AnswerValidationError, q models.QuestionFromService, value interface{}) {
+	config := textConfigOf(q)
+	answer := value.(string)
+
+	if config.MaxLength > 0 && utf8.RuneCountInString(answer) > config.MaxLength {
+		verr.add(q.ID, AnswerErrOutOfRange, "question %d allows at most %d characters", q.ID, config.MaxLength)
+	}
+
+	switch config.Format {
+	case questiontypes.TextFormatNumber:
+		validateNumberAnswer(verr, q, config, answer)
+	case questiontypes.TextFormatEmail:
+		if addr, err := mail.ParseAddress(answer); err != nil || addr.Name != "" || addr.Address != answer {
+			verr.add(q.ID, AnswerErrInvalidFormat, "question %d expects an email address", q.ID)
+		}
+	case questiontypes.TextFormatURL:
+		if u, err := url.ParseRequestURI(answer); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			verr.add(q.ID, AnswerErrInvalidFormat, "question %d expects a web address starting with http:// or https://", q.ID)
+		}
+	}
+
+	if config.Pattern != "" {
+		// The whole answer must match, as in the frontend
+		pattern, err := regexp.Compile("^(?:" + config.Pattern + ")$")
+		if err != nil {
+			logf("[SERVICE_WARN] Invalid pattern of question %d, not checking it: %v", q.ID, err)
+			return
+		}
+		if !pattern.MatchString(answer) {
+			if config.PatternMessage != "" {
+				verr.add(q.ID, AnswerErrInvalidFormat, "%s", config.PatternMessage)
+			} else {
+				verr.add(q.ID, AnswerErrInvalidFormat, "question %d does not match the expected format", q.ID)
+			}
+		}
+	}
+}
+
+func validateNumberAnswer(verr *AnswerValidationError, q models.QuestionFromService, config *questiontypes.TextConfig, answer string) {
+	num, decimals, ok := parseNumberAnswer(answer)
+	if !ok {
+		verr.add(q.ID, AnswerErrInvalidFormat, "question %d expects a number", q.ID)
+		return
+	}
+	if config.Decimals != nil && decimals > *config.Decimals {
+		if *config.Decimals == 0 {
+			verr.add(q.ID, AnswerErrInvalidFormat, "question %d expects a whole number", q.ID)
+		} else {
+			verr.add(q.ID, AnswerErrInvalidFormat, "question %d allows at most %d decimal places", q.ID, *config.Decimals)
+		}
+	}
+	switch {
+	case config.Min != nil && config.Max != nil && (num < *config.Min || num > *config.Max):
+		verr.add(q.ID, AnswerErrOutOfRange, "question %d expects a number between %g and %g", q.ID, *config.Min, *config.Max)
+	case config.Min != nil && config.Max == nil && num < *config.Min:
+		verr.add(q.ID, AnswerErrOutOfRange, "question %d expects a number of at least %g", q.ID, *config.Min)
+	case config.Max != nil && config.Min == nil && num > *config.Max:
+		verr.add(q.ID, AnswerErrOutOfRange, "question %d expects a number of at most %g", q.ID, *config.Max)
+	}
+}
+
+func validateDateAnswer(verr *AnswerValidationError, q models.QuestionFromService, value interface{}) {
+	date, err := time.Parse(questiontypes.DateAnswerLayout, value.(string))
+	if err != nil {
+		verr.add(q.ID, AnswerErrInvalidType, "question %d expects a date in YYYY-MM-DD format", q.ID)
+		return
+	}
+	// The bounds were validated by survey-service; unparsable ones are ignored
+	config := dateConfigOf(q)
+	min, minErr := time.Parse(questiontypes.DateAnswerLayout, config.Min)
+	max, maxErr := time.Parse(questiontypes.DateAnswerLayout, config.Max)
+	switch {
+	case minErr == nil && maxErr == nil && (date.Before(min) || date.After(max)):
+		verr.add(q.ID, AnswerErrOutOfRange, "question %d expects a date between %s and %s", q.ID, config.Min, config.Max)
+	case minErr == nil && maxErr != nil && date.Before(min):
+		verr.add(q.ID, AnswerErrOutOfRange, "question %d expects a date on or after %s", q.ID, config.Min)
+	case maxErr == nil && minErr != nil && date.After(max):
+		verr.add(q.ID, AnswerErrOutOfRange, "question %d expects a date on or before %s", q.ID, config.Max)
+	}
+}
+
+// aggregateFormattedTextAnswers lists the written answers, adding statistics for questions in the number format
+func aggregateFormattedTextAnswers(qa *models.QuestionAnalytics, q models.QuestionFromService, values []interface{}) {
+	aggregateTextAnswers(qa, q, values)
+	if textConfigOf(q).Format != questiontypes.TextFormatNumber {
+		return
+	}
+	nums := make([]float64, 0, len(values))
+	for _, value := range values {
+		if answer, ok := value.(string); ok {
+			if num, _, ok := parseNumberAnswer(answer); ok {
+				nums = append(nums, num)
+			}
+		}
+	}
+	qa.NumericSummary = numericSummary(nums)
+}
+
+// numericSummary computes the statistics of numeric answers
+func numericSummary(nums []float64) *models.NumericSummary {
+	summary := &models.NumericSummary{Count: len(nums), Percentiles: []models.PercentileValue{}, Histogram: []models.HistogramBucket{}}
+	if len(nums) == 0 {
+		return summary
+	}
+	sorted := append([]float64(nil), nums...)
+	sort.Float64s(sorted)
+
+	sum := 0.0
+	for _, num := range sorted {
+		sum += num
+	}
+	summary.Mean = sum / float64(len(sorted))
+	if len(sorted) > 1 {
+		squares := 0.0
+		for _, num := range sorted {
+			squares += (num - summary.Mean) * (num - summary.Mean)
+		}
+		summary.StdDev = math.Sqrt(squares / float64(len(sorted)-1))
+	}
+	summary.Min = sorted[0]
+	summary.Max = sorted[len(sorted)-1]
+	summary.Median = percentileOf(sorted, 50)
+	for _, p := range reportedPercentiles {
+		summary.Percentiles = append(summary.Percentiles, models.PercentileValue{Percentile: p, Value: percentileOf(sorted, p)})
+	}
+	summary.Histogram = histogramOf(sorted)
+	return summary
+}
+
+// percentileOf interpolates linearly between the closest ranks of sorted, which must not be empty
+func percentileOf(sorted []float64, p int) float64 {
+	rank := float64(p) / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	if lower >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lower] + (rank-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
+
+// histogramOf spreads sorted, which must not be empty, over equally wide buckets: one per doubling of
+// the number of answers (Sturges' rule), or a single bucket when all answers are equal
+func histogramOf(sorted []float64) []models.HistogramBucket {
+	min, max := sorted[0], sorted[len(sorted)-1]
+	if min == max {
+		return []models.HistogramBucket{{From: min, To: max, Count: len(sorted)}}
+	}
+	count := int(math.Ceil(math.Log2(float64(len(sorted))))) + 1
+	if count > maxHistogramBuckets {
+		count = maxHistogramBuckets
+	}
+	width := (max - min) / float64(count)
+	buckets := make([]models.HistogramBucket, count)
+	for i := range buckets {
+		buckets[i].From = min + float64(i)*width
+		buckets[i].To = min + float64(i+1)*width
+	}
+	buckets[count-1].To = max
+	for _, num := range sorted {
+		i := int((num - min) / width)
+		if i >= count {
+			i = count - 1
+		}
+		buckets[i].Count++
+	}
+	return buckets
+}
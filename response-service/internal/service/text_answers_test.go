@@ -0,0 +1,109 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextAnswerConstraints(t *testing.T) {
+	age := models.QuestionFromService{ID: 1, Type: "short_answer", Config: []byte(`{"format": "number", "min": 0, "max": 130, "decimals": 0}`)}
+	price := models.QuestionFromService{ID: 2, Type: "short_answer", Config: []byte(`{"format": "number", "min": 0.5, "decimals": 2}`)}
+	email := models.QuestionFromService{ID: 3, Type: "short_answer", Config: []byte(`{"format": "email"}`)}
+	website := models.QuestionFromService{ID: 4, Type: "text", Config: []byte(`{"format": "url"}`)}
+	postcode := models.QuestionFromService{ID: 5, Type: "text", Config: []byte(`{"pattern": "[0-9]{5}", "pattern_message": "Enter 5 digits"}`)}
+	bio := models.QuestionFromService{ID: 6, Type: "paragraph", Config: []byte(`{"max_length": 5}`)}
+	visit := models.QuestionFromService{ID: 7, Type: "date", Config: []byte(`{"min": "2025-01-01", "max": "2025-12-31"}`)}
+
+	tests := []struct {
+		name      string
+		question  models.QuestionFromService
+		value     string
+		wantCodes []string
+	}{
+		{name: "Whole number in range", question: age, value: "42"},
+		{name: "Number with spaces", question: age, value: " 42 "},
+		{name: "Not a number", question: age, value: "forty", wantCodes: []string{AnswerErrInvalidFormat}},
+		{name: "Exponent", question: age, value: "4e1", wantCodes: []string{AnswerErrInvalidFormat}},
+		{name: "Decimals on a whole number", question: age, value: "42.5", wantCodes: []string{AnswerErrInvalidFormat}},
+		{name: "Number above max", question: age, value: "131", wantCodes: []string{AnswerErrOutOfRange}},
+		{name: "Decimals within limit", question: price, value: "9.99"},
+		{name: "Too many decimals and below min", question: price, value: "0.125", wantCodes: []string{AnswerErrInvalidFormat, AnswerErrOutOfRange}},
+		{name: "Email address", question: email, value: "ann@example.com"},
+		{name: "Email with display name", question: email, value: "Ann <ann@example.com>", wantCodes: []string{AnswerErrInvalidFormat}},
+		{name: "Not an email", question: email, value: "ann.example.com", wantCodes: []string{AnswerErrInvalidFormat}},
+		{name: "Web address", question: website, value: "https://example.com/about"},
+		{name: "Address without scheme", question: website, value: "example.com", wantCodes: []string{AnswerErrInvalidFormat}},
+		{name: "Other scheme", question: website, value: "ftp://example.com", wantCodes: []string{AnswerErrInvalidFormat}},
+		{name: "Matches pattern", question: postcode, value: "12345"},
+		{name: "Pattern must match the whole answer", question: postcode, value: "123456", wantCodes: []string{AnswerErrInvalidFormat}},
+		{name: "Within max length", question: bio, value: "héllo"},
+		{name: "Over max length", question: bio, value: "hello!", wantCodes: []string{AnswerErrOutOfRange}},
+		{name: "Date in range", question: visit, value: "2025-06-15"},
+		{name: "Date before range", question: visit, value: "2024-12-31", wantCodes: []string{AnswerErrOutOfRange}},
+		{name: "Not a date", question: visit, value: "15/06/2025", wantCodes: []string{AnswerErrInvalidType}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAnswers([]models.QuestionFromService{tt.question}, []models.Answer{{QuestionID: tt.question.ID, Value: tt.value}})
+			if tt.wantCodes == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var verr *AnswerValidationError
+			if assert.True(t, errors.As(err, &verr)) {
+				var codes []string
+				for _, e := range verr.Errors {
+					codes = append(codes, e.Code)
+				}
+				assert.Equal(t, tt.wantCodes, codes)
+			}
+		})
+	}
+
+	t.Run("Pattern message is reported", func(t *testing.T) {
+		err := validateAnswers([]models.QuestionFromService{postcode}, []models.Answer{{QuestionID: 5, Value: "abc"}})
+		var verr *AnswerValidationError
+		require.True(t, errors.As(err, &verr))
+		assert.Equal(t, "Enter 5 digits", verr.Errors[0].Message)
+	})
+}
+
+func TestNumericAnalytics(t *testing.T) {
+	q := models.QuestionFromService{ID: 1, Type: "short_answer", Config: []byte(`{"format": "number"}`)}
+	values := []interface{}{"1", "2", "3", "4", "10", "not a number"}
+
+	var qa models.QuestionAnalytics
+	answerKindOfType(t, "short_answer").aggregate(&qa, q, values)
+	assert.Len(t, qa.TextResponses, 6)
+	summary := qa.NumericSummary
+	require.NotNil(t, summary)
+	assert.Equal(t, 5, summary.Count)
+	assert.Equal(t, 4.0, summary.Mean)
+	assert.Equal(t, 3.0, summary.Median)
+	assert.InDelta(t, 3.5355, summary.StdDev, 0.0001)
+	assert.Equal(t, 1.0, summary.Min)
+	assert.Equal(t, 10.0, summary.Max)
+	wantPercentiles := map[int]float64{10: 1.4, 25: 2, 50: 3, 75: 4, 90: 7.6}
+	require.Len(t, summary.Percentiles, len(wantPercentiles))
+	for _, p := range summary.Percentiles {
+		assert.InDelta(t, wantPercentiles[p.Percentile], p.Value, 1e-9, "percentile %d", p.Percentile)
+	}
+
+	// 5 answers make 4 buckets of width 2.25 between 1 and 10
+	var counts []int
+	for _, bucket := range summary.Histogram {
+		counts = append(counts, bucket.Count)
+	}
+	assert.Equal(t, []int{3, 1, 0, 1}, counts)
+	assert.Equal(t, 10.0, summary.Histogram[3].To)
+
+	t.Run("Plain text questions have no statistics", func(t *testing.T) {
+		var qa models.QuestionAnalytics
+		answerKindOfType(t, "short_answer").aggregate(&qa, models.QuestionFromService{ID: 2, Type: "short_answer"}, values)
+		assert.Nil(t, qa.NumericSummary)
+	})
+}
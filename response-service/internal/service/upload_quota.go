@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/contextkeys"
+)
+
+// ErrUploadQuotaExceeded is returned when a respondent has uploaded too many files, or too many bytes, recently
+var ErrUploadQuotaExceeded = errors.New("upload quota exceeded, try again later")
+
+// UploadQuota limits how much each respondent uploads within a window. Signed-in respondents are told apart by
+// user, anonymous ones by client address. A zero MaxFiles or MaxBytes leaves that limit off.
+type UploadQuota struct {
+	MaxFiles int
+	MaxBytes int64
+	Window   time.Duration
+}
+
+// uploadUsage is what one respondent uploaded in the current window
+type uploadUsage struct {
+	since time.Time
+	files int
+	bytes int64
+}
+
+// uploadQuotaTracker counts uploads per respondent in memory, so each replica enforces the quota on its own
+type uploadQuotaTracker struct {
+	quota     UploadQuota
+	mu        sync.Mutex
+	usage     map[string]*uploadUsage
+	lastPrune time.Time
+}
+
+func newUploadQuotaTracker(quota UploadQuota) *uploadQuotaTracker {
+	return &uploadQuotaTracker{quota: quota, usage: make(map[string]*uploadUsage)}
+}
+
+// uploaderKey identifies the respondent of ctx for the quota
+func uploaderKey(ctx context.Context) string {
+	if uid, ok := ctx.Value(contextkeys.UserIDKey).(int); ok {
+		return fmt.Sprintf("user:%d", uid)
+	}
+	ip, _ := ctx.Value(contextkeys.ClientIPKey).(string)
+	return "ip:" + ip
+}
+
+// uploadReservation holds the bytes reserved for an upload in flight until it is settled
+type uploadReservation struct {
+	usage *uploadUsage
+	bytes int64
+}
+
+// reserve counts an upload against the respondent's quota before it is stored, and reserves up to maxSize of the
+// bytes the respondent has left, so concurrent uploads cannot together exceed the quota. The reservation's bytes
+// are how much the upload may take; when bytes are not limited nothing is reserved and the upload may take maxSize.
+func (t *uploadQuotaTracker) reserve(key string, maxSize int64, now time.Time) (*uploadReservation, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Forget respondents whose window has passed, at most once per window
+	if now.Sub(t.lastPrune) >= t.quota.Window {
+		for k, usage := range t.usage {
+			if now.Sub(usage.since) >= t.quota.Window {
+				delete(t.usage, k)
+			}
+		}
+		t.lastPrune = now
+	}
+
+	usage, ok := t.usage[key]
+	if !ok || now.Sub(usage.since) >= t.quota.Window {
+		usage = &uploadUsage{since: now}
+		t.usage[key] = usage
+	}
+	if t.quota.MaxFiles > 0 && usage.files >= t.quota.MaxFiles {
+		return nil, fmt.Errorf("%w: at most %d files per %s", ErrUploadQuotaExceeded, t.quota.MaxFiles, t.quota.Window)
+	}
+	if t.quota.MaxBytes > 0 && usage.bytes >= t.quota.MaxBytes {
+		return nil, fmt.Errorf("%w: at most %d bytes per %s", ErrUploadQuotaExceeded, t.quota.MaxBytes, t.quota.Window)
+	}
+	usage.files++
+	reservation := &uploadReservation{usage: usage, bytes: maxSize}
+	if t.quota.MaxBytes <= 0 {
+		reservation.bytes = 0
+	} else if remaining := t.quota.MaxBytes - usage.bytes; remaining < maxSize {
+		reservation.bytes = remaining
+	}
+	usage.bytes += reservation.bytes
+	return reservation, nil
+}
+
+// settle replaces the bytes reserved for an upload with its stored size, 0 when the upload failed.
+// The upload still counts as one of the respondent's files.
+func (t *uploadQuotaTracker) settle(reservation *uploadReservation, size int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	reservation.usage.bytes += size - reservation.bytes
+	reservation.bytes = size
+}
+
+// LimitUploads enforces quota on UploadFile. Without it, uploads are only limited by the size limits of the questions.
+func (s *ResponseService) LimitUploads(quota UploadQuota) {
+	s.uploadQuota = newUploadQuotaTracker(quota)
+}
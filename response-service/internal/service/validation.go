@@ -0,0 +1,251 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Codes reported in models.AnswerError so the frontend can react to each kind of problem
+const (
+	AnswerErrUnknownQuestion = "unknown_question"
+	AnswerErrDuplicateAnswer = "duplicate_answer"
+	AnswerErrRequired        = "required"
+	AnswerErrInvalidType     = "invalid_type"
+	AnswerErrInvalidOption   = "invalid_option"
+	AnswerErrOutOfRange      = "out_of_range"
+	AnswerErrInvalidFormat   = "invalid_format"
+	AnswerErrInvalidFile     = "invalid_file"
+	AnswerErrHiddenQuestion  = "hidden_question"
+)
+
+// ErrInvalidAnswers is matched by errors.Is when a submission fails answer validation
+var ErrInvalidAnswers = errors.New("invalid answers")
+
+// AnswerValidationError carries every per-question problem found in a submission
+type AnswerValidationError struct {
+	Errors []models.AnswerError
+}
+
+func (e *AnswerValidationError) Error() string {
+	return fmt.Sprintf("%s: %d answer(s) failed validation", ErrInvalidAnswers.Error(), len(e.Errors))
+}
+
+// Unwrap allows errors.Is(err, ErrInvalidAnswers)
+func (e *AnswerValidationError) Unwrap() error {
+	return ErrInvalidAnswers
+}
+
+func (e *AnswerValidationError) add(questionID int, code, format string, args ...interface{}) {
+	e.Errors = append(e.Errors, models.AnswerError{
+		QuestionID: questionID,
+		Code:       code,
+		Message:    fmt.Sprintf(format, args...),
+	})
+}
+
+// validateAnswers checks submitted answers against the survey's questions.
+// It returns nil when every answer is acceptable, or an *AnswerValidationError listing all problems.
+// Answers selecting options are completed in place with the IDs and texts of the options, see normalizeOptionAnswers.
+func validateAnswers(questions []models.QuestionFromService, answers []models.Answer) error {
+	return checkAnswers(questions, answers, func(models.QuestionFromService) bool { return true })
+}
+
+// validateDraftAnswers checks the answers given so far in a draft; required questions may still be unanswered
+func validateDraftAnswers(questions []models.QuestionFromService, answers []models.Answer) error {
+	return checkAnswers(questions, answers, nil)
+}
+
+// validatePageAnswers checks the answers to one section (page) of the survey before the respondent moves on.
+// Answers on earlier pages are taken into account for the survey logic, but only the page's own problems are reported.
+func validatePageAnswers(questions []models.QuestionFromService, sectionID int, answers []models.Answer) error {
+	onPage := func(q models.QuestionFromService) bool {
+		return q.SectionID != nil && *q.SectionID == sectionID
+	}
+
+	err := checkAnswers(questions, answers, onPage)
+	var verr *AnswerValidationError
+	if !errors.As(err, &verr) {
+		return err
+	}
+
+	pageQuestions := make(map[int]bool)
+	for _, q := range questions {
+		if onPage(q) {
+			pageQuestions[q.ID] = true
+		}
+	}
+	pageErr := &AnswerValidationError{}
+	for _, e := range verr.Errors {
+		if pageQuestions[e.QuestionID] {
+			pageErr.Errors = append(pageErr.Errors, e)
+		}
+	}
+	if len(pageErr.Errors) > 0 {
+		return pageErr
+	}
+	return nil
+}
+
+// checkAnswers validates answers against the survey's questions. Questions for which enforceRequired
+// returns true must be answered when they are required; a nil enforceRequired checks no required question.
+func checkAnswers(questions []models.QuestionFromService, answers []models.Answer, enforceRequired func(models.QuestionFromService) bool) error {
+	verr := &AnswerValidationError{}
+
+	questionsByID := make(map[int]models.QuestionFromService, len(questions))
+	for _, q := range questions {
+		questionsByID[q.ID] = q
+	}
+	normalizeOptionAnswers(verr, questionsByID, answers)
+
+	hidden := hiddenQuestions(questions, answers)
+
+	answered := make(map[int]bool, len(answers))
+	values := make(map[int]interface{}, len(answers))
+	for _, ans := range answers {
+		q, ok := questionsByID[ans.QuestionID]
+		if !ok {
+			verr.add(ans.QuestionID, AnswerErrUnknownQuestion, "question %d does not belong to this survey", ans.QuestionID)
+			continue
+		}
+		if _, seen := answered[ans.QuestionID]; seen {
+			verr.add(ans.QuestionID, AnswerErrDuplicateAnswer, "question %d was answered more than once", ans.QuestionID)
+			continue
+		}
+		answered[ans.QuestionID] = !isEmptyAnswer(ans.Value)
+		values[ans.QuestionID] = ans.Value
+
+		if isEmptyAnswer(ans.Value) {
+			continue // Handled by the required check below
+		}
+		if hidden[q.ID] {
+			verr.add(q.ID, AnswerErrHiddenQuestion, "question %d is skipped by the survey logic and must not be answered", q.ID)
+			continue
+		}
+		validateAnswerValue(verr, q, ans.Value)
+		validateOtherText(verr, q, ans)
+	}
+
+	for _, q := range questions {
+		if enforceRequired == nil || !enforceRequired(q) || !q.Required || hidden[q.ID] {
+			continue
+		}
+		if !answered[q.ID] {
+			verr.add(q.ID, AnswerErrRequired, "question %d is required", q.ID)
+			continue
+		}
+		// Some types are only fully answered once each of their parts is, e.g. every row of a matrix
+		if kind, ok := answerKindOf(q.Type); ok && kind.unansweredParts != nil {
+			for _, part := range kind.unansweredParts(q, values[q.ID]) {
+				verr.add(q.ID, AnswerErrRequired, "question %d needs an answer for %s", q.ID, part)
+			}
+		}
+	}
+
+	if len(verr.Errors) > 0 {
+		return verr
+	}
+	return nil
+}
+
+// validateAnswerValue checks the shape and content of a non-empty answer value against its question type
+func validateAnswerValue(verr *AnswerValidationError, q models.QuestionFromService, value interface{}) {
+	kind, ok := answerKindOf(q.Type)
+	if !ok {
+		return // Unknown question types are stored as submitted
+	}
+	if !kind.shape.matches(value) {
+		verr.add(q.ID, AnswerErrInvalidType, "question %d expects %s", q.ID, kind.shape.description)
+		return
+	}
+	if kind.validate != nil {
+		kind.validate(verr, q, value)
+	}
+}
+
+// isEmptyAnswer reports whether a value counts as "not answered"
+func isEmptyAnswer(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []interface{}:
+		return len(v) == 0
+	case primitive.A:
+		return len(v) == 0
+	case []string:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	case primitive.M:
+		return len(v) == 0
+	case primitive.D:
+		return len(v) == 0
+	}
+	return false
+}
+
+// toStringSlice converts the list shapes produced by JSON and BSON decoding into a []string
+func toStringSlice(value interface{}) ([]string, bool) {
+	var items []interface{}
+	switch v := value.(type) {
+	case []string:
+		return v, true
+	case []interface{}:
+		items = v
+	case primitive.A:
+		items = v
+	default:
+		return nil, false
+	}
+
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, false
+		}
+		result = append(result, s)
+	}
+	return result, true
+}
+
+// toStringMap converts the object shapes produced by JSON and BSON decoding into a map[string]string
+func toStringMap(value interface{}) (map[string]string, bool) {
+	var entries map[string]interface{}
+	switch v := value.(type) {
+	case map[string]string:
+		return v, true
+	case map[string]interface{}:
+		entries = v
+	case primitive.M:
+		entries = v
+	case primitive.D:
+		entries = v.Map()
+	default:
+		return nil, false
+	}
+
+	result := make(map[string]string, len(entries))
+	for key, item := range entries {
+		s, ok := item.(string)
+		if !ok {
+			return nil, false
+		}
+		result[key] = s
+	}
+	return result, true
+}
+
+func hasOption(q models.QuestionFromService, text string) bool {
+	for _, opt := range q.Options {
+		if opt.Text == text {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func validationTestQuestions() []models.QuestionFromService {
+	return []models.QuestionFromService{
+		{ID: 1, Text: "Name", Type: "text", Required: true},
+		{ID: 2, Text: "Favourite colour", Type: "multiple_choice", Options: []models.QuestionOptionFromService{
+			{ID: 10, Text: "Red"}, {ID: 11, Text: "Blue"},
+		}},
+		{ID: 3, Text: "Hobbies", Type: "checkbox", Options: []models.QuestionOptionFromService{
+			{ID: 20, Text: "Chess"}, {ID: 21, Text: "Running"},
+		}},
+		{ID: 4, Text: "Satisfaction", Type: "linear_scale"},
+		{ID: 5, Text: "Birthday", Type: "date"},
+	}
+}
+
+func TestValidateAnswers(t *testing.T) {
+	tests := []struct {
+		name       string
+		answers    []models.Answer
+		wantCodes  map[int]string
+		wantErrNil bool
+	}{
+		{
+			name: "Valid answers",
+			answers: []models.Answer{
+				{QuestionID: 1, Value: "Alice"},
+				{QuestionID: 2, Value: "Red"},
+				{QuestionID: 3, Value: []interface{}{"Chess", "Running"}},
+				{QuestionID: 4, Value: float64(4)},
+				{QuestionID: 5, Value: "1990-05-17"},
+			},
+			wantErrNil: true,
+		},
+		{
+			name: "Optional questions left empty",
+			answers: []models.Answer{
+				{QuestionID: 1, Value: "Alice"},
+				{QuestionID: 2, Value: nil},
+				{QuestionID: 3, Value: []interface{}{}},
+			},
+			wantErrNil: true,
+		},
+		{
+			name:      "Missing required question",
+			answers:   []models.Answer{{QuestionID: 1, Value: "  "}},
+			wantCodes: map[int]string{1: AnswerErrRequired},
+		},
+		{
+			name: "Unknown question",
+			answers: []models.Answer{
+				{QuestionID: 1, Value: "Alice"},
+				{QuestionID: 99, Value: "Hello"},
+			},
+			wantCodes: map[int]string{99: AnswerErrUnknownQuestion},
+		},
+		{
+			name: "Option not in question",
+			answers: []models.Answer{
+				{QuestionID: 1, Value: "Alice"},
+				{QuestionID: 2, Value: "Green"},
+				{QuestionID: 3, Value: []interface{}{"Chess", "Swimming"}},
+			},
+			wantCodes: map[int]string{2: AnswerErrInvalidOption, 3: AnswerErrInvalidOption},
+		},
+		{
+			name: "Wrong value shapes",
+			answers: []models.Answer{
+				{QuestionID: 1, Value: float64(42)},
+				{QuestionID: 3, Value: "Chess"},
+				{QuestionID: 4, Value: "5"},
+				{QuestionID: 5, Value: "17/05/1990"},
+			},
+			wantCodes: map[int]string{1: AnswerErrInvalidType, 3: AnswerErrInvalidType, 4: AnswerErrInvalidType, 5: AnswerErrInvalidType},
+		},
+		{
+			name: "Linear scale out of range",
+			answers: []models.Answer{
+				{QuestionID: 1, Value: "Alice"},
+				{QuestionID: 4, Value: float64(6)},
+			},
+			wantCodes: map[int]string{4: AnswerErrOutOfRange},
+		},
+		{
+			name: "Duplicate answer",
+			answers: []models.Answer{
+				{QuestionID: 1, Value: "Alice"},
+				{QuestionID: 1, Value: "Bob"},
+			},
+			wantCodes: map[int]string{1: AnswerErrDuplicateAnswer},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAnswers(validationTestQuestions(), tt.answers)
+			if tt.wantErrNil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.True(t, errors.Is(err, ErrInvalidAnswers))
+			var verr *AnswerValidationError
+			if assert.True(t, errors.As(err, &verr)) {
+				gotCodes := make(map[int]string)
+				for _, e := range verr.Errors {
+					gotCodes[e.QuestionID] = e.Code
+				}
+				assert.Equal(t, tt.wantCodes, gotCodes)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+)
+
+// getSurveyVersionDetails fetches a published version of a survey from the survey-service
+func (s *ResponseService) getSurveyVersionDetails(ctx context.Context, surveyID, version int) (*models.SurveyDetailsFromService, error) {
+	var surveyDetails models.SurveyDetailsFromService
+	if err := s.getFromSurveyService(ctx, fmt.Sprintf("/api/v1/surveys/%d/versions/%d", surveyID, version), &surveyDetails); err != nil {
+		logf("[SERVICE_ERROR] getSurveyVersionDetails: Failed to fetch version %d of SurveyID %d: %v", version, surveyID, err)
+		return nil, err
+	}
+	return &surveyDetails, nil
+}
+
+// getAnsweredVersions loads every older survey version referenced by the responses, keyed by version number.
+// Versions that cannot be loaded are skipped, so their responses are read against the current version.
+func (s *ResponseService) getAnsweredVersions(ctx context.Context, current *models.SurveyDetailsFromService, responses []*models.Response) map[int]*models.SurveyDetailsFromService {
+	versions := make(map[int]*models.SurveyDetailsFromService)
+	for _, resp := range responses {
+		if resp.SurveyVersion == 0 || resp.SurveyVersion == current.Version {
+			continue
+		}
+		if _, loaded := versions[resp.SurveyVersion]; loaded {
+			continue
+		}
+		details, err := s.getSurveyVersionDetails(ctx, current.ID, resp.SurveyVersion)
+		if err != nil {
+			logf("[SERVICE_WARN] getAnsweredVersions: Using current version for responses on version %d of SurveyID %d: %v", resp.SurveyVersion, current.ID, err)
+		}
+		versions[resp.SurveyVersion] = details
+	}
+	return versions
+}
+
+// translateResponsesToCurrentVersion rewrites choice answers to the current option texts, so renamed options keep
+// their counts. Answers stored with option IDs are mapped by those; older answers given on previous versions are
+// matched to the options of their version. Answers whose option was removed are left as submitted.
+func (s *ResponseService) translateResponsesToCurrentVersion(ctx context.Context, current *models.SurveyDetailsFromService, responses []*models.Response) []*models.Response {
+	versions := s.getAnsweredVersions(ctx, current, responses)
+
+	currentOptionTexts := make(map[int]string)
+	for _, q := range current.Questions {
+		for _, opt := range q.Options {
+			currentOptionTexts[opt.ID] = opt.Text
+		}
+	}
+
+	translated := make([]*models.Response, 0, len(responses))
+	for _, resp := range responses {
+		old := versions[resp.SurveyVersion]
+		if old == nil && !hasOptionIDs(resp) {
+			translated = append(translated, resp)
+			continue
+		}
+
+		oldQuestions := make(map[int]models.QuestionFromService)
+		if old != nil {
+			for _, q := range old.Questions {
+				oldQuestions[q.ID] = q
+			}
+		}
+
+		copied := *resp
+		copied.Answers = make([]models.Answer, len(resp.Answers))
+		for i, ans := range resp.Answers {
+			copied.Answers[i] = ans
+			if len(ans.OptionIDs) > 0 {
+				copied.Answers[i].Value = currentOptionValue(ans, currentOptionTexts)
+			} else if q, ok := oldQuestions[ans.QuestionID]; ok {
+				copied.Answers[i].Value = translateAnswerValue(q, ans.Value, currentOptionTexts)
+			}
+		}
+		translated = append(translated, &copied)
+	}
+	return translated
+}
+
+// hasOptionIDs reports whether any answer of a response is stored with option IDs
+func hasOptionIDs(resp *models.Response) bool {
+	for _, ans := range resp.Answers {
+		if len(ans.OptionIDs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// translateAnswerValue maps option texts of an old question to the current texts of the same options
+func translateAnswerValue(oldQuestion models.QuestionFromService, value interface{}, currentOptionTexts map[int]string) interface{} {
+	return mapOptionTexts(value, func(text string) string {
+		for _, opt := range oldQuestion.Options {
+			if opt.Text == text {
+				if currentText, ok := currentOptionTexts[opt.ID]; ok {
+					return currentText
+				}
+				break
+			}
+		}
+		return text
+	})
+}
+
+// sortedVersionNumbers returns the version numbers of a version map in ascending order
+func sortedVersionNumbers(versions map[int]*models.SurveyDetailsFromService) []int {
+	numbers := make([]int, 0, len(versions))
+	for version := range versions {
+		numbers = append(numbers, version)
+	}
+	sort.Ints(numbers)
+	return numbers
+}
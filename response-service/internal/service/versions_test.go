@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/contextkeys"
+	"github.com/VitaliySynytskyi/survey-platform/response-service/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// versionedSurveyHandler serves version 2 of a survey as current and version 1 from the versions endpoint.
+// Between the versions option 11 was renamed from "Blue" to "Navy" and question 2 was removed.
+func versionedSurveyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/surveys/1":
+			w.Write([]byte(`{
+				"id": 1, "title": "Colours", "is_active": true, "version": 2, "access_role": "owner",
+				"questions": [
+					{"id": 1, "text": "Favourite colour?", "type": "multiple_choice",
+					 "options": [{"id": 10, "text": "Red"}, {"id": 11, "text": "Navy"}]}
+				]
+			}`))
+		case "/api/v1/surveys/1/versions/1":
+			w.Write([]byte(`{
+				"id": 1, "title": "Colours", "is_active": true, "version": 1,
+				"questions": [
+					{"id": 1, "text": "Favourite colour?", "type": "multiple_choice",
+					 "options": [{"id": 10, "text": "Red"}, {"id": 11, "text": "Blue"}]},
+					{"id": 2, "text": "Why?", "type": "text"}
+				]
+			}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+}
+
+func versionedTestResponses() []*models.Response {
+	testTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	return []*models.Response{
+		{
+			ID: primitive.NewObjectID(), SurveyID: 1, SurveyVersion: 1, SubmittedAt: testTime,
+			Answers: []models.Answer{{QuestionID: 1, Value: "Blue"}, {QuestionID: 2, Value: "Calm"}},
+		},
+		{
+			ID: primitive.NewObjectID(), SurveyID: 1, SurveyVersion: 2, SubmittedAt: testTime.Add(time.Hour),
+			Answers: []models.Answer{{QuestionID: 1, Value: "Navy"}},
+		},
+		{
+			ID: primitive.NewObjectID(), SurveyID: 1, SubmittedAt: testTime.Add(2 * time.Hour),
+			Answers: []models.Answer{{QuestionID: 1, Value: "Red"}},
+		},
+	}
+}
+
+func TestGetSurveyAnalyticsAcrossVersions(t *testing.T) {
+	mockRepo := new(MockRepository)
+	ctx := context.WithValue(context.Background(), contextkeys.UserIDKey, 1)
+
+	mockServer, mockURL := setupMockSurveyService(t, versionedSurveyHandler())
+	defer mockServer.Close()
+
+	responses := versionedTestResponses()
+	mockRepo.On("GetResponsesBySurveyID", ctx, 1).Return(responses, nil)
+
+	service := NewResponseService(mockRepo, mockURL)
+	result, err := service.GetSurveyAnalytics(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, result.QuestionAnalytics, 1)
+
+	// The "Blue" answer given on version 1 is counted as the renamed "Navy" option
+	counts := make(map[string]int)
+	for _, opt := range result.QuestionAnalytics[0].OptionsSummary {
+		counts[opt.OptionText] = opt.Count
+	}
+	assert.Equal(t, map[string]int{"Red": 1, "Navy": 2}, counts)
+
+	// Stored responses are left untouched
+	assert.Equal(t, "Blue", responses[0].Answers[0].Value)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExportSurveyResponsesCSVAcrossVersions(t *testing.T) {
+	mockRepo := new(MockRepository)
+	ctx := context.WithValue(context.Background(), contextkeys.UserIDKey, 1)
+
+	mockServer, mockURL := setupMockSurveyService(t, versionedSurveyHandler())
+	defer mockServer.Close()
+
+	mockRepo.On("GetResponsesBySurveyID", ctx, 1).Return(versionedTestResponses(), nil)
+
+	service := NewResponseService(mockRepo, mockURL)
+	csvData, _, err := service.ExportSurveyResponsesCSV(ctx, 1)
+	require.NoError(t, err)
+
+	rows, err := csv.NewReader(strings.NewReader(csvData)).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+
+	assert.Equal(t, []string{"ResponseID", "SubmittedAt", "UserID", "SurveyVersion", "Favourite colour?", "Why? (v1)"}, rows[0])
+	assert.Equal(t, []string{"1", "Blue", "Calm"}, rows[1][3:])
+	assert.Equal(t, []string{"2", "Navy", ""}, rows[2][3:])
+	assert.Equal(t, []string{"", "Red", ""}, rows[3][3:])
+}
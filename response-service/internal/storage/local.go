@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// metaSuffix names the file next to each blob that holds its BlobInfo
+const metaSuffix = ".meta.json"
+
+// LocalStore keeps blobs as files below a root directory
+type LocalStore struct {
+	root string
+}
+
+// NewLocalStore creates a LocalStore rooted at dir, creating the directory if needed
+func NewLocalStore(dir string) (*LocalStore, error) {
+	if dir == "" {
+		return nil, errors.New("local storage needs a directory")
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory %s: %w", dir, err)
+	}
+	return &LocalStore{root: dir}, nil
+}
+
+func (s *LocalStore) pathOf(key string) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// Put writes content to a temporary file and moves it into place once complete, so readers never see partial blobs
+func (s *LocalStore) Put(ctx context.Context, key string, content io.Reader, info BlobInfo) (*BlobInfo, error) {
+	blobPath, err := s.pathOf(key)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(blobPath)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create directory for blob %s: %w", key, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create blob %s: %w", key, err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	size, err := io.Copy(tmp, content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	info.Size = size
+	info.UploadedAt = time.Now().UTC()
+	meta, err := json.Marshal(info)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode info of blob %s: %w", key, err)
+	}
+	if err := os.WriteFile(blobPath+metaSuffix, meta, 0o640); err != nil {
+		return nil, fmt.Errorf("failed to write info of blob %s: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), blobPath); err != nil {
+		os.Remove(blobPath + metaSuffix)
+		return nil, fmt.Errorf("failed to store blob %s: %w", key, err)
+	}
+	return &info, nil
+}
+
+// Stat reads the info stored next to the blob
+func (s *LocalStore) Stat(ctx context.Context, key string) (*BlobInfo, error) {
+	blobPath, err := s.pathOf(key)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := os.Stat(blobPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrBlobNotFound
+		}
+		return nil, fmt.Errorf("failed to stat blob %s: %w", key, err)
+	}
+	meta, err := os.ReadFile(blobPath + metaSuffix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read info of blob %s: %w", key, err)
+	}
+	var info BlobInfo
+	if err := json.Unmarshal(meta, &info); err != nil {
+		return nil, fmt.Errorf("failed to decode info of blob %s: %w", key, err)
+	}
+	if info.UploadedAt.IsZero() {
+		info.UploadedAt = stat.ModTime().UTC() // Stored before the upload time was recorded
+	}
+	return &info, nil
+}
+
+// Open opens the blob's file for reading
+func (s *LocalStore) Open(ctx context.Context, key string) (io.ReadCloser, *BlobInfo, error) {
+	info, err := s.Stat(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	blobPath, _ := s.pathOf(key)
+	f, err := os.Open(blobPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, ErrBlobNotFound // Deleted since Stat
+		}
+		return nil, nil, fmt.Errorf("failed to open blob %s: %w", key, err)
+	}
+	return f, info, nil
+}
+
+// Delete removes the blob and its info
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	blobPath, err := s.pathOf(key)
+	if err != nil {
+		return err
+	}
+	for _, p := range []string{blobPath, blobPath + metaSuffix} {
+		if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to delete blob %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// List walks the directory of prefix, skipping the info files and the temporary files of unfinished uploads
+func (s *LocalStore) List(ctx context.Context, prefix string) ([]string, error) {
+	dir, err := s.pathOf(prefix)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	err = filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		name := entry.Name()
+		if entry.IsDir() || strings.HasSuffix(name, metaSuffix) || strings.HasPrefix(name, ".upload-") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, p)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list blobs under %s: %w", prefix, err)
+	}
+	return keys, nil
+}
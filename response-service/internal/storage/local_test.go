@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, errors.New("connection reset") }
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	const key = "surveys/1/questions/2/abc"
+
+	t.Run("Put, read back and delete", func(t *testing.T) {
+		info, err := store.Put(ctx, key, strings.NewReader("hello"), BlobInfo{Name: "hello.txt", ContentType: "text/plain"})
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), info.UploadedAt, time.Minute)
+		assert.Equal(t, BlobInfo{Name: "hello.txt", ContentType: "text/plain", Size: 5, UploadedAt: info.UploadedAt}, *info)
+
+		stat, err := store.Stat(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, *info, *stat)
+
+		content, opened, err := store.Open(ctx, key)
+		require.NoError(t, err)
+		data, err := io.ReadAll(content)
+		content.Close()
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(data))
+		assert.Equal(t, *info, *opened)
+
+		require.NoError(t, store.Delete(ctx, key))
+		_, err = store.Stat(ctx, key)
+		assert.True(t, errors.Is(err, ErrBlobNotFound))
+		assert.NoError(t, store.Delete(ctx, key), "deleting a missing blob is not an error")
+	})
+
+	t.Run("Failed read stores nothing", func(t *testing.T) {
+		_, err := store.Put(ctx, key, failingReader{}, BlobInfo{Name: "broken"})
+		assert.Error(t, err)
+		_, err = store.Stat(ctx, key)
+		assert.True(t, errors.Is(err, ErrBlobNotFound))
+
+		leftovers, err := os.ReadDir(filepath.Join(store.root, "surveys", "1", "questions", "2"))
+		require.NoError(t, err)
+		assert.Empty(t, leftovers)
+	})
+
+	t.Run("List skips info and unfinished files", func(t *testing.T) {
+		for _, k := range []string{"surveys/1/questions/2/a", "surveys/1/questions/3/b", "surveys/2/questions/4/c"} {
+			_, err := store.Put(ctx, k, strings.NewReader("x"), BlobInfo{})
+			require.NoError(t, err)
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(store.root, "surveys", "1", "questions", "2", ".upload-123"), nil, 0o640))
+
+		keys, err := store.List(ctx, "surveys/1")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"surveys/1/questions/2/a", "surveys/1/questions/3/b"}, keys)
+
+		keys, err = store.List(ctx, "surveys/9")
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+	})
+
+	t.Run("Keys cannot escape the root", func(t *testing.T) {
+		for _, bad := range []string{"", "../outside", "/etc/passwd", "surveys/../../outside", "surveys//1"} {
+			_, err := store.Put(ctx, bad, strings.NewReader("x"), BlobInfo{})
+			assert.True(t, errors.Is(err, ErrInvalidKey), "key %q", bad)
+		}
+	})
+}
+
+func TestNew(t *testing.T) {
+	store, err := New(BackendLocal, t.TempDir())
+	require.NoError(t, err)
+	assert.IsType(t, &LocalStore{}, store)
+
+	_, err = New("s3", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// ErrBlobNotFound is returned when no blob is stored under a key
+var ErrBlobNotFound = errors.New("blob not found")
+
+// ErrInvalidKey is returned for keys that are not clean, relative, slash-separated paths
+var ErrInvalidKey = errors.New("invalid blob key")
+
+// BlobInfo describes a stored blob
+type BlobInfo struct {
+	Name        string    `json:"name"`         // Original file name, used when the blob is downloaded
+	ContentType string    `json:"content_type"` // MIME type the blob is served with
+	Size        int64     `json:"size"`         // In bytes
+	UploadedAt  time.Time `json:"uploaded_at"`  // Set by Put
+}
+
+// BlobStore keeps uploaded files under slash-separated keys such as "surveys/1/questions/2/<id>".
+// The local filesystem backend is built in; an S3-compatible backend would map keys to object keys
+// and BlobInfo to the object's content type and metadata.
+type BlobStore interface {
+	// Put stores content under key, replacing any blob there, and returns its info with Size set.
+	// When reading content fails, nothing is stored and the read error is returned.
+	Put(ctx context.Context, key string, content io.Reader, info BlobInfo) (*BlobInfo, error)
+	// Stat returns the info of the blob stored under key
+	Stat(ctx context.Context, key string) (*BlobInfo, error)
+	// Open returns the content and info of the blob stored under key; the caller closes the content
+	Open(ctx context.Context, key string) (io.ReadCloser, *BlobInfo, error)
+	// Delete removes the blob stored under key. Deleting a missing blob is not an error.
+	Delete(ctx context.Context, key string) error
+	// List returns the keys of the blobs stored below prefix, e.g. "surveys/1"
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// Storage backends selectable in the configuration
+const (
+	BackendLocal = "local"
+)
+
+// New creates the blob store of the configured backend. dir is the root directory of the local backend.
+func New(backend, dir string) (BlobStore, error) {
+	switch backend {
+	case BackendLocal, "":
+		return NewLocalStore(dir)
+	default:
+		return nil, fmt.Errorf("unsupported storage backend %q", backend)
+	}
+}
+
+// checkKey rejects keys that could escape the store, e.g. "../x" or "/etc/passwd"
+func checkKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key || key == ".." || strings.HasPrefix(key, "../") {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return nil
+}
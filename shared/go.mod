@@ -0,0 +1,3 @@
+module github.com/VitaliySynytskyi/survey-platform/shared
+
+go 1.20
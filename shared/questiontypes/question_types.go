@@ -0,0 +1,445 @@
+// Package questiontypes is the registry of the question types of the survey platform and their configs.
+// survey-service validates the configs of the questions it stores with it, and response-service decodes them
+// to validate, aggregate and export answers, so a new type is registered here once.
+package questiontypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Question types known to the platform
+const (
+	QuestionTypeText           = "text"
+	QuestionTypeShortAnswer    = "short_answer"
+	QuestionTypeParagraph      = "paragraph"
+	QuestionTypeSingleChoice   = "single_choice"
+	QuestionTypeMultipleChoice = "multiple_choice"
+	QuestionTypeDropdown       = "dropdown"
+	QuestionTypeCheckbox       = "checkbox"
+	QuestionTypeLinearScale    = "linear_scale"
+	QuestionTypeDate           = "date"
+	QuestionTypeMatrix         = "matrix"
+	QuestionTypeRanking        = "ranking"
+	QuestionTypeFileUpload     = "file_upload"
+)
+
+// QuestionConfig is the type-specific configuration of a question, stored as JSON in Question.Config
+type QuestionConfig interface {
+	Validate() error
+}
+
+// OptionsConfig is implemented by configs that depend on the question's options
+type OptionsConfig interface {
+	ValidateOptions(optionCount int) error
+}
+
+// AnswerKind is the kind of value answers to a question type hold. response-service validates, aggregates and
+// exports answers by their kind, so a new type taking an existing kind of answer needs no change there.
+type AnswerKind string
+
+// Kinds of answers
+const (
+	AnswerText       AnswerKind = "text"        // A written answer, constrained by a TextConfig
+	AnswerOption     AnswerKind = "option"      // The text of one of the question's options
+	AnswerOptionList AnswerKind = "option_list" // The texts of any of the question's options, e.g. ticked checkboxes
+	AnswerScale      AnswerKind = "scale"       // A whole number on the question's LinearScaleConfig
+	AnswerDate       AnswerKind = "date"        // A date in DateAnswerLayout
+	AnswerRowMap     AnswerKind = "row_map"     // An object mapping the rows of a MatrixConfig to the chosen columns
+	AnswerRanking    AnswerKind = "ranking"     // The texts of the question's options in order of preference
+	AnswerFile       AnswerKind = "file"        // The ID of a file uploaded to response-service
+)
+
+// QuestionTypeDefinition describes how questions of one type are set up
+type QuestionTypeDefinition struct {
+	Name       string
+	HasOptions bool                  // Choice types need at least one option; other types take none
+	Answer     AnswerKind            // The kind of value answers hold
+	NewConfig  func() QuestionConfig // Returns the zero config to decode into, nil when the type takes no configuration
+}
+
+var questionTypes = make(map[string]QuestionTypeDefinition)
+
+// RegisterQuestionType adds a question type to the registry. It panics if the name is already taken.
+func RegisterQuestionType(def QuestionTypeDefinition) {
+	if _, exists := questionTypes[def.Name]; exists {
+		panic(fmt.Sprintf("question type %q registered twice", def.Name))
+	}
+	questionTypes[def.Name] = def
+}
+
+// LookupQuestionType returns the registered definition of a question type
+func LookupQuestionType(name string) (QuestionTypeDefinition, bool) {
+	def, ok := questionTypes[name]
+	return def, ok
+}
+
+// QuestionTypeNames lists the registered question types in alphabetical order
+func QuestionTypeNames() []string {
+	names := make([]string, 0, len(questionTypes))
+	for name := range questionTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DecodeConfig strictly decodes and validates a question's configuration for this type.
+// Types without configuration only accept an empty config; nil is returned for them.
+func (d QuestionTypeDefinition) DecodeConfig(raw json.RawMessage) (QuestionConfig, error) {
+	empty := isEmptyConfig(raw)
+	if d.NewConfig == nil {
+		if !empty {
+			return nil, fmt.Errorf("question type %q takes no config", d.Name)
+		}
+		return nil, nil
+	}
+
+	config := d.NewConfig()
+	if !empty {
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(config); err != nil {
+			return nil, fmt.Errorf("invalid config for question type %q: %w", d.Name, err)
+		}
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config for question type %q: %w", d.Name, err)
+	}
+	return config, nil
+}
+
+// NormalizeConfig validates a question's configuration like DecodeConfig and encodes it with the defaults
+// filled in, so stored questions always carry their complete config. It returns nil for types without one.
+func (d QuestionTypeDefinition) NormalizeConfig(raw json.RawMessage) (json.RawMessage, error) {
+	config, err := d.DecodeConfig(raw)
+	if err != nil || config == nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config for question type %q: %w", d.Name, err)
+	}
+	return encoded, nil
+}
+
+func isEmptyConfig(raw json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(raw)
+	return len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) || bytes.Equal(trimmed, []byte("{}"))
+}
+
+// Limits of linear_scale questions; questions stored without config use the defaults
+const (
+	DefaultScaleMin  = 1
+	DefaultScaleMax  = 5
+	MaxScalePoints   = 101 // Enough for a 0-100 slider
+	MaxScaleLabelLen = 100
+)
+
+// LinearScaleConfig is the config of linear_scale questions: respondents pick Min, Min+Step, ... up to Max
+type LinearScaleConfig struct {
+	Min      int    `json:"min"`
+	Max      int    `json:"max"`
+	Step     int    `json:"step"`
+	MinLabel string `json:"min_label,omitempty"` // Shown at the low end, e.g. "Not at all likely"
+	MaxLabel string `json:"max_label,omitempty"` // Shown at the high end, e.g. "Extremely likely"
+}
+
+// DefaultLinearScaleConfig returns the scale of linear_scale questions stored without config
+func DefaultLinearScaleConfig() *LinearScaleConfig {
+	return &LinearScaleConfig{Min: DefaultScaleMin, Max: DefaultScaleMax, Step: 1}
+}
+
+// Validate checks that the scale has at least two points and that Step leads from Min to Max
+func (c *LinearScaleConfig) Validate() error {
+	switch {
+	case c.Step < 1:
+		return fmt.Errorf("step must be at least 1, got %d", c.Step)
+	case c.Max <= c.Min:
+		return fmt.Errorf("max (%d) must be greater than min (%d)", c.Max, c.Min)
+	case (c.Max-c.Min)%c.Step != 0:
+		return fmt.Errorf("step %d does not reach max %d from min %d", c.Step, c.Max, c.Min)
+	case (c.Max-c.Min)/c.Step+1 > MaxScalePoints:
+		return fmt.Errorf("scale has more than %d points", MaxScalePoints)
+	case len(c.MinLabel) > MaxScaleLabelLen || len(c.MaxLabel) > MaxScaleLabelLen:
+		return fmt.Errorf("labels must be at most %d characters", MaxScaleLabelLen)
+	}
+	return nil
+}
+
+// Points lists the values respondents can pick on the scale
+func (c *LinearScaleConfig) Points() []int {
+	points := make([]int, 0, (c.Max-c.Min)/c.Step+1)
+	for v := c.Min; v <= c.Max; v += c.Step {
+		points = append(points, v)
+	}
+	return points
+}
+
+// Includes reports whether value is one of the points of the scale
+func (c *LinearScaleConfig) Includes(value int) bool {
+	return value >= c.Min && value <= c.Max && (value-c.Min)%c.Step == 0
+}
+
+// Limits of matrix questions
+const (
+	MaxMatrixRows    = 50
+	MaxMatrixColumns = 20
+)
+
+// MatrixConfig is the config of matrix (grid) questions: each row is rated on the same columns,
+// e.g. statements on an agreement scale. Answers map each row's text to the text of the chosen column.
+type MatrixConfig struct {
+	Rows    []string `json:"rows"`
+	Columns []string `json:"columns"`
+}
+
+// Validate checks that the matrix has rows and at least two columns, each with a distinct, non-blank text
+func (c *MatrixConfig) Validate() error {
+	switch {
+	case len(c.Rows) == 0 || len(c.Rows) > MaxMatrixRows:
+		return fmt.Errorf("a matrix needs between 1 and %d rows, got %d", MaxMatrixRows, len(c.Rows))
+	case len(c.Columns) < 2 || len(c.Columns) > MaxMatrixColumns:
+		return fmt.Errorf("a matrix needs between 2 and %d columns, got %d", MaxMatrixColumns, len(c.Columns))
+	}
+	if err := checkDistinctTexts("row", c.Rows); err != nil {
+		return err
+	}
+	return checkDistinctTexts("column", c.Columns)
+}
+
+func checkDistinctTexts(kind string, texts []string) error {
+	seen := make(map[string]bool, len(texts))
+	for i, text := range texts {
+		if strings.TrimSpace(text) == "" {
+			return fmt.Errorf("%s %d has no text", kind, i+1)
+		}
+		if seen[text] {
+			return fmt.Errorf("%s %q appears more than once", kind, text)
+		}
+		seen[text] = true
+	}
+	return nil
+}
+
+// Answer formats of text questions
+const (
+	TextFormatNumber = "number"
+	TextFormatEmail  = "email"
+	TextFormatURL    = "url"
+)
+
+// Limits of text question configs
+const (
+	MaxNumberDecimals    = 10
+	MaxTextPatternLen    = 500
+	MaxPatternMessageLen = 200
+	DateAnswerLayout     = "2006-01-02" // Dates are answered and configured as YYYY-MM-DD
+)
+
+// TextConfig is the optional config of text, short_answer and paragraph questions, constraining the answers accepted.
+// Min, Max and Decimals only apply to the number format.
+type TextConfig struct {
+	Format         string   `json:"format,omitempty"` // "", TextFormatNumber, TextFormatEmail or TextFormatURL
+	Min            *float64 `json:"min,omitempty"`
+	Max            *float64 `json:"max,omitempty"`
+	Decimals       *int     `json:"decimals,omitempty"`        // Most decimal places allowed, 0 for whole numbers
+	Pattern        string   `json:"pattern,omitempty"`         // Regular expression (RE2 syntax) the whole answer must match
+	PatternMessage string   `json:"pattern_message,omitempty"` // Shown to respondents whose answer does not match Pattern
+	MaxLength      int      `json:"max_length,omitempty"`      // In characters, 0 for no limit
+}
+
+// Validate checks that the format is known, that its settings fit it, and that the pattern compiles
+func (c *TextConfig) Validate() error {
+	switch c.Format {
+	case "", TextFormatNumber, TextFormatEmail, TextFormatURL:
+	default:
+		return fmt.Errorf("unknown format %q", c.Format)
+	}
+	if c.Format != TextFormatNumber && (c.Min != nil || c.Max != nil || c.Decimals != nil) {
+		return fmt.Errorf("min, max and decimals only apply to the %q format", TextFormatNumber)
+	}
+	switch {
+	case c.Min != nil && c.Max != nil && *c.Max < *c.Min:
+		return fmt.Errorf("max (%g) must not be below min (%g)", *c.Max, *c.Min)
+	case c.Decimals != nil && (*c.Decimals < 0 || *c.Decimals > MaxNumberDecimals):
+		return fmt.Errorf("decimals must be between 0 and %d, got %d", MaxNumberDecimals, *c.Decimals)
+	case c.MaxLength < 0:
+		return fmt.Errorf("max_length must not be negative, got %d", c.MaxLength)
+	case len(c.Pattern) > MaxTextPatternLen:
+		return fmt.Errorf("pattern must be at most %d characters", MaxTextPatternLen)
+	case len(c.PatternMessage) > MaxPatternMessageLen:
+		return fmt.Errorf("pattern_message must be at most %d characters", MaxPatternMessageLen)
+	case c.PatternMessage != "" && c.Pattern == "":
+		return fmt.Errorf("pattern_message needs a pattern")
+	}
+	if c.Pattern != "" {
+		if _, err := regexp.Compile(c.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+	return nil
+}
+
+// DateConfig is the optional config of date questions, limiting answers to the range Min to Max (both inclusive)
+type DateConfig struct {
+	Min string `json:"min,omitempty"` // YYYY-MM-DD
+	Max string `json:"max,omitempty"` // YYYY-MM-DD
+}
+
+// Validate checks that the bounds are dates and in order
+func (c *DateConfig) Validate() error {
+	var min, max time.Time
+	var err error
+	if c.Min != "" {
+		if min, err = time.Parse(DateAnswerLayout, c.Min); err != nil {
+			return fmt.Errorf("min must be a date in YYYY-MM-DD format, got %q", c.Min)
+		}
+	}
+	if c.Max != "" {
+		if max, err = time.Parse(DateAnswerLayout, c.Max); err != nil {
+			return fmt.Errorf("max must be a date in YYYY-MM-DD format, got %q", c.Max)
+		}
+	}
+	if c.Min != "" && c.Max != "" && max.Before(min) {
+		return fmt.Errorf("max (%s) must not be before min (%s)", c.Max, c.Min)
+	}
+	return nil
+}
+
+// Limits of file_upload questions. Response-service may enforce a lower size limit of its own.
+const (
+	DefaultMaxFileSize  = 10 << 20 // 10 MiB
+	MaxFileSize         = 50 << 20 // 50 MiB
+	MaxAllowedFileTypes = 20
+)
+
+// FileUploadConfig is the config of file_upload questions, whose answers reference a file uploaded to response-service.
+// AllowedTypes are matched against the type detected from the content of an upload, whatever its name; formats
+// built on ZIP, such as Office documents, are detected as "application/zip".
+type FileUploadConfig struct {
+	MaxSize      int64    `json:"max_size"`                // In bytes
+	AllowedTypes []string `json:"allowed_types,omitempty"` // MIME types such as "application/pdf" or "image/*"; empty allows any
+}
+
+// Validate checks the size limit and that each allowed type is a MIME type or a wildcard over one
+func (c *FileUploadConfig) Validate() error {
+	if c.MaxSize < 1 || c.MaxSize > MaxFileSize {
+		return fmt.Errorf("max_size must be between 1 and %d bytes, got %d", MaxFileSize, c.MaxSize)
+	}
+	if len(c.AllowedTypes) > MaxAllowedFileTypes {
+		return fmt.Errorf("at most %d allowed types, got %d", MaxAllowedFileTypes, len(c.AllowedTypes))
+	}
+	for _, allowed := range c.AllowedTypes {
+		mediaType, params, err := mime.ParseMediaType(allowed)
+		if err != nil || len(params) > 0 || mediaType != allowed || !strings.Contains(allowed, "/") || strings.HasPrefix(allowed, "*") {
+			return fmt.Errorf("allowed type %q is not a MIME type", allowed)
+		}
+	}
+	return nil
+}
+
+// Allows reports whether a file of contentType may be uploaded; "image/*" allows every image type
+func (c *FileUploadConfig) Allows(contentType string) bool {
+	if len(c.AllowedTypes) == 0 {
+		return true
+	}
+	for _, allowed := range c.AllowedTypes {
+		if allowed == contentType || (strings.HasSuffix(allowed, "/*") && strings.HasPrefix(contentType, strings.TrimSuffix(allowed, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// RankingConfig is the config of ranking questions, whose answers order the question's options
+type RankingConfig struct {
+	TopN int `json:"top_n,omitempty"` // Respondents rank only their first TopN options; 0 ranks them all
+}
+
+// Validate checks that TopN is not negative
+func (c *RankingConfig) Validate() error {
+	if c.TopN < 0 {
+		return fmt.Errorf("top_n must not be negative, got %d", c.TopN)
+	}
+	return nil
+}
+
+// ValidateOptions checks that there are enough options to rank the first TopN
+func (c *RankingConfig) ValidateOptions(optionCount int) error {
+	if optionCount < 2 {
+		return fmt.Errorf("a ranking needs at least 2 options, got %d", optionCount)
+	}
+	if c.TopN > optionCount {
+		return fmt.Errorf("top_n (%d) exceeds the number of options (%d)", c.TopN, optionCount)
+	}
+	return nil
+}
+
+// The "Other" option of choice questions. Respondents select OtherOptionValue, and response-service stores the text
+// they write next to it in the answer.
+const (
+	OtherOptionValue  = "__other__"
+	MaxOtherLabelLen  = 100
+	DefaultOtherLabel = "Other"
+)
+
+// ChoiceConfig is the config of single_choice, multiple_choice, dropdown and checkbox questions
+type ChoiceConfig struct {
+	AllowOther bool   `json:"allow_other,omitempty"` // Offer an "Other" option with a free-text answer after the options
+	OtherLabel string `json:"other_label,omitempty"` // Label of the "Other" option; DefaultOtherLabel when empty
+}
+
+// Validate checks that the label is short and only set when "Other" is allowed
+func (c *ChoiceConfig) Validate() error {
+	if c.OtherLabel != "" && !c.AllowOther {
+		return fmt.Errorf("other_label is set but allow_other is not")
+	}
+	if len([]rune(c.OtherLabel)) > MaxOtherLabelLen {
+		return fmt.Errorf("other_label must be at most %d characters", MaxOtherLabelLen)
+	}
+	return nil
+}
+
+func init() {
+	for _, name := range []string{QuestionTypeText, QuestionTypeShortAnswer, QuestionTypeParagraph} {
+		RegisterQuestionType(QuestionTypeDefinition{Name: name, Answer: AnswerText, NewConfig: func() QuestionConfig { return &TextConfig{} }})
+	}
+	RegisterQuestionType(QuestionTypeDefinition{Name: QuestionTypeDate, Answer: AnswerDate, NewConfig: func() QuestionConfig { return &DateConfig{} }})
+	RegisterQuestionType(QuestionTypeDefinition{
+		Name:      QuestionTypeLinearScale,
+		Answer:    AnswerScale,
+		NewConfig: func() QuestionConfig { return DefaultLinearScaleConfig() },
+	})
+	RegisterQuestionType(QuestionTypeDefinition{
+		Name:      QuestionTypeMatrix,
+		Answer:    AnswerRowMap,
+		NewConfig: func() QuestionConfig { return &MatrixConfig{} },
+	})
+	RegisterQuestionType(QuestionTypeDefinition{
+		Name:      QuestionTypeFileUpload,
+		Answer:    AnswerFile,
+		NewConfig: func() QuestionConfig { return &FileUploadConfig{MaxSize: DefaultMaxFileSize} },
+	})
+	RegisterQuestionType(QuestionTypeDefinition{
+		Name:       QuestionTypeRanking,
+		HasOptions: true,
+		Answer:     AnswerRanking,
+		NewConfig:  func() QuestionConfig { return &RankingConfig{} },
+	})
+	for _, name := range []string{QuestionTypeSingleChoice, QuestionTypeMultipleChoice, QuestionTypeDropdown} {
+		RegisterQuestionType(QuestionTypeDefinition{Name: name, HasOptions: true, Answer: AnswerOption, NewConfig: func() QuestionConfig { return &ChoiceConfig{} }})
+	}
+	RegisterQuestionType(QuestionTypeDefinition{
+		Name:       QuestionTypeCheckbox,
+		HasOptions: true,
+		Answer:     AnswerOptionList,
+		NewConfig:  func() QuestionConfig { return &ChoiceConfig{} },
+	})
+}
@@ -34,7 +34,10 @@ func main() {
 			Password: getEnv("DB_PASSWORD", "postgres"),
 			Name:     getEnv("DB_NAME", "survey_db"),
 		},
-		Port: getEnv("PORT", "8082"),
+		Port:               getEnv("PORT", "8082"),
+		ResponseServiceURL: getEnv("RESPONSE_SERVICE_URL", "http://localhost:8083"),
+		ServiceToken:       getEnv("SERVICE_TOKEN", ""),
+		SchedulerInterval:  getEnvDuration("SCHEDULER_INTERVAL", time.Minute),
 	}
 
 	// Database connection
@@ -68,6 +71,11 @@ func main() {
 	// Initialize service
 	surveyService := service.NewSurveyService(repo)
 
+	// Start the scheduler that opens and closes surveys by date and response quota.
+	// Every replica runs it; an advisory lock makes sure only one applies transitions at a time.
+	scheduler := service.NewScheduler(repo, service.NewHTTPResponseCounter(cfg.ResponseServiceURL, cfg.ServiceToken), cfg.SchedulerInterval)
+	go scheduler.Run(context.Background())
+
 	// Initialize router
 	router := gin.Default()
 
@@ -93,9 +101,34 @@ func main() {
 			surveys.PUT("/:id", surveyHandler.UpdateSurvey)
 			surveys.DELETE("/:id", surveyHandler.DeleteSurvey)
 			surveys.PATCH("/:id/status", surveyHandler.UpdateSurveyStatus)
+			surveys.GET("/:id/versions", surveyHandler.ListSurveyVersions)
+			surveys.GET("/:id/versions/:version", surveyHandler.GetSurveyVersion)
+			surveys.GET("/:id/status-history", surveyHandler.ListSurveyStatusHistory)
+			surveys.GET("/:id/definition", surveyHandler.ExportSurveyDefinition)
+			surveys.POST("/import", surveyHandler.ImportSurveyDefinition)
+			surveys.POST("/import/:format", surveyHandler.ImportExternalSurvey)
+			surveys.POST("/:id/clone", surveyHandler.CloneSurvey)
+			surveys.POST("/:id/transfer", surveyHandler.TransferSurveyOwnership)
+			surveys.GET("/:id/transfers", surveyHandler.ListSurveyOwnershipTransfers)
+			surveys.POST("/:id/collaborators", surveyHandler.InviteCollaborator)
+			surveys.GET("/:id/collaborators", surveyHandler.ListCollaborators)
+			surveys.DELETE("/:id/collaborators/:userId", surveyHandler.RemoveCollaborator)
 
 			// Question routes
 			surveys.POST("/:id/questions", surveyHandler.AddQuestion)
+
+			// Template routes
+			templateHandler := handlers.NewTemplateHandler(surveyService)
+			surveys.PUT("/:id/template", templateHandler.SetTemplate)
+			surveys.DELETE("/:id/template", templateHandler.UnsetTemplate)
+		}
+
+		// Template library routes
+		templates := api.Group("/templates")
+		{
+			templateHandler := handlers.NewTemplateHandler(surveyService)
+			templates.GET("", templateHandler.ListTemplates)
+			templates.POST("/:id/instantiate", templateHandler.InstantiateTemplate)
 		}
 
 		// Question routes
@@ -126,3 +159,17 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvDuration parses a duration such as "30s" from an environment variable, falling back to a default value
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Printf("Invalid %s %q, using %s", key, value, defaultValue)
+		return defaultValue
+	}
+	return duration
+}
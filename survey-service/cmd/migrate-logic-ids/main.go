@@ -0,0 +1,55 @@
+// Command migrate-logic-ids stores question and option IDs in the display rules, skip rules and answer pipes saved
+// before they carried them, which name questions by position and options by text. It is safe to run more than once.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/repository"
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/service"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report what would change without writing to the database")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+	connString := fmt.Sprintf("postgres://%s:%s@%s:%s/%s",
+		getEnv("DB_USER", "postgres"), getEnv("DB_PASSWORD", "postgres"),
+		getEnv("DB_HOST", "localhost"), getEnv("DB_PORT", "5432"), getEnv("DB_NAME", "survey_db"))
+
+	dbPool, err := pgxpool.New(context.Background(), connString)
+	if err != nil {
+		log.Fatalf("Unable to connect to database: %v", err)
+	}
+	defer dbPool.Close()
+
+	surveyService := service.NewSurveyService(repository.NewPostgresRepository(dbPool))
+	report, err := surveyService.MigrateQuestionLogic(context.Background(), *dryRun)
+	if err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+
+	action := "Updated"
+	if *dryRun {
+		action = "Would update"
+	}
+	log.Printf("%s %d questions in %d of %d surveys with logic; skipped %d surveys whose logic names a missing question or option",
+		action, report.UpdatedQuestions, report.UpdatedSurveys, report.Surveys, report.SkippedSurveys)
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
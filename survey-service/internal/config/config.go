@@ -1,9 +1,14 @@
 package config
 
+import "time"
+
 // Config represents the application configuration
 type Config struct {
-	DB   DBConfig
-	Port string
+	DB                 DBConfig
+	Port               string
+	ResponseServiceURL string        // Used by the scheduler to check response quotas
+	ServiceToken       string        // Shared with response-service, which requires it on internal routes
+	SchedulerInterval  time.Duration // How often the scheduler opens and closes surveys
 }
 
 // DBConfig represents the database configuration
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
@@ -38,12 +39,17 @@ func (h *QuestionHandler) UpdateQuestion(c *gin.Context) {
 
 	// Create question model from request
 	question := &models.Question{
-		ID:       id,
-		SurveyID: req.SurveyID,
-		Text:     req.Text,
-		Type:     req.Type,
-		Required: req.Required,
-		OrderNum: req.OrderNum,
+		ID:               id,
+		SurveyID:         req.SurveyID,
+		Text:             req.Text,
+		Type:             req.Type,
+		Required:         req.Required,
+		OrderNum:         req.OrderNum,
+		SectionID:        req.SectionID,
+		Config:           req.Config,
+		DisplayRules:     req.DisplayRules,
+		SkipRules:        req.SkipRules,
+		RandomizeOptions: req.RandomizeOptions,
 	}
 
 	// Convert option requests to option models
@@ -58,6 +64,10 @@ func (h *QuestionHandler) UpdateQuestion(c *gin.Context) {
 
 	if err := h.surveyService.UpdateQuestion(c.Request.Context(), question, options); err != nil {
 		log.Printf("Error updating question: %v", err)
+		if errors.Is(err, service.ErrInvalidQuestion) || errors.Is(err, service.ErrInvalidSection) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update question"})
 		return
 	}
@@ -75,6 +85,10 @@ func (h *QuestionHandler) DeleteQuestion(c *gin.Context) {
 
 	if err := h.surveyService.DeleteQuestion(c.Request.Context(), id); err != nil {
 		log.Printf("Error deleting question: %v", err)
+		if errors.Is(err, service.ErrInvalidQuestion) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete question"})
 		return
 	}
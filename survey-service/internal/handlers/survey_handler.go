@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"strconv"
@@ -11,6 +15,7 @@ import (
 	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
 	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/service"
 	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
 )
 
 // SurveyHandler handles HTTP requests related to surveys
@@ -72,6 +77,16 @@ func getUserContext(c *gin.Context) (context.Context, error) {
 	// Use exported keys from service package
 	ctx := context.WithValue(c.Request.Context(), service.UserIDKey, userID)
 	ctx = context.WithValue(ctx, service.UserRolesKey, cleanRoles)
+
+	// X-Org-ID is only sent for users working in an organization; the others see surveys outside every organization
+	if orgIDStr := c.GetHeader("X-Org-ID"); orgIDStr != "" {
+		orgID, err := strconv.Atoi(orgIDStr)
+		if err != nil {
+			log.Printf("Error parsing X-Org-ID header: %v", err)
+			return nil, errors.New("invalid X-Org-ID header")
+		}
+		ctx = context.WithValue(ctx, service.OrgIDKey, orgID)
+	}
 	return ctx, nil
 }
 
@@ -92,18 +107,26 @@ func (h *SurveyHandler) CreateSurvey(c *gin.Context) {
 
 	survey := &models.Survey{
 		// CreatorID is now handled by the service using context
-		Title:       req.Title,
-		Description: req.Description,
-		IsActive:    req.IsActive,
-		StartDate:   req.StartDate,
-		EndDate:     req.EndDate,
+		Title:              req.Title,
+		Description:        req.Description,
+		IsActive:           req.IsActive,
+		StartDate:          req.StartDate,
+		EndDate:            req.EndDate,
+		MaxResponses:       req.MaxResponses,
+		OneResponsePerUser: req.OneResponsePerUser,
+		AnonymousLimit:     req.AnonymousLimit,
+		AllowAnonymous:     req.AllowAnonymous,
+		AllowResponseEdits: req.AllowResponseEdits,
+		Sections:           sectionsFromRequest(req.Sections),
 	}
 
 	id, err := h.surveyService.CreateSurvey(userCtx, survey, req.Questions)
 	if err != nil {
 		log.Printf("Error creating survey with questions: %v", err)
 		// Check for specific error types if service layer provides them (e.g. forbidden)
-		if strings.Contains(err.Error(), "forbidden") { // Basic check
+		if errors.Is(err, service.ErrInvalidQuestion) || errors.Is(err, service.ErrInvalidSection) || errors.Is(err, service.ErrInvalidSchedule) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "forbidden") { // Basic check
 			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create survey"})
@@ -122,9 +145,11 @@ func (h *SurveyHandler) GetSurvey(c *gin.Context) {
 		return
 	}
 
-	// For GetSurvey, user context might be needed if non-active surveys have restricted access
-	// Assuming for now GetSurvey is public for active surveys, or service handles auth
-	userCtx, err := getUserContext(c) // Get context for potential auth in service
+	// Anonymous respondents arrive without X-User-ID; the service decides whether the survey allows them
+	userCtx := c.Request.Context()
+	if c.GetHeader("X-User-ID") != "" {
+		userCtx, err = getUserContext(c) // Get context for potential auth in service
+	}
 	if err != nil {
 		// If getting context fails, it implies an issue with required headers for authenticated access.
 		// If this endpoint is truly public for some cases, this error might be too strict.
@@ -135,10 +160,15 @@ func (h *SurveyHandler) GetSurvey(c *gin.Context) {
 		return
 	}
 
-	survey, err := h.surveyService.GetSurvey(userCtx, id) // Pass userCtx
+	// Respondents pass their layout seed to get their own order of shuffled questions and options
+	survey, err := h.surveyService.GetSurveyForRespondent(userCtx, id, c.Query("seed")) // Pass userCtx
 	if err != nil {
 		log.Printf("Error getting survey: %v", err)
-		if strings.Contains(err.Error(), "not found") {
+		if errors.Is(err, service.ErrInvalidLayoutSeed) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else if errors.Is(err, service.ErrAuthenticationRequired) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required to take this survey"})
+		} else if strings.Contains(err.Error(), "not found") {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Survey not found"})
 		} else if strings.Contains(err.Error(), "forbidden") {
 			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
@@ -183,16 +213,24 @@ func (h *SurveyHandler) UpdateSurvey(c *gin.Context) {
 	surveyToUpdate := &models.Survey{
 		ID: id,
 		// CreatorID will be validated by service against context userID or admin role
-		Title:       req.Title,
-		Description: req.Description,
-		IsActive:    req.IsActive,
-		StartDate:   req.StartDate,
-		EndDate:     req.EndDate,
+		Title:              req.Title,
+		Description:        req.Description,
+		IsActive:           req.IsActive,
+		StartDate:          req.StartDate,
+		EndDate:            req.EndDate,
+		MaxResponses:       req.MaxResponses,
+		OneResponsePerUser: req.OneResponsePerUser,
+		AnonymousLimit:     req.AnonymousLimit,
+		AllowAnonymous:     req.AllowAnonymous,
+		AllowResponseEdits: req.AllowResponseEdits,
+		Sections:           sectionsFromRequest(req.Sections),
 	}
 
 	if err := h.surveyService.UpdateSurveyWithQuestions(userCtx, surveyToUpdate, req.Questions); err != nil {
 		log.Printf("Error updating survey with questions: %v", err)
-		if strings.Contains(err.Error(), "not found") {
+		if errors.Is(err, service.ErrInvalidQuestion) || errors.Is(err, service.ErrInvalidSection) || errors.Is(err, service.ErrInvalidSchedule) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "not found") {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Survey not found"})
 		} else if strings.Contains(err.Error(), "forbidden") {
 			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
@@ -274,8 +312,105 @@ func (h *SurveyHandler) UpdateSurveyStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Survey status updated successfully"})
 }
 
+// ListSurveyVersions handles GET /api/v1/surveys/:id/versions request
+func (h *SurveyHandler) ListSurveyVersions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	userCtx, err := getUserContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	versions, err := h.surveyService.ListSurveyVersions(userCtx, id)
+	if err != nil {
+		log.Printf("Error listing survey versions: %v", err)
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Survey not found"})
+		} else if strings.Contains(err.Error(), "forbidden") {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve survey versions"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+// ListSurveyStatusHistory handles GET /api/v1/surveys/:id/status-history request
+func (h *SurveyHandler) ListSurveyStatusHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	userCtx, err := getUserContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	history, err := h.surveyService.ListSurveyStatusHistory(userCtx, id)
+	if err != nil {
+		log.Printf("Error listing survey status history: %v", err)
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Survey not found"})
+		} else if strings.Contains(err.Error(), "forbidden") {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve survey status history"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// GetSurveyVersion handles GET /api/v1/surveys/:id/versions/:version request
+func (h *SurveyHandler) GetSurveyVersion(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey version"})
+		return
+	}
+
+	userCtx, err := getUserContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication context error: " + err.Error()})
+		return
+	}
+
+	survey, err := h.surveyService.GetSurveyVersion(userCtx, id, version)
+	if err != nil {
+		log.Printf("Error getting survey version: %v", err)
+		if strings.Contains(err.Error(), "version not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Survey version not found"})
+		} else if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Survey not found"})
+		} else if strings.Contains(err.Error(), "forbidden") {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve survey version"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, survey)
+}
+
 // AddQuestion handles POST /api/v1/surveys/:id/questions request
-// This handler needs to ensure the user adding a question is the survey owner or admin.
+// This handler needs to ensure the user adding a question is the survey owner, an editor or an admin.
 // The service.AddQuestion method will need to perform this check.
 func (h *SurveyHandler) AddQuestion(c *gin.Context) {
 	surveyID, err := strconv.Atoi(c.Param("id"))
@@ -301,7 +436,9 @@ func (h *SurveyHandler) AddQuestion(c *gin.Context) {
 	questionID, err := h.surveyService.AddQuestion(userCtx, &req) // Pass userCtx
 	if err != nil {
 		log.Printf("Error adding question: %v", err)
-		if strings.Contains(err.Error(), "not found") { // e.g. survey not found
+		if errors.Is(err, service.ErrInvalidQuestion) || errors.Is(err, service.ErrInvalidSection) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else if strings.Contains(err.Error(), "not found") { // e.g. survey not found
 			c.JSON(http.StatusNotFound, gin.H{"error": "Survey not found or not authorized"})
 		} else if strings.Contains(err.Error(), "forbidden") {
 			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden to add question to this survey"})
@@ -331,8 +468,13 @@ func (h *SurveyHandler) GetMySurveys(c *gin.Context) {
 		limit = 10
 	}
 	// Potentially add a max limit check, e.g., if limit > 100 { limit = 100 }
+	status := c.Query("status")
+	if status != "" && !models.IsValidSurveyStatus(status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status filter, expected scheduled, open or closed"})
+		return
+	}
 
-	surveys, total, err := h.surveyService.ListUserSurveys(userCtx, page, limit)
+	surveys, total, err := h.surveyService.ListUserSurveys(userCtx, status, page, limit)
 	if err != nil {
 		log.Printf("Error getting user surveys: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve your surveys"})
@@ -371,8 +513,13 @@ func (h *SurveyHandler) GetAllSurveysPublic(c *gin.Context) {
 	if limit < 1 {
 		limit = 10
 	}
+	status := c.Query("status")
+	if status != "" && !models.IsValidSurveyStatus(status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status filter, expected scheduled, open or closed"})
+		return
+	}
 
-	surveys, total, err := h.surveyService.ListAllPublicSurveys(userCtx, page, limit) // Service might filter by is_active or other criteria
+	surveys, total, err := h.surveyService.ListAllPublicSurveys(userCtx, status, page, limit) // Service might filter by is_active or other criteria
 	if err != nil {
 		log.Printf("Error getting all public surveys: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve all surveys"})
@@ -390,3 +537,365 @@ func (h *SurveyHandler) GetAllSurveysPublic(c *gin.Context) {
 		"limit": limit,
 	})
 }
+
+// sectionsFromRequest converts requested sections to models; their positions are kept as the page order
+func sectionsFromRequest(requested []models.SectionUpdateRequest) []*models.Section {
+	sections := make([]*models.Section, 0, len(requested))
+	for _, req := range requested {
+		section := &models.Section{Title: req.Title, Description: req.Description, RandomizeQuestions: req.RandomizeQuestions}
+		if req.ID != nil {
+			section.ID = *req.ID
+		}
+		sections = append(sections, section)
+	}
+	return sections
+}
+
+// ExportSurveyDefinition handles GET /api/v1/surveys/:id/definition request.
+// The document is JSON unless ?format=yaml is given.
+func (h *SurveyHandler) ExportSurveyDefinition(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "yaml" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format, expected json or yaml"})
+		return
+	}
+
+	userCtx, err := getUserContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	definition, err := h.surveyService.ExportSurveyDefinition(userCtx, id)
+	if err != nil {
+		log.Printf("Error exporting survey definition: %v", err)
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Survey not found"})
+		} else if strings.Contains(err.Error(), "forbidden") {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export survey"})
+		}
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=survey-%d.%s", id, format))
+	if format == "yaml" {
+		data, err := yaml.Marshal(definition)
+		if err != nil {
+			log.Printf("Error encoding survey definition as YAML: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export survey"})
+			return
+		}
+		c.Data(http.StatusOK, "application/yaml; charset=utf-8", data)
+		return
+	}
+	// Indented, so exported documents diff well in version control
+	c.IndentedJSON(http.StatusOK, definition)
+}
+
+// ImportSurveyDefinition handles POST /api/v1/surveys/import request.
+// The body is a survey definition in JSON, or in YAML when the Content-Type says so.
+func (h *SurveyHandler) ImportSurveyDefinition(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxDefinitionSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read survey definition"})
+		return
+	}
+	if len(body) > maxDefinitionSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Survey definition is too large"})
+		return
+	}
+
+	definition, err := decodeSurveyDefinition(body, strings.Contains(c.ContentType(), "yaml"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey definition: " + err.Error()})
+		return
+	}
+
+	userCtx, err := getUserContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := h.surveyService.ImportSurveyDefinition(userCtx, definition)
+	if err != nil {
+		log.Printf("Error importing survey definition: %v", err)
+		if errors.Is(err, service.ErrInvalidDefinition) || errors.Is(err, service.ErrInvalidQuestion) || errors.Is(err, service.ErrInvalidSection) || errors.Is(err, service.ErrInvalidSchedule) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import survey"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id, "message": "Survey imported successfully"})
+}
+
+// maxDefinitionSize limits the size of an imported survey definition document
+const maxDefinitionSize = 2 << 20
+
+// decodeSurveyDefinition parses a definition document strictly, so misspelt fields are reported rather than dropped
+func decodeSurveyDefinition(body []byte, isYAML bool) (*models.SurveyDefinition, error) {
+	var definition models.SurveyDefinition
+	if isYAML {
+		decoder := yaml.NewDecoder(bytes.NewReader(body))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&definition); err != nil {
+			return nil, err
+		}
+		return &definition, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&definition); err != nil {
+		return nil, err
+	}
+	return &definition, nil
+}
+
+// ImportExternalSurvey handles POST /api/v1/surveys/import/:format request.
+// The body is a file exported by another tool, see models.ImportFormatLimeSurvey and models.ImportFormatQTI.
+func (h *SurveyHandler) ImportExternalSurvey(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxExternalImportSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read import file"})
+		return
+	}
+	if len(body) > maxExternalImportSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Import file is too large"})
+		return
+	}
+
+	userCtx, err := getUserContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.surveyService.ImportExternalSurvey(userCtx, c.Param("format"), body)
+	if err != nil {
+		log.Printf("Error importing survey: %v", err)
+		if errors.Is(err, service.ErrInvalidImport) || errors.Is(err, service.ErrInvalidDefinition) || errors.Is(err, service.ErrInvalidQuestion) || errors.Is(err, service.ErrInvalidSection) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import survey"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, report)
+}
+
+// maxExternalImportSize limits the size of files imported from other tools, which may hold several languages or a package of items
+const maxExternalImportSize = 10 << 20
+
+// CloneSurvey handles POST /api/v1/surveys/:id/clone request.
+// The body is optional; without a title the copy is named after the original.
+func (h *SurveyHandler) CloneSurvey(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	var req models.CloneSurveyRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	userCtx, err := getUserContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	surveyID, err := h.surveyService.CloneSurvey(userCtx, id, req.Title)
+	if err != nil {
+		log.Printf("Error cloning survey: %v", err)
+		if errors.Is(err, service.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Survey not found"})
+		} else if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clone survey"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": surveyID, "message": "Survey cloned successfully"})
+}
+
+// TransferSurveyOwnership handles POST /api/v1/surveys/:id/transfer request
+func (h *SurveyHandler) TransferSurveyOwnership(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	var req models.TransferSurveyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userCtx, err := getUserContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	transfer, err := h.surveyService.TransferSurveyOwnership(userCtx, id, req)
+	if err != nil {
+		log.Printf("Error transferring survey ownership: %v", err)
+		if errors.Is(err, service.ErrInvalidTransfer) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else if errors.Is(err, service.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Survey not found"})
+		} else if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer survey"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+// ListSurveyOwnershipTransfers handles GET /api/v1/surveys/:id/transfers request
+func (h *SurveyHandler) ListSurveyOwnershipTransfers(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	userCtx, err := getUserContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	transfers, err := h.surveyService.ListSurveyOwnershipTransfers(userCtx, id)
+	if err != nil {
+		log.Printf("Error listing survey ownership transfers: %v", err)
+		if errors.Is(err, service.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Survey not found"})
+		} else if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve ownership transfers"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, transfers)
+}
+
+// InviteCollaborator handles POST /api/v1/surveys/:id/collaborators request
+func (h *SurveyHandler) InviteCollaborator(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	var req models.InviteCollaboratorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userCtx, err := getUserContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	collaborator, err := h.surveyService.InviteCollaborator(userCtx, id, req)
+	if err != nil {
+		log.Printf("Error inviting collaborator: %v", err)
+		writeCollaboratorError(c, err, "Failed to invite collaborator")
+		return
+	}
+
+	c.JSON(http.StatusOK, collaborator)
+}
+
+// ListCollaborators handles GET /api/v1/surveys/:id/collaborators request
+func (h *SurveyHandler) ListCollaborators(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	userCtx, err := getUserContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	collaborators, err := h.surveyService.ListCollaborators(userCtx, id)
+	if err != nil {
+		log.Printf("Error listing collaborators: %v", err)
+		writeCollaboratorError(c, err, "Failed to retrieve collaborators")
+		return
+	}
+
+	c.JSON(http.StatusOK, collaborators)
+}
+
+// RemoveCollaborator handles DELETE /api/v1/surveys/:id/collaborators/:userId request
+func (h *SurveyHandler) RemoveCollaborator(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+	collaboratorID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	userCtx, err := getUserContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.surveyService.RemoveCollaborator(userCtx, id, collaboratorID); err != nil {
+		log.Printf("Error removing collaborator: %v", err)
+		writeCollaboratorError(c, err, "Failed to remove collaborator")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Collaborator removed successfully"})
+}
+
+// writeCollaboratorError maps errors of the collaborator endpoints to HTTP statuses
+func writeCollaboratorError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidCollaborator):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Survey or collaborator not found"})
+	case errors.Is(err, service.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
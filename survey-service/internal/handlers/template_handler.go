@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// TemplateHandler handles HTTP requests related to survey templates
+type TemplateHandler struct {
+	surveyService service.SurveyServiceInterface
+}
+
+// NewTemplateHandler creates a new template handler
+func NewTemplateHandler(surveyService service.SurveyServiceInterface) *TemplateHandler {
+	return &TemplateHandler{
+		surveyService: surveyService,
+	}
+}
+
+// SetTemplate handles PUT /api/v1/surveys/:id/template request
+func (h *TemplateHandler) SetTemplate(c *gin.Context) {
+	var req models.SetTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.setTemplate(c, req.Visibility)
+}
+
+// UnsetTemplate handles DELETE /api/v1/surveys/:id/template request
+func (h *TemplateHandler) UnsetTemplate(c *gin.Context) {
+	h.setTemplate(c, "")
+}
+
+func (h *TemplateHandler) setTemplate(c *gin.Context, visibility string) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid survey ID"})
+		return
+	}
+
+	userCtx, err := getUserContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.surveyService.SetSurveyTemplate(userCtx, id, visibility); err != nil {
+		log.Printf("Error setting survey template visibility: %v", err)
+		if errors.Is(err, service.ErrInvalidTemplate) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else if errors.Is(err, service.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Survey not found"})
+		} else if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update survey template"})
+		}
+		return
+	}
+
+	if visibility == "" {
+		c.JSON(http.StatusOK, gin.H{"message": "Survey is no longer a template"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Survey marked as template", "visibility": visibility})
+}
+
+// ListTemplates handles GET /api/v1/templates request
+func (h *TemplateHandler) ListTemplates(c *gin.Context) {
+	userCtx, err := getUserContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	templates, total, err := h.surveyService.ListTemplates(userCtx, page, limit)
+	if err != nil {
+		log.Printf("Error listing templates: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve templates"})
+		return
+	}
+
+	if templates == nil {
+		templates = []*models.Survey{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  templates,
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+// InstantiateTemplate handles POST /api/v1/templates/:id/instantiate request
+func (h *TemplateHandler) InstantiateTemplate(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return
+	}
+
+	// The body is optional; without a title the new survey keeps the template's
+	var req models.InstantiateTemplateRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	userCtx, err := getUserContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	surveyID, err := h.surveyService.InstantiateTemplate(userCtx, id, req.Title)
+	if err != nil {
+		log.Printf("Error instantiating template: %v", err)
+		if errors.Is(err, service.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create survey from template"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": surveyID, "message": "Survey created from template"})
+}
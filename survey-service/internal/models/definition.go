@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// SurveyDefinitionSchemaVersion is the version of the SurveyDefinition format written by exports.
+// Imports accept documents up to this version; see docs/survey-definition.md for the format.
+const SurveyDefinitionSchemaVersion = 1
+
+// SurveyDefinition is a portable document describing a survey: its settings, sections, questions and options.
+// It carries no database IDs, owners or timestamps, so it can be kept in version control and imported into
+// any environment. Sections and questions are listed in order; rules, pipes and sections refer to them by
+// their position in the document, starting at 1.
+type SurveyDefinition struct {
+	SchemaVersion int                      `json:"schema_version" yaml:"schema_version"`
+	Title         string                   `json:"title" yaml:"title"`
+	Description   string                   `json:"description,omitempty" yaml:"description,omitempty"`
+	Settings      SurveyDefinitionSettings `json:"settings" yaml:"settings"`
+	Sections      []SectionDefinition      `json:"sections,omitempty" yaml:"sections,omitempty"`
+	Questions     []QuestionDefinition     `json:"questions" yaml:"questions"`
+}
+
+// SurveyDefinitionSettings holds the response settings and schedule of a SurveyDefinition
+type SurveyDefinitionSettings struct {
+	IsActive           bool       `json:"is_active" yaml:"is_active"`
+	StartDate          *time.Time `json:"start_date,omitempty" yaml:"start_date,omitempty"` // Omitted when the survey opens right away
+	EndDate            *time.Time `json:"end_date,omitempty" yaml:"end_date,omitempty"`     // Omitted when the survey stays open
+	MaxResponses       *int       `json:"max_responses,omitempty" yaml:"max_responses,omitempty"`
+	OneResponsePerUser bool       `json:"one_response_per_user" yaml:"one_response_per_user"`
+	AnonymousLimit     string     `json:"anonymous_limit,omitempty" yaml:"anonymous_limit,omitempty"`
+	AllowAnonymous     bool       `json:"allow_anonymous" yaml:"allow_anonymous"`
+	AllowResponseEdits bool       `json:"allow_response_edits" yaml:"allow_response_edits"`
+}
+
+// SectionDefinition is a page of a SurveyDefinition
+type SectionDefinition struct {
+	Title              string `json:"title" yaml:"title"`
+	Description        string `json:"description,omitempty" yaml:"description,omitempty"`
+	RandomizeQuestions bool   `json:"randomize_questions,omitempty" yaml:"randomize_questions,omitempty"`
+}
+
+// QuestionDefinition is a question of a SurveyDefinition
+type QuestionDefinition struct {
+	Text     string   `json:"text" yaml:"text"`
+	Type     string   `json:"type" yaml:"type"`
+	Required bool     `json:"required,omitempty" yaml:"required,omitempty"`
+	Section  int      `json:"section,omitempty" yaml:"section,omitempty"` // Position of the question's section, 0 for none
+	Options  []string `json:"options,omitempty" yaml:"options,omitempty"`
+	// Config holds the type-specific settings as an object, so YAML documents can write it natively
+	Config           map[string]interface{} `json:"config,omitempty" yaml:"config,omitempty"`
+	DisplayRules     []DisplayRule          `json:"display_rules,omitempty" yaml:"display_rules,omitempty"`
+	SkipRules        []SkipRule             `json:"skip_rules,omitempty" yaml:"skip_rules,omitempty"`
+	RandomizeOptions bool                   `json:"randomize_options,omitempty" yaml:"randomize_options,omitempty"`
+}
+
+// Formats of surveys exported from other tools that can be imported
+const (
+	ImportFormatLimeSurvey = "lss" // LimeSurvey survey structure file
+	ImportFormatQTI        = "qti" // IMS QTI 2.1, 2.2 or 3.0 item, or a content package (.zip) of items
+)
+
+// ImportIssue describes a part of an imported file that could not be carried over as it was
+type ImportIssue struct {
+	Source  string `json:"source,omitempty"` // Code or identifier of the question in the imported file, empty for the whole file
+	Message string `json:"message"`
+}
+
+// ImportReport is the outcome of importing a survey from another tool
+type ImportReport struct {
+	SurveyID  int           `json:"survey_id"`
+	Format    string        `json:"format"`
+	Questions int           `json:"questions"` // Number of questions created
+	Issues    []ImportIssue `json:"issues"`
+}
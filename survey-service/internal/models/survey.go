@@ -1,34 +1,290 @@
 package models
 
 import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
 	"time"
 )
 
 // Survey represents a survey in the system
 type Survey struct {
-	ID          int         `json:"id"`
-	CreatorID   int         `json:"creator_id"`
-	Title       string      `json:"title"`
-	Description string      `json:"description,omitempty"`
-	IsActive    bool        `json:"is_active"`
-	StartDate   time.Time   `json:"start_date,omitempty"`
-	EndDate     time.Time   `json:"end_date,omitempty"`
-	Questions   []*Question `json:"questions,omitempty"`
-	CreatedAt   time.Time   `json:"created_at"`
-	UpdatedAt   time.Time   `json:"updated_at"`
+	ID                 int         `json:"id"`
+	CreatorID          int         `json:"creator_id"`
+	OrgID              *int        `json:"org_id,omitempty"` // The organization (tenant) the survey belongs to, nil outside every organization
+	Title              string      `json:"title"`
+	Description        string      `json:"description,omitempty"`
+	IsActive           bool        `json:"is_active"`
+	StartDate          time.Time   `json:"start_date,omitempty"`
+	EndDate            time.Time   `json:"end_date,omitempty"`
+	MaxResponses       *int        `json:"max_responses,omitempty"`   // Response quota closing the survey, nil for none
+	OneResponsePerUser bool        `json:"one_response_per_user"`     // Enforced by the response-service for logged-in respondents
+	AnonymousLimit     string      `json:"anonymous_limit,omitempty"` // '', 'ip' or 'device', see AnonymousLimit* constants
+	AllowAnonymous     bool        `json:"allow_anonymous"`           // Whether respondents may take the survey without logging in
+	AllowResponseEdits bool        `json:"allow_response_edits"`      // Whether respondents may edit or withdraw submitted responses while it is open
+	Sections           []*Section  `json:"sections,omitempty"`        // Pages of the survey, ordered by OrderNum
+	Questions          []*Question `json:"questions,omitempty"`
+	Version            int         `json:"version"` // Latest published version, 0 if never published
+	Status             string      `json:"status"`  // Effective status derived from IsActive and the schedule, see EffectiveStatus
+	// TemplateVisibility marks the survey as a template others can start new surveys from, see TemplateVisibility*.
+	// It is empty for surveys that are not templates.
+	TemplateVisibility string `json:"template_visibility,omitempty"`
+	// AccessRole is the current user's role on the survey, see AccessRole*. It is set when an authenticated user
+	// fetches or lists surveys they own or collaborate on, and is empty otherwise, e.g. for admins.
+	AccessRole string `json:"access_role,omitempty"`
+	// Layout is the order one respondent is shown the questions and options in, set when a survey with
+	// randomized questions or options is fetched with a layout seed
+	Layout    *SurveyLayout `json:"layout,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// Effective survey statuses derived from IsActive, StartDate and EndDate
+const (
+	SurveyStatusScheduled = "scheduled" // Active, but StartDate has not been reached yet
+	SurveyStatusOpen      = "open"      // Active and within its schedule, accepting responses
+	SurveyStatusClosed    = "closed"    // Deactivated by hand, or EndDate has passed
+)
+
+// IsValidSurveyStatus reports whether status is one of the effective survey statuses
+func IsValidSurveyStatus(status string) bool {
+	switch status {
+	case SurveyStatusScheduled, SurveyStatusOpen, SurveyStatusClosed:
+		return true
+	}
+	return false
+}
+
+// EffectiveStatus derives the survey's status at the given time.
+// Zero StartDate or EndDate means the schedule is unbounded on that side.
+func (s *Survey) EffectiveStatus(now time.Time) string {
+	switch {
+	case !s.IsActive:
+		return SurveyStatusClosed
+	case !s.EndDate.IsZero() && !now.Before(s.EndDate):
+		return SurveyStatusClosed
+	case !s.StartDate.IsZero() && now.Before(s.StartDate):
+		return SurveyStatusScheduled
+	}
+	return SurveyStatusOpen
+}
+
+// Limits on how often anonymous respondents may answer a survey
+const (
+	AnonymousLimitNone   = ""       // No limit
+	AnonymousLimitIP     = "ip"     // One response per IP address
+	AnonymousLimitDevice = "device" // One response per device token sent by the client
+)
+
+// IsValidAnonymousLimit reports whether limit is one of the AnonymousLimit* values
+func IsValidAnonymousLimit(limit string) bool {
+	switch limit {
+	case AnonymousLimitNone, AnonymousLimitIP, AnonymousLimitDevice:
+		return true
+	}
+	return false
+}
+
+// Who may start new surveys from a template
+const (
+	TemplateVisibilityPrivate = "private" // Only the template's creator
+	TemplateVisibilityOrg     = "org"     // Every user of the creator's organization
+	TemplateVisibilityGlobal  = "global"  // Every user; only admins may publish global templates
+)
+
+// IsValidTemplateVisibility reports whether visibility is one of the TemplateVisibility* values
+func IsValidTemplateVisibility(visibility string) bool {
+	switch visibility {
+	case TemplateVisibilityPrivate, TemplateVisibilityOrg, TemplateVisibilityGlobal:
+		return true
+	}
+	return false
+}
+
+// Roles a user can have on a survey. Collaborators are invited as viewers, editors or analysts.
+const (
+	AccessRoleOwner   = "owner"   // The survey's creator; only owners may delete, transfer or share it
+	AccessRoleEditor  = "editor"  // May change the survey, its sections and questions
+	AccessRoleAnalyst = "analyst" // May see responses and analytics
+	AccessRoleViewer  = "viewer"  // May see the survey, its versions and history
+)
+
+// IsValidCollaboratorRole reports whether role is one collaborators can be invited as
+func IsValidCollaboratorRole(role string) bool {
+	switch role {
+	case AccessRoleViewer, AccessRoleEditor, AccessRoleAnalyst:
+		return true
+	}
+	return false
+}
+
+// Reasons recorded with a survey status change
+const (
+	StatusChangeManual    = "manual"         // Toggled by the owner or an admin
+	StatusChangeStartDate = "start_date"     // Opened by the scheduler once StartDate passed
+	StatusChangeEndDate   = "end_date"       // Closed by the scheduler once EndDate passed
+	StatusChangeQuota     = "response_quota" // Closed by the scheduler once MaxResponses was reached
+)
+
+// SurveyStatusChange records a transition of a survey's effective status
+type SurveyStatusChange struct {
+	ID         int       `json:"id"`
+	SurveyID   int       `json:"survey_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Reason     string    `json:"reason"`
+	ChangedBy  *int      `json:"changed_by,omitempty"` // nil when changed by the scheduler
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+// SurveyOwnershipTransfer records a survey being handed from one user to another.
+// The user IDs are nil once the users have been deleted.
+type SurveyOwnershipTransfer struct {
+	ID            int       `json:"id"`
+	SurveyID      int       `json:"survey_id"`
+	FromUserID    *int      `json:"from_user_id"`
+	ToUserID      *int      `json:"to_user_id"`
+	TransferredBy *int      `json:"transferred_by"` // The previous owner or an admin
+	TransferredAt time.Time `json:"transferred_at"`
+}
+
+// SurveyCollaborator is a user invited to work on someone else's survey
+type SurveyCollaborator struct {
+	SurveyID  int       `json:"survey_id"`
+	UserID    int       `json:"user_id"`
+	Username  string    `json:"username,omitempty"`
+	Email     string    `json:"email,omitempty"`
+	Role      string    `json:"role"`       // See AccessRoleViewer, AccessRoleEditor and AccessRoleAnalyst
+	InvitedBy *int      `json:"invited_by"` // Nil once the inviting user has been deleted
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SurveyVersion is an immutable snapshot of a published survey with its questions and options
+type SurveyVersion struct {
+	ID        int       `json:"id"`
+	SurveyID  int       `json:"survey_id"`
+	Version   int       `json:"version"`
+	Snapshot  *Survey   `json:"snapshot,omitempty"`
+	CreatedBy *int      `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 // Question represents a question in a survey
 type Question struct {
-	ID        int               `json:"id"`
-	SurveyID  int               `json:"survey_id"`
-	Text      string            `json:"text"`
-	Type      string            `json:"type"` // 'text', 'single_choice', etc.
-	Required  bool              `json:"required"`
-	OrderNum  int               `json:"order_num"`
-	Options   []*QuestionOption `json:"options,omitempty"`
-	CreatedAt time.Time         `json:"created_at"`
-	UpdatedAt time.Time         `json:"updated_at"`
+	ID           int               `json:"id"`
+	SurveyID     int               `json:"survey_id"`
+	Text         string            `json:"text"`
+	Type         string            `json:"type"` // 'text', 'single_choice', etc.
+	Required     bool              `json:"required"`
+	OrderNum     int               `json:"order_num"`
+	SectionID    *int              `json:"section_id,omitempty"` // Section (page) the question is shown on, nil for surveys without sections
+	Options      []*QuestionOption `json:"options,omitempty"`
+	Config       json.RawMessage   `json:"config,omitempty"`        // Type-specific settings, see questiontypes.QuestionTypeDefinition.NewConfig
+	DisplayRules []DisplayRule     `json:"display_rules,omitempty"` // The question is shown only if every rule matches
+	SkipRules    []SkipRule        `json:"skip_rules,omitempty"`    // Checked once the question is answered, the first match applies
+	// RandomizeOptions shows each respondent the options in their own random order
+	RandomizeOptions bool      `json:"randomize_options,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// Operators comparing a respondent's answer in display and skip rules
+const (
+	LogicOperatorEquals    = "equals"     // The answer is exactly the value
+	LogicOperatorNotEquals = "not_equals" // The answer is anything but the value, including no answer
+	LogicOperatorIncludes  = "includes"   // The value is one of the selected options, or the answer itself
+)
+
+// IsValidLogicOperator reports whether operator is one of the LogicOperator* values
+func IsValidLogicOperator(operator string) bool {
+	switch operator {
+	case LogicOperatorEquals, LogicOperatorNotEquals, LogicOperatorIncludes:
+		return true
+	}
+	return false
+}
+
+// DisplayRule shows a question only when the answer to an earlier question matches.
+// Stored rules refer to the question by ID and, when it is a choice question, to the option by ID, so reordering
+// questions or renaming options never retargets them. Requests may refer to the question by position and to the
+// option by its text instead, as new questions and options have no IDs yet; the survey stores the IDs they resolve to.
+type DisplayRule struct {
+	QuestionID int    `json:"question_id,omitempty" yaml:"-"`
+	Question   int    `json:"question,omitempty" yaml:"question"` // Position (OrderNum) of the question, when QuestionID is not set
+	Operator   string `json:"operator" yaml:"operator"`
+	OptionID   int    `json:"option_id,omitempty" yaml:"-"` // The option checked on choice questions
+	Value      string `json:"value,omitempty" yaml:"value"` // The text compared with other answers, or the text of the option
+}
+
+// PipePattern matches a reference to an earlier answer in a question's text, such as "You said {{Q2}} - why?".
+// The number is the position (OrderNum) of the referenced question; respondents see its answer in place of
+// the reference. Pipes written this way are stored as StoredPipePattern references.
+var PipePattern = regexp.MustCompile(`\{\{\s*Q(\d+)\s*\}\}`)
+
+// StoredPipePattern matches a pipe as stored, such as "You said {{question:42}} - why?", where 42 is the ID of
+// the referenced question
+var StoredPipePattern = regexp.MustCompile(`\{\{\s*question:(\d+)\s*\}\}`)
+
+// StoredPipe returns the stored pipe referencing the question with the given ID
+func StoredPipe(questionID int) string {
+	return fmt.Sprintf("{{question:%d}}", questionID)
+}
+
+// PipedQuestions returns the positions of the questions whose answers a text pipes in, in order of appearance
+func PipedQuestions(text string) []int {
+	return pipeNumbers(PipePattern, text)
+}
+
+// PipedQuestionIDs returns the IDs of the questions whose answers a text pipes in through stored pipes
+func PipedQuestionIDs(text string) []int {
+	return pipeNumbers(StoredPipePattern, text)
+}
+
+func pipeNumbers(pattern *regexp.Regexp, text string) []int {
+	var numbers []int
+	for _, match := range pattern.FindAllStringSubmatch(text, -1) {
+		if number, err := strconv.Atoi(match[1]); err == nil {
+			numbers = append(numbers, number)
+		}
+	}
+	return numbers
+}
+
+// SkipRule jumps forward when the answer to its own question matches; the questions in between are skipped.
+// Like DisplayRule, stored rules refer to questions and options by ID, and requests may use positions and texts.
+type SkipRule struct {
+	Operator         string `json:"operator" yaml:"operator"`
+	OptionID         int    `json:"option_id,omitempty" yaml:"-"`
+	Value            string `json:"value,omitempty" yaml:"value"`
+	SkipToQuestionID int    `json:"skip_to_question_id,omitempty" yaml:"-"` // The later question the survey continues at
+	SkipTo           int    `json:"skip_to,omitempty" yaml:"skip_to"`       // Its position (OrderNum), when SkipToQuestionID is not set
+}
+
+// Section groups consecutive questions of a survey into a page
+type Section struct {
+	ID          int    `json:"id"`
+	SurveyID    int    `json:"survey_id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	OrderNum    int    `json:"order_num"`
+	// RandomizeQuestions shows each respondent the section's questions in their own random order
+	RandomizeQuestions bool      `json:"randomize_questions,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// SurveyLayout records the order a respondent is shown a survey in. The same seed always gives the same layout.
+type SurveyLayout struct {
+	Seed          string        `json:"seed"`
+	QuestionOrder []int         `json:"question_order"`          // Question IDs in the order shown
+	OptionOrders  []OptionOrder `json:"option_orders,omitempty"` // Questions whose options are shown in random order
+}
+
+// OptionOrder is the order the options of one question are shown in
+type OptionOrder struct {
+	QuestionID int   `json:"question_id"`
+	OptionIDs  []int `json:"option_ids"`
 }
 
 // QuestionOption represents an option for a question
@@ -43,42 +299,99 @@ type QuestionOption struct {
 
 // CreateSurveyRequest represents the data needed to create a new survey
 type CreateSurveyRequest struct {
-	Title       string                  `json:"title" binding:"required"`
-	Description string                  `json:"description"`
-	IsActive    bool                    `json:"is_active"`
-	StartDate   time.Time               `json:"start_date"`
-	EndDate     time.Time               `json:"end_date"`
-	Questions   []QuestionUpdateRequest `json:"questions,omitempty"`
+	Title              string                  `json:"title" binding:"required"`
+	Description        string                  `json:"description"`
+	IsActive           bool                    `json:"is_active"`
+	StartDate          time.Time               `json:"start_date"`
+	EndDate            time.Time               `json:"end_date"`
+	MaxResponses       *int                    `json:"max_responses,omitempty"`
+	OneResponsePerUser bool                    `json:"one_response_per_user"`
+	AnonymousLimit     string                  `json:"anonymous_limit"`
+	AllowAnonymous     bool                    `json:"allow_anonymous"`
+	AllowResponseEdits bool                    `json:"allow_response_edits"`
+	Sections           []SectionUpdateRequest  `json:"sections,omitempty"`
+	Questions          []QuestionUpdateRequest `json:"questions,omitempty"`
 }
 
 // UpdateSurveyRequest represents the data needed to update a survey
 type UpdateSurveyRequest struct {
-	Title       string                  `json:"title"`
-	Description string                  `json:"description"`
-	IsActive    bool                    `json:"is_active"`
-	StartDate   time.Time               `json:"start_date"`
-	EndDate     time.Time               `json:"end_date"`
-	Questions   []QuestionUpdateRequest `json:"questions"`
+	Title              string                  `json:"title"`
+	Description        string                  `json:"description"`
+	IsActive           bool                    `json:"is_active"`
+	StartDate          time.Time               `json:"start_date"`
+	EndDate            time.Time               `json:"end_date"`
+	MaxResponses       *int                    `json:"max_responses,omitempty"`
+	OneResponsePerUser bool                    `json:"one_response_per_user"`
+	AnonymousLimit     string                  `json:"anonymous_limit"`
+	AllowAnonymous     bool                    `json:"allow_anonymous"`
+	AllowResponseEdits bool                    `json:"allow_response_edits"`
+	Sections           []SectionUpdateRequest  `json:"sections"`
+	Questions          []QuestionUpdateRequest `json:"questions"`
+}
+
+// SectionUpdateRequest represents a section within a survey create or update.
+// Sections are matched by ID like questions; sections missing from an update are deleted.
+type SectionUpdateRequest struct {
+	ID                 *int   `json:"id,omitempty"`
+	Title              string `json:"title"`
+	Description        string `json:"description"`
+	RandomizeQuestions bool   `json:"randomize_questions,omitempty"`
 }
 
 // QuestionUpdateRequest represents data for updating/creating a question within a survey update
 type QuestionUpdateRequest struct {
-	ID       *int     `json:"id,omitempty"`
-	Text     string   `json:"text" binding:"required"`
-	Type     string   `json:"type" binding:"required"`
-	Required bool     `json:"required"`
-	OrderNum int      `json:"order_num"`
-	Options  []string `json:"options"`
+	ID           *int                  `json:"id,omitempty"`
+	Text         string                `json:"text" binding:"required"`
+	Type         string                `json:"type" binding:"required"`
+	Required     bool                  `json:"required"`
+	OrderNum     int                   `json:"order_num"`
+	Section      int                   `json:"section,omitempty"` // Position of the question's section in the request's sections, starting at 1; 0 for none
+	Options      []OptionUpdateRequest `json:"options"`
+	Config       json.RawMessage       `json:"config,omitempty"`
+	DisplayRules []DisplayRule         `json:"display_rules,omitempty"` // Refer to questions by ID or by their position in the request, starting at 1
+	SkipRules    []SkipRule            `json:"skip_rules,omitempty"`
+	// RandomizeOptions shuffles the options for each respondent
+	RandomizeOptions bool `json:"randomize_options,omitempty"`
+}
+
+// OptionUpdateRequest represents an option within a question update.
+// ID is set for options that already exist; options without an ID are matched by text or created.
+type OptionUpdateRequest struct {
+	ID   *int   `json:"id,omitempty"`
+	Text string `json:"text"`
+}
+
+// UnmarshalJSON accepts either a plain option text or an {"id", "text"} object
+func (o *OptionUpdateRequest) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*o = OptionUpdateRequest{Text: text}
+		return nil
+	}
+
+	type plain OptionUpdateRequest
+	var opt plain
+	if err := json.Unmarshal(data, &opt); err != nil {
+		return err
+	}
+	*o = OptionUpdateRequest(opt)
+	return nil
 }
 
 // CreateQuestionRequest represents the data needed to create a new question
 type CreateQuestionRequest struct {
-	SurveyID int                           `json:"survey_id" binding:"required"`
-	Text     string                        `json:"text" binding:"required"`
-	Type     string                        `json:"type" binding:"required,oneof=text single_choice"`
-	Required bool                          `json:"required"`
-	OrderNum int                           `json:"order_num"`
-	Options  []CreateQuestionOptionRequest `json:"options"`
+	SurveyID     int                           `json:"survey_id" binding:"required"`
+	Text         string                        `json:"text" binding:"required"`
+	Type         string                        `json:"type" binding:"required"` // Checked against the question type registry
+	Required     bool                          `json:"required"`
+	OrderNum     int                           `json:"order_num"`
+	SectionID    *int                          `json:"section_id,omitempty"`
+	Options      []CreateQuestionOptionRequest `json:"options"`
+	Config       json.RawMessage               `json:"config,omitempty"`
+	DisplayRules []DisplayRule                 `json:"display_rules,omitempty"`
+	SkipRules    []SkipRule                    `json:"skip_rules,omitempty"`
+	// RandomizeOptions shuffles the options for each respondent
+	RandomizeOptions bool `json:"randomize_options,omitempty"`
 }
 
 // CreateQuestionOptionRequest represents the data needed to create a new question option
@@ -87,6 +400,35 @@ type CreateQuestionOptionRequest struct {
 	OrderNum int    `json:"order_num"`
 }
 
+// SetTemplateRequest marks a survey as a template with the given visibility
+type SetTemplateRequest struct {
+	Visibility string `json:"visibility" binding:"required"`
+}
+
+// InstantiateTemplateRequest starts a new survey from a template; an empty title keeps the template's
+type InstantiateTemplateRequest struct {
+	Title string `json:"title"`
+}
+
+// CloneSurveyRequest copies a survey; an empty title names the copy after the original
+type CloneSurveyRequest struct {
+	Title string `json:"title"`
+}
+
+// TransferSurveyRequest hands a survey to another user, identified by ID or by email
+type TransferSurveyRequest struct {
+	NewOwnerID    int    `json:"new_owner_id"`
+	NewOwnerEmail string `json:"new_owner_email"`
+}
+
+// InviteCollaboratorRequest adds a user, identified by ID or by email, to a survey's collaborators.
+// Inviting a collaborator again changes their role.
+type InviteCollaboratorRequest struct {
+	UserID int    `json:"user_id"`
+	Email  string `json:"email"`
+	Role   string `json:"role" binding:"required"`
+}
+
 // UpdateSurveyStatusRequest represents the data needed to update a survey's active status
 type UpdateSurveyStatusRequest struct {
 	IsActive *bool `json:"is_active" binding:"required"` // Pointer to allow false value
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 )
@@ -34,6 +35,30 @@ func TestSurvey(t *testing.T) {
 	}
 }
 
+func TestSurveyEffectiveStatus(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		survey Survey
+		want   string
+	}{
+		{"Active without schedule", Survey{IsActive: true}, SurveyStatusOpen},
+		{"Inactive", Survey{IsActive: false}, SurveyStatusClosed},
+		{"Before start", Survey{IsActive: true, StartDate: now.Add(time.Hour)}, SurveyStatusScheduled},
+		{"Within window", Survey{IsActive: true, StartDate: now.Add(-time.Hour), EndDate: now.Add(time.Hour)}, SurveyStatusOpen},
+		{"At end", Survey{IsActive: true, EndDate: now}, SurveyStatusClosed},
+		{"Inactive before start", Survey{IsActive: false, StartDate: now.Add(time.Hour)}, SurveyStatusClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.survey.EffectiveStatus(now); got != tt.want {
+				t.Errorf("Expected status %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
 func TestQuestion(t *testing.T) {
 	// Test creation of Question struct
 	now := time.Now()
@@ -148,3 +173,21 @@ func TestSurveyWithQuestions(t *testing.T) {
 		t.Errorf("Expected first option text to be 'Yes', got %s", survey.Questions[0].Options[0].Text)
 	}
 }
+
+func TestOptionUpdateRequestUnmarshal(t *testing.T) {
+	var req QuestionUpdateRequest
+	payload := `{"text": "Pick one", "type": "multiple_choice", "options": ["Plain", {"id": 7, "text": "Existing"}]}`
+	if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		t.Fatalf("Unexpected error unmarshalling options: %v", err)
+	}
+
+	if len(req.Options) != 2 {
+		t.Fatalf("Expected 2 options, got %d", len(req.Options))
+	}
+	if req.Options[0].ID != nil || req.Options[0].Text != "Plain" {
+		t.Errorf("Expected plain string option without ID, got %+v", req.Options[0])
+	}
+	if req.Options[1].ID == nil || *req.Options[1].ID != 7 || req.Options[1].Text != "Existing" {
+		t.Errorf("Expected option with ID 7, got %+v", req.Options[1])
+	}
+}
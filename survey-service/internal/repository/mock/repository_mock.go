@@ -2,6 +2,10 @@ package mock
 
 import (
 	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
 	"github.com/jackc/pgx/v5"
@@ -74,19 +78,37 @@ func (m *MockTx) Conn() *pgx.Conn {
 // MockRepository is a mock implementation of the SurveyRepositoryInterface
 type MockRepository struct {
 	// Mock behavior flags and return values
-	Surveys     map[int]*models.Survey
-	Questions   map[int]*models.Question
-	Options     map[int]*models.QuestionOption
-	SurveyCount int
-	ErrorMock   error
+	Surveys       map[int]*models.Survey
+	Sections      map[int]*models.Section
+	Questions     map[int]*models.Question
+	Options       map[int]*models.QuestionOption
+	Versions      map[int][]*models.SurveyVersion   // Keyed by survey ID, oldest first
+	History       []*models.SurveyStatusChange      // Oldest first
+	Transfers     []*models.SurveyOwnershipTransfer // Oldest first
+	Users         map[string]int                    // Registered users' IDs by email; every positive ID counts as a user
+	Collaborators []*models.SurveyCollaborator      // In the order they were invited
+	OrgMembers    map[int][]int                     // IDs of the members of each organization
+	LockHeld      bool                              // Simulates another replica holding the advisory lock
+	SurveyCount   int
+	ErrorMock     error
+
+	// ID sequences, so IDs are never reused after a delete (like SERIAL columns)
+	lastSurveyID   int
+	lastSectionID  int
+	lastQuestionID int
+	lastOptionID   int
 }
 
 // NewMockRepository creates a new instance of the mock repository
 func NewMockRepository() *MockRepository {
 	return &MockRepository{
-		Surveys:   make(map[int]*models.Survey),
-		Questions: make(map[int]*models.Question),
-		Options:   make(map[int]*models.QuestionOption),
+		Surveys:    make(map[int]*models.Survey),
+		Sections:   make(map[int]*models.Section),
+		Questions:  make(map[int]*models.Question),
+		Options:    make(map[int]*models.QuestionOption),
+		Versions:   make(map[int][]*models.SurveyVersion),
+		Users:      make(map[string]int),
+		OrgMembers: make(map[int][]int),
 	}
 }
 
@@ -114,7 +136,8 @@ func (m *MockRepository) CreateSurvey(ctx context.Context, survey *models.Survey
 		return 0, m.ErrorMock
 	}
 
-	survey.ID = len(m.Surveys) + 1
+	m.lastSurveyID++
+	survey.ID = m.lastSurveyID
 	m.Surveys[survey.ID] = survey
 	return survey.ID, nil
 }
@@ -129,19 +152,34 @@ func (m *MockRepository) GetSurvey(ctx context.Context, id int) (*models.Survey,
 	if !exists {
 		return nil, nil
 	}
+
+	// Attach sections and questions like the Postgres repository does
+	survey.Sections, _ = m.GetSectionsBySurveyID(ctx, id)
+	questions, _ := m.GetQuestionsBySurveyID(ctx, id)
+	survey.Questions = questions
 	return survey, nil
 }
 
-// ListSurveysByCreatorID mocks listing surveys by creator ID
-func (m *MockRepository) ListSurveysByCreatorID(ctx context.Context, creatorID int, offset, limit int) ([]*models.Survey, int, error) {
+// ListSurveysForUser mocks listing the surveys a user created or collaborates on
+func (m *MockRepository) ListSurveysForUser(ctx context.Context, orgID *int, userID int, status string, offset, limit int) ([]*models.Survey, int, error) {
 	if m.ErrorMock != nil {
 		return nil, 0, m.ErrorMock
 	}
 
+	now := time.Now()
 	var surveys []*models.Survey
 	for _, survey := range m.Surveys {
-		if survey.CreatorID == creatorID {
-			surveys = append(surveys, survey)
+		if !inOrg(survey, orgID) || (status != "" && survey.EffectiveStatus(now) != status) {
+			continue
+		}
+		role := m.collaboratorRole(survey.ID, userID)
+		if survey.CreatorID == userID {
+			role = models.AccessRoleOwner
+		}
+		if role != "" {
+			listed := *survey
+			listed.AccessRole = role
+			surveys = append(surveys, &listed)
 		}
 	}
 
@@ -160,14 +198,18 @@ func (m *MockRepository) ListSurveysByCreatorID(ctx context.Context, creatorID i
 	return surveys, totalCount, nil
 }
 
-// ListAllSurveys mocks listing all surveys
-func (m *MockRepository) ListAllSurveys(ctx context.Context, isUserAdmin bool, offset, limit int) ([]*models.Survey, int, error) {
+// ListAllSurveys mocks listing all surveys of an organization
+func (m *MockRepository) ListAllSurveys(ctx context.Context, orgID *int, isUserAdmin bool, status string, offset, limit int) ([]*models.Survey, int, error) {
 	if m.ErrorMock != nil {
 		return nil, 0, m.ErrorMock
 	}
 
+	now := time.Now()
 	var surveys []*models.Survey
 	for _, survey := range m.Surveys {
+		if !inOrg(survey, orgID) || (status != "" && survey.EffectiveStatus(now) != status) {
+			continue
+		}
 		// Include all surveys for admins, but only active surveys for non-admin users
 		if isUserAdmin || survey.IsActive {
 			surveys = append(surveys, survey)
@@ -228,13 +270,70 @@ func (m *MockRepository) UpdateSurveyStatus(ctx context.Context, id int, isActiv
 	return nil
 }
 
+// SetSurveyTemplateVisibility mocks marking a survey as a template
+func (m *MockRepository) SetSurveyTemplateVisibility(ctx context.Context, id int, visibility string) error {
+	if m.ErrorMock != nil {
+		return m.ErrorMock
+	}
+
+	survey, exists := m.Surveys[id]
+	if !exists {
+		return errors.New("survey not found")
+	}
+	survey.TemplateVisibility = visibility
+	return nil
+}
+
+// ListTemplates mocks listing the templates a user may start surveys from
+func (m *MockRepository) ListTemplates(ctx context.Context, orgID *int, userID int, isUserAdmin bool, offset, limit int) ([]*models.Survey, int, error) {
+	templates, err := m.listSurveysWhere(func(survey *models.Survey) bool {
+		if survey.TemplateVisibility == "" {
+			return false
+		}
+		if survey.TemplateVisibility == models.TemplateVisibilityGlobal {
+			return true
+		}
+		return inOrg(survey, orgID) && (isUserAdmin || survey.CreatorID == userID || survey.TemplateVisibility != models.TemplateVisibilityPrivate)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	totalCount := len(templates)
+	if offset >= len(templates) {
+		return []*models.Survey{}, totalCount, nil
+	}
+	end := offset + limit
+	if end > len(templates) {
+		end = len(templates)
+	}
+	return templates[offset:end], totalCount, nil
+}
+
+// GetSectionsBySurveyID mocks retrieving the sections of a survey in page order
+func (m *MockRepository) GetSectionsBySurveyID(ctx context.Context, surveyID int) ([]*models.Section, error) {
+	if m.ErrorMock != nil {
+		return nil, m.ErrorMock
+	}
+
+	sections := []*models.Section{}
+	for _, section := range m.Sections {
+		if section.SurveyID == surveyID {
+			sections = append(sections, section)
+		}
+	}
+	sort.Slice(sections, func(i, j int) bool { return sections[i].OrderNum < sections[j].OrderNum })
+	return sections, nil
+}
+
 // CreateQuestion mocks creating a question
 func (m *MockRepository) CreateQuestion(ctx context.Context, question *models.Question) (int, error) {
 	if m.ErrorMock != nil {
 		return 0, m.ErrorMock
 	}
 
-	question.ID = len(m.Questions) + 1
+	m.lastQuestionID++
+	question.ID = m.lastQuestionID
 	m.Questions[question.ID] = question
 	return question.ID, nil
 }
@@ -264,6 +363,13 @@ func (m *MockRepository) GetQuestionsBySurveyID(ctx context.Context, surveyID in
 			questions = append(questions, question)
 		}
 	}
+	sort.Slice(questions, func(i, j int) bool { return questions[i].OrderNum < questions[j].OrderNum })
+
+	// Attach options like the Postgres repository does
+	for _, question := range questions {
+		options, _ := m.GetQuestionOptionsByQuestionID(ctx, question.ID)
+		question.Options = options
+	}
 
 	return questions, nil
 }
@@ -289,6 +395,12 @@ func (m *MockRepository) DeleteQuestion(ctx context.Context, id int) error {
 	}
 
 	delete(m.Questions, id)
+	// Mirror ON DELETE CASCADE on question_options
+	for optionID, option := range m.Options {
+		if option.QuestionID == id {
+			delete(m.Options, optionID)
+		}
+	}
 	return nil
 }
 
@@ -298,7 +410,8 @@ func (m *MockRepository) CreateQuestionOption(ctx context.Context, option *model
 		return 0, m.ErrorMock
 	}
 
-	option.ID = len(m.Options) + 1
+	m.lastOptionID++
+	option.ID = m.lastOptionID
 	m.Options[option.ID] = option
 	return option.ID, nil
 }
@@ -315,6 +428,7 @@ func (m *MockRepository) GetQuestionOptionsByQuestionID(ctx context.Context, que
 			options = append(options, option)
 		}
 	}
+	sort.Slice(options, func(i, j int) bool { return options[i].OrderNum < options[j].OrderNum })
 
 	return options, nil
 }
@@ -346,6 +460,66 @@ func (m *MockRepository) UpdateSurveyTx(ctx context.Context, tx pgx.Tx, survey *
 	return m.UpdateSurvey(ctx, survey)
 }
 
+// UpdateSurveyCreatorTx mocks handing a survey to another user in a transaction
+func (m *MockRepository) UpdateSurveyCreatorTx(ctx context.Context, tx pgx.Tx, surveyID, creatorID int) error {
+	if m.ErrorMock != nil {
+		return m.ErrorMock
+	}
+
+	survey, exists := m.Surveys[surveyID]
+	if !exists {
+		return errors.New("survey not found")
+	}
+	survey.CreatorID = creatorID
+	return nil
+}
+
+// CreateSectionTx mocks creating a section in a transaction
+func (m *MockRepository) CreateSectionTx(ctx context.Context, tx pgx.Tx, section *models.Section) (int, error) {
+	if m.ErrorMock != nil {
+		return 0, m.ErrorMock
+	}
+
+	m.lastSectionID++
+	section.ID = m.lastSectionID
+	m.Sections[section.ID] = section
+	return section.ID, nil
+}
+
+// GetSectionsBySurveyIDTx mocks retrieving sections by survey ID in a transaction
+func (m *MockRepository) GetSectionsBySurveyIDTx(ctx context.Context, tx pgx.Tx, surveyID int) ([]*models.Section, error) {
+	return m.GetSectionsBySurveyID(ctx, surveyID)
+}
+
+// UpdateSectionTx mocks updating a section in a transaction
+func (m *MockRepository) UpdateSectionTx(ctx context.Context, tx pgx.Tx, section *models.Section) error {
+	if m.ErrorMock != nil {
+		return m.ErrorMock
+	}
+
+	if _, exists := m.Sections[section.ID]; !exists {
+		return errors.New("section not found during tx update")
+	}
+	m.Sections[section.ID] = section
+	return nil
+}
+
+// DeleteSectionTx mocks deleting a section in a transaction
+func (m *MockRepository) DeleteSectionTx(ctx context.Context, tx pgx.Tx, id int) error {
+	if m.ErrorMock != nil {
+		return m.ErrorMock
+	}
+
+	delete(m.Sections, id)
+	// Mirror ON DELETE SET NULL on questions.section_id
+	for _, question := range m.Questions {
+		if question.SectionID != nil && *question.SectionID == id {
+			question.SectionID = nil
+		}
+	}
+	return nil
+}
+
 // CreateQuestionTx mocks creating a question in a transaction
 func (m *MockRepository) CreateQuestionTx(ctx context.Context, tx pgx.Tx, question *models.Question) (int, error) {
 	return m.CreateQuestion(ctx, question)
@@ -371,6 +545,36 @@ func (m *MockRepository) CreateQuestionOptionTx(ctx context.Context, tx pgx.Tx,
 	return m.CreateQuestionOption(ctx, option)
 }
 
+// GetQuestionOptionsByQuestionIDTx mocks retrieving options by question ID in a transaction
+func (m *MockRepository) GetQuestionOptionsByQuestionIDTx(ctx context.Context, tx pgx.Tx, questionID int) ([]*models.QuestionOption, error) {
+	return m.GetQuestionOptionsByQuestionID(ctx, questionID)
+}
+
+// UpdateQuestionOptionTx mocks updating a question option in a transaction
+func (m *MockRepository) UpdateQuestionOptionTx(ctx context.Context, tx pgx.Tx, option *models.QuestionOption) error {
+	if m.ErrorMock != nil {
+		return m.ErrorMock
+	}
+
+	existing, exists := m.Options[option.ID]
+	if !exists || existing.QuestionID != option.QuestionID {
+		return errors.New("question option not found during tx update")
+	}
+
+	m.Options[option.ID] = option
+	return nil
+}
+
+// DeleteQuestionOptionTx mocks deleting a single question option in a transaction
+func (m *MockRepository) DeleteQuestionOptionTx(ctx context.Context, tx pgx.Tx, id int) error {
+	if m.ErrorMock != nil {
+		return m.ErrorMock
+	}
+
+	delete(m.Options, id)
+	return nil
+}
+
 // DeleteQuestionOptionsTx mocks deleting options for a question in a transaction
 func (m *MockRepository) DeleteQuestionOptionsTx(ctx context.Context, tx pgx.Tx, questionID int) error {
 	return m.DeleteQuestionOptions(ctx, questionID)
@@ -390,3 +594,301 @@ func (m *MockRepository) DeleteQuestionsBySurveyIDTx(ctx context.Context, tx pgx
 
 	return nil
 }
+
+// CreateSurveyVersionTx mocks storing a survey snapshot with the next version number
+func (m *MockRepository) CreateSurveyVersionTx(ctx context.Context, tx pgx.Tx, version *models.SurveyVersion) (int, error) {
+	if m.ErrorMock != nil {
+		return 0, m.ErrorMock
+	}
+
+	version.Version = len(m.Versions[version.SurveyID]) + 1
+	version.ID = version.Version
+	m.Versions[version.SurveyID] = append(m.Versions[version.SurveyID], version)
+	return version.Version, nil
+}
+
+// GetLatestSurveyVersionTx mocks retrieving the latest snapshot of a survey
+func (m *MockRepository) GetLatestSurveyVersionTx(ctx context.Context, tx pgx.Tx, surveyID int) (*models.SurveyVersion, error) {
+	if m.ErrorMock != nil {
+		return nil, m.ErrorMock
+	}
+
+	versions := m.Versions[surveyID]
+	if len(versions) == 0 {
+		return nil, nil
+	}
+	return versions[len(versions)-1], nil
+}
+
+// GetLatestSurveyVersionNumber mocks retrieving the latest version number of a survey
+func (m *MockRepository) GetLatestSurveyVersionNumber(ctx context.Context, surveyID int) (int, error) {
+	if m.ErrorMock != nil {
+		return 0, m.ErrorMock
+	}
+
+	return len(m.Versions[surveyID]), nil
+}
+
+// GetSurveyVersion mocks retrieving a specific snapshot of a survey
+func (m *MockRepository) GetSurveyVersion(ctx context.Context, surveyID, version int) (*models.SurveyVersion, error) {
+	if m.ErrorMock != nil {
+		return nil, m.ErrorMock
+	}
+
+	versions := m.Versions[surveyID]
+	if version < 1 || version > len(versions) {
+		return nil, errors.New("survey version not found")
+	}
+	return versions[version-1], nil
+}
+
+// ListSurveyVersions mocks listing the version history of a survey, newest first
+func (m *MockRepository) ListSurveyVersions(ctx context.Context, surveyID int) ([]*models.SurveyVersion, error) {
+	if m.ErrorMock != nil {
+		return nil, m.ErrorMock
+	}
+
+	versions := []*models.SurveyVersion{}
+	stored := m.Versions[surveyID]
+	for i := len(stored) - 1; i >= 0; i-- {
+		versions = append(versions, stored[i])
+	}
+	return versions, nil
+}
+
+// CreateSurveyStatusChange mocks recording a survey status change
+func (m *MockRepository) CreateSurveyStatusChange(ctx context.Context, change *models.SurveyStatusChange) error {
+	if m.ErrorMock != nil {
+		return m.ErrorMock
+	}
+
+	change.ID = len(m.History) + 1
+	change.ChangedAt = time.Now()
+	m.History = append(m.History, change)
+	return nil
+}
+
+// ListSurveyStatusHistory mocks listing the status changes of a survey, newest first
+func (m *MockRepository) ListSurveyStatusHistory(ctx context.Context, surveyID int) ([]*models.SurveyStatusChange, error) {
+	if m.ErrorMock != nil {
+		return nil, m.ErrorMock
+	}
+
+	history := []*models.SurveyStatusChange{}
+	for i := len(m.History) - 1; i >= 0; i-- {
+		if m.History[i].SurveyID == surveyID {
+			history = append(history, m.History[i])
+		}
+	}
+	return history, nil
+}
+
+// UserExists mocks looking up a user; every positive ID is taken as registered
+func (m *MockRepository) UserExists(ctx context.Context, userID int) (bool, error) {
+	if m.ErrorMock != nil {
+		return false, m.ErrorMock
+	}
+	return userID > 0, nil
+}
+
+// GetUserIDByEmail mocks looking up a user by email in Users
+func (m *MockRepository) GetUserIDByEmail(ctx context.Context, email string) (int, error) {
+	if m.ErrorMock != nil {
+		return 0, m.ErrorMock
+	}
+	return m.Users[strings.ToLower(email)], nil
+}
+
+// IsOrgMember mocks looking up a membership in OrgMembers
+func (m *MockRepository) IsOrgMember(ctx context.Context, orgID, userID int) (bool, error) {
+	if m.ErrorMock != nil {
+		return false, m.ErrorMock
+	}
+	for _, memberID := range m.OrgMembers[orgID] {
+		if memberID == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// inOrg reports whether a survey belongs to the organization orgID, or to none if orgID is nil
+func inOrg(survey *models.Survey, orgID *int) bool {
+	if survey.OrgID == nil || orgID == nil {
+		return survey.OrgID == nil && orgID == nil
+	}
+	return *survey.OrgID == *orgID
+}
+
+// CreateOwnershipTransferTx mocks recording an ownership transfer in a transaction
+func (m *MockRepository) CreateOwnershipTransferTx(ctx context.Context, tx pgx.Tx, transfer *models.SurveyOwnershipTransfer) error {
+	if m.ErrorMock != nil {
+		return m.ErrorMock
+	}
+
+	transfer.ID = len(m.Transfers) + 1
+	transfer.TransferredAt = time.Now()
+	m.Transfers = append(m.Transfers, transfer)
+	return nil
+}
+
+// ListOwnershipTransfers mocks listing the ownership transfers of a survey, newest first
+func (m *MockRepository) ListOwnershipTransfers(ctx context.Context, surveyID int) ([]*models.SurveyOwnershipTransfer, error) {
+	if m.ErrorMock != nil {
+		return nil, m.ErrorMock
+	}
+
+	transfers := []*models.SurveyOwnershipTransfer{}
+	for i := len(m.Transfers) - 1; i >= 0; i-- {
+		if m.Transfers[i].SurveyID == surveyID {
+			transfers = append(transfers, m.Transfers[i])
+		}
+	}
+	return transfers, nil
+}
+
+// ListSurveysDueToOpen mocks listing surveys whose recorded opening is due
+func (m *MockRepository) ListSurveysDueToOpen(ctx context.Context) ([]*models.Survey, error) {
+	now := time.Now()
+	return m.listSurveysWhere(func(survey *models.Survey) bool {
+		if survey.EffectiveStatus(now) != models.SurveyStatusOpen || survey.StartDate.IsZero() || !survey.StartDate.After(survey.CreatedAt) {
+			return false
+		}
+		for _, change := range m.History {
+			if change.SurveyID == survey.ID && !change.ChangedAt.Before(survey.StartDate) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// ListSurveysDueToClose mocks listing active surveys whose end date has passed
+func (m *MockRepository) ListSurveysDueToClose(ctx context.Context) ([]*models.Survey, error) {
+	now := time.Now()
+	return m.listSurveysWhere(func(survey *models.Survey) bool {
+		return survey.IsActive && !survey.EndDate.IsZero() && !now.Before(survey.EndDate)
+	})
+}
+
+// ListActiveSurveysWithQuota mocks listing active surveys that have a response quota
+func (m *MockRepository) ListActiveSurveysWithQuota(ctx context.Context) ([]*models.Survey, error) {
+	return m.listSurveysWhere(func(survey *models.Survey) bool {
+		return survey.IsActive && survey.MaxResponses != nil
+	})
+}
+
+// ListSurveysWithQuestionLogic mocks listing surveys with a question that has display or skip rules or pipes an answer
+func (m *MockRepository) ListSurveysWithQuestionLogic(ctx context.Context) ([]*models.Survey, error) {
+	withLogic := make(map[int]bool)
+	for _, q := range m.Questions {
+		if len(q.DisplayRules) > 0 || len(q.SkipRules) > 0 || strings.Contains(q.Text, "{{") {
+			withLogic[q.SurveyID] = true
+		}
+	}
+	return m.listSurveysWhere(func(survey *models.Survey) bool { return withLogic[survey.ID] })
+}
+
+func (m *MockRepository) listSurveysWhere(match func(survey *models.Survey) bool) ([]*models.Survey, error) {
+	if m.ErrorMock != nil {
+		return nil, m.ErrorMock
+	}
+
+	var surveys []*models.Survey
+	for _, survey := range m.Surveys {
+		if match(survey) {
+			surveys = append(surveys, survey)
+		}
+	}
+	sort.Slice(surveys, func(i, j int) bool { return surveys[i].ID < surveys[j].ID })
+	return surveys, nil
+}
+
+// WithAdvisoryLock mocks running fn under an advisory lock, skipping it while LockHeld is set
+func (m *MockRepository) WithAdvisoryLock(ctx context.Context, key int64, fn func(ctx context.Context) error) (bool, error) {
+	if m.LockHeld {
+		return false, nil
+	}
+	return true, fn(ctx)
+}
+
+// GetCollaboratorRole mocks looking up a user's collaborator role on a survey
+func (m *MockRepository) GetCollaboratorRole(ctx context.Context, surveyID, userID int) (string, error) {
+	if m.ErrorMock != nil {
+		return "", m.ErrorMock
+	}
+	return m.collaboratorRole(surveyID, userID), nil
+}
+
+func (m *MockRepository) collaboratorRole(surveyID, userID int) string {
+	for _, collaborator := range m.Collaborators {
+		if collaborator.SurveyID == surveyID && collaborator.UserID == userID {
+			return collaborator.Role
+		}
+	}
+	return ""
+}
+
+// UpsertCollaborator mocks adding a collaborator or changing their role
+func (m *MockRepository) UpsertCollaborator(ctx context.Context, collaborator *models.SurveyCollaborator) error {
+	if m.ErrorMock != nil {
+		return m.ErrorMock
+	}
+
+	for _, existing := range m.Collaborators {
+		if existing.SurveyID == collaborator.SurveyID && existing.UserID == collaborator.UserID {
+			existing.Role = collaborator.Role
+			collaborator.InvitedBy, collaborator.CreatedAt = existing.InvitedBy, existing.CreatedAt
+			return nil
+		}
+	}
+	collaborator.CreatedAt = time.Now()
+	saved := *collaborator
+	m.Collaborators = append(m.Collaborators, &saved)
+	return nil
+}
+
+// ListCollaborators mocks listing the collaborators of a survey, with emails from Users
+func (m *MockRepository) ListCollaborators(ctx context.Context, surveyID int) ([]*models.SurveyCollaborator, error) {
+	if m.ErrorMock != nil {
+		return nil, m.ErrorMock
+	}
+
+	collaborators := []*models.SurveyCollaborator{}
+	for _, collaborator := range m.Collaborators {
+		if collaborator.SurveyID != surveyID {
+			continue
+		}
+		listed := *collaborator
+		for email, id := range m.Users {
+			if id == collaborator.UserID {
+				listed.Email = email
+			}
+		}
+		collaborators = append(collaborators, &listed)
+	}
+	return collaborators, nil
+}
+
+// DeleteCollaborator mocks removing a collaborator from a survey
+func (m *MockRepository) DeleteCollaborator(ctx context.Context, surveyID, userID int) error {
+	if m.ErrorMock != nil {
+		return m.ErrorMock
+	}
+
+	for i, collaborator := range m.Collaborators {
+		if collaborator.SurveyID == surveyID && collaborator.UserID == userID {
+			m.Collaborators = append(m.Collaborators[:i], m.Collaborators[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("collaborator not found")
+}
+
+// DeleteCollaboratorTx mocks removing a user from a survey's collaborators in a transaction, if they are one
+func (m *MockRepository) DeleteCollaboratorTx(ctx context.Context, tx pgx.Tx, surveyID, userID int) error {
+	if err := m.DeleteCollaborator(ctx, surveyID, userID); err != nil && m.ErrorMock != nil {
+		return err
+	}
+	return nil
+}
@@ -72,7 +72,7 @@ func TestMockRepositorySurveyOperations(t *testing.T) {
 	}
 
 	// List surveys
-	surveys, count, err := repo.ListSurveysByCreatorID(ctx, 1, 0, 10)
+	surveys, count, err := repo.ListSurveysForUser(ctx, nil, 1, "", 0, 10)
 	if err != nil {
 		t.Fatalf("Unexpected error listing surveys: %v", err)
 	}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/VitaliySynytskyi/survey-platform/shared/questiontypes"
 	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
 )
 
@@ -23,6 +25,28 @@ func NewPostgresRepository(db *pgxpool.Pool) *PostgresRepository {
 	return &PostgresRepository{db: db}
 }
 
+// Schedule columns as nullable timestamps. Rows written before dates were stored as NULL
+// hold Go's zero time, which NULLIF maps back to "no date".
+const (
+	sqlStartDate = "NULLIF(start_date, '0001-01-01 00:00:00+00')"
+	sqlEndDate   = "NULLIF(end_date, '0001-01-01 00:00:00+00')"
+)
+
+// surveyStatusConditions are SQL conditions matching models.Survey.EffectiveStatus for each status
+var surveyStatusConditions = map[string]string{
+	models.SurveyStatusScheduled: "is_active AND " + sqlStartDate + " > NOW() AND COALESCE(" + sqlEndDate + " > NOW(), TRUE)",
+	models.SurveyStatusOpen:      "is_active AND COALESCE(" + sqlStartDate + " <= NOW(), TRUE) AND COALESCE(" + sqlEndDate + " > NOW(), TRUE)",
+	models.SurveyStatusClosed:    "(NOT is_active OR " + sqlEndDate + " <= NOW())",
+}
+
+// nullableTime stores unset survey dates as NULL
+func nullableTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
 // Transaction management
 func (r *PostgresRepository) BeginTx(ctx context.Context) (pgx.Tx, error) {
 	return r.db.Begin(ctx)
@@ -39,8 +63,8 @@ func (r *PostgresRepository) RollbackTx(ctx context.Context, tx pgx.Tx) error {
 // CreateSurvey creates a new survey in the database
 func (r *PostgresRepository) CreateSurvey(ctx context.Context, survey *models.Survey) (int, error) {
 	query := `
-		INSERT INTO surveys (creator_id, title, description, is_active, start_date, end_date)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO surveys (creator_id, title, description, is_active, start_date, end_date, max_responses, one_response_per_user, anonymous_limit, allow_anonymous, allow_response_edits, org_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id, created_at, updated_at
 	`
 
@@ -52,8 +76,14 @@ func (r *PostgresRepository) CreateSurvey(ctx context.Context, survey *models.Su
 		survey.Title,
 		survey.Description,
 		survey.IsActive,
-		survey.StartDate,
-		survey.EndDate,
+		nullableTime(survey.StartDate),
+		nullableTime(survey.EndDate),
+		survey.MaxResponses,
+		survey.OneResponsePerUser,
+		survey.AnonymousLimit,
+		survey.AllowAnonymous,
+		survey.AllowResponseEdits,
+		survey.OrgID,
 	).Scan(&id, &createdAt, &updatedAt)
 
 	if err != nil {
@@ -70,8 +100,8 @@ func (r *PostgresRepository) CreateSurvey(ctx context.Context, survey *models.Su
 // CreateSurveyTx creates a new survey in the database using a transaction
 func (r *PostgresRepository) CreateSurveyTx(ctx context.Context, tx pgx.Tx, survey *models.Survey) (int, error) {
 	query := `
-		INSERT INTO surveys (creator_id, title, description, is_active, start_date, end_date)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO surveys (creator_id, title, description, is_active, start_date, end_date, max_responses, one_response_per_user, anonymous_limit, allow_anonymous, allow_response_edits, org_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id, created_at, updated_at
 	`
 
@@ -83,8 +113,14 @@ func (r *PostgresRepository) CreateSurveyTx(ctx context.Context, tx pgx.Tx, surv
 		survey.Title,
 		survey.Description,
 		survey.IsActive,
-		survey.StartDate,
-		survey.EndDate,
+		nullableTime(survey.StartDate),
+		nullableTime(survey.EndDate),
+		survey.MaxResponses,
+		survey.OneResponsePerUser,
+		survey.AnonymousLimit,
+		survey.AllowAnonymous,
+		survey.AllowResponseEdits,
+		survey.OrgID,
 	).Scan(&id, &createdAt, &updatedAt)
 
 	if err != nil {
@@ -101,7 +137,7 @@ func (r *PostgresRepository) CreateSurveyTx(ctx context.Context, tx pgx.Tx, surv
 // GetSurvey retrieves a survey by its ID
 func (r *PostgresRepository) GetSurvey(ctx context.Context, id int) (*models.Survey, error) {
 	query := `
-		SELECT id, creator_id, title, description, is_active, start_date, end_date, created_at, updated_at
+		SELECT id, creator_id, org_id, title, description, is_active, start_date, end_date, max_responses, one_response_per_user, anonymous_limit, allow_anonymous, allow_response_edits, template_visibility, created_at, updated_at
 		FROM surveys
 		WHERE id = $1
 	`
@@ -112,11 +148,18 @@ func (r *PostgresRepository) GetSurvey(ctx context.Context, id int) (*models.Sur
 	err := r.db.QueryRow(ctx, query, id).Scan(
 		&survey.ID,
 		&survey.CreatorID,
+		&survey.OrgID,
 		&survey.Title,
 		&survey.Description,
 		&survey.IsActive,
 		&startDate,
 		&endDate,
+		&survey.MaxResponses,
+		&survey.OneResponsePerUser,
+		&survey.AnonymousLimit,
+		&survey.AllowAnonymous,
+		&survey.AllowResponseEdits,
+		&survey.TemplateVisibility,
 		&survey.CreatedAt,
 		&survey.UpdatedAt,
 	)
@@ -136,7 +179,13 @@ func (r *PostgresRepository) GetSurvey(ctx context.Context, id int) (*models.Sur
 		survey.EndDate = *endDate
 	}
 
-	// Get questions
+	// Get sections and questions
+	sections, err := r.GetSectionsBySurveyID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	survey.Sections = sections
+
 	questions, err := r.GetQuestionsBySurveyID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -146,31 +195,40 @@ func (r *PostgresRepository) GetSurvey(ctx context.Context, id int) (*models.Sur
 	return &survey, nil
 }
 
-// ListSurveysByCreatorID retrieves paginated surveys created by a specific user.
-func (r *PostgresRepository) ListSurveysByCreatorID(ctx context.Context, creatorID int, offset, limit int) ([]*models.Survey, int, error) {
-	log.Printf("[REPO_IMPL] ListSurveysByCreatorID called for creatorID: %d, Offset: %d, Limit: %d", creatorID, offset, limit)
+// ListSurveysForUser retrieves paginated surveys of an organization (nil for surveys outside every organization) that a
+// specific user created or collaborates on, optionally filtered by effective status, with the user's AccessRole on each.
+// An empty status lists surveys in every status.
+func (r *PostgresRepository) ListSurveysForUser(ctx context.Context, orgID *int, userID int, status string, offset, limit int) ([]*models.Survey, int, error) {
+	log.Printf("[REPO_IMPL] ListSurveysForUser called for userID: %d, Status: %q, Offset: %d, Limit: %d", userID, status, offset, limit)
+
+	whereConditions := "WHERE org_id IS NOT DISTINCT FROM $2 AND (creator_id = $1 OR id IN (SELECT survey_id FROM survey_collaborators WHERE user_id = $1))"
+	if condition, ok := surveyStatusConditions[status]; ok {
+		whereConditions += " AND " + condition
+	}
 
 	dataQuery := `
-		SELECT id, creator_id, title, description, is_active, start_date, end_date, created_at, updated_at
+		SELECT id, creator_id, org_id, title, description, is_active, start_date, end_date, max_responses, one_response_per_user, anonymous_limit, allow_anonymous, allow_response_edits, template_visibility, created_at, updated_at,
+			CASE WHEN creator_id = $1 THEN 'owner'
+				ELSE (SELECT role FROM survey_collaborators c WHERE c.survey_id = surveys.id AND c.user_id = $1) END
 		FROM surveys
-		WHERE creator_id = $1
+		` + whereConditions + `
 		ORDER BY updated_at DESC
-		LIMIT $2 OFFSET $3
+		LIMIT $3 OFFSET $4
 	`
-	countQuery := `SELECT COUNT(*) FROM surveys WHERE creator_id = $1`
+	countQuery := `SELECT COUNT(*) FROM surveys ` + whereConditions
 
 	// Get total count
 	var total int
-	err := r.db.QueryRow(ctx, countQuery, creatorID).Scan(&total)
+	err := r.db.QueryRow(ctx, countQuery, userID, orgID).Scan(&total)
 	if err != nil {
-		log.Printf("[REPO_ERROR] ListSurveysByCreatorID: r.db.QueryRow for count failed: %v", err)
-		return nil, 0, fmt.Errorf("failed to count surveys by creator ID %d: %w", creatorID, err)
+		log.Printf("[REPO_ERROR] ListSurveysForUser: r.db.QueryRow for count failed: %v", err)
+		return nil, 0, fmt.Errorf("failed to count surveys of user %d: %w", userID, err)
 	}
 
-	rows, err := r.db.Query(ctx, dataQuery, creatorID, limit, offset)
+	rows, err := r.db.Query(ctx, dataQuery, userID, orgID, limit, offset)
 	if err != nil {
-		log.Printf("[REPO_ERROR] ListSurveysByCreatorID: r.db.Query for data failed: %v", err)
-		return nil, total, fmt.Errorf("failed to query surveys by creator ID %d: %w", creatorID, err)
+		log.Printf("[REPO_ERROR] ListSurveysForUser: r.db.Query for data failed: %v", err)
+		return nil, total, fmt.Errorf("failed to query surveys of user %d: %w", userID, err)
 	}
 	defer rows.Close()
 
@@ -179,11 +237,11 @@ func (r *PostgresRepository) ListSurveysByCreatorID(ctx context.Context, creator
 		var survey models.Survey
 		var startDate, endDate *time.Time
 		err := rows.Scan(
-			&survey.ID, &survey.CreatorID, &survey.Title, &survey.Description,
-			&survey.IsActive, &startDate, &endDate, &survey.CreatedAt, &survey.UpdatedAt,
+			&survey.ID, &survey.CreatorID, &survey.OrgID, &survey.Title, &survey.Description,
+			&survey.IsActive, &startDate, &endDate, &survey.MaxResponses, &survey.OneResponsePerUser, &survey.AnonymousLimit, &survey.AllowAnonymous, &survey.AllowResponseEdits, &survey.TemplateVisibility, &survey.CreatedAt, &survey.UpdatedAt, &survey.AccessRole,
 		)
 		if err != nil {
-			log.Printf("[REPO_ERROR] ListSurveysByCreatorID: rows.Scan failed: %v", err)
+			log.Printf("[REPO_ERROR] ListSurveysForUser: rows.Scan failed: %v", err)
 			return nil, total, fmt.Errorf("failed to scan survey row: %w", err)
 		}
 		if startDate != nil {
@@ -195,7 +253,7 @@ func (r *PostgresRepository) ListSurveysByCreatorID(ctx context.Context, creator
 
 		questions, err := r.GetQuestionsBySurveyID(ctx, survey.ID) // N+1 query
 		if err != nil {
-			log.Printf("[REPO_ERROR] ListSurveysByCreatorID: GetQuestionsBySurveyID for survey %d failed: %v", survey.ID, err)
+			log.Printf("[REPO_ERROR] ListSurveysForUser: GetQuestionsBySurveyID for survey %d failed: %v", survey.ID, err)
 			return nil, total, fmt.Errorf("failed to get questions for survey ID %d: %w", survey.ID, err)
 		}
 		survey.Questions = questions
@@ -203,32 +261,39 @@ func (r *PostgresRepository) ListSurveysByCreatorID(ctx context.Context, creator
 	}
 
 	if err = rows.Err(); err != nil {
-		log.Printf("[REPO_ERROR] ListSurveysByCreatorID: rows.Err() after loop: %v", err)
+		log.Printf("[REPO_ERROR] ListSurveysForUser: rows.Err() after loop: %v", err)
 		return nil, total, fmt.Errorf("error iterating survey rows: %w", err)
 	}
 
-	log.Printf("[REPO_INFO] ListSurveysByCreatorID: Found %d surveys (total %d) for creatorID: %d", len(surveys), total, creatorID)
+	log.Printf("[REPO_INFO] ListSurveysForUser: Found %d surveys (total %d) for userID: %d", len(surveys), total, userID)
 	return surveys, total, nil
 }
 
-// ListAllSurveys retrieves all surveys paginated (optionally filtered for non-admins).
-func (r *PostgresRepository) ListAllSurveys(ctx context.Context, isUserAdmin bool, offset, limit int) ([]*models.Survey, int, error) {
-	log.Printf("[REPO_IMPL] ListAllSurveys called. IsAdmin: %t, Offset: %d, Limit: %d", isUserAdmin, offset, limit)
+// ListAllSurveys retrieves all surveys of an organization (nil for surveys outside every organization) paginated,
+// optionally filtered for non-admins and by effective status.
+func (r *PostgresRepository) ListAllSurveys(ctx context.Context, orgID *int, isUserAdmin bool, status string, offset, limit int) ([]*models.Survey, int, error) {
+	log.Printf("[REPO_IMPL] ListAllSurveys called. IsAdmin: %t, Status: %q, Offset: %d, Limit: %d", isUserAdmin, status, offset, limit)
 
 	var dataQuery strings.Builder
 	var countQuery strings.Builder
 	var queryArgs []interface{}
 	var countArgs []interface{}
 
-	dataQuery.WriteString("SELECT id, creator_id, title, description, is_active, start_date, end_date, created_at, updated_at FROM surveys")
+	dataQuery.WriteString("SELECT id, creator_id, org_id, title, description, is_active, start_date, end_date, max_responses, one_response_per_user, anonymous_limit, allow_anonymous, allow_response_edits, template_visibility, created_at, updated_at FROM surveys")
 	countQuery.WriteString("SELECT COUNT(*) FROM surveys")
 
-	whereConditions := ""
+	// Every user, admins included, only sees the surveys of their own organization
+	whereConditions := " WHERE org_id IS NOT DISTINCT FROM $1"
+	queryArgs = append(queryArgs, orgID)
+	countArgs = append(countArgs, orgID)
 	if !isUserAdmin {
-		whereConditions = " WHERE is_active = $1"
+		whereConditions += " AND is_active = $2"
 		queryArgs = append(queryArgs, true) // Argument for is_active in data query
 		countArgs = append(countArgs, true) // Argument for is_active in count query
 	}
+	if condition, ok := surveyStatusConditions[status]; ok {
+		whereConditions += " AND " + condition
+	}
 
 	dataQuery.WriteString(whereConditions)
 	countQuery.WriteString(whereConditions)
@@ -258,8 +323,8 @@ func (r *PostgresRepository) ListAllSurveys(ctx context.Context, isUserAdmin boo
 		var survey models.Survey
 		var startDate, endDate *time.Time
 		err := rows.Scan(
-			&survey.ID, &survey.CreatorID, &survey.Title, &survey.Description,
-			&survey.IsActive, &startDate, &endDate, &survey.CreatedAt, &survey.UpdatedAt,
+			&survey.ID, &survey.CreatorID, &survey.OrgID, &survey.Title, &survey.Description,
+			&survey.IsActive, &startDate, &endDate, &survey.MaxResponses, &survey.OneResponsePerUser, &survey.AnonymousLimit, &survey.AllowAnonymous, &survey.AllowResponseEdits, &survey.TemplateVisibility, &survey.CreatedAt, &survey.UpdatedAt,
 		)
 		if err != nil {
 			log.Printf("[REPO_ERROR] ListAllSurveys: rows.Scan failed: %v", err)
@@ -294,7 +359,7 @@ func (r *PostgresRepository) ListAllSurveys(ctx context.Context, isUserAdmin boo
 func (r *PostgresRepository) GetSurveysByCreatorID(ctx context.Context, creatorID int) ([]*models.Survey, error) {
 	log.Printf("[REPO_IMPL] GetSurveysByCreatorID called for creatorID: %d", creatorID)
 	query := `
-		SELECT id, creator_id, title, description, is_active, start_date, end_date, created_at, updated_at
+		SELECT id, creator_id, title, description, is_active, start_date, end_date, max_responses, one_response_per_user, anonymous_limit, allow_anonymous, allow_response_edits, created_at, updated_at
 		FROM surveys
 		WHERE creator_id = $1
 		ORDER BY updated_at DESC
@@ -320,6 +385,11 @@ func (r *PostgresRepository) GetSurveysByCreatorID(ctx context.Context, creatorI
 			&survey.IsActive,
 			&startDate, // Scan into nullable time pointers
 			&endDate,
+			&survey.MaxResponses,
+			&survey.OneResponsePerUser,
+			&survey.AnonymousLimit,
+			&survey.AllowAnonymous,
+			&survey.AllowResponseEdits,
 			&survey.CreatedAt,
 			&survey.UpdatedAt,
 		)
@@ -369,7 +439,7 @@ func (r *PostgresRepository) GetSurveysByCreatorID(ctx context.Context, creatorI
 func (r *PostgresRepository) GetAllSurveys(ctx context.Context) ([]*models.Survey, error) {
 	log.Println("[REPO_IMPL] GetAllSurveys called")
 	query := `
-		SELECT id, creator_id, title, description, is_active, start_date, end_date, created_at, updated_at
+		SELECT id, creator_id, title, description, is_active, start_date, end_date, max_responses, one_response_per_user, anonymous_limit, allow_anonymous, allow_response_edits, created_at, updated_at
 		FROM surveys
 		ORDER BY updated_at DESC
 	`
@@ -394,6 +464,11 @@ func (r *PostgresRepository) GetAllSurveys(ctx context.Context) ([]*models.Surve
 			&survey.IsActive,
 			&startDate,
 			&endDate,
+			&survey.MaxResponses,
+			&survey.OneResponsePerUser,
+			&survey.AnonymousLimit,
+			&survey.AllowAnonymous,
+			&survey.AllowResponseEdits,
 			&survey.CreatedAt,
 			&survey.UpdatedAt,
 		)
@@ -438,8 +513,9 @@ func (r *PostgresRepository) GetAllSurveys(ctx context.Context) ([]*models.Surve
 func (r *PostgresRepository) UpdateSurvey(ctx context.Context, survey *models.Survey) error {
 	query := `
 		UPDATE surveys
-		SET title = $1, description = $2, is_active = $3, start_date = $4, end_date = $5, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $6
+		SET title = $1, description = $2, is_active = $3, start_date = $4, end_date = $5, max_responses = $6,
+			one_response_per_user = $7, anonymous_limit = $8, allow_anonymous = $9, allow_response_edits = $10, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $11
 		RETURNING updated_at
 	`
 
@@ -448,8 +524,13 @@ func (r *PostgresRepository) UpdateSurvey(ctx context.Context, survey *models.Su
 		survey.Title,
 		survey.Description,
 		survey.IsActive,
-		survey.StartDate,
-		survey.EndDate,
+		nullableTime(survey.StartDate),
+		nullableTime(survey.EndDate),
+		survey.MaxResponses,
+		survey.OneResponsePerUser,
+		survey.AnonymousLimit,
+		survey.AllowAnonymous,
+		survey.AllowResponseEdits,
 		survey.ID,
 	).Scan(&updatedAt)
 
@@ -468,8 +549,9 @@ func (r *PostgresRepository) UpdateSurvey(ctx context.Context, survey *models.Su
 func (r *PostgresRepository) UpdateSurveyTx(ctx context.Context, tx pgx.Tx, survey *models.Survey) error {
 	query := `
 		UPDATE surveys
-		SET title = $1, description = $2, is_active = $3, start_date = $4, end_date = $5, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $6
+		SET title = $1, description = $2, is_active = $3, start_date = $4, end_date = $5, max_responses = $6,
+			one_response_per_user = $7, anonymous_limit = $8, allow_anonymous = $9, allow_response_edits = $10, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $11
 		RETURNING updated_at
 	`
 	var updatedAt time.Time
@@ -477,8 +559,13 @@ func (r *PostgresRepository) UpdateSurveyTx(ctx context.Context, tx pgx.Tx, surv
 		survey.Title,
 		survey.Description,
 		survey.IsActive,
-		survey.StartDate,
-		survey.EndDate,
+		nullableTime(survey.StartDate),
+		nullableTime(survey.EndDate),
+		survey.MaxResponses,
+		survey.OneResponsePerUser,
+		survey.AnonymousLimit,
+		survey.AllowAnonymous,
+		survey.AllowResponseEdits,
 		survey.ID,
 	).Scan(&updatedAt)
 
@@ -526,23 +613,194 @@ func (r *PostgresRepository) DeleteSurvey(ctx context.Context, id int) error {
 	return nil
 }
 
+// SetSurveyTemplateVisibility marks a survey as a template with the given visibility, or unmarks it for an empty one
+func (r *PostgresRepository) SetSurveyTemplateVisibility(ctx context.Context, id int, visibility string) error {
+	result, err := r.db.Exec(ctx, "UPDATE surveys SET template_visibility = $1, updated_at = NOW() WHERE id = $2", visibility, id)
+	if err != nil {
+		return fmt.Errorf("failed to set template visibility of survey %d: %w", id, err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("survey not found")
+	}
+	return nil
+}
+
+// ListTemplates retrieves paginated templates a user of an organization (nil outside every organization) may start
+// surveys from: global ones, and those of the organization that are their own or shared with it. Admins get all
+// templates of the organization.
+func (r *PostgresRepository) ListTemplates(ctx context.Context, orgID *int, userID int, isUserAdmin bool, offset, limit int) ([]*models.Survey, int, error) {
+	whereConditions := `WHERE template_visibility <> '' AND (template_visibility = 'global'
+		OR (org_id IS NOT DISTINCT FROM $3 AND (creator_id = $1 OR template_visibility = 'org' OR $2)))`
+
+	dataQuery := `
+		SELECT id, creator_id, org_id, title, description, is_active, start_date, end_date, max_responses, one_response_per_user, anonymous_limit, allow_anonymous, allow_response_edits, template_visibility, created_at, updated_at
+		FROM surveys
+		` + whereConditions + `
+		ORDER BY updated_at DESC
+		LIMIT $4 OFFSET $5
+	`
+	countQuery := `SELECT COUNT(*) FROM surveys ` + whereConditions
+
+	var total int
+	if err := r.db.QueryRow(ctx, countQuery, userID, isUserAdmin, orgID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count templates: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, dataQuery, userID, isUserAdmin, orgID, limit, offset)
+	if err != nil {
+		return nil, total, fmt.Errorf("failed to query templates: %w", err)
+	}
+	defer rows.Close()
+
+	var surveys []*models.Survey
+	for rows.Next() {
+		var survey models.Survey
+		var startDate, endDate *time.Time
+		err := rows.Scan(
+			&survey.ID, &survey.CreatorID, &survey.OrgID, &survey.Title, &survey.Description,
+			&survey.IsActive, &startDate, &endDate, &survey.MaxResponses, &survey.OneResponsePerUser, &survey.AnonymousLimit, &survey.AllowAnonymous, &survey.AllowResponseEdits, &survey.TemplateVisibility, &survey.CreatedAt, &survey.UpdatedAt,
+		)
+		if err != nil {
+			return nil, total, fmt.Errorf("failed to scan template row: %w", err)
+		}
+		if startDate != nil {
+			survey.StartDate = *startDate
+		}
+		if endDate != nil {
+			survey.EndDate = *endDate
+		}
+		surveys = append(surveys, &survey)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, total, fmt.Errorf("error iterating template rows: %w", err)
+	}
+
+	// Attach questions so the library can preview what a template asks
+	for _, survey := range surveys {
+		questions, err := r.GetQuestionsBySurveyID(ctx, survey.ID)
+		if err != nil {
+			return nil, total, fmt.Errorf("failed to get questions for template %d: %w", survey.ID, err)
+		}
+		survey.Questions = questions
+	}
+	return surveys, total, nil
+}
+
+// selectSectionsQuery lists the sections of a survey in page order
+const selectSectionsQuery = `
+	SELECT id, survey_id, title, description, order_num, randomize_questions, created_at, updated_at
+	FROM survey_sections
+	WHERE survey_id = $1
+	ORDER BY order_num
+`
+
+// GetSectionsBySurveyID retrieves all sections of a survey in page order
+func (r *PostgresRepository) GetSectionsBySurveyID(ctx context.Context, surveyID int) ([]*models.Section, error) {
+	rows, err := r.db.Query(ctx, selectSectionsQuery, surveyID)
+	if err != nil {
+		return nil, err
+	}
+	return scanSections(rows)
+}
+
+// GetSectionsBySurveyIDTx retrieves all sections of a survey in page order using a transaction
+func (r *PostgresRepository) GetSectionsBySurveyIDTx(ctx context.Context, tx pgx.Tx, surveyID int) ([]*models.Section, error) {
+	rows, err := tx.Query(ctx, selectSectionsQuery, surveyID)
+	if err != nil {
+		return nil, err
+	}
+	return scanSections(rows)
+}
+
+// scanSections scans survey_sections rows, returning an empty slice when there are none
+func scanSections(rows pgx.Rows) ([]*models.Section, error) {
+	defer rows.Close()
+	sections := []*models.Section{}
+	for rows.Next() {
+		var section models.Section
+		if err := rows.Scan(
+			&section.ID, &section.SurveyID, &section.Title, &section.Description,
+			&section.OrderNum, &section.RandomizeQuestions, &section.CreatedAt, &section.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		sections = append(sections, &section)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return sections, nil
+}
+
+// CreateSectionTx creates a new section in the database using a transaction
+func (r *PostgresRepository) CreateSectionTx(ctx context.Context, tx pgx.Tx, section *models.Section) (int, error) {
+	query := `
+		INSERT INTO survey_sections (survey_id, title, description, order_num, randomize_questions)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+	err := tx.QueryRow(ctx, query,
+		section.SurveyID, section.Title, section.Description, section.OrderNum, section.RandomizeQuestions,
+	).Scan(&section.ID, &section.CreatedAt, &section.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return section.ID, nil
+}
+
+// UpdateSectionTx updates a section in the database using a transaction
+func (r *PostgresRepository) UpdateSectionTx(ctx context.Context, tx pgx.Tx, section *models.Section) error {
+	query := `
+		UPDATE survey_sections
+		SET title = $1, description = $2, order_num = $3, randomize_questions = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5
+		RETURNING updated_at
+	`
+	err := tx.QueryRow(ctx, query,
+		section.Title, section.Description, section.OrderNum, section.RandomizeQuestions, section.ID,
+	).Scan(&section.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("section not found during tx update")
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteSectionTx deletes a section using a transaction. Its questions are kept without a section.
+func (r *PostgresRepository) DeleteSectionTx(ctx context.Context, tx pgx.Tx, id int) error {
+	_, err := tx.Exec(ctx, `DELETE FROM survey_sections WHERE id = $1`, id)
+	return err
+}
+
 // CreateQuestion creates a new question in the database
 func (r *PostgresRepository) CreateQuestion(ctx context.Context, question *models.Question) (int, error) {
 	query := `
-		INSERT INTO questions (survey_id, text, type, required, order_num)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO questions (survey_id, text, type, required, order_num, display_rules, skip_rules, section_id, config, randomize_options)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id, created_at, updated_at
 	`
 
+	displayRules, skipRules, err := encodeQuestionRules(question)
+	if err != nil {
+		return 0, err
+	}
+
 	var id int
 	var createdAt, updatedAt time.Time
 
-	err := r.db.QueryRow(ctx, query,
+	err = r.db.QueryRow(ctx, query,
 		question.SurveyID,
 		question.Text,
 		question.Type,
 		question.Required,
 		question.OrderNum,
+		displayRules,
+		skipRules,
+		question.SectionID,
+		questionConfigColumn(question),
+		question.RandomizeOptions,
 	).Scan(&id, &createdAt, &updatedAt)
 
 	if err != nil {
@@ -559,18 +817,27 @@ func (r *PostgresRepository) CreateQuestion(ctx context.Context, question *model
 // CreateQuestionTx creates a new question in the database using a transaction
 func (r *PostgresRepository) CreateQuestionTx(ctx context.Context, tx pgx.Tx, question *models.Question) (int, error) {
 	query := `
-		INSERT INTO questions (survey_id, text, type, required, order_num)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO questions (survey_id, text, type, required, order_num, display_rules, skip_rules, section_id, config, randomize_options)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id, created_at, updated_at
 	`
+	displayRules, skipRules, err := encodeQuestionRules(question)
+	if err != nil {
+		return 0, err
+	}
 	var id int
 	var createdAt, updatedAt time.Time
-	err := tx.QueryRow(ctx, query,
+	err = tx.QueryRow(ctx, query,
 		question.SurveyID,
 		question.Text,
 		question.Type,
 		question.Required,
 		question.OrderNum,
+		displayRules,
+		skipRules,
+		question.SectionID,
+		questionConfigColumn(question),
+		question.RandomizeOptions,
 	).Scan(&id, &createdAt, &updatedAt)
 	if err != nil {
 		return 0, err
@@ -584,7 +851,7 @@ func (r *PostgresRepository) CreateQuestionTx(ctx context.Context, tx pgx.Tx, qu
 // GetQuestionsBySurveyID retrieves all questions for a survey
 func (r *PostgresRepository) GetQuestionsBySurveyID(ctx context.Context, surveyID int) ([]*models.Question, error) {
 	query := `
-		SELECT id, survey_id, text, type, required, order_num, created_at, updated_at
+		SELECT id, survey_id, text, type, required, order_num, display_rules, skip_rules, section_id, config, randomize_options, created_at, updated_at
 		FROM questions
 		WHERE survey_id = $1
 		ORDER BY order_num
@@ -600,6 +867,7 @@ func (r *PostgresRepository) GetQuestionsBySurveyID(ctx context.Context, surveyI
 
 	for rows.Next() {
 		var question models.Question
+		var displayRules, skipRules, config []byte
 
 		err := rows.Scan(
 			&question.ID,
@@ -608,6 +876,11 @@ func (r *PostgresRepository) GetQuestionsBySurveyID(ctx context.Context, surveyI
 			&question.Type,
 			&question.Required,
 			&question.OrderNum,
+			&displayRules,
+			&skipRules,
+			&question.SectionID,
+			&config,
+			&question.RandomizeOptions,
 			&question.CreatedAt,
 			&question.UpdatedAt,
 		)
@@ -615,16 +888,19 @@ func (r *PostgresRepository) GetQuestionsBySurveyID(ctx context.Context, surveyI
 		if err != nil {
 			return nil, err
 		}
+		if err := decodeQuestionRules(&question, displayRules, skipRules); err != nil {
+			return nil, err
+		}
+		setQuestionConfig(&question, config)
 
 		// Fetch options for question types that support them
-		switch question.Type {
-		case "multiple_choice", "checkbox", "dropdown":
+		if def, ok := questiontypes.LookupQuestionType(question.Type); ok && def.HasOptions {
 			options, err := r.GetQuestionOptionsByQuestionID(ctx, question.ID)
 			if err != nil {
 				return nil, err // Or log and continue if one question's options failing shouldn't fail all
 			}
 			question.Options = options
-		default:
+		} else {
 			question.Options = []*models.QuestionOption{} // Ensure options is an empty slice, not nil, for other types
 		}
 
@@ -641,7 +917,7 @@ func (r *PostgresRepository) GetQuestionsBySurveyID(ctx context.Context, surveyI
 // GetQuestionsBySurveyIDTx retrieves all questions for a survey using a transaction
 func (r *PostgresRepository) GetQuestionsBySurveyIDTx(ctx context.Context, tx pgx.Tx, surveyID int) ([]*models.Question, error) {
 	query := `
-		SELECT id, survey_id, text, type, required, order_num, created_at, updated_at
+		SELECT id, survey_id, text, type, required, order_num, display_rules, skip_rules, section_id, config, randomize_options, created_at, updated_at
 		FROM questions
 		WHERE survey_id = $1
 		ORDER BY order_num
@@ -654,22 +930,26 @@ func (r *PostgresRepository) GetQuestionsBySurveyIDTx(ctx context.Context, tx pg
 	var questions []*models.Question
 	for rows.Next() {
 		var question models.Question
+		var displayRules, skipRules, config []byte
 		err := rows.Scan(
 			&question.ID, &question.SurveyID, &question.Text, &question.Type,
-			&question.Required, &question.OrderNum, &question.CreatedAt, &question.UpdatedAt,
+			&question.Required, &question.OrderNum, &displayRules, &skipRules, &question.SectionID, &config, &question.RandomizeOptions, &question.CreatedAt, &question.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
 		}
-		switch question.Type {
-		case "multiple_choice", "checkbox", "dropdown":
+		if err := decodeQuestionRules(&question, displayRules, skipRules); err != nil {
+			return nil, err
+		}
+		setQuestionConfig(&question, config)
+		if def, ok := questiontypes.LookupQuestionType(question.Type); ok && def.HasOptions {
 			// Use GetQuestionOptionsByQuestionIDTx for transactional consistency
 			options, err := r.GetQuestionOptionsByQuestionIDTx(ctx, tx, question.ID)
 			if err != nil {
 				return nil, err
 			}
 			question.Options = options
-		default:
+		} else {
 			question.Options = []*models.QuestionOption{}
 		}
 		questions = append(questions, &question)
@@ -680,21 +960,85 @@ func (r *PostgresRepository) GetQuestionsBySurveyIDTx(ctx context.Context, tx pg
 	return questions, nil
 }
 
+// encodeQuestionRules encodes a question's display and skip rules for their JSONB columns
+func encodeQuestionRules(question *models.Question) ([]byte, []byte, error) {
+	displayRules := question.DisplayRules
+	if displayRules == nil {
+		displayRules = []models.DisplayRule{}
+	}
+	skipRules := question.SkipRules
+	if skipRules == nil {
+		skipRules = []models.SkipRule{}
+	}
+
+	encodedDisplay, err := json.Marshal(displayRules)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode display rules: %w", err)
+	}
+	encodedSkip, err := json.Marshal(skipRules)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode skip rules: %w", err)
+	}
+	return encodedDisplay, encodedSkip, nil
+}
+
+// decodeQuestionRules decodes the JSONB display and skip rules scanned for a question
+func decodeQuestionRules(question *models.Question, displayRules, skipRules []byte) error {
+	if len(displayRules) > 0 {
+		if err := json.Unmarshal(displayRules, &question.DisplayRules); err != nil {
+			return fmt.Errorf("failed to decode display rules of question %d: %w", question.ID, err)
+		}
+	}
+	if len(skipRules) > 0 {
+		if err := json.Unmarshal(skipRules, &question.SkipRules); err != nil {
+			return fmt.Errorf("failed to decode skip rules of question %d: %w", question.ID, err)
+		}
+	}
+	return nil
+}
+
+// questionConfigColumn returns a question's config for its JSONB column, an empty object when it has none
+func questionConfigColumn(question *models.Question) []byte {
+	if len(question.Config) == 0 {
+		return []byte("{}")
+	}
+	return question.Config
+}
+
+// setQuestionConfig sets the config scanned for a question, leaving it nil when the question has none
+func setQuestionConfig(question *models.Question, config []byte) {
+	if len(config) == 0 || string(config) == "{}" {
+		question.Config = nil
+		return
+	}
+	question.Config = json.RawMessage(config)
+}
+
 // UpdateQuestion updates a question in the database
 func (r *PostgresRepository) UpdateQuestion(ctx context.Context, question *models.Question) error {
 	query := `
 		UPDATE questions
-		SET text = $1, type = $2, required = $3, order_num = $4, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $5
+		SET text = $1, type = $2, required = $3, order_num = $4, display_rules = $5, skip_rules = $6, section_id = $7, config = $8, randomize_options = $9, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $10
 		RETURNING updated_at
 	`
 
+	displayRules, skipRules, err := encodeQuestionRules(question)
+	if err != nil {
+		return err
+	}
+
 	var updatedAt time.Time
-	err := r.db.QueryRow(ctx, query,
+	err = r.db.QueryRow(ctx, query,
 		question.Text,
 		question.Type,
 		question.Required,
 		question.OrderNum,
+		displayRules,
+		skipRules,
+		question.SectionID,
+		questionConfigColumn(question),
+		question.RandomizeOptions,
 		question.ID,
 	).Scan(&updatedAt)
 
@@ -713,13 +1057,17 @@ func (r *PostgresRepository) UpdateQuestion(ctx context.Context, question *model
 func (r *PostgresRepository) UpdateQuestionTx(ctx context.Context, tx pgx.Tx, question *models.Question) error {
 	query := `
 		UPDATE questions
-		SET text = $1, type = $2, required = $3, order_num = $4, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $5
+		SET text = $1, type = $2, required = $3, order_num = $4, display_rules = $5, skip_rules = $6, section_id = $7, config = $8, randomize_options = $9, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $10
 		RETURNING updated_at
 	`
+	displayRules, skipRules, err := encodeQuestionRules(question)
+	if err != nil {
+		return err
+	}
 	var updatedAt time.Time
-	err := tx.QueryRow(ctx, query,
-		question.Text, question.Type, question.Required, question.OrderNum, question.ID,
+	err = tx.QueryRow(ctx, query,
+		question.Text, question.Type, question.Required, question.OrderNum, displayRules, skipRules, question.SectionID, questionConfigColumn(question), question.RandomizeOptions, question.ID,
 	).Scan(&updatedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -883,6 +1231,41 @@ func (r *PostgresRepository) GetQuestionOptionsByQuestionIDTx(ctx context.Contex
 	return options, nil
 }
 
+// UpdateQuestionOptionTx updates an option's text and position using a transaction
+func (r *PostgresRepository) UpdateQuestionOptionTx(ctx context.Context, tx pgx.Tx, option *models.QuestionOption) error {
+	query := `
+		UPDATE question_options
+		SET text = $1, order_num = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND question_id = $4
+		RETURNING updated_at
+	`
+	var updatedAt time.Time
+	err := tx.QueryRow(ctx, query,
+		option.Text, option.OrderNum, option.ID, option.QuestionID,
+	).Scan(&updatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("question option not found during tx update")
+		}
+		return err
+	}
+	option.UpdatedAt = updatedAt
+	return nil
+}
+
+// DeleteQuestionOptionTx deletes a single question option using a transaction
+func (r *PostgresRepository) DeleteQuestionOptionTx(ctx context.Context, tx pgx.Tx, id int) error {
+	query := `DELETE FROM question_options WHERE id = $1`
+	result, err := tx.Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("question option not found during tx delete")
+	}
+	return nil
+}
+
 // DeleteQuestionOptions deletes all options for a question
 func (r *PostgresRepository) DeleteQuestionOptions(ctx context.Context, questionID int) error {
 	query := `DELETE FROM question_options WHERE question_id = $1`
@@ -903,12 +1286,30 @@ func (r *PostgresRepository) DeleteQuestionOptionsTx(ctx context.Context, tx pgx
 	return err
 }
 
-// GetQuestionByID retrieves a single question by its ID.
+// GetQuestionByID retrieves a single question by its ID, without its options. It returns nil if there is none.
 func (r *PostgresRepository) GetQuestionByID(ctx context.Context, id int) (*models.Question, error) {
-	log.Printf("[REPO_STUB] GetQuestionByID called for ID: %d", id)
-	// Placeholder implementation - actual query needed
-	// SELECT id, survey_id, text, type, required, order_num, created_at, updated_at FROM questions WHERE id = $1
-	return nil, errors.New("GetQuestionByID: not implemented")
+	query := `
+		SELECT id, survey_id, text, type, required, order_num, display_rules, skip_rules, section_id, config, randomize_options, created_at, updated_at
+		FROM questions
+		WHERE id = $1
+	`
+	var question models.Question
+	var displayRules, skipRules, config []byte
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&question.ID, &question.SurveyID, &question.Text, &question.Type,
+		&question.Required, &question.OrderNum, &displayRules, &skipRules, &question.SectionID, &config, &question.RandomizeOptions, &question.CreatedAt, &question.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := decodeQuestionRules(&question, displayRules, skipRules); err != nil {
+		return nil, err
+	}
+	setQuestionConfig(&question, config)
+	return &question, nil
 }
 
 // DeleteQuestionsBySurveyIDTx deletes all questions (and their options) associated with a survey ID within a transaction.
@@ -927,3 +1328,421 @@ func (r *PostgresRepository) DeleteQuestionsBySurveyIDTx(ctx context.Context, tx
 	}
 	return nil
 }
+
+// CreateSurveyVersionTx stores a new snapshot with the next version number for the survey using a transaction
+func (r *PostgresRepository) CreateSurveyVersionTx(ctx context.Context, tx pgx.Tx, version *models.SurveyVersion) (int, error) {
+	snapshot, err := json.Marshal(version.Snapshot)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode survey snapshot: %w", err)
+	}
+
+	// Lock the survey row so concurrent publishes cannot pick the same version number
+	if _, err := tx.Exec(ctx, `SELECT id FROM surveys WHERE id = $1 FOR UPDATE`, version.SurveyID); err != nil {
+		return 0, fmt.Errorf("failed to lock survey %d: %w", version.SurveyID, err)
+	}
+
+	query := `
+		INSERT INTO survey_versions (survey_id, version, snapshot, created_by)
+		SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3
+		FROM survey_versions
+		WHERE survey_id = $1
+		RETURNING id, version, created_at
+	`
+	err = tx.QueryRow(ctx, query, version.SurveyID, snapshot, version.CreatedBy).Scan(
+		&version.ID, &version.Version, &version.CreatedAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert survey version for survey %d: %w", version.SurveyID, err)
+	}
+	return version.Version, nil
+}
+
+// GetLatestSurveyVersionTx retrieves the most recent snapshot of a survey using a transaction.
+// It returns nil, nil when the survey has never been published.
+func (r *PostgresRepository) GetLatestSurveyVersionTx(ctx context.Context, tx pgx.Tx, surveyID int) (*models.SurveyVersion, error) {
+	query := `
+		SELECT id, survey_id, version, snapshot, created_by, created_at
+		FROM survey_versions
+		WHERE survey_id = $1
+		ORDER BY version DESC
+		LIMIT 1
+	`
+	version, err := scanSurveyVersion(tx.QueryRow(ctx, query, surveyID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return version, nil
+}
+
+// GetLatestSurveyVersionNumber returns the latest published version number of a survey, or 0 if there is none
+func (r *PostgresRepository) GetLatestSurveyVersionNumber(ctx context.Context, surveyID int) (int, error) {
+	var version int
+	err := r.db.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM survey_versions WHERE survey_id = $1`, surveyID).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest version for survey %d: %w", surveyID, err)
+	}
+	return version, nil
+}
+
+// GetSurveyVersion retrieves a specific snapshot of a survey
+func (r *PostgresRepository) GetSurveyVersion(ctx context.Context, surveyID, version int) (*models.SurveyVersion, error) {
+	query := `
+		SELECT id, survey_id, version, snapshot, created_by, created_at
+		FROM survey_versions
+		WHERE survey_id = $1 AND version = $2
+	`
+	surveyVersion, err := scanSurveyVersion(r.db.QueryRow(ctx, query, surveyID, version))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("survey version not found")
+		}
+		return nil, err
+	}
+	return surveyVersion, nil
+}
+
+// ListSurveyVersions retrieves the version history of a survey, newest first, without the snapshots
+func (r *PostgresRepository) ListSurveyVersions(ctx context.Context, surveyID int) ([]*models.SurveyVersion, error) {
+	query := `
+		SELECT id, survey_id, version, created_by, created_at
+		FROM survey_versions
+		WHERE survey_id = $1
+		ORDER BY version DESC
+	`
+	rows, err := r.db.Query(ctx, query, surveyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query versions for survey %d: %w", surveyID, err)
+	}
+	defer rows.Close()
+
+	versions := []*models.SurveyVersion{}
+	for rows.Next() {
+		var version models.SurveyVersion
+		if err := rows.Scan(&version.ID, &version.SurveyID, &version.Version, &version.CreatedBy, &version.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan survey version row: %w", err)
+		}
+		versions = append(versions, &version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating survey version rows: %w", err)
+	}
+	return versions, nil
+}
+
+// scanSurveyVersion scans a full survey_versions row, decoding the JSONB snapshot
+func scanSurveyVersion(row pgx.Row) (*models.SurveyVersion, error) {
+	var version models.SurveyVersion
+	var snapshot []byte
+	if err := row.Scan(&version.ID, &version.SurveyID, &version.Version, &snapshot, &version.CreatedBy, &version.CreatedAt); err != nil {
+		return nil, err
+	}
+	version.Snapshot = &models.Survey{}
+	if err := json.Unmarshal(snapshot, version.Snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot of survey %d version %d: %w", version.SurveyID, version.Version, err)
+	}
+	return &version, nil
+}
+
+// CreateSurveyStatusChange records a transition of a survey's effective status
+func (r *PostgresRepository) CreateSurveyStatusChange(ctx context.Context, change *models.SurveyStatusChange) error {
+	query := `
+		INSERT INTO survey_status_history (survey_id, from_status, to_status, reason, changed_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, changed_at
+	`
+	err := r.db.QueryRow(ctx, query, change.SurveyID, change.FromStatus, change.ToStatus, change.Reason, change.ChangedBy).
+		Scan(&change.ID, &change.ChangedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record status change of survey %d: %w", change.SurveyID, err)
+	}
+	return nil
+}
+
+// ListSurveyStatusHistory lists the status changes of a survey, newest first
+func (r *PostgresRepository) ListSurveyStatusHistory(ctx context.Context, surveyID int) ([]*models.SurveyStatusChange, error) {
+	query := `
+		SELECT id, survey_id, from_status, to_status, reason, changed_by, changed_at
+		FROM survey_status_history
+		WHERE survey_id = $1
+		ORDER BY changed_at DESC, id DESC
+	`
+	rows, err := r.db.Query(ctx, query, surveyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query status history of survey %d: %w", surveyID, err)
+	}
+	defer rows.Close()
+
+	history := []*models.SurveyStatusChange{}
+	for rows.Next() {
+		var change models.SurveyStatusChange
+		if err := rows.Scan(&change.ID, &change.SurveyID, &change.FromStatus, &change.ToStatus, &change.Reason, &change.ChangedBy, &change.ChangedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan status change row: %w", err)
+		}
+		history = append(history, &change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating status change rows: %w", err)
+	}
+	return history, nil
+}
+
+// UpdateSurveyCreatorTx hands a survey to another user within a transaction
+func (r *PostgresRepository) UpdateSurveyCreatorTx(ctx context.Context, tx pgx.Tx, surveyID, creatorID int) error {
+	result, err := tx.Exec(ctx, "UPDATE surveys SET creator_id = $1, updated_at = NOW() WHERE id = $2", creatorID, surveyID)
+	if err != nil {
+		return fmt.Errorf("failed to update creator of survey %d: %w", surveyID, err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("survey not found")
+	}
+	return nil
+}
+
+// UserExists reports whether an active user has the given ID. Users are managed by auth-service in the same database.
+func (r *PostgresRepository) UserExists(ctx context.Context, userID int) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND is_active)", userID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to look up user %d: %w", userID, err)
+	}
+	return exists, nil
+}
+
+// GetUserIDByEmail returns the ID of the active user with the given email, compared case-insensitively, or 0 if there is none
+func (r *PostgresRepository) GetUserIDByEmail(ctx context.Context, email string) (int, error) {
+	var id int
+	err := r.db.QueryRow(ctx, "SELECT id FROM users WHERE LOWER(email) = LOWER($1) AND is_active", email).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up user by email: %w", err)
+	}
+	return id, nil
+}
+
+// IsOrgMember reports whether a user belongs to an organization. Memberships are managed by auth-service in the same
+// database.
+func (r *PostgresRepository) IsOrgMember(ctx context.Context, orgID, userID int) (bool, error) {
+	var member bool
+	err := r.db.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM organization_members WHERE org_id = $1 AND user_id = $2)", orgID, userID).Scan(&member)
+	if err != nil {
+		return false, fmt.Errorf("failed to look up membership of user %d in organization %d: %w", userID, orgID, err)
+	}
+	return member, nil
+}
+
+// CreateOwnershipTransferTx records a survey being handed to another user within a transaction
+func (r *PostgresRepository) CreateOwnershipTransferTx(ctx context.Context, tx pgx.Tx, transfer *models.SurveyOwnershipTransfer) error {
+	query := `
+		INSERT INTO survey_ownership_transfers (survey_id, from_user_id, to_user_id, transferred_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, transferred_at
+	`
+	err := tx.QueryRow(ctx, query, transfer.SurveyID, transfer.FromUserID, transfer.ToUserID, transfer.TransferredBy).
+		Scan(&transfer.ID, &transfer.TransferredAt)
+	if err != nil {
+		return fmt.Errorf("failed to record ownership transfer of survey %d: %w", transfer.SurveyID, err)
+	}
+	return nil
+}
+
+// ListOwnershipTransfers lists the ownership transfers of a survey, newest first
+func (r *PostgresRepository) ListOwnershipTransfers(ctx context.Context, surveyID int) ([]*models.SurveyOwnershipTransfer, error) {
+	query := `
+		SELECT id, survey_id, from_user_id, to_user_id, transferred_by, transferred_at
+		FROM survey_ownership_transfers
+		WHERE survey_id = $1
+		ORDER BY transferred_at DESC, id DESC
+	`
+	rows, err := r.db.Query(ctx, query, surveyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ownership transfers of survey %d: %w", surveyID, err)
+	}
+	defer rows.Close()
+
+	transfers := []*models.SurveyOwnershipTransfer{}
+	for rows.Next() {
+		var transfer models.SurveyOwnershipTransfer
+		if err := rows.Scan(&transfer.ID, &transfer.SurveyID, &transfer.FromUserID, &transfer.ToUserID, &transfer.TransferredBy, &transfer.TransferredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan ownership transfer row: %w", err)
+		}
+		transfers = append(transfers, &transfer)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ownership transfer rows: %w", err)
+	}
+	return transfers, nil
+}
+
+// GetCollaboratorRole returns a user's collaborator role on a survey, or an empty string if they are not a collaborator
+func (r *PostgresRepository) GetCollaboratorRole(ctx context.Context, surveyID, userID int) (string, error) {
+	var role string
+	err := r.db.QueryRow(ctx, "SELECT role FROM survey_collaborators WHERE survey_id = $1 AND user_id = $2", surveyID, userID).Scan(&role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to look up collaborator %d of survey %d: %w", userID, surveyID, err)
+	}
+	return role, nil
+}
+
+// UpsertCollaborator adds a collaborator to a survey, or changes the role of an existing one
+func (r *PostgresRepository) UpsertCollaborator(ctx context.Context, collaborator *models.SurveyCollaborator) error {
+	query := `
+		INSERT INTO survey_collaborators (survey_id, user_id, role, invited_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (survey_id, user_id) DO UPDATE SET role = EXCLUDED.role
+		RETURNING invited_by, created_at
+	`
+	err := r.db.QueryRow(ctx, query, collaborator.SurveyID, collaborator.UserID, collaborator.Role, collaborator.InvitedBy).
+		Scan(&collaborator.InvitedBy, &collaborator.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save collaborator %d of survey %d: %w", collaborator.UserID, collaborator.SurveyID, err)
+	}
+	return nil
+}
+
+// ListCollaborators lists the collaborators of a survey with their usernames and emails, in the order they were invited
+func (r *PostgresRepository) ListCollaborators(ctx context.Context, surveyID int) ([]*models.SurveyCollaborator, error) {
+	query := `
+		SELECT c.survey_id, c.user_id, u.username, u.email, c.role, c.invited_by, c.created_at
+		FROM survey_collaborators c
+		JOIN users u ON u.id = c.user_id
+		WHERE c.survey_id = $1
+		ORDER BY c.created_at, c.user_id
+	`
+	rows, err := r.db.Query(ctx, query, surveyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query collaborators of survey %d: %w", surveyID, err)
+	}
+	defer rows.Close()
+
+	collaborators := []*models.SurveyCollaborator{}
+	for rows.Next() {
+		var collaborator models.SurveyCollaborator
+		if err := rows.Scan(&collaborator.SurveyID, &collaborator.UserID, &collaborator.Username, &collaborator.Email, &collaborator.Role, &collaborator.InvitedBy, &collaborator.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan collaborator row: %w", err)
+		}
+		collaborators = append(collaborators, &collaborator)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating collaborator rows: %w", err)
+	}
+	return collaborators, nil
+}
+
+// DeleteCollaborator removes a collaborator from a survey
+func (r *PostgresRepository) DeleteCollaborator(ctx context.Context, surveyID, userID int) error {
+	result, err := r.db.Exec(ctx, "DELETE FROM survey_collaborators WHERE survey_id = $1 AND user_id = $2", surveyID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove collaborator %d of survey %d: %w", userID, surveyID, err)
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("collaborator not found")
+	}
+	return nil
+}
+
+// DeleteCollaboratorTx removes a user from a survey's collaborators within a transaction, if they are one
+func (r *PostgresRepository) DeleteCollaboratorTx(ctx context.Context, tx pgx.Tx, surveyID, userID int) error {
+	if _, err := tx.Exec(ctx, "DELETE FROM survey_collaborators WHERE survey_id = $1 AND user_id = $2", surveyID, userID); err != nil {
+		return fmt.Errorf("failed to remove collaborator %d of survey %d: %w", userID, surveyID, err)
+	}
+	return nil
+}
+
+// ListSurveysDueToOpen lists active surveys whose start date has passed since they were created
+// and whose opening has not been recorded yet
+func (r *PostgresRepository) ListSurveysDueToOpen(ctx context.Context) ([]*models.Survey, error) {
+	return r.listSurveysWhere(ctx, surveyStatusConditions[models.SurveyStatusOpen]+
+		" AND "+sqlStartDate+" > created_at"+
+		" AND NOT EXISTS (SELECT 1 FROM survey_status_history h WHERE h.survey_id = surveys.id AND h.changed_at >= surveys.start_date)")
+}
+
+// ListSurveysDueToClose lists active surveys whose end date has passed
+func (r *PostgresRepository) ListSurveysDueToClose(ctx context.Context) ([]*models.Survey, error) {
+	return r.listSurveysWhere(ctx, "is_active AND "+sqlEndDate+" <= NOW()")
+}
+
+// ListActiveSurveysWithQuota lists active surveys that have a response quota
+func (r *PostgresRepository) ListActiveSurveysWithQuota(ctx context.Context) ([]*models.Survey, error) {
+	return r.listSurveysWhere(ctx, "is_active AND max_responses IS NOT NULL")
+}
+
+// ListSurveysWithQuestionLogic lists surveys with a question that has display or skip rules or pipes an answer
+func (r *PostgresRepository) ListSurveysWithQuestionLogic(ctx context.Context) ([]*models.Survey, error) {
+	return r.listSurveysWhere(ctx, `id IN (
+			SELECT survey_id FROM questions
+			WHERE display_rules <> '[]'::jsonb OR skip_rules <> '[]'::jsonb OR text LIKE '%{{%'
+		)`)
+}
+
+// listSurveysWhere lists surveys without their questions, filtered by a trusted SQL condition
+func (r *PostgresRepository) listSurveysWhere(ctx context.Context, condition string) ([]*models.Survey, error) {
+	query := `
+		SELECT id, creator_id, title, description, is_active, start_date, end_date, max_responses, one_response_per_user, anonymous_limit, allow_anonymous, allow_response_edits, created_at, updated_at
+		FROM surveys
+		WHERE ` + condition + `
+		ORDER BY id
+	`
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query surveys: %w", err)
+	}
+	defer rows.Close()
+
+	var surveys []*models.Survey
+	for rows.Next() {
+		var survey models.Survey
+		var startDate, endDate *time.Time
+		err := rows.Scan(
+			&survey.ID, &survey.CreatorID, &survey.Title, &survey.Description,
+			&survey.IsActive, &startDate, &endDate, &survey.MaxResponses, &survey.OneResponsePerUser, &survey.AnonymousLimit, &survey.AllowAnonymous, &survey.AllowResponseEdits, &survey.CreatedAt, &survey.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan survey row: %w", err)
+		}
+		if startDate != nil {
+			survey.StartDate = *startDate
+		}
+		if endDate != nil {
+			survey.EndDate = *endDate
+		}
+		surveys = append(surveys, &survey)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating survey rows: %w", err)
+	}
+	return surveys, nil
+}
+
+// WithAdvisoryLock runs fn while holding a Postgres session-level advisory lock on a dedicated connection.
+// If another session holds the lock, fn is not run and false is returned.
+func (r *PostgresRepository) WithAdvisoryLock(ctx context.Context, key int64, fn func(ctx context.Context) error) (bool, error) {
+	conn, err := r.db.Acquire(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire connection for advisory lock: %w", err)
+	}
+	defer conn.Release()
+
+	var acquired bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&acquired); err != nil {
+		return false, fmt.Errorf("failed to take advisory lock %d: %w", key, err)
+	}
+	if !acquired {
+		return false, nil
+	}
+	defer func() {
+		// Unlock with a fresh context so the lock is released even if ctx was cancelled
+		if _, err := conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", key); err != nil {
+			log.Printf("[REPO_ERROR] WithAdvisoryLock: failed to release advisory lock %d: %v", key, err)
+		}
+	}()
+
+	return true, fn(ctx)
+}
@@ -17,11 +17,16 @@ type SurveyRepositoryInterface interface {
 	// Survey operations (non-transactional)
 	CreateSurvey(ctx context.Context, survey *models.Survey) (int, error)
 	GetSurvey(ctx context.Context, id int) (*models.Survey, error)
-	ListSurveysByCreatorID(ctx context.Context, creatorID int, offset, limit int) ([]*models.Survey, int, error)
-	ListAllSurveys(ctx context.Context, isUserAdmin bool, offset, limit int) ([]*models.Survey, int, error)
+	ListSurveysForUser(ctx context.Context, orgID *int, userID int, status string, offset, limit int) ([]*models.Survey, int, error)
+	ListAllSurveys(ctx context.Context, orgID *int, isUserAdmin bool, status string, offset, limit int) ([]*models.Survey, int, error)
 	UpdateSurvey(ctx context.Context, survey *models.Survey) error
 	DeleteSurvey(ctx context.Context, id int) error
 	UpdateSurveyStatus(ctx context.Context, id int, isActive bool) error
+	SetSurveyTemplateVisibility(ctx context.Context, id int, visibility string) error
+	ListTemplates(ctx context.Context, orgID *int, userID int, isUserAdmin bool, offset, limit int) ([]*models.Survey, int, error)
+
+	// Section operations (non-transactional)
+	GetSectionsBySurveyID(ctx context.Context, surveyID int) ([]*models.Section, error)
 
 	// Question operations (non-transactional)
 	CreateQuestion(ctx context.Context, question *models.Question) (int, error)
@@ -38,6 +43,13 @@ type SurveyRepositoryInterface interface {
 	// Survey operations (transactional)
 	CreateSurveyTx(ctx context.Context, tx pgx.Tx, survey *models.Survey) (int, error)
 	UpdateSurveyTx(ctx context.Context, tx pgx.Tx, survey *models.Survey) error
+	UpdateSurveyCreatorTx(ctx context.Context, tx pgx.Tx, surveyID, creatorID int) error
+
+	// Section operations (transactional)
+	CreateSectionTx(ctx context.Context, tx pgx.Tx, section *models.Section) (int, error)
+	GetSectionsBySurveyIDTx(ctx context.Context, tx pgx.Tx, surveyID int) ([]*models.Section, error)
+	UpdateSectionTx(ctx context.Context, tx pgx.Tx, section *models.Section) error
+	DeleteSectionTx(ctx context.Context, tx pgx.Tx, id int) error
 
 	// Question operations (transactional)
 	CreateQuestionTx(ctx context.Context, tx pgx.Tx, question *models.Question) (int, error)
@@ -47,7 +59,41 @@ type SurveyRepositoryInterface interface {
 
 	// QuestionOption operations (transactional)
 	CreateQuestionOptionTx(ctx context.Context, tx pgx.Tx, option *models.QuestionOption) (int, error)
-	// GetQuestionOptionsByQuestionIDTx is not strictly needed if GetQuestionsBySurveyIDTx handles it, but can be added for consistency
+	GetQuestionOptionsByQuestionIDTx(ctx context.Context, tx pgx.Tx, questionID int) ([]*models.QuestionOption, error)
+	UpdateQuestionOptionTx(ctx context.Context, tx pgx.Tx, option *models.QuestionOption) error
+	DeleteQuestionOptionTx(ctx context.Context, tx pgx.Tx, id int) error
 	DeleteQuestionOptionsTx(ctx context.Context, tx pgx.Tx, questionID int) error
 	DeleteQuestionsBySurveyIDTx(ctx context.Context, tx pgx.Tx, surveyID int) error
+
+	// Survey version operations
+	CreateSurveyVersionTx(ctx context.Context, tx pgx.Tx, version *models.SurveyVersion) (int, error)
+	GetLatestSurveyVersionTx(ctx context.Context, tx pgx.Tx, surveyID int) (*models.SurveyVersion, error)
+	GetLatestSurveyVersionNumber(ctx context.Context, surveyID int) (int, error)
+	GetSurveyVersion(ctx context.Context, surveyID, version int) (*models.SurveyVersion, error)
+	ListSurveyVersions(ctx context.Context, surveyID int) ([]*models.SurveyVersion, error)
+
+	// Survey status history and scheduling
+	CreateSurveyStatusChange(ctx context.Context, change *models.SurveyStatusChange) error
+	ListSurveyStatusHistory(ctx context.Context, surveyID int) ([]*models.SurveyStatusChange, error)
+	ListSurveysDueToOpen(ctx context.Context) ([]*models.Survey, error)
+	ListSurveysDueToClose(ctx context.Context) ([]*models.Survey, error)
+	ListActiveSurveysWithQuota(ctx context.Context) ([]*models.Survey, error)
+	ListSurveysWithQuestionLogic(ctx context.Context) ([]*models.Survey, error)
+	// Users and ownership transfers
+	UserExists(ctx context.Context, userID int) (bool, error)
+	GetUserIDByEmail(ctx context.Context, email string) (int, error)
+	IsOrgMember(ctx context.Context, orgID, userID int) (bool, error)
+	CreateOwnershipTransferTx(ctx context.Context, tx pgx.Tx, transfer *models.SurveyOwnershipTransfer) error
+	ListOwnershipTransfers(ctx context.Context, surveyID int) ([]*models.SurveyOwnershipTransfer, error)
+
+	// Collaborators
+	GetCollaboratorRole(ctx context.Context, surveyID, userID int) (string, error)
+	UpsertCollaborator(ctx context.Context, collaborator *models.SurveyCollaborator) error
+	ListCollaborators(ctx context.Context, surveyID int) ([]*models.SurveyCollaborator, error)
+	DeleteCollaborator(ctx context.Context, surveyID, userID int) error
+	DeleteCollaboratorTx(ctx context.Context, tx pgx.Tx, surveyID, userID int) error
+
+	// WithAdvisoryLock runs fn only if the session-level advisory lock for key could be taken.
+	// It reports whether the lock was acquired.
+	WithAdvisoryLock(ctx context.Context, key int64, fn func(ctx context.Context) error) (bool, error)
 }
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
+)
+
+// CloneSurvey copies a survey for the current user (owner, collaborator or admin), with its settings, sections, questions and
+// options, in one transaction. Responses, versions and history stay with the original. An empty title names the copy
+// after the original.
+func (s *SurveyService) CloneSurvey(ctx context.Context, surveyID int, title string) (int, error) {
+	survey, _, err := s.authorizeSurveyAccess(ctx, surveyID, accessView)
+	if err != nil {
+		return 0, err
+	}
+	userID, _, err := getUserAndRolesFromContext(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("CloneSurvey: %w", err)
+	}
+
+	if strings.TrimSpace(title) == "" {
+		title = survey.Title + " (copy)"
+	}
+	return s.copySurvey(ctx, survey, userID, title)
+}
+
+// copySurvey creates a survey owned by ownerID, in the organization of the user in context, from the settings, sections,
+// questions and options of source in one transaction. The copy is inactive until its owner opens it, has no schedule
+// and is not a template.
+func (s *SurveyService) copySurvey(ctx context.Context, source *models.Survey, ownerID int, title string) (surveyID int, err error) {
+	survey := &models.Survey{
+		CreatorID:          ownerID,
+		OrgID:              orgIDFromContext(ctx),
+		Title:              title,
+		Description:        source.Description,
+		MaxResponses:       source.MaxResponses,
+		OneResponsePerUser: source.OneResponsePerUser,
+		AnonymousLimit:     source.AnonymousLimit,
+		AllowAnonymous:     source.AllowAnonymous,
+		AllowResponseEdits: source.AllowResponseEdits,
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+			panic(p)
+		} else if err != nil {
+			_ = tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	surveyID, err = s.repo.CreateSurveyTx(ctx, tx, survey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create copy of survey %d: %w", source.ID, err)
+	}
+
+	sectionIDs := make(map[int]int, len(source.Sections)) // Source section ID -> copied section ID
+	for _, section := range source.Sections {
+		copied := &models.Section{
+			SurveyID:           surveyID,
+			Title:              section.Title,
+			Description:        section.Description,
+			OrderNum:           section.OrderNum,
+			RandomizeQuestions: section.RandomizeQuestions,
+		}
+		if _, err = s.repo.CreateSectionTx(ctx, tx, copied); err != nil {
+			return 0, fmt.Errorf("failed to copy section %d of survey %d: %w", section.ID, source.ID, err)
+		}
+		sectionIDs[section.ID] = copied.ID
+	}
+
+	copiedQuestions := make([]*models.Question, 0, len(source.Questions))
+	questionIDs := make(map[int]int, len(source.Questions)) // Source question ID -> copied question ID
+	optionIDs := make(map[int]int)                          // Source option ID -> copied option ID
+	for _, question := range source.Questions {
+		copied := &models.Question{
+			SurveyID:         surveyID,
+			Text:             question.Text,
+			Type:             question.Type,
+			Required:         question.Required,
+			OrderNum:         question.OrderNum,
+			Config:           question.Config,
+			DisplayRules:     question.DisplayRules,
+			SkipRules:        question.SkipRules,
+			RandomizeOptions: question.RandomizeOptions,
+		}
+		if question.SectionID != nil {
+			sectionID := sectionIDs[*question.SectionID]
+			copied.SectionID = &sectionID
+		}
+		var questionID int
+		questionID, err = s.repo.CreateQuestionTx(ctx, tx, copied)
+		if err != nil {
+			return 0, fmt.Errorf("failed to copy question %d of survey %d: %w", question.ID, source.ID, err)
+		}
+
+		copied.ID = questionID
+		questionIDs[question.ID] = questionID
+
+		for _, option := range question.Options {
+			copiedOption := &models.QuestionOption{
+				QuestionID: questionID,
+				Text:       option.Text,
+				OrderNum:   option.OrderNum,
+			}
+			copiedOption.ID, err = s.repo.CreateQuestionOptionTx(ctx, tx, copiedOption)
+			if err != nil {
+				return 0, fmt.Errorf("failed to copy option %d of survey %d: %w", option.ID, source.ID, err)
+			}
+			copied.Options = append(copied.Options, copiedOption)
+			optionIDs[option.ID] = copiedOption.ID
+		}
+		copiedQuestions = append(copiedQuestions, copied)
+	}
+
+	// Rules and pipes refer to the source's questions and options until they are pointed at the copies
+	for _, copied := range copiedQuestions {
+		remapQuestionLogic(copied, questionIDs, optionIDs)
+	}
+	if _, err = resolveQuestionLogic(copiedQuestions); err != nil {
+		return 0, fmt.Errorf("failed to copy the logic of survey %d: %w", source.ID, err)
+	}
+	for _, copied := range copiedQuestions {
+		if !hasQuestionLogic(copied) {
+			continue
+		}
+		if err = s.repo.UpdateQuestionTx(ctx, tx, copied); err != nil {
+			return 0, fmt.Errorf("failed to copy the logic of question %d: %w", copied.ID, err)
+		}
+	}
+
+	return surveyID, nil
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
+)
+
+// ErrInvalidCollaborator is returned for collaborators with an unknown role, unknown users and the survey's owner
+var ErrInvalidCollaborator = errors.New("invalid collaborator")
+
+// InviteCollaborator adds an active user of the survey's organization, identified by ID or else by email, to its
+// collaborators with a role, or changes the role of an existing collaborator (owner or admin only)
+func (s *SurveyService) InviteCollaborator(ctx context.Context, surveyID int, req models.InviteCollaboratorRequest) (*models.SurveyCollaborator, error) {
+	survey, _, err := s.authorizeSurveyAccess(ctx, surveyID, accessManage)
+	if err != nil {
+		return nil, err
+	}
+	userID, _, err := getUserAndRolesFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("InviteCollaborator: %w", err)
+	}
+
+	if !models.IsValidCollaboratorRole(req.Role) {
+		return nil, fmt.Errorf("%w: role must be %q, %q or %q", ErrInvalidCollaborator,
+			models.AccessRoleViewer, models.AccessRoleEditor, models.AccessRoleAnalyst)
+	}
+	collaboratorID, err := s.resolveUser(ctx, survey.OrgID, req.UserID, req.Email, ErrInvalidCollaborator)
+	if err != nil {
+		return nil, err
+	}
+	if collaboratorID == survey.CreatorID {
+		return nil, fmt.Errorf("%w: user %d owns survey %d", ErrInvalidCollaborator, collaboratorID, surveyID)
+	}
+
+	collaborator := &models.SurveyCollaborator{
+		SurveyID:  surveyID,
+		UserID:    collaboratorID,
+		Role:      req.Role,
+		InvitedBy: &userID,
+	}
+	if err := s.repo.UpsertCollaborator(ctx, collaborator); err != nil {
+		return nil, err
+	}
+	return collaborator, nil
+}
+
+// ListCollaborators lists the collaborators of a survey (owner, collaborator or admin)
+func (s *SurveyService) ListCollaborators(ctx context.Context, surveyID int) ([]*models.SurveyCollaborator, error) {
+	if _, _, err := s.authorizeSurveyAccess(ctx, surveyID, accessView); err != nil {
+		return nil, err
+	}
+	return s.repo.ListCollaborators(ctx, surveyID)
+}
+
+// RemoveCollaborator removes a user from a survey's collaborators (owner or admin only).
+// Collaborators may also remove themselves to leave a survey.
+func (s *SurveyService) RemoveCollaborator(ctx context.Context, surveyID, collaboratorID int) error {
+	userID, _, err := getUserAndRolesFromContext(ctx)
+	if err != nil {
+		return fmt.Errorf("RemoveCollaborator: %w", err)
+	}
+	access := accessManage
+	if collaboratorID == userID {
+		access = accessView
+	}
+	if _, _, err := s.authorizeSurveyAccess(ctx, surveyID, access); err != nil {
+		return err
+	}
+
+	role, err := s.repo.GetCollaboratorRole(ctx, surveyID, collaboratorID)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		return ErrNotFound
+	}
+	return s.repo.DeleteCollaborator(ctx, surveyID, collaboratorID)
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/repository/mock"
+)
+
+func TestSurveyCollaborators(t *testing.T) {
+	mockRepo := mock.NewMockRepository()
+	mockRepo.Users["viewer@example.com"] = 2
+	service := NewSurveyService(mockRepo)
+	ownerCtx := setupTestContext(1, []string{"user"})
+	viewerCtx := setupTestContext(2, []string{"user"})
+	editorCtx := setupTestContext(3, []string{"user"})
+	analystCtx := setupTestContext(4, []string{"user"})
+	strangerCtx := setupTestContext(5, []string{"user"})
+
+	surveyID, err := service.CreateSurvey(ownerCtx, &models.Survey{Title: "Team study"}, []models.QuestionUpdateRequest{
+		{Text: "How was your week?", Type: "text"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error creating survey: %v", err)
+	}
+	if err := service.UpdateSurveyStatus(ownerCtx, surveyID, false); err != nil {
+		t.Fatalf("Unexpected error deactivating survey: %v", err)
+	}
+
+	invites := []models.InviteCollaboratorRequest{
+		{Email: "Viewer@example.com", Role: models.AccessRoleViewer},
+		{UserID: 3, Role: models.AccessRoleEditor},
+		{UserID: 4, Role: models.AccessRoleAnalyst},
+	}
+	for _, invite := range invites {
+		if _, err := service.InviteCollaborator(ownerCtx, surveyID, invite); err != nil {
+			t.Fatalf("Unexpected error inviting %+v: %v", invite, err)
+		}
+	}
+
+	t.Run("Invitations are validated", func(t *testing.T) {
+		if _, err := service.InviteCollaborator(ownerCtx, surveyID, models.InviteCollaboratorRequest{UserID: 5, Role: models.AccessRoleOwner}); !errors.Is(err, ErrInvalidCollaborator) {
+			t.Errorf("Expected ErrInvalidCollaborator for the owner role, got %v", err)
+		}
+		if _, err := service.InviteCollaborator(ownerCtx, surveyID, models.InviteCollaboratorRequest{UserID: 1, Role: models.AccessRoleEditor}); !errors.Is(err, ErrInvalidCollaborator) {
+			t.Errorf("Expected ErrInvalidCollaborator for the owner, got %v", err)
+		}
+		if _, err := service.InviteCollaborator(ownerCtx, surveyID, models.InviteCollaboratorRequest{Email: "nobody@example.com", Role: models.AccessRoleViewer}); !errors.Is(err, ErrInvalidCollaborator) {
+			t.Errorf("Expected ErrInvalidCollaborator for an unknown email, got %v", err)
+		}
+		if _, err := service.InviteCollaborator(editorCtx, surveyID, models.InviteCollaboratorRequest{UserID: 5, Role: models.AccessRoleViewer}); !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected ErrForbidden when an editor invites, got %v", err)
+		}
+	})
+
+	t.Run("Every collaborator can view the inactive survey", func(t *testing.T) {
+		for role, ctx := range map[string]context.Context{models.AccessRoleViewer: viewerCtx, models.AccessRoleEditor: editorCtx, models.AccessRoleAnalyst: analystCtx} {
+			survey, err := service.GetSurvey(ctx, surveyID)
+			if err != nil {
+				t.Fatalf("Unexpected error getting survey as %s: %v", role, err)
+			}
+			if survey.AccessRole != role {
+				t.Errorf("Expected access role %q, got %q", role, survey.AccessRole)
+			}
+		}
+		if _, err := service.GetSurvey(strangerCtx, surveyID); !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected ErrForbidden for other users, got %v", err)
+		}
+		if surveys, total, _ := service.ListUserSurveys(viewerCtx, "", 1, 10); total != 1 || surveys[0].AccessRole != models.AccessRoleViewer {
+			t.Errorf("Expected the shared survey in the viewer's list, got %d", total)
+		}
+		if surveys, _, _ := service.ListUserSurveys(ownerCtx, "", 1, 10); surveys[0].AccessRole != models.AccessRoleOwner {
+			t.Errorf("Expected the owner's access role, got %q", surveys[0].AccessRole)
+		}
+	})
+
+	t.Run("Only editors change questions", func(t *testing.T) {
+		req := &models.CreateQuestionRequest{SurveyID: surveyID, Text: "Anything else?", Type: "text"}
+		if _, err := service.AddQuestion(editorCtx, req); err != nil {
+			t.Errorf("Unexpected error adding a question as editor: %v", err)
+		}
+		for _, ctx := range []context.Context{viewerCtx, analystCtx} {
+			if _, err := service.AddQuestion(ctx, req); !errors.Is(err, ErrForbidden) {
+				t.Errorf("Expected ErrForbidden for viewers and analysts, got %v", err)
+			}
+		}
+	})
+
+	t.Run("Only owners delete or transfer", func(t *testing.T) {
+		if err := service.DeleteSurvey(editorCtx, surveyID); !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected ErrForbidden when an editor deletes, got %v", err)
+		}
+		if _, err := service.TransferSurveyOwnership(editorCtx, surveyID, models.TransferSurveyRequest{NewOwnerID: 3}); !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected ErrForbidden when an editor transfers, got %v", err)
+		}
+	})
+
+	t.Run("Inviting again changes the role", func(t *testing.T) {
+		collaborator, err := service.InviteCollaborator(ownerCtx, surveyID, models.InviteCollaboratorRequest{UserID: 4, Role: models.AccessRoleEditor})
+		if err != nil || collaborator.Role != models.AccessRoleEditor {
+			t.Fatalf("Expected the analyst to become an editor, got %+v, %v", collaborator, err)
+		}
+		collaborators, err := service.ListCollaborators(viewerCtx, surveyID)
+		if err != nil {
+			t.Fatalf("Unexpected error listing collaborators: %v", err)
+		}
+		if len(collaborators) != 3 || collaborators[0].Email != "viewer@example.com" || collaborators[2].Role != models.AccessRoleEditor {
+			t.Errorf("Expected 3 collaborators in invitation order, got %+v", collaborators)
+		}
+	})
+
+	t.Run("Collaborators are removed by the owner or leave", func(t *testing.T) {
+		if err := service.RemoveCollaborator(editorCtx, surveyID, 2); !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected ErrForbidden when an editor removes someone, got %v", err)
+		}
+		if err := service.RemoveCollaborator(viewerCtx, surveyID, 2); err != nil {
+			t.Errorf("Unexpected error leaving the survey: %v", err)
+		}
+		if err := service.RemoveCollaborator(ownerCtx, surveyID, 4); err != nil {
+			t.Errorf("Unexpected error removing a collaborator: %v", err)
+		}
+		if err := service.RemoveCollaborator(ownerCtx, surveyID, 4); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for a removed collaborator, got %v", err)
+		}
+		if _, err := service.GetSurvey(viewerCtx, surveyID); !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected the former viewer to lose access, got %v", err)
+		}
+	})
+
+	t.Run("The new owner stops being a collaborator", func(t *testing.T) {
+		if _, err := service.TransferSurveyOwnership(ownerCtx, surveyID, models.TransferSurveyRequest{NewOwnerID: 3}); err != nil {
+			t.Fatalf("Unexpected error transferring survey: %v", err)
+		}
+		collaborators, _ := service.ListCollaborators(editorCtx, surveyID)
+		if len(collaborators) != 0 {
+			t.Errorf("Expected no collaborators left, got %+v", collaborators)
+		}
+		if survey, _ := service.GetSurvey(editorCtx, surveyID); survey.AccessRole != models.AccessRoleOwner {
+			t.Errorf("Expected the new owner's access role, got %q", survey.AccessRole)
+		}
+	})
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
+)
+
+// ErrInvalidDefinition is returned for survey definition documents that cannot be imported
+var ErrInvalidDefinition = errors.New("invalid survey definition")
+
+// ExportSurveyDefinition returns the portable definition of a survey (owner, collaborator or admin)
+func (s *SurveyService) ExportSurveyDefinition(ctx context.Context, surveyID int) (*models.SurveyDefinition, error) {
+	survey, _, err := s.authorizeSurveyAccess(ctx, surveyID, accessView)
+	if err != nil {
+		return nil, err
+	}
+	return newSurveyDefinition(survey)
+}
+
+// newSurveyDefinition describes a stored survey as a definition document. Rules and pipes refer to questions and
+// options by ID, which mean nothing in another environment, so they are rewritten to question positions and option
+// texts. Rules saved before they referred to IDs hold OrderNums, which may have gaps, and are rewritten the same way.
+func newSurveyDefinition(survey *models.Survey) (*models.SurveyDefinition, error) {
+	definition := &models.SurveyDefinition{
+		SchemaVersion: models.SurveyDefinitionSchemaVersion,
+		Title:         survey.Title,
+		Description:   survey.Description,
+		Settings: models.SurveyDefinitionSettings{
+			IsActive:           survey.IsActive,
+			MaxResponses:       survey.MaxResponses,
+			OneResponsePerUser: survey.OneResponsePerUser,
+			AnonymousLimit:     survey.AnonymousLimit,
+			AllowAnonymous:     survey.AllowAnonymous,
+			AllowResponseEdits: survey.AllowResponseEdits,
+		},
+		Questions: make([]models.QuestionDefinition, 0, len(survey.Questions)),
+	}
+	if !survey.StartDate.IsZero() {
+		startDate := survey.StartDate.UTC()
+		definition.Settings.StartDate = &startDate
+	}
+	if !survey.EndDate.IsZero() {
+		endDate := survey.EndDate.UTC()
+		definition.Settings.EndDate = &endDate
+	}
+
+	sectionPositions := make(map[int]int, len(survey.Sections))
+	for i, section := range survey.Sections {
+		sectionPositions[section.ID] = i + 1
+		definition.Sections = append(definition.Sections, models.SectionDefinition{
+			Title:              section.Title,
+			Description:        section.Description,
+			RandomizeQuestions: section.RandomizeQuestions,
+		})
+	}
+
+	positions := make(map[int]int, len(survey.Questions))   // OrderNum -> position
+	idPositions := make(map[int]int, len(survey.Questions)) // Question ID -> position
+	optionTexts := make(map[int]string)                     // Option ID -> text
+	for i, question := range survey.Questions {
+		positions[question.OrderNum] = i + 1
+		idPositions[question.ID] = i + 1
+		for _, option := range question.Options {
+			optionTexts[option.ID] = option.Text
+		}
+	}
+	position := func(questionID, orderNum int) int {
+		if questionID != 0 {
+			return idPositions[questionID]
+		}
+		if p, ok := positions[orderNum]; ok {
+			return p
+		}
+		return orderNum
+	}
+	value := func(optionID int, value string) string {
+		if optionID != 0 {
+			return optionTexts[optionID]
+		}
+		return value
+	}
+
+	for _, question := range survey.Questions {
+		text := models.PipePattern.ReplaceAllStringFunc(question.Text, func(pipe string) string {
+			orderNum, _ := strconv.Atoi(models.PipePattern.FindStringSubmatch(pipe)[1])
+			return fmt.Sprintf("{{Q%d}}", position(0, orderNum))
+		})
+		q := models.QuestionDefinition{
+			Text: models.StoredPipePattern.ReplaceAllStringFunc(text, func(pipe string) string {
+				id, _ := strconv.Atoi(models.StoredPipePattern.FindStringSubmatch(pipe)[1])
+				return fmt.Sprintf("{{Q%d}}", position(id, 0))
+			}),
+			Type:             question.Type,
+			Required:         question.Required,
+			RandomizeOptions: question.RandomizeOptions,
+		}
+		if question.SectionID != nil {
+			q.Section = sectionPositions[*question.SectionID]
+		}
+		for _, option := range question.Options {
+			q.Options = append(q.Options, option.Text)
+		}
+		if len(question.Config) > 0 && string(question.Config) != "null" {
+			if err := json.Unmarshal(question.Config, &q.Config); err != nil {
+				return nil, fmt.Errorf("failed to read config of question %d: %w", question.ID, err)
+			}
+		}
+		for _, rule := range question.DisplayRules {
+			q.DisplayRules = append(q.DisplayRules, models.DisplayRule{
+				Question: position(rule.QuestionID, rule.Question),
+				Operator: rule.Operator,
+				Value:    value(rule.OptionID, rule.Value),
+			})
+		}
+		for _, rule := range question.SkipRules {
+			q.SkipRules = append(q.SkipRules, models.SkipRule{
+				Operator: rule.Operator,
+				Value:    value(rule.OptionID, rule.Value),
+				SkipTo:   position(rule.SkipToQuestionID, rule.SkipTo),
+			})
+		}
+		definition.Questions = append(definition.Questions, q)
+	}
+	return definition, nil
+}
+
+// ImportSurveyDefinition creates a survey owned by the current user from a definition document.
+// The document is validated like a CreateSurvey request and the survey is created in one transaction.
+func (s *SurveyService) ImportSurveyDefinition(ctx context.Context, definition *models.SurveyDefinition) (int, error) {
+	if definition.SchemaVersion < 1 || definition.SchemaVersion > models.SurveyDefinitionSchemaVersion {
+		return 0, fmt.Errorf("%w: unsupported schema_version %d, expected 1 to %d", ErrInvalidDefinition,
+			definition.SchemaVersion, models.SurveyDefinitionSchemaVersion)
+	}
+	if strings.TrimSpace(definition.Title) == "" {
+		return 0, fmt.Errorf("%w: title is required", ErrInvalidDefinition)
+	}
+
+	survey := &models.Survey{
+		Title:              definition.Title,
+		Description:        definition.Description,
+		IsActive:           definition.Settings.IsActive,
+		MaxResponses:       definition.Settings.MaxResponses,
+		OneResponsePerUser: definition.Settings.OneResponsePerUser,
+		AnonymousLimit:     definition.Settings.AnonymousLimit,
+		AllowAnonymous:     definition.Settings.AllowAnonymous,
+		AllowResponseEdits: definition.Settings.AllowResponseEdits,
+	}
+	if definition.Settings.StartDate != nil {
+		survey.StartDate = *definition.Settings.StartDate
+	}
+	if definition.Settings.EndDate != nil {
+		survey.EndDate = *definition.Settings.EndDate
+	}
+	if err := validateSchedule(survey); err != nil {
+		return 0, err
+	}
+	for _, section := range definition.Sections {
+		survey.Sections = append(survey.Sections, &models.Section{
+			Title:              section.Title,
+			Description:        section.Description,
+			RandomizeQuestions: section.RandomizeQuestions,
+		})
+	}
+
+	questions := make([]models.QuestionUpdateRequest, 0, len(definition.Questions))
+	for i, q := range definition.Questions {
+		if strings.TrimSpace(q.Text) == "" || q.Type == "" {
+			return 0, fmt.Errorf("%w: question %d needs a text and a type", ErrInvalidDefinition, i+1)
+		}
+		request := models.QuestionUpdateRequest{
+			Text:             q.Text,
+			Type:             q.Type,
+			Required:         q.Required,
+			Section:          q.Section,
+			DisplayRules:     q.DisplayRules,
+			SkipRules:        q.SkipRules,
+			RandomizeOptions: q.RandomizeOptions,
+		}
+		for _, option := range q.Options {
+			request.Options = append(request.Options, models.OptionUpdateRequest{Text: option})
+		}
+		if q.Config != nil {
+			config, err := json.Marshal(q.Config)
+			if err != nil {
+				return 0, fmt.Errorf("%w: config of question %d: %v", ErrInvalidDefinition, i+1, err)
+			}
+			request.Config = config
+		}
+		questions = append(questions, request)
+	}
+
+	return s.CreateSurvey(ctx, survey, questions)
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/VitaliySynytskyi/survey-platform/shared/questiontypes"
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/repository/mock"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSurveyDefinitions(t *testing.T) {
+	mockRepo := mock.NewMockRepository()
+	service := NewSurveyService(mockRepo)
+	userCtx := setupTestContext(1, []string{"user"})
+
+	maxResponses := 50
+	startDate := time.Date(2030, 3, 1, 9, 0, 0, 0, time.UTC)
+	surveyID, err := service.CreateSurvey(userCtx, &models.Survey{
+		Title:          "Onboarding",
+		Description:    "First week check-in",
+		StartDate:      startDate,
+		EndDate:        startDate.AddDate(0, 0, 14),
+		MaxResponses:   &maxResponses,
+		AllowAnonymous: true,
+		Sections:       []*models.Section{{Title: "Start"}, {Title: "Details", RandomizeQuestions: true}},
+	}, []models.QuestionUpdateRequest{
+		{Text: "Team", Type: questiontypes.QuestionTypeDropdown, Section: 1, Required: true, RandomizeOptions: true,
+			Options: []models.OptionUpdateRequest{{Text: "Sales"}, {Text: "Engineering"}}},
+		{Text: "How is {{Q1}} treating you?", Type: questiontypes.QuestionTypeLinearScale, Section: 2,
+			Config:       json.RawMessage(`{"min": 1, "max": 5, "step": 1, "min_label": "Badly"}`),
+			DisplayRules: []models.DisplayRule{{Question: 1, Operator: models.LogicOperatorEquals, Value: "Sales"}}},
+		{Text: "Anything else?", Type: questiontypes.QuestionTypeText, Section: 2},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error creating survey: %v", err)
+	}
+	// Questions added one by one can leave gaps in OrderNum; the document numbers them by position
+	for _, question := range mockRepo.Questions {
+		question.OrderNum *= 10
+		for i := range question.DisplayRules {
+			question.DisplayRules[i].Question *= 10
+		}
+		if question.Type == questiontypes.QuestionTypeLinearScale {
+			question.Text = "How is {{Q10}} treating you?"
+		}
+	}
+
+	definition, err := service.ExportSurveyDefinition(userCtx, surveyID)
+	if err != nil {
+		t.Fatalf("Unexpected error exporting survey: %v", err)
+	}
+	if definition.SchemaVersion != models.SurveyDefinitionSchemaVersion || definition.Title != "Onboarding" || *definition.Settings.MaxResponses != 50 {
+		t.Errorf("Expected the survey's settings in the document, got %+v", definition)
+	}
+	if settings := definition.Settings; settings.StartDate == nil || !settings.StartDate.Equal(startDate) || settings.EndDate == nil || !settings.EndDate.Equal(startDate.AddDate(0, 0, 14)) {
+		t.Errorf("Expected the schedule in the document, got %+v", settings)
+	}
+	if len(definition.Sections) != 2 || !definition.Sections[1].RandomizeQuestions {
+		t.Errorf("Expected both sections, got %+v", definition.Sections)
+	}
+	scale := definition.Questions[1]
+	if scale.Text != "How is {{Q1}} treating you?" || scale.DisplayRules[0].Question != 1 || scale.Section != 2 {
+		t.Errorf("Expected pipes, rules and sections to refer to positions, got %+v", scale)
+	}
+	if scale.Config["min_label"] != "Badly" {
+		t.Errorf("Expected the config as an object, got %v", scale.Config)
+	}
+	if team := definition.Questions[0]; !reflect.DeepEqual(team.Options, []string{"Sales", "Engineering"}) || !team.RandomizeOptions {
+		t.Errorf("Expected the options in order, got %+v", team)
+	}
+
+	t.Run("YAML round trip", func(t *testing.T) {
+		data, err := yaml.Marshal(definition)
+		if err != nil {
+			t.Fatalf("Unexpected error encoding YAML: %v", err)
+		}
+		var decoded models.SurveyDefinition
+		if err := yaml.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("Unexpected error decoding YAML: %v", err)
+		}
+
+		importedID, err := service.ImportSurveyDefinition(setupTestContext(2, []string{"user"}), &decoded)
+		if err != nil {
+			t.Fatalf("Unexpected error importing survey: %v", err)
+		}
+		imported, _ := mockRepo.GetSurvey(userCtx, importedID)
+		if imported.CreatorID != 2 {
+			t.Errorf("Expected the importing user to own the survey, got %d", imported.CreatorID)
+		}
+		exported, err := newSurveyDefinition(imported)
+		if err != nil {
+			t.Fatalf("Unexpected error exporting imported survey: %v", err)
+		}
+		if !reflect.DeepEqual(exported, definition) {
+			t.Errorf("Expected the imported survey to export the same document\ngot  %+v\nwant %+v", exported, definition)
+		}
+	})
+
+	t.Run("Invalid documents", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			definition models.SurveyDefinition
+			wantErr    error
+		}{
+			{"Missing schema version", models.SurveyDefinition{Title: "A"}, ErrInvalidDefinition},
+			{"Newer schema version", models.SurveyDefinition{SchemaVersion: models.SurveyDefinitionSchemaVersion + 1, Title: "A"}, ErrInvalidDefinition},
+			{"Missing title", models.SurveyDefinition{SchemaVersion: 1}, ErrInvalidDefinition},
+			{"Question without type", models.SurveyDefinition{SchemaVersion: 1, Title: "A", Questions: []models.QuestionDefinition{{Text: "Q"}}}, ErrInvalidDefinition},
+			{"Unknown question type", models.SurveyDefinition{SchemaVersion: 1, Title: "A", Questions: []models.QuestionDefinition{{Text: "Q", Type: "essay"}}}, ErrInvalidQuestion},
+			{"End date not after start date", models.SurveyDefinition{SchemaVersion: 1, Title: "A", Settings: models.SurveyDefinitionSettings{StartDate: &startDate, EndDate: &startDate}}, ErrInvalidSchedule},
+			{"Unknown section", models.SurveyDefinition{SchemaVersion: 1, Title: "A", Questions: []models.QuestionDefinition{{Text: "Q", Type: "text", Section: 1}}}, ErrInvalidSection},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				surveys := len(mockRepo.Surveys)
+				if _, err := service.ImportSurveyDefinition(userCtx, &tt.definition); !errors.Is(err, tt.wantErr) {
+					t.Errorf("Expected %v, got %v", tt.wantErr, err)
+				}
+				if len(mockRepo.Surveys) != surveys {
+					t.Error("Expected no survey to be created")
+				}
+			})
+		}
+	})
+}
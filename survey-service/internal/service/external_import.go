@@ -0,0 +1,240 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/VitaliySynytskyi/survey-platform/shared/questiontypes"
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
+)
+
+// ErrInvalidImport is returned for files from other tools that cannot be read
+var ErrInvalidImport = errors.New("invalid import file")
+
+// ImportExternalSurvey creates a survey owned by the current user from a file exported by another tool, see
+// models.ImportFormatLimeSurvey and models.ImportFormatQTI. Questions are mapped onto the platform's question types;
+// anything that cannot be mapped is left out or simplified and listed in the report. The survey is created
+// inactive through CreateSurvey, so it is checked like any other survey and created in one transaction.
+func (s *SurveyService) ImportExternalSurvey(ctx context.Context, format string, data []byte) (*models.ImportReport, error) {
+	var converter *surveyConverter
+	var err error
+	switch format {
+	case models.ImportFormatLimeSurvey:
+		converter, err = convertLimeSurvey(data)
+	case models.ImportFormatQTI:
+		converter, err = convertQTI(data)
+	default:
+		return nil, fmt.Errorf("%w: unknown format %q", ErrInvalidImport, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	surveyID, err := s.ImportSurveyDefinition(ctx, &converter.definition)
+	if err != nil {
+		return nil, err
+	}
+	issues := converter.issues
+	if issues == nil {
+		issues = []models.ImportIssue{}
+	}
+	return &models.ImportReport{
+		SurveyID:  surveyID,
+		Format:    format,
+		Questions: len(converter.definition.Questions),
+		Issues:    issues,
+	}, nil
+}
+
+// surveyConverter collects the definition of an imported survey and the issues found while mapping it
+type surveyConverter struct {
+	definition models.SurveyDefinition
+	issues     []models.ImportIssue
+}
+
+func newSurveyConverter(title string) *surveyConverter {
+	if strings.TrimSpace(title) == "" {
+		title = "Imported survey"
+	}
+	return &surveyConverter{definition: models.SurveyDefinition{
+		SchemaVersion: models.SurveyDefinitionSchemaVersion,
+		Title:         title,
+		Questions:     []models.QuestionDefinition{},
+	}}
+}
+
+func (c *surveyConverter) report(source, format string, args ...interface{}) {
+	c.issues = append(c.issues, models.ImportIssue{Source: source, Message: fmt.Sprintf(format, args...)})
+}
+
+// addQuestion appends a mapped question and returns its position. A question the platform would reject, such as a
+// matrix with too many columns, is reported and left out instead of failing the whole import; 0 is returned for it.
+func (c *surveyConverter) addQuestion(source string, question models.QuestionDefinition) int {
+	position := len(c.definition.Questions) + 1
+	var config json.RawMessage
+	if question.Config != nil {
+		config, _ = json.Marshal(question.Config)
+	}
+	if _, err := validateQuestionType(position, question.Type, config, len(question.Options)); err != nil {
+		reason := strings.TrimPrefix(err.Error(), ErrInvalidQuestion.Error()+": ")
+		c.report(source, "not imported: %s", strings.Replace(reason, fmt.Sprintf("question %d", position), "question", 1))
+		return 0
+	}
+	if question.RandomizeOptions {
+		if def, _ := questiontypes.LookupQuestionType(question.Type); !def.HasOptions {
+			question.RandomizeOptions = false
+		}
+	}
+	c.definition.Questions = append(c.definition.Questions, question)
+	return position
+}
+
+// xmlNode is an element or a run of text of a parsed XML document. Elements and attributes are known by their local
+// names, as the formats imported are read without regard to namespaces.
+type xmlNode struct {
+	name     string // Empty for text
+	attrs    map[string]string
+	children []*xmlNode
+	text     string
+}
+
+// parseXMLTree reads a whole XML document into a tree, keeping text and elements in document order
+func parseXMLTree(data []byte) (*xmlNode, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Entity = xml.HTMLEntity
+
+	root := &xmlNode{}
+	stack := []*xmlNode{root}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+		parent := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: t.Name.Local, attrs: make(map[string]string, len(t.Attr))}
+			for _, attr := range t.Attr {
+				node.attrs[attr.Name.Local] = attr.Value
+			}
+			parent.children = append(parent.children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			parent.children = append(parent.children, &xmlNode{text: string(t)})
+		}
+	}
+	for _, node := range root.children {
+		if node.name != "" {
+			return node, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: the file is not an XML document", ErrInvalidImport)
+}
+
+// child returns the first child element with the given name, or nil
+func (n *xmlNode) child(name string) *xmlNode {
+	if n == nil {
+		return nil
+	}
+	for _, child := range n.children {
+		if child.name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// elements returns the child elements with the given name
+func (n *xmlNode) elements(name string) []*xmlNode {
+	if n == nil {
+		return nil
+	}
+	var result []*xmlNode
+	for _, child := range n.children {
+		if child.name == name {
+			result = append(result, child)
+		}
+	}
+	return result
+}
+
+// find returns the descendant elements matching match, in document order, without looking inside the matches
+func (n *xmlNode) find(match func(*xmlNode) bool) []*xmlNode {
+	if n == nil {
+		return nil
+	}
+	var result []*xmlNode
+	for _, child := range n.children {
+		if child.name == "" {
+			continue
+		}
+		if match(child) {
+			result = append(result, child)
+		} else {
+			result = append(result, child.find(match)...)
+		}
+	}
+	return result
+}
+
+// blockElements start a new line of text; text around them is kept apart when elements are flattened
+var blockElements = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "tr": true, "td": true, "th": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+// textContent returns the text of the node and its descendants with whitespace collapsed,
+// leaving out the descendants matching skip
+func (n *xmlNode) textContent(skip func(*xmlNode) bool) string {
+	if n == nil {
+		return ""
+	}
+	var b strings.Builder
+	var walk func(*xmlNode)
+	walk = func(node *xmlNode) {
+		for _, child := range node.children {
+			switch {
+			case child.name == "":
+				b.WriteString(child.text)
+			case skip != nil && skip(child):
+			case blockElements[child.name]:
+				b.WriteString(" ")
+				walk(child)
+				b.WriteString(" ")
+			default:
+				walk(child)
+			}
+		}
+	}
+	walk(n)
+	return collapseWhitespace(b.String())
+}
+
+var (
+	htmlBreakPattern = regexp.MustCompile(`(?i)<(br|/p|/div|/li|/h[1-6])\b[^>]*>`)
+	htmlTagPattern   = regexp.MustCompile(`<[^>]*>`)
+)
+
+// plainText turns the HTML that other tools store question texts as into plain text
+func plainText(text string) string {
+	text = htmlBreakPattern.ReplaceAllString(text, " ")
+	text = htmlTagPattern.ReplaceAllString(text, "")
+	return collapseWhitespace(html.UnescapeString(text))
+}
+
+func collapseWhitespace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
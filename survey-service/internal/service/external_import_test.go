@@ -0,0 +1,295 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/VitaliySynytskyi/survey-platform/shared/questiontypes"
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/repository/mock"
+)
+
+// limeSurveyFile is a LimeSurvey 3 survey structure file in two languages, trimmed to the columns the importer reads
+const limeSurveyFile = `<?xml version="1.0" encoding="UTF-8"?>
+<document>
+ <LimeSurveyDocType>Survey</LimeSurveyDocType>
+ <DBVersion>359</DBVersion>
+ <languages><language>en</language><language>de</language></languages>
+ <answers><rows>
+  <row><qid>10</qid><code>A2</code><answer><![CDATA[Engineering]]></answer><sortorder>2</sortorder><language>en</language><scale_id>0</scale_id></row>
+  <row><qid>10</qid><code>A1</code><answer><![CDATA[<b>Sales</b>]]></answer><sortorder>1</sortorder><language>en</language><scale_id>0</scale_id></row>
+  <row><qid>10</qid><code>A1</code><answer><![CDATA[Vertrieb]]></answer><sortorder>1</sortorder><language>de</language><scale_id>0</scale_id></row>
+  <row><qid>13</qid><code>A1</code><answer><![CDATA[Agree]]></answer><sortorder>1</sortorder><language>en</language><scale_id>0</scale_id></row>
+  <row><qid>13</qid><code>A2</code><answer><![CDATA[Disagree]]></answer><sortorder>2</sortorder><language>en</language><scale_id>0</scale_id></row>
+ </rows></answers>
+ <groups><rows>
+  <row><gid>2</gid><group_name><![CDATA[Work]]></group_name><group_order>1</group_order><description/><language>en</language></row>
+  <row><gid>1</gid><group_name><![CDATA[About you]]></group_name><group_order>0</group_order><description><![CDATA[<p>A few basics</p>]]></description><language>en</language></row>
+ </rows></groups>
+ <questions><rows>
+  <row><qid>10</qid><parent_qid>0</parent_qid><gid>1</gid><type>L</type><title>team</title><question><![CDATA[<p>Which team are you in?</p>]]></question><other>Y</other><mandatory>Y</mandatory><question_order>1</question_order><language>en</language><relevance>1</relevance></row>
+  <row><qid>10</qid><parent_qid>0</parent_qid><gid>1</gid><type>L</type><title>team</title><question><![CDATA[Welches Team?]]></question><other>Y</other><mandatory>Y</mandatory><question_order>1</question_order><language>de</language><relevance>1</relevance></row>
+  <row><qid>11</qid><parent_qid>0</parent_qid><gid>1</gid><type>N</type><title>age</title><question><![CDATA[How old are you?]]></question><other>N</other><mandatory>N</mandatory><question_order>2</question_order><language>en</language><relevance>1</relevance></row>
+  <row><qid>12</qid><parent_qid>0</parent_qid><gid>2</gid><type>T</type><title>why</title><question><![CDATA[Why did you join {team}? Tell us about {age.shown} and {TOKEN:FIRSTNAME}]]></question><other>N</other><mandatory>N</mandatory><question_order>1</question_order><language>en</language><relevance><![CDATA[team == "A1"]]></relevance></row>
+  <row><qid>13</qid><parent_qid>0</parent_qid><gid>2</gid><type>F</type><title>stmt</title><question><![CDATA[Statements]]></question><other>N</other><mandatory>N</mandatory><question_order>2</question_order><language>en</language><relevance>1</relevance></row>
+  <row><qid>14</qid><parent_qid>0</parent_qid><gid>2</gid><type>K</type><title>nums</title><question><![CDATA[Numbers]]></question><other>N</other><mandatory>N</mandatory><question_order>3</question_order><language>en</language><relevance>1</relevance></row>
+  <row><qid>15</qid><parent_qid>0</parent_qid><gid>2</gid><type>M</type><title>tools</title><question><![CDATA[Tools you use]]></question><other>N</other><mandatory>N</mandatory><question_order>4</question_order><language>en</language><relevance>1</relevance></row>
+ </rows></questions>
+ <subquestions><rows>
+  <row><qid>21</qid><parent_qid>13</parent_qid><gid>2</gid><type>T</type><title>SQ1</title><question><![CDATA[I like my job]]></question><question_order>1</question_order><language>en</language><scale_id>0</scale_id></row>
+  <row><qid>22</qid><parent_qid>13</parent_qid><gid>2</gid><type>T</type><title>SQ2</title><question><![CDATA[I like my team]]></question><question_order>2</question_order><language>en</language><scale_id>0</scale_id></row>
+ </rows></subquestions>
+ <question_attributes><rows>
+  <row><qid>10</qid><attribute>random_order</attribute><value>1</value></row>
+  <row><qid>11</qid><attribute>num_value_int_only</attribute><value>1</value></row>
+  <row><qid>11</qid><attribute>min_num_value_n</attribute><value>16</value></row>
+ </rows></question_attributes>
+ <surveys><rows>
+  <row><sid>123</sid><language>en</language><alloweditaftercompletion>Y</alloweditaftercompletion><active>Y</active></row>
+ </rows></surveys>
+ <surveys_languagesettings><rows>
+  <row><surveyls_survey_id>123</surveyls_survey_id><surveyls_language>en</surveyls_language><surveyls_title><![CDATA[Staff &amp; teams]]></surveyls_title><surveyls_description><![CDATA[<p>Yearly</p>]]></surveyls_description><language>en</language></row>
+ </rows></surveys_languagesettings>
+</document>`
+
+// limeSurveyL10nFile is a LimeSurvey 5 survey structure file, which keeps texts in separate localization tables
+const limeSurveyL10nFile = `<?xml version="1.0" encoding="UTF-8"?>
+<document>
+ <LimeSurveyDocType>Survey</LimeSurveyDocType>
+ <languages><language>en</language></languages>
+ <answers><rows>
+  <row><aid>1</aid><qid>5</qid><code>A1</code><sortorder>1</sortorder><scale_id>0</scale_id></row>
+  <row><aid>2</aid><qid>5</qid><code>A2</code><sortorder>2</sortorder><scale_id>0</scale_id></row>
+ </rows></answers>
+ <answer_l10ns><rows>
+  <row><id>1</id><aid>1</aid><answer><![CDATA[Red]]></answer><language>en</language></row>
+  <row><id>2</id><aid>2</aid><answer><![CDATA[Blue]]></answer><language>en</language></row>
+ </rows></answer_l10ns>
+ <groups><rows><row><gid>3</gid><group_order>1</group_order></row></rows></groups>
+ <group_l10ns><rows><row><id>1</id><gid>3</gid><group_name><![CDATA[Colours]]></group_name><language>en</language></row></rows></group_l10ns>
+ <questions><rows>
+  <row><qid>5</qid><parent_qid>0</parent_qid><gid>3</gid><type>!</type><title>colour</title><other>N</other><mandatory>N</mandatory><question_order>1</question_order></row>
+ </rows></questions>
+ <question_l10ns><rows>
+  <row><id>1</id><qid>5</qid><question><![CDATA[Favourite colour]]></question><language>en</language></row>
+ </rows></question_l10ns>
+ <surveys><rows><row><sid>9</sid><language>en</language></row></rows></surveys>
+ <surveys_languagesettings><rows>
+  <row><surveyls_language>en</surveyls_language><surveyls_title><![CDATA[Colours]]></surveyls_title></row>
+ </rows></surveys_languagesettings>
+</document>`
+
+const qtiChoiceItem = `<?xml version="1.0" encoding="UTF-8"?>
+<assessmentItem xmlns="http://www.imsglobal.org/xsd/imsqti_v2p1" identifier="fruit" title="Fruit">
+ <responseDeclaration identifier="RESPONSE" cardinality="single" baseType="identifier">
+  <correctResponse><value>A</value></correctResponse>
+ </responseDeclaration>
+ <responseDeclaration identifier="AGE" cardinality="single" baseType="integer"/>
+ <itemBody>
+  <choiceInteraction responseIdentifier="RESPONSE" shuffle="true" maxChoices="1">
+   <prompt>Which fruit do you <em>prefer</em>?</prompt>
+   <simpleChoice identifier="A">Apple</simpleChoice>
+   <simpleChoice identifier="B">Banana</simpleChoice>
+  </choiceInteraction>
+  <p>I am <textEntryInteraction responseIdentifier="AGE"/> years old.</p>
+  <hotspotInteraction responseIdentifier="SPOT"/>
+ </itemBody>
+</assessmentItem>`
+
+func TestImportExternalSurvey(t *testing.T) {
+	mockRepo := mock.NewMockRepository()
+	service := NewSurveyService(mockRepo)
+	userCtx := setupTestContext(1, []string{"user"})
+
+	imported := func(t *testing.T, report *models.ImportReport) *models.SurveyDefinition {
+		t.Helper()
+		survey, err := mockRepo.GetSurvey(userCtx, report.SurveyID)
+		if err != nil {
+			t.Fatalf("Expected the survey to be created, got %v", err)
+		}
+		definition, err := newSurveyDefinition(survey)
+		if err != nil {
+			t.Fatalf("Unexpected error reading imported survey: %v", err)
+		}
+		return definition
+	}
+	hasIssue := func(report *models.ImportReport, source, text string) bool {
+		for _, issue := range report.Issues {
+			if issue.Source == source && strings.Contains(issue.Message, text) {
+				return true
+			}
+		}
+		return false
+	}
+
+	t.Run("LimeSurvey", func(t *testing.T) {
+		report, err := service.ImportExternalSurvey(userCtx, models.ImportFormatLimeSurvey, []byte(limeSurveyFile))
+		if err != nil {
+			t.Fatalf("Unexpected error importing survey: %v", err)
+		}
+		definition := imported(t, report)
+		if definition.Title != "Staff & teams" || definition.Description != "Yearly" {
+			t.Errorf("Expected the title and description in plain text, got %q and %q", definition.Title, definition.Description)
+		}
+		if definition.Settings.IsActive || !definition.Settings.AllowResponseEdits || !definition.Settings.AllowAnonymous {
+			t.Errorf("Expected an inactive survey with the file's settings, got %+v", definition.Settings)
+		}
+		wantSections := []models.SectionDefinition{{Title: "About you", Description: "A few basics"}, {Title: "Work"}}
+		if !reflect.DeepEqual(definition.Sections, wantSections) {
+			t.Errorf("Expected the groups as sections in order, got %+v", definition.Sections)
+		}
+		if report.Questions != 4 || len(definition.Questions) != 4 {
+			t.Fatalf("Expected 4 questions, got %d", len(definition.Questions))
+		}
+
+		team, age, why, statements := definition.Questions[0], definition.Questions[1], definition.Questions[2], definition.Questions[3]
+		if team.Text != "Which team are you in?" || team.Type != questiontypes.QuestionTypeSingleChoice || !team.Required || !team.RandomizeOptions ||
+			!reflect.DeepEqual(team.Options, []string{"Sales", "Engineering"}) || team.Config["allow_other"] != true {
+			t.Errorf("Expected a required, shuffled single choice in the base language with Other, got %+v", team)
+		}
+		if age.Type != questiontypes.QuestionTypeText || age.Config["format"] != questiontypes.TextFormatNumber || age.Config["min"] != 16.0 || age.Config["decimals"] != 0.0 {
+			t.Errorf("Expected a whole number question from 16, got %+v", age)
+		}
+		if why.Text != "Why did you join {{Q1}}? Tell us about {{Q2}} and {TOKEN:FIRSTNAME}" || why.Type != questiontypes.QuestionTypeParagraph || why.Section != 2 {
+			t.Errorf("Expected references to earlier answers as pipes, got %+v", why)
+		}
+		wantMatrix := map[string]interface{}{"rows": []interface{}{"I like my job", "I like my team"}, "columns": []interface{}{"Agree", "Disagree"}}
+		if statements.Type != questiontypes.QuestionTypeMatrix || !reflect.DeepEqual(statements.Config, wantMatrix) {
+			t.Errorf("Expected an array as a matrix, got %+v", statements)
+		}
+
+		if !hasIssue(report, "why", `condition "team == \"A1\"" was not imported`) || !hasIssue(report, "why", "expressions") {
+			t.Errorf("Expected the condition and the unknown expression to be reported, got %+v", report.Issues)
+		}
+		if !hasIssue(report, "nums", "Multiple numerical input is not supported") || !hasIssue(report, "", "base language (en)") {
+			t.Errorf("Expected the unsupported type and the languages to be reported, got %+v", report.Issues)
+		}
+		if !hasIssue(report, "tools", "needs at least one option") {
+			t.Errorf("Expected the question without options to be reported, got %+v", report.Issues)
+		}
+	})
+
+	t.Run("LimeSurvey with localization tables", func(t *testing.T) {
+		report, err := service.ImportExternalSurvey(userCtx, models.ImportFormatLimeSurvey, []byte(limeSurveyL10nFile))
+		if err != nil {
+			t.Fatalf("Unexpected error importing survey: %v", err)
+		}
+		definition := imported(t, report)
+		if len(definition.Sections) != 1 || definition.Sections[0].Title != "Colours" {
+			t.Errorf("Expected the group name from its localization, got %+v", definition.Sections)
+		}
+		if len(definition.Questions) != 1 || definition.Questions[0].Text != "Favourite colour" ||
+			!reflect.DeepEqual(definition.Questions[0].Options, []string{"Red", "Blue"}) || definition.Questions[0].Type != questiontypes.QuestionTypeDropdown {
+			t.Errorf("Expected a dropdown with localized texts, got %+v", definition.Questions)
+		}
+		if len(report.Issues) != 0 {
+			t.Errorf("Expected no issues, got %+v", report.Issues)
+		}
+	})
+
+	t.Run("QTI item", func(t *testing.T) {
+		report, err := service.ImportExternalSurvey(userCtx, models.ImportFormatQTI, []byte(qtiChoiceItem))
+		if err != nil {
+			t.Fatalf("Unexpected error importing survey: %v", err)
+		}
+		definition := imported(t, report)
+		if definition.Title != "Fruit" || len(definition.Questions) != 2 {
+			t.Fatalf("Expected 2 questions of the item, got %+v", definition)
+		}
+		fruit, age := definition.Questions[0], definition.Questions[1]
+		if fruit.Text != "Which fruit do you prefer?" || fruit.Type != questiontypes.QuestionTypeSingleChoice || !fruit.RandomizeOptions ||
+			!reflect.DeepEqual(fruit.Options, []string{"Apple", "Banana"}) {
+			t.Errorf("Expected a shuffled single choice, got %+v", fruit)
+		}
+		if age.Text != "I am years old." || age.Type != questiontypes.QuestionTypeShortAnswer || age.Config["decimals"] != 0.0 {
+			t.Errorf("Expected a whole number text entry worded by the item, got %+v", age)
+		}
+		if !hasIssue(report, "fruit.SPOT", "hotspotInteraction is not supported") || !hasIssue(report, "", "scoring") {
+			t.Errorf("Expected the hotspot and the scoring to be reported, got %+v", report.Issues)
+		}
+	})
+
+	t.Run("QTI 3.0 content package", func(t *testing.T) {
+		files := map[string]string{
+			"imsmanifest.xml": `<manifest><resources>
+				<resource type="imsqti_test_xmlv3p0" href="tests/test.xml"/>
+				<resource type="imsqti_item_xmlv3p0" href="items/scale.xml"/>
+			</resources></manifest>`,
+			"tests/test.xml": `<qti-assessment-test identifier="t" title="Course feedback"><qti-test-part identifier="p">
+				<qti-assessment-section identifier="s1" title="Rating"><qti-ordering shuffle="true"/>
+					<qti-assessment-item-ref identifier="i1" href="../items/scale.xml"/>
+					<qti-assessment-item-ref identifier="i2" href="../items/missing.xml"/>
+				</qti-assessment-section>
+				<qti-assessment-section identifier="s2" title="Comments">
+					<qti-assessment-item-ref identifier="i3" href="../items/comments.xml"/>
+				</qti-assessment-section>
+			</qti-test-part></qti-assessment-test>`,
+			"items/scale.xml": `<qti-assessment-item identifier="scale" title="Scale"><qti-item-body>
+				<qti-slider-interaction response-identifier="R" lower-bound="0" upper-bound="10" step="2"><qti-prompt>Rate the course</qti-prompt></qti-slider-interaction>
+			</qti-item-body></qti-assessment-item>`,
+			"items/comments.xml": `<qti-assessment-item identifier="comments" title="Comments"><qti-item-body>
+				<p>Anything else?</p><qti-extended-text-interaction response-identifier="R"/>
+			</qti-item-body></qti-assessment-item>`,
+		}
+		var buf bytes.Buffer
+		writer := zip.NewWriter(&buf)
+		for name, content := range files {
+			w, _ := writer.Create(name)
+			w.Write([]byte(content))
+		}
+		writer.Close()
+
+		report, err := service.ImportExternalSurvey(userCtx, models.ImportFormatQTI, buf.Bytes())
+		if err != nil {
+			t.Fatalf("Unexpected error importing package: %v", err)
+		}
+		definition := imported(t, report)
+		wantSections := []models.SectionDefinition{{Title: "Rating", RandomizeQuestions: true}, {Title: "Comments"}}
+		if definition.Title != "Course feedback" || !reflect.DeepEqual(definition.Sections, wantSections) {
+			t.Errorf("Expected the test's title and sections, got %q and %+v", definition.Title, definition.Sections)
+		}
+		if len(definition.Questions) != 2 {
+			t.Fatalf("Expected 2 questions, got %+v", definition.Questions)
+		}
+		scale, _ := json.Marshal(definition.Questions[0].Config)
+		if definition.Questions[0].Type != questiontypes.QuestionTypeLinearScale || string(scale) != `{"max":10,"min":0,"step":2}` || definition.Questions[0].Section != 1 {
+			t.Errorf("Expected a 0-10 scale in the first section, got %+v", definition.Questions[0])
+		}
+		if comments := definition.Questions[1]; comments.Text != "Anything else?" || comments.Type != questiontypes.QuestionTypeParagraph || comments.Section != 2 {
+			t.Errorf("Expected a paragraph question in the second section, got %+v", comments)
+		}
+		if !hasIssue(report, "i2", "was not found") {
+			t.Errorf("Expected the missing item to be reported, got %+v", report.Issues)
+		}
+	})
+
+	t.Run("Unreadable files", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			format string
+			data   string
+		}{
+			{"Unknown format", "csv", "a,b"},
+			{"Not XML", models.ImportFormatLimeSurvey, "{}"},
+			{"Not a survey structure", models.ImportFormatLimeSurvey, "<document><LimeSurveyDocType>Responses</LimeSurveyDocType></document>"},
+			{"QTI 1.2", models.ImportFormatQTI, "<questestinterop><item/></questestinterop>"},
+			{"QTI test without its items", models.ImportFormatQTI, "<assessmentTest/>"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				surveys := len(mockRepo.Surveys)
+				if _, err := service.ImportExternalSurvey(userCtx, tt.format, []byte(tt.data)); !errors.Is(err, ErrInvalidImport) {
+					t.Errorf("Expected ErrInvalidImport, got %v", err)
+				}
+				if len(mockRepo.Surveys) != surveys {
+					t.Error("Expected no survey to be created")
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,352 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VitaliySynytskyi/survey-platform/shared/questiontypes"
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
+)
+
+// lssRow is a row of a table of a LimeSurvey survey structure file, by column name
+type lssRow map[string]string
+
+// lssDocument is a parsed LimeSurvey survey structure (.lss) file. Files are database dumps of the survey's tables;
+// texts are kept per language, either on the rows themselves (LimeSurvey 3 and older) or in separate *_l10ns tables.
+type lssDocument struct {
+	root     *xmlNode
+	language string // Base language of the survey; rows in other languages are ignored
+}
+
+func (d *lssDocument) rows(table string) []lssRow {
+	var rows []lssRow
+	for _, node := range d.root.child(table).child("rows").elements("row") {
+		row := make(lssRow)
+		for _, field := range node.children {
+			if field.name != "" {
+				row[field.name] = strings.TrimSpace(field.textContent(nil))
+			}
+		}
+		if language := row["language"]; language == "" || d.language == "" || language == d.language {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// texts returns a column of a localization table by the ID in key
+func (d *lssDocument) texts(table, key, column string) map[string]string {
+	texts := make(map[string]string)
+	for _, row := range d.rows(table) {
+		texts[row[key]] = row[column]
+	}
+	return texts
+}
+
+func (r lssRow) int(column string) int {
+	value, _ := strconv.Atoi(r[column])
+	return value
+}
+
+// sortRows orders rows by an integer column, keeping the file order between equal values
+func sortRows(rows []lssRow, column string) {
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].int(column) < rows[j].int(column) })
+}
+
+// lssQuestionTypeNames name the LimeSurvey question types that cannot be imported, for the report
+var lssQuestionTypeNames = map[string]string{
+	"1": "Array dual scale",
+	":": "Array (numbers)",
+	";": "Array (texts)",
+	"K": "Multiple numerical input",
+	"Q": "Multiple short text",
+	"I": "Language switch",
+	"*": "Equation",
+	"X": "Text display",
+}
+
+// Answer options of the LimeSurvey question types with fixed answers
+var (
+	lssYesNo          = []string{"Yes", "No"}
+	lssGender         = []string{"Female", "Male"}
+	lssFivePoint      = []string{"1", "2", "3", "4", "5"}
+	lssTenPoint       = []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"}
+	lssYesNoUncertain = []string{"Yes", "Uncertain", "No"}
+	lssIncreaseSame   = []string{"Increase", "Same", "Decrease"}
+)
+
+// lssReferencePattern matches LimeSurvey expressions that show an earlier answer, such as "{Q1}" or "{Q1.shown}"
+var lssReferencePattern = regexp.MustCompile(`\{\s*([A-Za-z][A-Za-z0-9_]*)(?:\.shown)?\s*\}`)
+
+// lssExpressionPattern matches any LimeSurvey expression, such as "{TOKEN:FIRSTNAME}" or "{if(Q1 > 2, 'a', 'b')}"
+var lssExpressionPattern = regexp.MustCompile(`\{[^{}\s][^{}]*\}`)
+
+// convertLimeSurvey maps a LimeSurvey survey structure file onto a survey definition. Groups become sections.
+// Texts are taken in the survey's base language and stripped of HTML.
+func convertLimeSurvey(data []byte) (*surveyConverter, error) {
+	root, err := parseXMLTree(data)
+	if err != nil {
+		return nil, err
+	}
+	if root.name != "document" || root.child("LimeSurveyDocType").textContent(nil) != "Survey" {
+		return nil, fmt.Errorf("%w: not a LimeSurvey survey structure (.lss) file", ErrInvalidImport)
+	}
+
+	doc := &lssDocument{root: root}
+	surveys := doc.rows("surveys")
+	if len(surveys) == 0 {
+		return nil, fmt.Errorf("%w: the file describes no survey", ErrInvalidImport)
+	}
+	settings := surveys[0]
+	doc.language = settings["language"]
+	if doc.language == "" {
+		doc.language = root.child("languages").child("language").textContent(nil)
+	}
+
+	var title, description string
+	if languageSettings := doc.rows("surveys_languagesettings"); len(languageSettings) > 0 {
+		title = plainText(languageSettings[0]["surveyls_title"])
+		description = plainText(languageSettings[0]["surveyls_description"])
+	}
+	c := newSurveyConverter(title)
+	c.definition.Description = description
+	c.definition.Settings = models.SurveyDefinitionSettings{
+		AllowAnonymous:     settings["usetokens"] != "Y",
+		AllowResponseEdits: settings["alloweditaftercompletion"] == "Y",
+	}
+	if languages := root.child("languages").elements("language"); len(languages) > 1 {
+		c.report("", "only the base language (%s) was imported", doc.language)
+	}
+
+	groups := doc.rows("groups")
+	sortRows(groups, "group_order")
+	groupNames := doc.texts("group_l10ns", "gid", "group_name")
+	groupDescriptions := doc.texts("group_l10ns", "gid", "description")
+	sectionPositions := make(map[string]int, len(groups))
+	for i, group := range groups {
+		name, ok := group["group_name"]
+		if !ok {
+			name = groupNames[group["gid"]]
+		}
+		groupDescription, ok := group["description"]
+		if !ok {
+			groupDescription = groupDescriptions[group["gid"]]
+		}
+		sectionPositions[group["gid"]] = i + 1
+		c.definition.Sections = append(c.definition.Sections, models.SectionDefinition{
+			Title:       plainText(name),
+			Description: plainText(groupDescription),
+		})
+	}
+
+	// Subquestions are the rows of arrays and the options of multiple choice questions. Depending on the version
+	// they are listed with the questions or on their own.
+	var questions []lssRow
+	subquestions := make(map[string][]lssRow)
+	for _, row := range append(doc.rows("questions"), doc.rows("subquestions")...) {
+		if parent := row["parent_qid"]; parent != "" && parent != "0" {
+			if row.int("scale_id") == 0 {
+				subquestions[parent] = append(subquestions[parent], row)
+			}
+			continue
+		}
+		questions = append(questions, row)
+	}
+	sort.SliceStable(questions, func(i, j int) bool {
+		gi, gj := sectionPositions[questions[i]["gid"]], sectionPositions[questions[j]["gid"]]
+		if gi != gj {
+			return gi < gj
+		}
+		return questions[i].int("question_order") < questions[j].int("question_order")
+	})
+	questionTexts := doc.texts("question_l10ns", "qid", "question")
+
+	answerTexts := doc.texts("answer_l10ns", "aid", "answer")
+	answers := make(map[string][]lssRow)
+	for _, row := range doc.rows("answers") {
+		if row.int("scale_id") == 0 {
+			answers[row["qid"]] = append(answers[row["qid"]], row)
+		}
+	}
+
+	attributes := make(map[string]map[string]string)
+	for _, row := range doc.rows("question_attributes") {
+		if attributes[row["qid"]] == nil {
+			attributes[row["qid"]] = make(map[string]string)
+		}
+		attributes[row["qid"]][row["attribute"]] = row["value"]
+	}
+
+	questionText := func(row lssRow) string {
+		text, ok := row["question"]
+		if !ok {
+			text = questionTexts[row["qid"]]
+		}
+		return plainText(text)
+	}
+	answerList := func(qid string) []string {
+		rows := answers[qid]
+		sortRows(rows, "sortorder")
+		texts := make([]string, 0, len(rows))
+		for _, row := range rows {
+			text, ok := row["answer"]
+			if !ok {
+				text = answerTexts[row["aid"]]
+			}
+			texts = append(texts, plainText(text))
+		}
+		return texts
+	}
+	subquestionList := func(qid string) []string {
+		rows := subquestions[qid]
+		sortRows(rows, "question_order")
+		texts := make([]string, 0, len(rows))
+		for _, row := range rows {
+			texts = append(texts, questionText(row))
+		}
+		return texts
+	}
+
+	positions := make(map[string]int) // Question code -> position, for rewriting references to earlier answers
+	for _, row := range questions {
+		code, qid := row["title"], row["qid"]
+		attrs := attributes[qid]
+		question := models.QuestionDefinition{
+			Text:             questionText(row),
+			Required:         row["mandatory"] == "Y",
+			Section:          sectionPositions[row["gid"]],
+			RandomizeOptions: attrs["random_order"] == "1",
+		}
+		if question.Text == "" {
+			question.Text = code
+		}
+		question.Text = lssReferencePattern.ReplaceAllStringFunc(question.Text, func(reference string) string {
+			if position, ok := positions[lssReferencePattern.FindStringSubmatch(reference)[1]]; ok {
+				return fmt.Sprintf("{{Q%d}}", position)
+			}
+			return reference
+		})
+		if lssExpressionPattern.MatchString(models.PipePattern.ReplaceAllString(question.Text, "")) {
+			c.report(code, "expressions in the question text were kept as written")
+		}
+
+		allowOther := false
+		switch questionType := row["type"]; questionType {
+		case "L", "O":
+			question.Type, question.Options, allowOther = questiontypes.QuestionTypeSingleChoice, answerList(qid), true
+			if questionType == "O" {
+				c.report(code, "the comment field of the question was not imported")
+			}
+		case "!":
+			question.Type, question.Options, allowOther = questiontypes.QuestionTypeDropdown, answerList(qid), true
+		case "M", "P":
+			question.Type, question.Options, allowOther = questiontypes.QuestionTypeCheckbox, subquestionList(qid), true
+			if questionType == "P" {
+				c.report(code, "the comment fields of the options were not imported")
+			}
+		case "R":
+			question.Type, question.Options = questiontypes.QuestionTypeRanking, answerList(qid)
+		case "Y":
+			question.Type, question.Options = questiontypes.QuestionTypeSingleChoice, lssYesNo
+		case "G":
+			question.Type, question.Options = questiontypes.QuestionTypeSingleChoice, lssGender
+		case "S":
+			question.Type = questiontypes.QuestionTypeShortAnswer
+		case "T", "U":
+			question.Type = questiontypes.QuestionTypeParagraph
+		case "N":
+			question.Type, question.Config = questiontypes.QuestionTypeText, lssNumberConfig(attrs)
+		case "D":
+			question.Type, question.Config = questiontypes.QuestionTypeDate, lssDateConfig(attrs)
+		case "5":
+			question.Type = questiontypes.QuestionTypeLinearScale
+		case "F", "H":
+			question.Type = questiontypes.QuestionTypeMatrix
+			question.Config = map[string]interface{}{"rows": subquestionList(qid), "columns": answerList(qid)}
+		case "A", "B", "C", "E":
+			columns := map[string][]string{"A": lssFivePoint, "B": lssTenPoint, "C": lssYesNoUncertain, "E": lssIncreaseSame}[questionType]
+			question.Type = questiontypes.QuestionTypeMatrix
+			question.Config = map[string]interface{}{"rows": subquestionList(qid), "columns": columns}
+		case "|":
+			question.Type, question.Config = questiontypes.QuestionTypeFileUpload, lssFileUploadConfig(attrs)
+			if attrs["allowed_filetypes"] != "" {
+				c.report(code, "the allowed file types were not imported, any file type is accepted")
+			}
+			if count, _ := strconv.Atoi(attrs["max_num_of_files"]); count > 1 {
+				c.report(code, "respondents can upload one file instead of %d", count)
+			}
+		default:
+			name, ok := lssQuestionTypeNames[questionType]
+			if !ok {
+				name = fmt.Sprintf("%q", questionType)
+			}
+			c.report(code, "not imported: the question type %s is not supported", name)
+			continue
+		}
+
+		if row["other"] == "Y" {
+			if allowOther {
+				config := map[string]interface{}{"allow_other": true}
+				if label := plainText(attrs["other_replace_text"]); label != "" {
+					config["other_label"] = label
+				}
+				question.Config = config
+			} else {
+				c.report(code, "the \"Other\" option was not imported")
+			}
+		}
+		if relevance := row["relevance"]; relevance != "" && relevance != "1" {
+			c.report(code, "the condition %q was not imported, the question is always shown", relevance)
+		}
+
+		if position := c.addQuestion(code, question); position > 0 {
+			positions[code] = position
+		}
+	}
+	return c, nil
+}
+
+// lssNumberConfig maps the limits of a numerical input question onto a text question config
+func lssNumberConfig(attrs map[string]string) map[string]interface{} {
+	config := map[string]interface{}{"format": questiontypes.TextFormatNumber}
+	if min, err := strconv.ParseFloat(attrs["min_num_value_n"], 64); err == nil {
+		config["min"] = min
+	}
+	if max, err := strconv.ParseFloat(attrs["max_num_value_n"], 64); err == nil {
+		config["max"] = max
+	}
+	if attrs["num_value_int_only"] == "1" {
+		config["decimals"] = 0
+	}
+	return config
+}
+
+// lssDateConfig maps the date range of a date question. Ranges given as expressions are left out.
+func lssDateConfig(attrs map[string]string) map[string]interface{} {
+	config := make(map[string]interface{})
+	for attr, key := range map[string]string{"date_min": "min", "date_max": "max"} {
+		value := attrs[attr]
+		if len(value) >= len(questiontypes.DateAnswerLayout) {
+			value = value[:len(questiontypes.DateAnswerLayout)]
+		}
+		if _, err := time.Parse(questiontypes.DateAnswerLayout, value); err == nil {
+			config[key] = value
+		}
+	}
+	if len(config) == 0 {
+		return nil
+	}
+	return config
+}
+
+// lssFileUploadConfig maps the size limit of a file upload question, given in KB
+func lssFileUploadConfig(attrs map[string]string) map[string]interface{} {
+	if size, err := strconv.ParseInt(attrs["max_filesize"], 10, 64); err == nil && size > 0 && size*1024 <= questiontypes.MaxFileSize {
+		return map[string]interface{}{"max_size": size * 1024}
+	}
+	return nil
+}
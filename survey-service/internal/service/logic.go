@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/VitaliySynytskyi/survey-platform/shared/questiontypes"
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// questionRefs finds the questions that display rules, skip rules and pipes refer to. Stored logic refers to
+// questions by ID; requests may also refer to them by position, which is their OrderNum.
+type questionRefs struct {
+	byID      map[int]*models.Question
+	byOrder   map[int]*models.Question
+	ambiguous map[int]bool // Positions several questions share
+}
+
+func newQuestionRefs(questions []*models.Question) *questionRefs {
+	refs := &questionRefs{
+		byID:      make(map[int]*models.Question, len(questions)),
+		byOrder:   make(map[int]*models.Question, len(questions)),
+		ambiguous: make(map[int]bool),
+	}
+	for _, q := range questions {
+		if q.ID != 0 {
+			refs.byID[q.ID] = q
+		}
+		if _, exists := refs.byOrder[q.OrderNum]; exists {
+			refs.ambiguous[q.OrderNum] = true
+		}
+		refs.byOrder[q.OrderNum] = q
+	}
+	return refs
+}
+
+// lookup returns the question from refers to with the given ID, or at the given position when id is 0
+func (r *questionRefs) lookup(from *models.Question, id, position int) (*models.Question, error) {
+	if id != 0 {
+		target, ok := r.byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: question %d refers to question ID %d, which is not part of the survey", ErrInvalidQuestion, from.OrderNum, id)
+		}
+		return target, nil
+	}
+	target, ok := r.byOrder[position]
+	if !ok {
+		return nil, fmt.Errorf("%w: question %d refers to question %d, which does not exist", ErrInvalidQuestion, from.OrderNum, position)
+	}
+	if r.ambiguous[position] {
+		return nil, fmt.Errorf("%w: question %d refers to question %d, but several questions share that position", ErrInvalidQuestion, from.OrderNum, position)
+	}
+	return target, nil
+}
+
+// pipedQuestions returns the questions whose answers q pipes into its text, through stored or written pipes
+func (r *questionRefs) pipedQuestions(q *models.Question) ([]*models.Question, error) {
+	var piped []*models.Question
+	for _, id := range models.PipedQuestionIDs(q.Text) {
+		target, err := r.lookup(q, id, 0)
+		if err != nil {
+			return nil, err
+		}
+		piped = append(piped, target)
+	}
+	for _, position := range models.PipedQuestions(q.Text) {
+		target, err := r.lookup(q, 0, position)
+		if err != nil {
+			return nil, err
+		}
+		piped = append(piped, target)
+	}
+	return piped, nil
+}
+
+// ruleOption returns the option of target a rule of question from compares its answer with: the option with the
+// given ID, or the one with the value as its text. Rules on questions without options compare the answer with the
+// value and get a nil option, as do rules on the "Other" option, which has no ID.
+func ruleOption(from, target *models.Question, optionID int, value string) (*models.QuestionOption, error) {
+	if def, ok := questiontypes.LookupQuestionType(target.Type); !ok || !def.HasOptions {
+		if optionID != 0 {
+			return nil, fmt.Errorf("%w: question %d refers to option %d of question %d, which has no options", ErrInvalidQuestion, from.OrderNum, optionID, target.OrderNum)
+		}
+		return nil, nil
+	}
+	for _, option := range target.Options {
+		if (optionID != 0 && option.ID == optionID) || (optionID == 0 && option.Text == value) {
+			return option, nil
+		}
+	}
+	if optionID == 0 && value == questiontypes.OtherOptionValue {
+		return nil, nil
+	}
+	if optionID != 0 {
+		return nil, fmt.Errorf("%w: question %d refers to option %d, which is not an option of question %d", ErrInvalidQuestion, from.OrderNum, optionID, target.OrderNum)
+	}
+	return nil, fmt.Errorf("%w: question %d compares question %d with %q, which is not one of its options", ErrInvalidQuestion, from.OrderNum, target.OrderNum, value)
+}
+
+// validateQuestionLogic checks the display and skip rules of a survey's questions, and the answers piped into their
+// texts. Display rules and pipes may only depend on earlier questions and skip rules may only jump to later ones,
+// so following the rules always moves forward through the survey and can never form a cycle. Rules on choice
+// questions must name one of their options.
+func validateQuestionLogic(questions []*models.Question) error {
+	refs := newQuestionRefs(questions)
+	for _, q := range questions {
+		for _, rule := range q.DisplayRules {
+			if !models.IsValidLogicOperator(rule.Operator) {
+				return fmt.Errorf("%w: display rule of question %d has unknown operator %q", ErrInvalidQuestion, q.OrderNum, rule.Operator)
+			}
+			target, err := refs.lookup(q, rule.QuestionID, rule.Question)
+			if err != nil {
+				return err
+			}
+			if target.OrderNum >= q.OrderNum {
+				return fmt.Errorf("%w: display rule of question %d must depend on an earlier question, not question %d", ErrInvalidQuestion, q.OrderNum, target.OrderNum)
+			}
+			if _, err := ruleOption(q, target, rule.OptionID, rule.Value); err != nil {
+				return err
+			}
+		}
+		piped, err := refs.pipedQuestions(q)
+		if err != nil {
+			return err
+		}
+		for _, target := range piped {
+			if target.OrderNum >= q.OrderNum {
+				return fmt.Errorf("%w: question %d can only pipe in the answer to an earlier question, not question %d", ErrInvalidQuestion, q.OrderNum, target.OrderNum)
+			}
+		}
+		for _, rule := range q.SkipRules {
+			if !models.IsValidLogicOperator(rule.Operator) {
+				return fmt.Errorf("%w: skip rule of question %d has unknown operator %q", ErrInvalidQuestion, q.OrderNum, rule.Operator)
+			}
+			target, err := refs.lookup(q, rule.SkipToQuestionID, rule.SkipTo)
+			if err != nil {
+				return err
+			}
+			if target.OrderNum <= q.OrderNum {
+				return fmt.Errorf("%w: skip rule of question %d must skip to a later question, not question %d", ErrInvalidQuestion, q.OrderNum, target.OrderNum)
+			}
+			if _, err := ruleOption(q, q, rule.OptionID, rule.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolveQuestionLogic rewrites the rules and pipes of stored questions, which all have IDs along with their
+// options, to refer to questions and options by ID. It returns the questions that changed and need saving.
+func resolveQuestionLogic(questions []*models.Question) ([]*models.Question, error) {
+	refs := newQuestionRefs(questions)
+	var changed []*models.Question
+	for _, q := range questions {
+		modified := false
+		for i, rule := range q.DisplayRules {
+			target, err := refs.lookup(q, rule.QuestionID, rule.Question)
+			if err != nil {
+				return nil, err
+			}
+			option, err := ruleOption(q, target, rule.OptionID, rule.Value)
+			if err != nil {
+				return nil, err
+			}
+			resolved := models.DisplayRule{QuestionID: target.ID, Operator: rule.Operator, Value: rule.Value}
+			if option != nil {
+				resolved.OptionID, resolved.Value = option.ID, ""
+			}
+			if resolved != rule {
+				q.DisplayRules[i] = resolved
+				modified = true
+			}
+		}
+		for i, rule := range q.SkipRules {
+			target, err := refs.lookup(q, rule.SkipToQuestionID, rule.SkipTo)
+			if err != nil {
+				return nil, err
+			}
+			option, err := ruleOption(q, q, rule.OptionID, rule.Value)
+			if err != nil {
+				return nil, err
+			}
+			resolved := models.SkipRule{Operator: rule.Operator, Value: rule.Value, SkipToQuestionID: target.ID}
+			if option != nil {
+				resolved.OptionID, resolved.Value = option.ID, ""
+			}
+			if resolved != rule {
+				q.SkipRules[i] = resolved
+				modified = true
+			}
+		}
+		if len(models.PipedQuestions(q.Text)) > 0 {
+			var lookupErr error
+			q.Text = models.PipePattern.ReplaceAllStringFunc(q.Text, func(pipe string) string {
+				position, _ := strconv.Atoi(models.PipePattern.FindStringSubmatch(pipe)[1])
+				target, err := refs.lookup(q, 0, position)
+				if err != nil {
+					lookupErr = err
+					return pipe
+				}
+				return models.StoredPipe(target.ID)
+			})
+			if lookupErr != nil {
+				return nil, lookupErr
+			}
+			modified = true
+		}
+		if modified {
+			changed = append(changed, q)
+		}
+	}
+	return changed, nil
+}
+
+// remapQuestionLogic points the rules and pipes of a copied question, which refer to questions and options by their
+// IDs in the original survey, at their copies. questionIDs and optionIDs map original IDs to the copies' IDs.
+// The rules are copied rather than changed in place, as the copy shares them with the original.
+func remapQuestionLogic(q *models.Question, questionIDs, optionIDs map[int]int) {
+	if len(q.DisplayRules) > 0 {
+		rules := make([]models.DisplayRule, len(q.DisplayRules))
+		for i, rule := range q.DisplayRules {
+			if rule.QuestionID != 0 {
+				rule.QuestionID = questionIDs[rule.QuestionID]
+			}
+			if rule.OptionID != 0 {
+				rule.OptionID = optionIDs[rule.OptionID]
+			}
+			rules[i] = rule
+		}
+		q.DisplayRules = rules
+	}
+	if len(q.SkipRules) > 0 {
+		rules := make([]models.SkipRule, len(q.SkipRules))
+		for i, rule := range q.SkipRules {
+			if rule.SkipToQuestionID != 0 {
+				rule.SkipToQuestionID = questionIDs[rule.SkipToQuestionID]
+			}
+			if rule.OptionID != 0 {
+				rule.OptionID = optionIDs[rule.OptionID]
+			}
+			rules[i] = rule
+		}
+		q.SkipRules = rules
+	}
+	q.Text = models.StoredPipePattern.ReplaceAllStringFunc(q.Text, func(pipe string) string {
+		id, _ := strconv.Atoi(models.StoredPipePattern.FindStringSubmatch(pipe)[1])
+		return models.StoredPipe(questionIDs[id])
+	})
+}
+
+// hasQuestionLogic reports whether a question has rules or pipes
+func hasQuestionLogic(q *models.Question) bool {
+	return len(q.DisplayRules) > 0 || len(q.SkipRules) > 0 || models.PipePattern.MatchString(q.Text) || models.StoredPipePattern.MatchString(q.Text)
+}
+
+// saveQuestionLogicTx resolves the rules and pipes of a survey's stored questions to IDs and saves the questions
+// that changed
+func (s *SurveyService) saveQuestionLogicTx(ctx context.Context, tx pgx.Tx, questions []*models.Question) error {
+	changed, err := resolveQuestionLogic(questions)
+	if err != nil {
+		return err
+	}
+	for _, q := range changed {
+		if err := s.repo.UpdateQuestionTx(ctx, tx, q); err != nil {
+			return fmt.Errorf("failed to save the logic of question %d: %w", q.ID, err)
+		}
+	}
+	return nil
+}
+
+// validateRequestedLogic validates the rules of a full question list, where each question's position is its OrderNum
+func validateRequestedLogic(requestedQuestions []models.QuestionUpdateRequest) error {
+	return validateQuestionLogic(requestedQuestionModels(requestedQuestions))
+}
+
+// requestedQuestionModels converts a full question list for validation before anything is stored. Each question's
+// position is its OrderNum, and sections are identified by their position in the request instead of an ID.
+// Questions and options keep the IDs the request gives them.
+func requestedQuestionModels(requestedQuestions []models.QuestionUpdateRequest) []*models.Question {
+	questions := make([]*models.Question, 0, len(requestedQuestions))
+	for i, reqQuestion := range requestedQuestions {
+		question := &models.Question{
+			Text:             reqQuestion.Text,
+			Type:             reqQuestion.Type,
+			OrderNum:         i + 1,
+			DisplayRules:     reqQuestion.DisplayRules,
+			SkipRules:        reqQuestion.SkipRules,
+			RandomizeOptions: reqQuestion.RandomizeOptions,
+		}
+		if reqQuestion.ID != nil {
+			question.ID = *reqQuestion.ID
+		}
+		if reqQuestion.Section > 0 {
+			section := reqQuestion.Section
+			question.SectionID = &section
+		}
+		for _, reqOpt := range reqQuestion.Options {
+			option := &models.QuestionOption{Text: reqOpt.Text}
+			if reqOpt.ID != nil {
+				option.ID = *reqOpt.ID
+			}
+			question.Options = append(question.Options, option)
+		}
+		questions = append(questions, question)
+	}
+	return questions
+}
+
+// replaceQuestion returns the survey's questions with question added, or swapped in for the stored question with its ID
+func replaceQuestion(questions []*models.Question, question *models.Question) []*models.Question {
+	result := make([]*models.Question, 0, len(questions)+1)
+	for _, q := range questions {
+		if q.ID != question.ID {
+			result = append(result, q)
+		}
+	}
+	return append(result, question)
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// QuestionLogicMigrationReport summarises a run of MigrateQuestionLogic
+type QuestionLogicMigrationReport struct {
+	Surveys          int // Surveys with display rules, skip rules or pipes
+	UpdatedSurveys   int
+	UpdatedQuestions int
+	SkippedSurveys   int // Surveys whose logic names a question or option that no longer exists, left as they are
+}
+
+// MigrateQuestionLogic stores question and option IDs in the display rules, skip rules and pipes saved before
+// they carried them, which name questions by OrderNum and options by text. Each survey is migrated in its own
+// transaction. With dryRun nothing is written.
+func (s *SurveyService) MigrateQuestionLogic(ctx context.Context, dryRun bool) (*QuestionLogicMigrationReport, error) {
+	surveys, err := s.repo.ListSurveysWithQuestionLogic(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &QuestionLogicMigrationReport{}
+	for _, survey := range surveys {
+		report.Surveys++
+		updated, err := s.migrateSurveyLogic(ctx, survey.ID, dryRun)
+		if errors.Is(err, ErrInvalidQuestion) {
+			log.Printf("[SERVICE_WARN] MigrateQuestionLogic: Skipping SurveyID %d: %v", survey.ID, err)
+			report.SkippedSurveys++
+			continue
+		}
+		if err != nil {
+			return report, fmt.Errorf("failed to migrate survey %d: %w", survey.ID, err)
+		}
+		if updated > 0 {
+			report.UpdatedSurveys++
+			report.UpdatedQuestions += updated
+		}
+	}
+	return report, nil
+}
+
+// migrateSurveyLogic resolves the logic of one survey's questions and returns how many of them changed
+func (s *SurveyService) migrateSurveyLogic(ctx context.Context, surveyID int, dryRun bool) (updated int, err error) {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+			panic(p)
+		} else if err != nil || dryRun {
+			_ = tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	questions, err := s.repo.GetQuestionsBySurveyIDTx(ctx, tx, surveyID)
+	if err != nil {
+		return 0, err
+	}
+	changed, err := resolveQuestionLogic(questions)
+	if err != nil {
+		return 0, err
+	}
+	if dryRun {
+		return len(changed), nil
+	}
+	for _, q := range changed {
+		if err := s.repo.UpdateQuestionTx(ctx, tx, q); err != nil {
+			return 0, fmt.Errorf("failed to save the logic of question %d: %w", q.ID, err)
+		}
+	}
+	return len(changed), nil
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/repository/mock"
+)
+
+func TestQuestionLogic(t *testing.T) {
+	mockRepo := mock.NewMockRepository()
+	service := NewSurveyService(mockRepo)
+	userCtx := setupTestContext(1, []string{"user"})
+
+	branching := []models.QuestionUpdateRequest{
+		{Text: "Do you own a car?", Type: "single_choice", Options: []models.OptionUpdateRequest{{Text: "Yes"}, {Text: "No"}},
+			SkipRules: []models.SkipRule{{Operator: models.LogicOperatorEquals, Value: "No", SkipTo: 3}}},
+		{Text: "Which brand?", Type: "text"},
+		{Text: "How do you commute?", Type: "checkbox", Options: []models.OptionUpdateRequest{{Text: "Bus"}, {Text: "Other"}}},
+		{Text: "Describe other", Type: "text", Required: true,
+			DisplayRules: []models.DisplayRule{{Question: 3, Operator: models.LogicOperatorIncludes, Value: "Other"}}},
+		{Text: "Why do you commute by {{Q3}}?", Type: "text"},
+	}
+
+	surveyID, err := service.CreateSurvey(userCtx, &models.Survey{Title: "Commute"}, branching)
+	if err != nil {
+		t.Fatalf("Unexpected error creating survey with rules: %v", err)
+	}
+	questions, _ := mockRepo.GetQuestionsBySurveyID(context.Background(), surveyID)
+	car, commute := questions[0], questions[2]
+	wantSkip := models.SkipRule{Operator: models.LogicOperatorEquals, OptionID: car.Options[1].ID, SkipToQuestionID: commute.ID}
+	if len(car.SkipRules) != 1 || car.SkipRules[0] != wantSkip {
+		t.Errorf("Expected the skip rule to be stored with question and option IDs, got %+v", car.SkipRules)
+	}
+	wantDisplay := models.DisplayRule{QuestionID: commute.ID, Operator: models.LogicOperatorIncludes, OptionID: commute.Options[1].ID}
+	if len(questions[3].DisplayRules) != 1 || questions[3].DisplayRules[0] != wantDisplay {
+		t.Errorf("Expected the display rule to be stored with question and option IDs, got %+v", questions[3].DisplayRules)
+	}
+	if want := fmt.Sprintf("Why do you commute by {{question:%d}}?", commute.ID); questions[4].Text != want {
+		t.Errorf("Expected the pipe to be stored as %q, got %q", want, questions[4].Text)
+	}
+
+	invalid := map[string][]models.QuestionUpdateRequest{
+		"skip backwards": {
+			{Text: "Q1", Type: "text"},
+			{Text: "Q2", Type: "text", SkipRules: []models.SkipRule{{Operator: models.LogicOperatorEquals, Value: "again", SkipTo: 1}}},
+		},
+		"skip to itself": {
+			{Text: "Q1", Type: "text", SkipRules: []models.SkipRule{{Operator: models.LogicOperatorEquals, Value: "x", SkipTo: 1}}},
+		},
+		"display depends on a later question": {
+			{Text: "Q1", Type: "text", DisplayRules: []models.DisplayRule{{Question: 2, Operator: models.LogicOperatorEquals, Value: "x"}}},
+			{Text: "Q2", Type: "text"},
+		},
+		"unknown target": {
+			{Text: "Q1", Type: "text", SkipRules: []models.SkipRule{{Operator: models.LogicOperatorEquals, Value: "x", SkipTo: 7}}},
+		},
+		"pipe from a later question": {
+			{Text: "You said {{Q2}} - why?", Type: "text"},
+			{Text: "Q2", Type: "text"},
+		},
+		"pipe from an unknown question": {
+			{Text: "Q1", Type: "text"},
+			{Text: "About {{ Q0 }}", Type: "text"},
+		},
+		"value that is not an option": {
+			{Text: "Q1", Type: "single_choice", Options: []models.OptionUpdateRequest{{Text: "Yes"}, {Text: "No"}}},
+			{Text: "Q2", Type: "text", DisplayRules: []models.DisplayRule{{Question: 1, Operator: models.LogicOperatorEquals, Value: "Maybe"}}},
+		},
+		"option of another question": {
+			{Text: "Q1", Type: "single_choice", Options: []models.OptionUpdateRequest{{Text: "Yes"}}},
+			{Text: "Q2", Type: "text", DisplayRules: []models.DisplayRule{{Question: 1, Operator: models.LogicOperatorEquals, OptionID: 999}}},
+		},
+		"unknown operator": {
+			{Text: "Q1", Type: "text"},
+			{Text: "Q2", Type: "text", DisplayRules: []models.DisplayRule{{Question: 1, Operator: "matches", Value: "x"}}},
+		},
+	}
+	for name, requested := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := service.CreateSurvey(userCtx, &models.Survey{Title: "Invalid"}, requested)
+			if !errors.Is(err, ErrInvalidQuestion) {
+				t.Errorf("Expected ErrInvalidQuestion, got %v", err)
+			}
+			err = service.UpdateSurveyWithQuestions(userCtx, &models.Survey{ID: surveyID, Title: "Commute"}, requested)
+			if !errors.Is(err, ErrInvalidQuestion) {
+				t.Errorf("Expected ErrInvalidQuestion on update, got %v", err)
+			}
+		})
+	}
+
+	t.Run("Added question is checked against the stored ones", func(t *testing.T) {
+		_, err := service.AddQuestion(userCtx, &models.CreateQuestionRequest{
+			SurveyID: surveyID, Text: "Loops back", Type: "text", OrderNum: 5,
+			SkipRules: []models.SkipRule{{Operator: models.LogicOperatorEquals, Value: "x", SkipTo: 2}},
+		})
+		if !errors.Is(err, ErrInvalidQuestion) {
+			t.Errorf("Expected ErrInvalidQuestion, got %v", err)
+		}
+
+		_, err = service.AddQuestion(userCtx, &models.CreateQuestionRequest{
+			SurveyID: surveyID, Text: "Car owners only", Type: "text", OrderNum: 5,
+			DisplayRules: []models.DisplayRule{{Question: 1, Operator: models.LogicOperatorNotEquals, Value: "No"}},
+		})
+		if err != nil {
+			t.Errorf("Unexpected error adding a question with a valid rule: %v", err)
+		}
+	})
+
+	t.Run("Questions other questions depend on cannot be deleted", func(t *testing.T) {
+		questions, _ := mockRepo.GetQuestionsBySurveyID(context.Background(), surveyID)
+		commute, brand := questions[2], questions[1]
+
+		// Question 4 is only shown depending on question 3, and question 5 pipes in its answer
+		if err := service.DeleteQuestion(userCtx, commute.ID); !errors.Is(err, ErrInvalidQuestion) {
+			t.Errorf("Expected ErrInvalidQuestion, got %v", err)
+		}
+		if _, exists := mockRepo.Questions[commute.ID]; !exists {
+			t.Errorf("Expected the question to be kept")
+		}
+
+		if err := service.DeleteQuestion(userCtx, brand.ID); err != nil {
+			t.Errorf("Unexpected error deleting a question nothing depends on: %v", err)
+		}
+		if _, exists := mockRepo.Questions[brand.ID]; exists {
+			t.Errorf("Expected the question to be deleted")
+		}
+	})
+}
+
+func TestQuestionLogicSurvivesEdits(t *testing.T) {
+	mockRepo := mock.NewMockRepository()
+	service := NewSurveyService(mockRepo)
+	userCtx := setupTestContext(1, []string{"user"})
+
+	surveyID, err := service.CreateSurvey(userCtx, &models.Survey{Title: "Pets"}, []models.QuestionUpdateRequest{
+		{Text: "Own a pet?", Type: "single_choice", Options: []models.OptionUpdateRequest{{Text: "Yes"}, {Text: "No"}}},
+		{Text: "Which pet?", Type: "text", DisplayRules: []models.DisplayRule{{Question: 1, Operator: models.LogicOperatorEquals, Value: "Yes"}}},
+		{Text: "Tell us about your {{Q2}}", Type: "text"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error creating survey: %v", err)
+	}
+	stored, _ := mockRepo.GetQuestionsBySurveyID(context.Background(), surveyID)
+	owns, which, about := stored[0], stored[1], stored[2]
+	yes := owns.Options[0].ID
+	wantRule := models.DisplayRule{QuestionID: owns.ID, Operator: models.LogicOperatorEquals, OptionID: yes}
+	wantText := fmt.Sprintf("Tell us about your {{question:%d}}", which.ID)
+
+	// A new first question and a renamed option move every position, but the rules keep their targets
+	err = service.UpdateSurveyWithQuestions(userCtx, &models.Survey{ID: surveyID, Title: "Pets"}, []models.QuestionUpdateRequest{
+		{Text: "Your name?", Type: "text"},
+		{ID: &owns.ID, Text: owns.Text, Type: owns.Type, Options: []models.OptionUpdateRequest{{ID: &yes, Text: "Yes, I do"}, {Text: "No"}}},
+		{ID: &which.ID, Text: which.Text, Type: which.Type, DisplayRules: which.DisplayRules},
+		{ID: &about.ID, Text: about.Text, Type: about.Type},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error updating survey: %v", err)
+	}
+	if rules := mockRepo.Questions[which.ID].DisplayRules; len(rules) != 1 || rules[0] != wantRule {
+		t.Errorf("Expected the display rule to keep its question and option, got %+v", rules)
+	}
+	if text := mockRepo.Questions[about.ID].Text; text != wantText {
+		t.Errorf("Expected the pipe to keep its question, got %q", text)
+	}
+
+	// Editing a single question matches its options by text, so rules naming them keep working
+	err = service.UpdateQuestion(userCtx, &models.Question{ID: owns.ID, SurveyID: surveyID, Text: "Do you own a pet?", Type: "single_choice", OrderNum: 2},
+		[]*models.QuestionOption{{Text: "No"}, {Text: "Yes, I do"}})
+	if err != nil {
+		t.Fatalf("Unexpected error updating question: %v", err)
+	}
+	if options, _ := mockRepo.GetQuestionOptionsByQuestionID(context.Background(), owns.ID); len(options) != 2 || options[1].ID != yes {
+		t.Errorf("Expected the options to keep their IDs, got %+v", options)
+	}
+
+	// Removing the option a rule depends on is rejected
+	err = service.UpdateQuestion(userCtx, &models.Question{ID: owns.ID, SurveyID: surveyID, Text: "Do you own a pet?", Type: "single_choice", OrderNum: 2},
+		[]*models.QuestionOption{{Text: "No"}})
+	if !errors.Is(err, ErrInvalidQuestion) {
+		t.Errorf("Expected ErrInvalidQuestion, got %v", err)
+	}
+}
+
+func TestMigrateQuestionLogic(t *testing.T) {
+	mockRepo := mock.NewMockRepository()
+	service := NewSurveyService(mockRepo)
+	userCtx := setupTestContext(1, []string{"user"})
+
+	newSurvey := func(title string) []*models.Question {
+		surveyID, err := service.CreateSurvey(userCtx, &models.Survey{Title: title}, []models.QuestionUpdateRequest{
+			{Text: "Own a pet?", Type: "single_choice", Options: []models.OptionUpdateRequest{{Text: "Yes"}, {Text: "No"}}},
+			{Text: "Which pet?", Type: "text"},
+			{Text: "Anything else?", Type: "text"},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error creating survey: %v", err)
+		}
+		stored, _ := mockRepo.GetQuestionsBySurveyID(context.Background(), surveyID)
+		return stored
+	}
+
+	// Logic saved before IDs were stored names questions by OrderNum and options by text
+	legacy := newSurvey("Legacy")
+	legacy[0].SkipRules = []models.SkipRule{{Operator: models.LogicOperatorEquals, Value: "No", SkipTo: 3}}
+	legacy[1].DisplayRules = []models.DisplayRule{{Question: 1, Operator: models.LogicOperatorEquals, Value: "Yes"}}
+	legacy[2].Text = "More about your {{Q2}}?"
+	broken := newSurvey("Broken")
+	broken[1].DisplayRules = []models.DisplayRule{{Question: 1, Operator: models.LogicOperatorEquals, Value: "Maybe"}}
+	newSurvey("Plain")
+
+	report, err := service.MigrateQuestionLogic(context.Background(), false)
+	if err != nil {
+		t.Fatalf("Unexpected error migrating: %v", err)
+	}
+	want := QuestionLogicMigrationReport{Surveys: 2, UpdatedSurveys: 1, UpdatedQuestions: 3, SkippedSurveys: 1}
+	if *report != want {
+		t.Errorf("Expected report %+v, got %+v", want, *report)
+	}
+
+	wantSkip := models.SkipRule{Operator: models.LogicOperatorEquals, OptionID: legacy[0].Options[1].ID, SkipToQuestionID: legacy[2].ID}
+	if rules := mockRepo.Questions[legacy[0].ID].SkipRules; len(rules) != 1 || rules[0] != wantSkip {
+		t.Errorf("Expected skip rule %+v, got %+v", wantSkip, rules)
+	}
+	wantDisplay := models.DisplayRule{QuestionID: legacy[0].ID, Operator: models.LogicOperatorEquals, OptionID: legacy[0].Options[0].ID}
+	if rules := mockRepo.Questions[legacy[1].ID].DisplayRules; len(rules) != 1 || rules[0] != wantDisplay {
+		t.Errorf("Expected display rule %+v, got %+v", wantDisplay, rules)
+	}
+	if text, wantText := mockRepo.Questions[legacy[2].ID].Text, fmt.Sprintf("More about your {{question:%d}}?", legacy[1].ID); text != wantText {
+		t.Errorf("Expected text %q, got %q", wantText, text)
+	}
+	if rules := mockRepo.Questions[broken[1].ID].DisplayRules; rules[0].Value != "Maybe" || rules[0].QuestionID != 0 {
+		t.Errorf("Expected the broken rule to be left as it is, got %+v", rules)
+	}
+
+	// Migrated logic is left alone by later runs
+	report, err = service.MigrateQuestionLogic(context.Background(), false)
+	if err != nil {
+		t.Fatalf("Unexpected error migrating again: %v", err)
+	}
+	if report.UpdatedQuestions != 0 {
+		t.Errorf("Expected nothing to change on a second run, got %+v", *report)
+	}
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/repository/mock"
+)
+
+// setupOrgTestContext is setupTestContext for a user working in an organization
+func setupOrgTestContext(userID int, roles []string, orgID int) context.Context {
+	return context.WithValue(setupTestContext(userID, roles), OrgIDKey, orgID)
+}
+
+func TestSurveysAreScopedToOrganizations(t *testing.T) {
+	mockRepo := mock.NewMockRepository()
+	mockRepo.OrgMembers[10] = []int{1, 2}
+	service := NewSurveyService(mockRepo)
+	ownerCtx := setupOrgTestContext(1, []string{"user"}, 10)
+	colleagueCtx := setupOrgTestContext(2, []string{"user"}, 10)
+	adminCtx := setupOrgTestContext(3, []string{"admin"}, 10)
+	otherAdminCtx := setupOrgTestContext(4, []string{"admin"}, 20)
+	outsiderCtx := setupTestContext(5, []string{"admin"})
+
+	surveyID, err := service.CreateSurvey(ownerCtx, &models.Survey{Title: "Department survey"}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating survey: %v", err)
+	}
+	if survey := mockRepo.Surveys[surveyID]; survey.OrgID == nil || *survey.OrgID != 10 {
+		t.Fatalf("Expected the survey in organization 10, got %v", survey.OrgID)
+	}
+	if err := service.UpdateSurveyStatus(ownerCtx, surveyID, false); err != nil {
+		t.Fatalf("Unexpected error deactivating survey: %v", err)
+	}
+
+	t.Run("Lists only show the organization's surveys", func(t *testing.T) {
+		if _, total, _ := service.ListAllPublicSurveys(adminCtx, "", 1, 10); total != 1 {
+			t.Errorf("Expected the admin of the organization to list 1 survey, got %d", total)
+		}
+		for name, ctx := range map[string]context.Context{"another organization": otherAdminCtx, "no organization": outsiderCtx} {
+			if _, total, _ := service.ListAllPublicSurveys(ctx, "", 1, 10); total != 0 {
+				t.Errorf("Expected an admin of %s to list no surveys, got %d", name, total)
+			}
+		}
+
+		if _, total, _ := service.ListUserSurveys(ownerCtx, "", 1, 10); total != 1 {
+			t.Errorf("Expected the owner to list 1 survey, got %d", total)
+		}
+		// The owner working outside the organization does not see it among their surveys
+		if _, total, _ := service.ListUserSurveys(setupTestContext(1, []string{"user"}), "", 1, 10); total != 0 {
+			t.Errorf("Expected the owner to list no surveys outside the organization, got %d", total)
+		}
+	})
+
+	t.Run("Admins of other organizations cannot access the survey", func(t *testing.T) {
+		if _, err := service.GetSurvey(otherAdminCtx, surveyID); !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected ErrForbidden reading the survey, got %v", err)
+		}
+		if err := service.DeleteSurvey(otherAdminCtx, surveyID); !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected ErrForbidden deleting the survey, got %v", err)
+		}
+		if _, err := service.GetSurvey(adminCtx, surveyID); err != nil {
+			t.Errorf("Expected the admin of the organization to read the survey, got %v", err)
+		}
+	})
+
+	t.Run("Collaborators and new owners must belong to the organization", func(t *testing.T) {
+		invite := models.InviteCollaboratorRequest{UserID: 4, Role: models.AccessRoleViewer}
+		if _, err := service.InviteCollaborator(ownerCtx, surveyID, invite); !errors.Is(err, ErrInvalidCollaborator) {
+			t.Errorf("Expected ErrInvalidCollaborator for a user of another organization, got %v", err)
+		}
+		transfer := models.TransferSurveyRequest{NewOwnerID: 4}
+		if _, err := service.TransferSurveyOwnership(ownerCtx, surveyID, transfer); !errors.Is(err, ErrInvalidTransfer) {
+			t.Errorf("Expected ErrInvalidTransfer to a user of another organization, got %v", err)
+		}
+
+		invite.UserID = 2
+		if _, err := service.InviteCollaborator(ownerCtx, surveyID, invite); err != nil {
+			t.Fatalf("Unexpected error inviting a colleague: %v", err)
+		}
+		if _, err := service.GetSurvey(colleagueCtx, surveyID); err != nil {
+			t.Errorf("Expected the colleague to read the survey, got %v", err)
+		}
+	})
+
+	t.Run("Copies stay in the organization of the user", func(t *testing.T) {
+		copyID, err := service.CloneSurvey(colleagueCtx, surveyID, "")
+		if err != nil {
+			t.Fatalf("Unexpected error cloning survey: %v", err)
+		}
+		if survey := mockRepo.Surveys[copyID]; survey.OrgID == nil || *survey.OrgID != 10 {
+			t.Errorf("Expected the copy in organization 10, got %v", survey.OrgID)
+		}
+	})
+}
+
+func TestSurveysFromBeforeJoiningAnOrganization(t *testing.T) {
+	mockRepo := mock.NewMockRepository()
+	service := NewSurveyService(mockRepo)
+	personalCtx := setupTestContext(1, []string{"user"})
+
+	surveyID, err := service.CreateSurvey(personalCtx, &models.Survey{Title: "Side project"}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating survey: %v", err)
+	}
+	mockRepo.OrgMembers[10] = []int{1}
+	orgCtx := setupOrgTestContext(1, []string{"user"}, 10)
+
+	if _, total, _ := service.ListUserSurveys(orgCtx, "", 1, 10); total != 0 {
+		t.Errorf("Expected the survey outside the organization's list, got %d surveys", total)
+	}
+	if _, err := service.GetSurvey(orgCtx, surveyID); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden reading the survey from the organization, got %v", err)
+	}
+
+	// Switching back to the personal workspace drops the organization from the user's token
+	if _, total, _ := service.ListUserSurveys(personalCtx, "", 1, 10); total != 1 {
+		t.Errorf("Expected the survey in the personal workspace, got %d surveys", total)
+	}
+	if _, err := service.GetSurvey(personalCtx, surveyID); err != nil {
+		t.Errorf("Expected the owner to read the survey in the personal workspace, got %v", err)
+	}
+	if err := service.UpdateSurveyStatus(personalCtx, surveyID, false); err != nil {
+		t.Errorf("Expected the owner to manage the survey in the personal workspace, got %v", err)
+	}
+	if survey := mockRepo.Surveys[surveyID]; survey.OrgID != nil {
+		t.Errorf("Expected the survey to stay outside of organizations, got %v", *survey.OrgID)
+	}
+}
+
+func TestTemplatesAreScopedToOrganizations(t *testing.T) {
+	mockRepo := mock.NewMockRepository()
+	service := NewSurveyService(mockRepo)
+	ownerCtx := setupOrgTestContext(1, []string{"admin"}, 10)
+	otherCtx := setupOrgTestContext(2, []string{"user"}, 20)
+
+	orgTemplateID, _ := service.CreateSurvey(ownerCtx, &models.Survey{Title: "Org template"}, nil)
+	globalTemplateID, _ := service.CreateSurvey(ownerCtx, &models.Survey{Title: "Global template"}, nil)
+	if err := service.SetSurveyTemplate(ownerCtx, orgTemplateID, models.TemplateVisibilityOrg); err != nil {
+		t.Fatalf("Unexpected error publishing org template: %v", err)
+	}
+	if err := service.SetSurveyTemplate(ownerCtx, globalTemplateID, models.TemplateVisibilityGlobal); err != nil {
+		t.Fatalf("Unexpected error publishing global template: %v", err)
+	}
+
+	templates, total, _ := service.ListTemplates(otherCtx, 1, 10)
+	if total != 1 || templates[0].ID != globalTemplateID {
+		t.Errorf("Expected another organization to list only the global template, got %d templates", total)
+	}
+
+	if _, err := service.InstantiateTemplate(otherCtx, orgTemplateID, ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound instantiating another organization's template, got %v", err)
+	}
+	surveyID, err := service.InstantiateTemplate(otherCtx, globalTemplateID, "")
+	if err != nil {
+		t.Fatalf("Unexpected error instantiating the global template: %v", err)
+	}
+	if survey := mockRepo.Surveys[surveyID]; survey.OrgID == nil || *survey.OrgID != 20 {
+		t.Errorf("Expected the new survey in organization 20, got %v", survey.OrgID)
+	}
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
+)
+
+// ErrInvalidTransfer is returned for ownership transfers to an unknown user or to the current owner
+var ErrInvalidTransfer = errors.New("invalid ownership transfer")
+
+// TransferSurveyOwnership hands a survey to another active user of its organization, identified by ID or else by email
+// (owner or admin only).
+// The new owner is recorded as the survey's creator, stops being a collaborator and the transfer is audited in the
+// same transaction.
+func (s *SurveyService) TransferSurveyOwnership(ctx context.Context, surveyID int, req models.TransferSurveyRequest) (transfer *models.SurveyOwnershipTransfer, err error) {
+	survey, _, err := s.authorizeSurveyAccess(ctx, surveyID, accessManage)
+	if err != nil {
+		return nil, err
+	}
+	userID, _, err := getUserAndRolesFromContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("TransferSurveyOwnership: %w", err)
+	}
+
+	newOwnerID, err := s.resolveUser(ctx, survey.OrgID, req.NewOwnerID, req.NewOwnerEmail, ErrInvalidTransfer)
+	if err != nil {
+		return nil, err
+	}
+	if newOwnerID == survey.CreatorID {
+		return nil, fmt.Errorf("%w: user %d already owns survey %d", ErrInvalidTransfer, newOwnerID, surveyID)
+	}
+
+	previousOwnerID := survey.CreatorID
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+			panic(p)
+		} else if err != nil {
+			_ = tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	if err = s.repo.UpdateSurveyCreatorTx(ctx, tx, surveyID, newOwnerID); err != nil {
+		return nil, fmt.Errorf("failed to transfer survey %d: %w", surveyID, err)
+	}
+	if err = s.repo.DeleteCollaboratorTx(ctx, tx, surveyID, newOwnerID); err != nil {
+		return nil, err
+	}
+	transfer = &models.SurveyOwnershipTransfer{
+		SurveyID:      surveyID,
+		FromUserID:    &previousOwnerID,
+		ToUserID:      &newOwnerID,
+		TransferredBy: &userID,
+	}
+	if err = s.repo.CreateOwnershipTransferTx(ctx, tx, transfer); err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// ListSurveyOwnershipTransfers lists the ownership transfers of a survey, newest first (owner, collaborator or admin)
+func (s *SurveyService) ListSurveyOwnershipTransfers(ctx context.Context, surveyID int) ([]*models.SurveyOwnershipTransfer, error) {
+	if _, _, err := s.authorizeSurveyAccess(ctx, surveyID, accessView); err != nil {
+		return nil, err
+	}
+	return s.repo.ListOwnershipTransfers(ctx, surveyID)
+}
+
+// resolveUser returns the ID of an active user given by ID, or else by email, who belongs to the organization orgID
+// unless it is nil. Unknown users and users of other organizations are reported as invalid.
+func (s *SurveyService) resolveUser(ctx context.Context, orgID *int, userID int, email string, invalid error) (int, error) {
+	email = strings.TrimSpace(email)
+	switch {
+	case userID > 0:
+		exists, err := s.repo.UserExists(ctx, userID)
+		if err != nil {
+			return 0, err
+		}
+		if !exists {
+			return 0, fmt.Errorf("%w: user %d does not exist", invalid, userID)
+		}
+	case email != "":
+		id, err := s.repo.GetUserIDByEmail(ctx, email)
+		if err != nil {
+			return 0, err
+		}
+		if id == 0 {
+			return 0, fmt.Errorf("%w: no user is registered with the email %q", invalid, email)
+		}
+		userID = id
+	default:
+		return 0, fmt.Errorf("%w: a user ID or email is required", invalid)
+	}
+
+	if orgID != nil {
+		member, err := s.repo.IsOrgMember(ctx, *orgID, userID)
+		if err != nil {
+			return 0, err
+		}
+		if !member {
+			return 0, fmt.Errorf("%w: user %d is not a member of the survey's organization", invalid, userID)
+		}
+	}
+	return userID, nil
+}
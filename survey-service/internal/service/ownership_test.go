@@ -0,0 +1,107 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/repository/mock"
+)
+
+func TestCloneSurvey(t *testing.T) {
+	mockRepo := mock.NewMockRepository()
+	service := NewSurveyService(mockRepo)
+	ownerCtx := setupTestContext(1, []string{"user"})
+	otherCtx := setupTestContext(2, []string{"user"})
+	adminCtx := setupTestContext(3, []string{"admin"})
+
+	survey := &models.Survey{Title: "Onboarding", IsActive: true, Sections: []*models.Section{{Title: "About you"}}}
+	surveyID, err := service.CreateSurvey(ownerCtx, survey, []models.QuestionUpdateRequest{
+		{Text: "Team", Type: "dropdown", Section: 1, Options: []models.OptionUpdateRequest{{Text: "Sales"}, {Text: "Support"}}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error creating survey: %v", err)
+	}
+
+	if _, err := service.CloneSurvey(otherCtx, surveyID, ""); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden when cloning another user's survey, got %v", err)
+	}
+	if _, err := service.CloneSurvey(ownerCtx, 999, ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown survey, got %v", err)
+	}
+
+	cloneID, err := service.CloneSurvey(ownerCtx, surveyID, "")
+	if err != nil {
+		t.Fatalf("Unexpected error cloning survey: %v", err)
+	}
+	clone, _ := service.GetSurvey(ownerCtx, cloneID)
+	if cloneID == surveyID || clone.Title != "Onboarding (copy)" || clone.CreatorID != 1 || clone.IsActive {
+		t.Errorf("Expected an inactive copy named after the original, got %+v", clone)
+	}
+	if len(clone.Questions) != 1 || len(clone.Questions[0].Options) != 2 || clone.Questions[0].ID == survey.Questions[0].ID {
+		t.Fatalf("Expected the questions and options to be copied, got %+v", clone.Questions)
+	}
+	if clone.Questions[0].SectionID == nil || *clone.Questions[0].SectionID != clone.Sections[0].ID {
+		t.Errorf("Expected the question on the copied section, got %v", clone.Questions[0].SectionID)
+	}
+
+	adminCloneID, err := service.CloneSurvey(adminCtx, surveyID, "Onboarding 2027")
+	if err != nil {
+		t.Fatalf("Unexpected error cloning survey as admin: %v", err)
+	}
+	if adminClone, _ := service.GetSurvey(adminCtx, adminCloneID); adminClone.Title != "Onboarding 2027" || adminClone.CreatorID != 3 {
+		t.Errorf("Expected the admin to own a copy with the requested title, got %+v", adminClone)
+	}
+}
+
+func TestTransferSurveyOwnership(t *testing.T) {
+	mockRepo := mock.NewMockRepository()
+	mockRepo.Users["new.owner@example.com"] = 2
+	service := NewSurveyService(mockRepo)
+	ownerCtx := setupTestContext(1, []string{"user"})
+	otherCtx := setupTestContext(2, []string{"user"})
+	adminCtx := setupTestContext(3, []string{"admin"})
+
+	surveyID, err := service.CreateSurvey(ownerCtx, &models.Survey{Title: "Handover"}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating survey: %v", err)
+	}
+
+	if _, err := service.TransferSurveyOwnership(otherCtx, surveyID, models.TransferSurveyRequest{NewOwnerID: 2}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden when taking another user's survey, got %v", err)
+	}
+	if _, err := service.TransferSurveyOwnership(ownerCtx, surveyID, models.TransferSurveyRequest{NewOwnerEmail: "nobody@example.com"}); !errors.Is(err, ErrInvalidTransfer) {
+		t.Errorf("Expected ErrInvalidTransfer for an unknown email, got %v", err)
+	}
+	if _, err := service.TransferSurveyOwnership(ownerCtx, surveyID, models.TransferSurveyRequest{NewOwnerID: 1}); !errors.Is(err, ErrInvalidTransfer) {
+		t.Errorf("Expected ErrInvalidTransfer for the current owner, got %v", err)
+	}
+	if _, err := service.TransferSurveyOwnership(ownerCtx, surveyID, models.TransferSurveyRequest{}); !errors.Is(err, ErrInvalidTransfer) {
+		t.Errorf("Expected ErrInvalidTransfer without a new owner, got %v", err)
+	}
+
+	transfer, err := service.TransferSurveyOwnership(ownerCtx, surveyID, models.TransferSurveyRequest{NewOwnerEmail: " New.Owner@example.com "})
+	if err != nil {
+		t.Fatalf("Unexpected error transferring survey: %v", err)
+	}
+	if *transfer.FromUserID != 1 || *transfer.ToUserID != 2 || *transfer.TransferredBy != 1 {
+		t.Errorf("Expected a transfer from user 1 to user 2, got %+v", transfer)
+	}
+	if survey, _ := mockRepo.GetSurvey(ownerCtx, surveyID); survey.CreatorID != 2 {
+		t.Errorf("Expected user 2 to own the survey, got %d", survey.CreatorID)
+	}
+	if _, err := service.ListSurveyOwnershipTransfers(ownerCtx, surveyID); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected the previous owner to lose access, got %v", err)
+	}
+
+	if _, err := service.TransferSurveyOwnership(adminCtx, surveyID, models.TransferSurveyRequest{NewOwnerID: 1}); err != nil {
+		t.Fatalf("Unexpected error transferring survey as admin: %v", err)
+	}
+	transfers, err := service.ListSurveyOwnershipTransfers(ownerCtx, surveyID)
+	if err != nil {
+		t.Fatalf("Unexpected error listing transfers: %v", err)
+	}
+	if len(transfers) != 2 || *transfers[0].TransferredBy != 3 || *transfers[0].ToUserID != 1 {
+		t.Errorf("Expected the admin transfer to be listed first, got %+v", transfers)
+	}
+}
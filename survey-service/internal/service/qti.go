@@ -0,0 +1,331 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/VitaliySynytskyi/survey-platform/shared/questiontypes"
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
+)
+
+// maxQTIPackageFileSize limits the size of each file read from a QTI content package once decompressed
+const maxQTIPackageFileSize = 2 << 20
+
+// qtiConverter maps QTI items onto a survey definition
+type qtiConverter struct {
+	*surveyConverter
+	scored bool // Whether any item had correct answers, which surveys have no use for
+}
+
+func newQTIConverter(title string) *qtiConverter {
+	return &qtiConverter{surveyConverter: newSurveyConverter(title)}
+}
+
+// result returns the converted survey, noting once that scoring was left out
+func (c *qtiConverter) result() *surveyConverter {
+	if c.scored {
+		c.report("", "correct answers and scoring were not imported")
+	}
+	return c.surveyConverter
+}
+
+// qtiPackage holds the XML files of a QTI content package by their path in the package
+type qtiPackage map[string]*xmlNode
+
+// convertQTI maps IMS QTI items onto a survey definition. data is either a single assessmentItem or a content
+// package (.zip). In a package the items are taken in the order of its assessmentTest, whose sections become the
+// survey's sections, or else in the order of its manifest. QTI 3.0 files are read like QTI 2.x ones. Scoring is left out.
+func convertQTI(data []byte) (*surveyConverter, error) {
+	if !bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		root, err := parseXMLTree(data)
+		if err != nil {
+			return nil, err
+		}
+		normalizeQTINames(root)
+		switch root.name {
+		case "assessmentItem":
+			c := newQTIConverter(root.attrs["title"])
+			c.addItem(root, 0)
+			return c.result(), nil
+		case "assessmentTest":
+			return nil, fmt.Errorf("%w: a QTI test refers to item files, upload the content package (.zip) instead", ErrInvalidImport)
+		case "questestinterop":
+			return nil, fmt.Errorf("%w: QTI 1.x files are not supported, export the items as QTI 2.1 or later", ErrInvalidImport)
+		}
+		return nil, fmt.Errorf("%w: not a QTI assessmentItem", ErrInvalidImport)
+	}
+
+	pkg, err := readQTIPackage(data)
+	if err != nil {
+		return nil, err
+	}
+	manifest := pkg["imsmanifest.xml"]
+	var tests, items []string
+	for _, resource := range manifest.child("resources").elements("resource") {
+		href := path.Clean(resource.attrs["href"])
+		switch root := pkg[href]; {
+		case root == nil:
+		case root.name == "assessmentTest":
+			tests = append(tests, href)
+		case root.name == "assessmentItem":
+			items = append(items, href)
+		}
+	}
+	if manifest == nil {
+		// Without a manifest every test or item in the package is taken, by path
+		for _, name := range pkg.paths() {
+			switch pkg[name].name {
+			case "assessmentTest":
+				tests = append(tests, name)
+			case "assessmentItem":
+				items = append(items, name)
+			}
+		}
+	}
+
+	if len(tests) > 0 {
+		test := pkg[tests[0]]
+		c := newQTIConverter(test.attrs["title"])
+		if len(tests) > 1 {
+			c.report("", "the package holds %d tests, only %q was imported", len(tests), tests[0])
+		}
+		c.addSections(pkg, path.Dir(tests[0]), test)
+		return c.result(), nil
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: the package holds no QTI items", ErrInvalidImport)
+	}
+	c := newQTIConverter("")
+	for _, item := range items {
+		c.addItem(pkg[item], 0)
+	}
+	return c.result(), nil
+}
+
+// readQTIPackage parses the XML files of a content package
+func readQTIPackage(data []byte) (qtiPackage, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	pkg := make(qtiPackage)
+	for _, file := range reader.File {
+		if !strings.EqualFold(path.Ext(file.Name), ".xml") {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidImport, file.Name, err)
+		}
+		content, err := io.ReadAll(io.LimitReader(rc, maxQTIPackageFileSize+1))
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidImport, file.Name, err)
+		}
+		if len(content) > maxQTIPackageFileSize {
+			return nil, fmt.Errorf("%w: %s is larger than %d bytes", ErrInvalidImport, file.Name, maxQTIPackageFileSize)
+		}
+		root, err := parseXMLTree(content)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file.Name, err)
+		}
+		normalizeQTINames(root)
+		pkg[path.Clean(file.Name)] = root
+	}
+	return pkg, nil
+}
+
+func (p qtiPackage) paths() []string {
+	paths := make([]string, 0, len(p))
+	for name := range p {
+		paths = append(paths, name)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// normalizeQTINames renames the elements and attributes of QTI 3.0, such as qti-choice-interaction and max-choices,
+// to their QTI 2.x names, such as choiceInteraction and maxChoices
+func normalizeQTINames(node *xmlNode) {
+	if strings.HasPrefix(node.name, "qti-") {
+		node.name = qtiCamelCase(strings.TrimPrefix(node.name, "qti-"))
+		attrs := make(map[string]string, len(node.attrs))
+		for name, value := range node.attrs {
+			attrs[qtiCamelCase(name)] = value
+		}
+		node.attrs = attrs
+	}
+	for _, child := range node.children {
+		normalizeQTINames(child)
+	}
+}
+
+func qtiCamelCase(name string) string {
+	parts := strings.Split(name, "-")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// addSections adds the items a test refers to. Each assessmentSection with items of its own becomes a section;
+// hrefs are relative to the directory of the test.
+func (c *qtiConverter) addSections(pkg qtiPackage, dir string, test *xmlNode) {
+	isSection := func(n *xmlNode) bool { return n.name == "assessmentSection" }
+	var walk func(*xmlNode)
+	walk = func(node *xmlNode) {
+		for _, section := range node.find(isSection) {
+			refs := section.elements("assessmentItemRef")
+			if len(refs) > 0 {
+				ordering := section.child("ordering")
+				c.definition.Sections = append(c.definition.Sections, models.SectionDefinition{
+					Title:              section.attrs["title"],
+					RandomizeQuestions: ordering != nil && ordering.attrs["shuffle"] == "true",
+				})
+				position := len(c.definition.Sections)
+				for _, ref := range refs {
+					href := path.Join(dir, ref.attrs["href"])
+					item := pkg[href]
+					if item == nil || item.name != "assessmentItem" {
+						c.report(ref.attrs["identifier"], "item %q was not found in the package", ref.attrs["href"])
+						continue
+					}
+					c.addItem(item, position)
+				}
+			}
+			walk(section)
+		}
+	}
+	walk(test)
+}
+
+// addItem adds a question for each interaction of an item. Questions are worded by the interaction's prompt, or
+// else by the item's text around its interactions.
+func (c *qtiConverter) addItem(item *xmlNode, section int) {
+	identifier := item.attrs["identifier"]
+	isInteraction := func(n *xmlNode) bool { return strings.HasSuffix(n.name, "Interaction") }
+	body := item.child("itemBody")
+	interactions := body.find(isInteraction)
+	if len(interactions) == 0 {
+		c.report(identifier, "not imported: the item has no interaction")
+		return
+	}
+	if len(item.find(func(n *xmlNode) bool { return n.name == "correctResponse" })) > 0 {
+		c.scored = true
+	}
+	bodyText := body.textContent(isInteraction)
+	if bodyText == "" {
+		bodyText = item.attrs["title"]
+	}
+
+	bodyUsed := 0
+	for _, interaction := range interactions {
+		source := identifier
+		if len(interactions) > 1 {
+			source = fmt.Sprintf("%s.%s", identifier, interaction.attrs["responseIdentifier"])
+		}
+		question := models.QuestionDefinition{
+			Text:    interaction.child("prompt").textContent(nil),
+			Section: section,
+		}
+		if question.Text == "" {
+			// Interactions without a prompt share the item's text; later ones are numbered to tell them apart
+			bodyUsed++
+			question.Text = bodyText
+			if bodyUsed > 1 {
+				question.Text = fmt.Sprintf("%s (%d)", bodyText, bodyUsed)
+			}
+		}
+		if !c.mapInteraction(source, item, interaction, &question) {
+			continue
+		}
+		c.addQuestion(source, question)
+	}
+}
+
+// mapInteraction sets the type, options and config of a question from an interaction.
+// It reports interactions that cannot be mapped and returns false for them.
+func (c *qtiConverter) mapInteraction(source string, item, interaction *xmlNode, question *models.QuestionDefinition) bool {
+	choices := func(name string) []string {
+		var texts []string
+		for _, choice := range interaction.find(func(n *xmlNode) bool { return n.name == name }) {
+			texts = append(texts, choice.textContent(nil))
+		}
+		return texts
+	}
+	shuffle := interaction.attrs["shuffle"] == "true"
+
+	switch interaction.name {
+	case "choiceInteraction":
+		question.Type = questiontypes.QuestionTypeCheckbox
+		if maxChoices := interaction.attrs["maxChoices"]; maxChoices == "" || maxChoices == "1" {
+			question.Type = questiontypes.QuestionTypeSingleChoice
+		}
+		question.Options, question.RandomizeOptions = choices("simpleChoice"), shuffle
+	case "inlineChoiceInteraction":
+		question.Type, question.Options, question.RandomizeOptions = questiontypes.QuestionTypeDropdown, choices("inlineChoice"), shuffle
+	case "orderInteraction":
+		question.Type, question.Options, question.RandomizeOptions = questiontypes.QuestionTypeRanking, choices("simpleChoice"), shuffle
+	case "textEntryInteraction":
+		question.Type = questiontypes.QuestionTypeShortAnswer
+		for _, declaration := range item.elements("responseDeclaration") {
+			if declaration.attrs["identifier"] != interaction.attrs["responseIdentifier"] {
+				continue
+			}
+			switch declaration.attrs["baseType"] {
+			case "integer":
+				question.Config = map[string]interface{}{"format": questiontypes.TextFormatNumber, "decimals": 0}
+			case "float":
+				question.Config = map[string]interface{}{"format": questiontypes.TextFormatNumber}
+			}
+		}
+	case "extendedTextInteraction":
+		question.Type = questiontypes.QuestionTypeParagraph
+	case "matchInteraction":
+		sets := interaction.elements("simpleMatchSet")
+		if len(sets) != 2 {
+			c.report(source, "not imported: a match interaction needs two sets")
+			return false
+		}
+		texts := func(set *xmlNode) []string {
+			var result []string
+			for _, choice := range set.elements("simpleAssociableChoice") {
+				result = append(result, choice.textContent(nil))
+			}
+			return result
+		}
+		question.Type = questiontypes.QuestionTypeMatrix
+		question.Config = map[string]interface{}{"rows": texts(sets[0]), "columns": texts(sets[1])}
+	case "sliderInteraction":
+		lower, errLower := strconv.ParseFloat(interaction.attrs["lowerBound"], 64)
+		upper, errUpper := strconv.ParseFloat(interaction.attrs["upperBound"], 64)
+		if errLower != nil || errUpper != nil || lower != math.Trunc(lower) || upper != math.Trunc(upper) {
+			c.report(source, "not imported: only sliders over whole numbers are supported")
+			return false
+		}
+		step := 1
+		if value, err := strconv.Atoi(interaction.attrs["step"]); err == nil && value > 0 {
+			step = value
+		}
+		question.Type = questiontypes.QuestionTypeLinearScale
+		question.Config = map[string]interface{}{"min": int(lower), "max": int(upper), "step": step}
+	case "uploadInteraction":
+		question.Type = questiontypes.QuestionTypeFileUpload
+		if fileType := interaction.attrs["type"]; fileType != "" {
+			question.Config = map[string]interface{}{"max_size": questiontypes.DefaultMaxFileSize, "allowed_types": []string{fileType}}
+		}
+	default:
+		c.report(source, "not imported: %s is not supported", interaction.name)
+		return false
+	}
+	return true
+}
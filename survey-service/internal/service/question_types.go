@@ -0,0 +1,52 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/VitaliySynytskyi/survey-platform/shared/questiontypes"
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
+)
+
+// validateQuestionType checks a question's type, number of options and config against the question type registry.
+// position identifies the question in error messages. The config is returned with its defaults filled in.
+func validateQuestionType(position int, questionType string, config json.RawMessage, optionCount int) (json.RawMessage, error) {
+	def, ok := questiontypes.LookupQuestionType(questionType)
+	if !ok {
+		return nil, fmt.Errorf("%w: question %d has unknown type %q", ErrInvalidQuestion, position, questionType)
+	}
+	if def.HasOptions && optionCount == 0 {
+		return nil, fmt.Errorf("%w: question %d of type %q needs at least one option", ErrInvalidQuestion, position, questionType)
+	}
+	if !def.HasOptions && optionCount > 0 {
+		return nil, fmt.Errorf("%w: question %d of type %q takes no options", ErrInvalidQuestion, position, questionType)
+	}
+	decoded, err := def.DecodeConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("%w: question %d: %v", ErrInvalidQuestion, position, err)
+	}
+	if optionsConfig, ok := decoded.(questiontypes.OptionsConfig); ok {
+		if err := optionsConfig.ValidateOptions(optionCount); err != nil {
+			return nil, fmt.Errorf("%w: question %d: %v", ErrInvalidQuestion, position, err)
+		}
+	}
+	normalized, err := def.NormalizeConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("%w: question %d: %v", ErrInvalidQuestion, position, err)
+	}
+	return normalized, nil
+}
+
+// validateRequestedQuestionTypes validates the types of a full question list, where each question's position is its OrderNum.
+// The requested configs are replaced with their normalized form.
+func validateRequestedQuestionTypes(requestedQuestions []models.QuestionUpdateRequest) error {
+	for i := range requestedQuestions {
+		reqQuestion := &requestedQuestions[i]
+		config, err := validateQuestionType(i+1, reqQuestion.Type, reqQuestion.Config, len(reqQuestion.Options))
+		if err != nil {
+			return err
+		}
+		reqQuestion.Config = config
+	}
+	return nil
+}
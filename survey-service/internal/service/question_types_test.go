@@ -0,0 +1,116 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/VitaliySynytskyi/survey-platform/shared/questiontypes"
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/repository/mock"
+)
+
+func TestQuestionTypeValidation(t *testing.T) {
+	mockRepo := mock.NewMockRepository()
+	service := NewSurveyService(mockRepo)
+	userCtx := setupTestContext(1, []string{"user"})
+
+	valid := []models.QuestionUpdateRequest{
+		{Text: "Name", Type: questiontypes.QuestionTypeShortAnswer},
+		{Text: "Favourite colour", Type: questiontypes.QuestionTypeSingleChoice, Options: []models.OptionUpdateRequest{{Text: "Red"}, {Text: "Blue"}}},
+		{Text: "How did you hear of us?", Type: questiontypes.QuestionTypeCheckbox, Config: json.RawMessage(`{"allow_other": true, "other_label": "Somewhere else"}`),
+			Options: []models.OptionUpdateRequest{{Text: "Friends"}, {Text: "Search"}}},
+		{Text: "Born on", Type: questiontypes.QuestionTypeDate, Config: json.RawMessage(`{"min": "1900-01-01", "max": "2025-12-31"}`)},
+		{Text: "Age", Type: questiontypes.QuestionTypeShortAnswer, Config: json.RawMessage(`{"format": "number", "min": 0, "max": 130, "decimals": 0}`)},
+		{Text: "Postcode", Type: questiontypes.QuestionTypeText, Config: json.RawMessage(`{"pattern": "[0-9]{5}", "pattern_message": "Enter 5 digits", "max_length": 5}`)},
+		{Text: "How much do you agree?", Type: questiontypes.QuestionTypeMatrix,
+			Config: json.RawMessage(`{"rows": ["The app is fast", "The app is easy"], "columns": ["Disagree", "Neutral", "Agree"]}`)},
+		{Text: "Rank your top 2", Type: questiontypes.QuestionTypeRanking, Config: json.RawMessage(`{"top_n": 2}`),
+			Options: []models.OptionUpdateRequest{{Text: "Price"}, {Text: "Speed"}, {Text: "Support"}}},
+		{Text: "Your CV", Type: questiontypes.QuestionTypeFileUpload, Config: json.RawMessage(`{"max_size": 2097152, "allowed_types": ["application/pdf", "image/*"]}`)},
+	}
+	surveyID, err := service.CreateSurvey(userCtx, &models.Survey{Title: "Types"}, valid)
+	if err != nil {
+		t.Fatalf("Unexpected error creating survey with registered types: %v", err)
+	}
+
+	invalid := map[string]models.QuestionUpdateRequest{
+		"unknown type":             {Text: "Q", Type: "hologram"},
+		"choice without options":   {Text: "Q", Type: questiontypes.QuestionTypeDropdown},
+		"text with options":        {Text: "Q", Type: questiontypes.QuestionTypeParagraph, Options: []models.OptionUpdateRequest{{Text: "A"}}},
+		"config on unconfigurable": {Text: "Q", Type: questiontypes.QuestionTypeDropdown, Config: json.RawMessage(`{"max": 3}`), Options: []models.OptionUpdateRequest{{Text: "A"}}},
+		"text unknown format":      {Text: "Q", Type: questiontypes.QuestionTypeText, Config: json.RawMessage(`{"format": "phone"}`)},
+		"bounds on email format":   {Text: "Q", Type: questiontypes.QuestionTypeShortAnswer, Config: json.RawMessage(`{"format": "email", "max": 3}`)},
+		"number max below min":     {Text: "Q", Type: questiontypes.QuestionTypeShortAnswer, Config: json.RawMessage(`{"format": "number", "min": 10, "max": 1}`)},
+		"invalid pattern":          {Text: "Q", Type: questiontypes.QuestionTypeParagraph, Config: json.RawMessage(`{"pattern": "[a-"}`)},
+		"date range reversed":      {Text: "Q", Type: questiontypes.QuestionTypeDate, Config: json.RawMessage(`{"min": "2025-01-02", "max": "2025-01-01"}`)},
+		"scale max below min":      {Text: "Q", Type: questiontypes.QuestionTypeLinearScale, Config: json.RawMessage(`{"min": 5, "max": 1}`)},
+		"scale step misses max":    {Text: "Q", Type: questiontypes.QuestionTypeLinearScale, Config: json.RawMessage(`{"min": 0, "max": 10, "step": 3}`)},
+		"scale unknown setting":    {Text: "Q", Type: questiontypes.QuestionTypeLinearScale, Config: json.RawMessage(`{"points": 7}`)},
+		"matrix without rows":      {Text: "Q", Type: questiontypes.QuestionTypeMatrix, Config: json.RawMessage(`{"columns": ["No", "Yes"]}`)},
+		"matrix duplicate column":  {Text: "Q", Type: questiontypes.QuestionTypeMatrix, Config: json.RawMessage(`{"rows": ["A"], "columns": ["Yes", "Yes"]}`)},
+		"upload too large":         {Text: "Q", Type: questiontypes.QuestionTypeFileUpload, Config: json.RawMessage(`{"max_size": 104857600}`)},
+		"upload bad type":          {Text: "Q", Type: questiontypes.QuestionTypeFileUpload, Config: json.RawMessage(`{"allowed_types": ["pdf"]}`)},
+		"other label without other": {Text: "Q", Type: questiontypes.QuestionTypeDropdown, Config: json.RawMessage(`{"other_label": "Else"}`),
+			Options: []models.OptionUpdateRequest{{Text: "A"}}},
+		"ranking single option": {Text: "Q", Type: questiontypes.QuestionTypeRanking, Options: []models.OptionUpdateRequest{{Text: "A"}}},
+		"ranking top_n too large": {Text: "Q", Type: questiontypes.QuestionTypeRanking, Config: json.RawMessage(`{"top_n": 3}`),
+			Options: []models.OptionUpdateRequest{{Text: "A"}, {Text: "B"}}},
+	}
+	for name, requested := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := service.CreateSurvey(userCtx, &models.Survey{Title: "Invalid"}, []models.QuestionUpdateRequest{requested})
+			if !errors.Is(err, ErrInvalidQuestion) {
+				t.Errorf("Expected ErrInvalidQuestion, got %v", err)
+			}
+			err = service.UpdateSurveyWithQuestions(userCtx, &models.Survey{ID: surveyID, Title: "Types"}, []models.QuestionUpdateRequest{requested})
+			if !errors.Is(err, ErrInvalidQuestion) {
+				t.Errorf("Expected ErrInvalidQuestion on update, got %v", err)
+			}
+		})
+	}
+
+	t.Run("Scale config is stored with its defaults", func(t *testing.T) {
+		scales := []models.QuestionUpdateRequest{
+			{Text: "How likely are you to recommend us?", Type: questiontypes.QuestionTypeLinearScale,
+				Config: json.RawMessage(`{"min": 0, "max": 10, "min_label": "Not at all likely", "max_label": "Extremely likely"}`)},
+			{Text: "Rate us", Type: questiontypes.QuestionTypeLinearScale},
+		}
+		id, err := service.CreateSurvey(userCtx, &models.Survey{Title: "NPS"}, scales)
+		if err != nil {
+			t.Fatalf("Unexpected error creating scale questions: %v", err)
+		}
+
+		fetched, err := service.GetSurvey(userCtx, id)
+		if err != nil {
+			t.Fatalf("Unexpected error getting survey: %v", err)
+		}
+		want := []questiontypes.LinearScaleConfig{
+			{Min: 0, Max: 10, Step: 1, MinLabel: "Not at all likely", MaxLabel: "Extremely likely"},
+			{Min: questiontypes.DefaultScaleMin, Max: questiontypes.DefaultScaleMax, Step: 1},
+		}
+		for i, q := range fetched.Questions {
+			var got questiontypes.LinearScaleConfig
+			if err := json.Unmarshal(q.Config, &got); err != nil {
+				t.Fatalf("Expected question %d to have a scale config, got %s: %v", i+1, q.Config, err)
+			}
+			if got != want[i] {
+				t.Errorf("Expected config %+v for question %d, got %+v", want[i], i+1, got)
+			}
+		}
+	})
+
+	t.Run("Added question is checked against the registry", func(t *testing.T) {
+		_, err := service.AddQuestion(userCtx, &models.CreateQuestionRequest{SurveyID: surveyID, Text: "Pick", Type: questiontypes.QuestionTypeCheckbox, OrderNum: 5})
+		if !errors.Is(err, ErrInvalidQuestion) {
+			t.Errorf("Expected ErrInvalidQuestion for a checkbox without options, got %v", err)
+		}
+		_, err = service.AddQuestion(userCtx, &models.CreateQuestionRequest{
+			SurveyID: surveyID, Text: "Pick", Type: questiontypes.QuestionTypeCheckbox, OrderNum: 5,
+			Options: []models.CreateQuestionOptionRequest{{Text: "A"}, {Text: "B"}},
+		})
+		if err != nil {
+			t.Errorf("Unexpected error adding a checkbox with options: %v", err)
+		}
+	})
+}
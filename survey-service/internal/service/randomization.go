@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+
+	"github.com/VitaliySynytskyi/survey-platform/shared/questiontypes"
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrInvalidLayoutSeed is returned when a survey is requested with an unusable layout seed
+var ErrInvalidLayoutSeed = errors.New("invalid layout seed")
+
+// MaxLayoutSeedLength bounds the seeds respondents' layouts are built from
+const MaxLayoutSeedLength = 128
+
+// validateRandomization checks that shuffling questions and options never breaks the survey logic.
+// shuffledSections holds the IDs of the sections whose questions are shuffled. A question of such a section may be
+// shown before any other question of it, so it cannot depend on them through display rules or pipes, and skip rules
+// can neither leave nor enter the section part way through.
+func validateRandomization(questions []*models.Question, shuffledSections map[int]bool) error {
+	shuffledSectionOf := func(q *models.Question) (int, bool) {
+		if q.SectionID != nil && shuffledSections[*q.SectionID] {
+			return *q.SectionID, true
+		}
+		return 0, false
+	}
+	// References that do not resolve are reported by validateQuestionLogic
+	refs := newQuestionRefs(questions)
+
+	for _, q := range questions {
+		if q.RandomizeOptions {
+			if def, ok := questiontypes.LookupQuestionType(q.Type); ok && !def.HasOptions {
+				return fmt.Errorf("%w: question %d of type %q has no options to shuffle", ErrInvalidQuestion, q.OrderNum, q.Type)
+			}
+		}
+		for _, rule := range q.SkipRules {
+			if target, err := refs.lookup(q, rule.SkipToQuestionID, rule.SkipTo); err == nil {
+				if _, shuffled := shuffledSectionOf(target); shuffled {
+					return fmt.Errorf("%w: skip rule of question %d jumps to question %d, whose section is shuffled", ErrInvalidSection, q.OrderNum, target.OrderNum)
+				}
+			}
+		}
+
+		section, shuffled := shuffledSectionOf(q)
+		if !shuffled {
+			continue
+		}
+		if len(q.SkipRules) > 0 {
+			return fmt.Errorf("%w: question %d cannot have skip rules, as its section is shuffled", ErrInvalidSection, q.OrderNum)
+		}
+		references, _ := refs.pipedQuestions(q)
+		for _, rule := range q.DisplayRules {
+			if target, err := refs.lookup(q, rule.QuestionID, rule.Question); err == nil {
+				references = append(references, target)
+			}
+		}
+		for _, other := range references {
+			if other.SectionID != nil && *other.SectionID == section {
+				return fmt.Errorf("%w: question %d depends on question %d of the same shuffled section, which may be shown after it", ErrInvalidSection, q.OrderNum, other.OrderNum)
+			}
+		}
+	}
+	return nil
+}
+
+// validateRequestedRandomization validates the randomization of a full question list, see requestedQuestionModels
+func validateRequestedRandomization(sections []*models.Section, requestedQuestions []models.QuestionUpdateRequest) error {
+	shuffledSections := make(map[int]bool)
+	for i, section := range sections {
+		if section.RandomizeQuestions {
+			shuffledSections[i+1] = true
+		}
+	}
+	return validateRandomization(requestedQuestionModels(requestedQuestions), shuffledSections)
+}
+
+// checkRandomizationTx validates the randomization of a survey's questions against its stored sections
+func (s *SurveyService) checkRandomizationTx(ctx context.Context, tx pgx.Tx, surveyID int, questions []*models.Question) error {
+	sections, err := s.repo.GetSectionsBySurveyIDTx(ctx, tx, surveyID)
+	if err != nil {
+		return fmt.Errorf("failed to load sections of survey %d: %w", surveyID, err)
+	}
+	shuffledSections := make(map[int]bool)
+	for _, section := range sections {
+		if section.RandomizeQuestions {
+			shuffledSections[section.ID] = true
+		}
+	}
+	return validateRandomization(questions, shuffledSections)
+}
+
+// GetSurveyForRespondent gets a survey like GetSurvey, laid out for the respondent identified by seed.
+// Questions of shuffled sections and shuffled options are returned in the respondent's order, which the
+// survey's Layout records; the same seed always gives the same layout.
+func (s *SurveyService) GetSurveyForRespondent(ctx context.Context, id int, seed string) (*models.Survey, error) {
+	if len(seed) > MaxLayoutSeedLength {
+		return nil, fmt.Errorf("%w: the layout seed must be at most %d characters", ErrInvalidLayoutSeed, MaxLayoutSeedLength)
+	}
+	survey, err := s.GetSurvey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if seed != "" && isRandomized(survey) {
+		return applyLayout(survey, seed), nil
+	}
+	return survey, nil
+}
+
+// isRandomized reports whether any of the survey's sections or questions is shuffled
+func isRandomized(survey *models.Survey) bool {
+	for _, section := range survey.Sections {
+		if section.RandomizeQuestions {
+			return true
+		}
+	}
+	for _, q := range survey.Questions {
+		if q.RandomizeOptions {
+			return true
+		}
+	}
+	return false
+}
+
+// applyLayout returns a copy of the survey with the questions of shuffled sections shuffled among their own
+// positions, so each page keeps its questions, and the options of shuffled questions shuffled. Questions keep
+// their OrderNum, which the survey logic refers to.
+func applyLayout(stored *models.Survey, seed string) *models.Survey {
+	survey := *stored
+	survey.Questions = append([]*models.Question(nil), stored.Questions...)
+
+	shuffledSections := make(map[int]bool)
+	for _, section := range survey.Sections {
+		if section.RandomizeQuestions {
+			shuffledSections[section.ID] = true
+		}
+	}
+	positions := make(map[int][]int) // Indexes in survey.Questions of the questions of each shuffled section
+	for i, q := range survey.Questions {
+		if q.SectionID != nil && shuffledSections[*q.SectionID] {
+			positions[*q.SectionID] = append(positions[*q.SectionID], i)
+		}
+	}
+	for sectionID, indexes := range positions {
+		questions := make([]*models.Question, len(indexes))
+		for i, index := range indexes {
+			questions[i] = survey.Questions[index]
+		}
+		layoutRand(seed, survey.ID, "section", sectionID).Shuffle(len(questions), func(i, j int) {
+			questions[i], questions[j] = questions[j], questions[i]
+		})
+		for i, index := range indexes {
+			survey.Questions[index] = questions[i]
+		}
+	}
+
+	layout := &models.SurveyLayout{Seed: seed, QuestionOrder: make([]int, 0, len(survey.Questions))}
+	for i, q := range survey.Questions {
+		layout.QuestionOrder = append(layout.QuestionOrder, q.ID)
+		if !q.RandomizeOptions || len(q.Options) == 0 {
+			continue
+		}
+		options := append([]*models.QuestionOption(nil), q.Options...)
+		layoutRand(seed, survey.ID, "question", q.ID).Shuffle(len(options), func(i, j int) {
+			options[i], options[j] = options[j], options[i]
+		})
+		shuffled := *q
+		shuffled.Options = options
+		survey.Questions[i] = &shuffled
+
+		order := models.OptionOrder{QuestionID: q.ID, OptionIDs: make([]int, len(options))}
+		for i, opt := range options {
+			order.OptionIDs[i] = opt.ID
+		}
+		layout.OptionOrders = append(layout.OptionOrders, order)
+	}
+	survey.Layout = layout
+	return &survey
+}
+
+// layoutRand returns the random source of one shuffle in a respondent's layout. Each section and question gets its
+// own source, so editing one part of the survey leaves the order of the others unchanged.
+func layoutRand(seed string, surveyID int, scope string, id int) *rand.Rand {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s\x00%d\x00%s\x00%d", seed, surveyID, scope, id)
+	return rand.New(rand.NewSource(int64(h.Sum64())))
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/repository/mock"
+)
+
+func TestRandomization(t *testing.T) {
+	mockRepo := mock.NewMockRepository()
+	service := NewSurveyService(mockRepo)
+	userCtx := setupTestContext(1, []string{"user"})
+
+	fruit := []models.OptionUpdateRequest{{Text: "Apple"}, {Text: "Banana"}, {Text: "Cherry"}, {Text: "Date"}, {Text: "Elderberry"}}
+	survey := &models.Survey{
+		Title:    "Snacks",
+		IsActive: true,
+		Sections: []*models.Section{{Title: "Warm up", RandomizeQuestions: true}, {Title: "Fruit"}},
+	}
+	surveyID, err := service.CreateSurvey(userCtx, survey, []models.QuestionUpdateRequest{
+		{Text: "Sweet or salty?", Type: "text", Section: 1},
+		{Text: "Hot or cold?", Type: "text", Section: 1},
+		{Text: "Crunchy or soft?", Type: "text", Section: 1},
+		{Text: "Big or small?", Type: "text", Section: 1},
+		{Text: "Favourite fruit", Type: "single_choice", Section: 2, Options: fruit, RandomizeOptions: true},
+		{Text: "You said {{Q5}} - why?", Type: "text", Section: 2},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error creating a randomized survey: %v", err)
+	}
+	stored, err := service.GetSurvey(userCtx, surveyID)
+	if err != nil {
+		t.Fatalf("Unexpected error getting survey: %v", err)
+	}
+	if !stored.Sections[0].RandomizeQuestions || !stored.Questions[4].RandomizeOptions {
+		t.Fatalf("Expected the randomization settings to be stored")
+	}
+
+	optionTexts := func(q *models.Question) []string {
+		texts := make([]string, len(q.Options))
+		for i, opt := range q.Options {
+			texts[i] = opt.Text
+		}
+		return texts
+	}
+
+	t.Run("Layout is stable per seed", func(t *testing.T) {
+		first, err := service.GetSurveyForRespondent(userCtx, surveyID, "respondent-1")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		again, _ := service.GetSurveyForRespondent(userCtx, surveyID, "respondent-1")
+		if first.Layout == nil || !reflect.DeepEqual(first.Layout, again.Layout) {
+			t.Fatalf("Expected the same layout for the same seed, got %+v and %+v", first.Layout, again.Layout)
+		}
+
+		order := first.Layout.QuestionOrder
+		for i, q := range first.Questions {
+			if order[i] != q.ID {
+				t.Errorf("Expected question %d at position %d of the layout, got %d", q.ID, i+1, order[i])
+			}
+		}
+		for i := 4; i < 6; i++ {
+			if first.Questions[i].ID != stored.Questions[i].ID {
+				t.Errorf("Expected questions outside the shuffled section to keep their place")
+			}
+		}
+		if len(first.Layout.OptionOrders) != 1 || first.Layout.OptionOrders[0].QuestionID != stored.Questions[4].ID {
+			t.Fatalf("Expected the option order of the fruit question, got %+v", first.Layout.OptionOrders)
+		}
+		for i, opt := range first.Questions[4].Options {
+			if first.Layout.OptionOrders[0].OptionIDs[i] != opt.ID {
+				t.Errorf("Expected the options in layout order")
+			}
+		}
+	})
+
+	t.Run("Seeds give different layouts", func(t *testing.T) {
+		layouts := make(map[string]bool)
+		for _, seed := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+			laidOut, _ := service.GetSurveyForRespondent(userCtx, surveyID, seed)
+			key := strings.Join(optionTexts(laidOut.Questions[4]), ",")
+			for _, q := range laidOut.Questions[:4] {
+				key += "," + q.Text
+			}
+			layouts[key] = true
+		}
+		if len(layouts) < 2 {
+			t.Errorf("Expected different seeds to give different layouts")
+		}
+	})
+
+	t.Run("Without a seed the survey is in stored order", func(t *testing.T) {
+		plain, _ := service.GetSurveyForRespondent(userCtx, surveyID, "")
+		if plain.Layout != nil {
+			t.Errorf("Expected no layout without a seed")
+		}
+		want := []string{"Apple", "Banana", "Cherry", "Date", "Elderberry"}
+		if got := optionTexts(plain.Questions[4]); !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected the stored option order %v, got %v", want, got)
+		}
+	})
+
+	t.Run("Overlong seed", func(t *testing.T) {
+		_, err := service.GetSurveyForRespondent(userCtx, surveyID, strings.Repeat("x", MaxLayoutSeedLength+1))
+		if !errors.Is(err, ErrInvalidLayoutSeed) {
+			t.Errorf("Expected ErrInvalidLayoutSeed, got %v", err)
+		}
+	})
+
+	invalid := map[string]struct {
+		questions []models.QuestionUpdateRequest
+		want      error
+	}{
+		"shuffled options without options": {
+			questions: []models.QuestionUpdateRequest{{Text: "Name", Type: "text", Section: 2, RandomizeOptions: true}},
+			want:      ErrInvalidQuestion,
+		},
+		"skip rule in a shuffled section": {
+			questions: []models.QuestionUpdateRequest{
+				{Text: "Q1", Type: "text", Section: 1, SkipRules: []models.SkipRule{{Operator: models.LogicOperatorEquals, Value: "x", SkipTo: 3}}},
+				{Text: "Q2", Type: "text", Section: 1},
+				{Text: "Q3", Type: "text", Section: 2},
+			},
+			want: ErrInvalidSection,
+		},
+		"skip into a shuffled section": {
+			questions: []models.QuestionUpdateRequest{
+				{Text: "Q1", Type: "text", Section: 1},
+				{Text: "Q2", Type: "text", Section: 2, SkipRules: []models.SkipRule{{Operator: models.LogicOperatorEquals, Value: "x", SkipTo: 4}}},
+				{Text: "Q3", Type: "text", Section: 3},
+				{Text: "Q4", Type: "text", Section: 3},
+			},
+			want: ErrInvalidSection,
+		},
+		"display rule within a shuffled section": {
+			questions: []models.QuestionUpdateRequest{
+				{Text: "Q1", Type: "text", Section: 1},
+				{Text: "Q2", Type: "text", Section: 1, DisplayRules: []models.DisplayRule{{Question: 1, Operator: models.LogicOperatorEquals, Value: "x"}}},
+			},
+			want: ErrInvalidSection,
+		},
+		"pipe within a shuffled section": {
+			questions: []models.QuestionUpdateRequest{
+				{Text: "Q1", Type: "text", Section: 1},
+				{Text: "You said {{Q1}}", Type: "text", Section: 1},
+			},
+			want: ErrInvalidSection,
+		},
+	}
+	for name, tt := range invalid {
+		t.Run(name, func(t *testing.T) {
+			survey := &models.Survey{Title: "Invalid", Sections: []*models.Section{
+				{Title: "Shuffled", RandomizeQuestions: true}, {Title: "Plain"}, {Title: "Also shuffled", RandomizeQuestions: true},
+			}}
+			if _, err := service.CreateSurvey(userCtx, survey, tt.questions); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	t.Run("Added question is checked against the shuffled sections", func(t *testing.T) {
+		sectionID := stored.Sections[0].ID
+		_, err := service.AddQuestion(userCtx, &models.CreateQuestionRequest{
+			SurveyID: surveyID, Text: "Why {{Q1}}?", Type: "text", OrderNum: 7, SectionID: &sectionID,
+		})
+		if !errors.Is(err, ErrInvalidSection) {
+			t.Errorf("Expected ErrInvalidSection, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/repository"
+)
+
+// schedulerLockKey is the Postgres advisory lock key that keeps a single replica's scheduler running at a time
+const schedulerLockKey int64 = 727001
+
+// ResponseCounter reports how many responses a survey has received
+type ResponseCounter interface {
+	CountResponses(ctx context.Context, surveyID int) (int, error)
+}
+
+// HTTPResponseCounter counts responses through the response-service API
+type HTTPResponseCounter struct {
+	responseServiceURL string
+	serviceToken       string
+	httpClient         *http.Client
+}
+
+// NewHTTPResponseCounter creates a ResponseCounter backed by the response-service.
+// serviceToken is the token the services share, which response-service requires on its internal routes.
+func NewHTTPResponseCounter(responseServiceURL, serviceToken string) *HTTPResponseCounter {
+	return &HTTPResponseCounter{
+		responseServiceURL: responseServiceURL,
+		serviceToken:       serviceToken,
+		httpClient:         &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// CountResponses calls GET /api/v1/surveys/:id/responses/count on the response-service
+func (c *HTTPResponseCounter) CountResponses(ctx context.Context, surveyID int) (int, error) {
+	countURL := fmt.Sprintf("%s/api/v1/surveys/%d/responses/count", c.responseServiceURL, surveyID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, countURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request to response-service: %w", err)
+	}
+	req.Header.Set("X-Service-Token", c.serviceToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to call response-service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("response-service returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Count int `json:"count"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, fmt.Errorf("failed to decode response from response-service: %w", err)
+	}
+	return body.Count, nil
+}
+
+// Scheduler periodically opens and closes surveys according to their schedule and response quota.
+// Every transition is recorded in the survey status history.
+type Scheduler struct {
+	repo     repository.SurveyRepositoryInterface
+	counter  ResponseCounter
+	interval time.Duration
+}
+
+// NewScheduler creates a new Scheduler
+func NewScheduler(repo repository.SurveyRepositoryInterface, counter ResponseCounter, interval time.Duration) *Scheduler {
+	return &Scheduler{repo: repo, counter: counter, interval: interval}
+}
+
+// Run checks surveys immediately and then on every interval until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	log.Printf("[SCHEDULER_INFO] Starting survey scheduler with interval %s", s.interval)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.RunOnce(ctx); err != nil {
+			log.Printf("[SCHEDULER_ERROR] %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			log.Println("[SCHEDULER_INFO] Survey scheduler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce applies all due transitions, unless another replica holds the scheduler lock
+func (s *Scheduler) RunOnce(ctx context.Context) error {
+	acquired, err := s.repo.WithAdvisoryLock(ctx, schedulerLockKey, s.applyTransitions)
+	if err != nil {
+		return fmt.Errorf("scheduler run failed: %w", err)
+	}
+	if !acquired {
+		log.Println("[SCHEDULER_INFO] Another replica holds the scheduler lock, skipping this run")
+	}
+	return nil
+}
+
+func (s *Scheduler) applyTransitions(ctx context.Context) error {
+	now := time.Now()
+
+	// Scheduled surveys are already active, so opening them only needs to be recorded
+	dueToOpen, err := s.repo.ListSurveysDueToOpen(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list surveys due to open: %w", err)
+	}
+	for _, survey := range dueToOpen {
+		if err := s.recordTransition(ctx, survey.ID, models.SurveyStatusScheduled, models.SurveyStatusOpen, models.StatusChangeStartDate); err != nil {
+			log.Printf("[SCHEDULER_ERROR] Failed to record opening of survey %d: %v", survey.ID, err)
+		}
+	}
+
+	dueToClose, err := s.repo.ListSurveysDueToClose(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list surveys due to close: %w", err)
+	}
+	for _, survey := range dueToClose {
+		s.closeSurvey(ctx, survey, models.SurveyStatusOpen, models.StatusChangeEndDate)
+	}
+
+	if s.counter == nil {
+		return nil
+	}
+	withQuota, err := s.repo.ListActiveSurveysWithQuota(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list surveys with a response quota: %w", err)
+	}
+	for _, survey := range withQuota {
+		count, err := s.counter.CountResponses(ctx, survey.ID)
+		if err != nil {
+			log.Printf("[SCHEDULER_ERROR] Failed to count responses of survey %d: %v", survey.ID, err)
+			continue
+		}
+		if count >= *survey.MaxResponses {
+			s.closeSurvey(ctx, survey, survey.EffectiveStatus(now), models.StatusChangeQuota)
+		}
+	}
+	return nil
+}
+
+// closeSurvey deactivates a survey and records why
+func (s *Scheduler) closeSurvey(ctx context.Context, survey *models.Survey, fromStatus, reason string) {
+	if err := s.repo.UpdateSurveyStatus(ctx, survey.ID, false); err != nil {
+		log.Printf("[SCHEDULER_ERROR] Failed to close survey %d (%s): %v", survey.ID, reason, err)
+		return
+	}
+	log.Printf("[SCHEDULER_INFO] Closed survey %d (%s)", survey.ID, reason)
+	if err := s.recordTransition(ctx, survey.ID, fromStatus, models.SurveyStatusClosed, reason); err != nil {
+		log.Printf("[SCHEDULER_ERROR] Failed to record closing of survey %d: %v", survey.ID, err)
+	}
+}
+
+func (s *Scheduler) recordTransition(ctx context.Context, surveyID int, fromStatus, toStatus, reason string) error {
+	return s.repo.CreateSurveyStatusChange(ctx, &models.SurveyStatusChange{
+		SurveyID:   surveyID,
+		FromStatus: fromStatus,
+		ToStatus:   toStatus,
+		Reason:     reason,
+	})
+}
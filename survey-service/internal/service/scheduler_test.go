@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/repository/mock"
+)
+
+// stubResponseCounter returns fixed response counts per survey
+type stubResponseCounter map[int]int
+
+func (c stubResponseCounter) CountResponses(ctx context.Context, surveyID int) (int, error) {
+	return c[surveyID], nil
+}
+
+func TestSchedulerRunOnce(t *testing.T) {
+	mockRepo := mock.NewMockRepository()
+	ctx := context.Background()
+	now := time.Now()
+	quota := 10
+
+	// Created while still scheduled, its start date has since passed
+	startedID, _ := mockRepo.CreateSurvey(ctx, &models.Survey{Title: "Started", IsActive: true, StartDate: now.Add(-time.Minute)})
+	mockRepo.Surveys[startedID].CreatedAt = now.Add(-time.Hour)
+	endedID, _ := mockRepo.CreateSurvey(ctx, &models.Survey{Title: "Ended", IsActive: true, EndDate: now.Add(-time.Minute)})
+	fullID, _ := mockRepo.CreateSurvey(ctx, &models.Survey{Title: "Full", IsActive: true, MaxResponses: &quota})
+	notFullID, _ := mockRepo.CreateSurvey(ctx, &models.Survey{Title: "Not full", IsActive: true, MaxResponses: &quota})
+
+	scheduler := NewScheduler(mockRepo, stubResponseCounter{fullID: 10, notFullID: 9}, time.Minute)
+
+	// Another replica holds the lock, nothing happens
+	mockRepo.LockHeld = true
+	if err := scheduler.RunOnce(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(mockRepo.History) != 0 || !mockRepo.Surveys[endedID].IsActive {
+		t.Fatal("Expected no transitions while the lock is held elsewhere")
+	}
+
+	mockRepo.LockHeld = false
+	if err := scheduler.RunOnce(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !mockRepo.Surveys[startedID].IsActive || mockRepo.Surveys[endedID].IsActive ||
+		mockRepo.Surveys[fullID].IsActive || !mockRepo.Surveys[notFullID].IsActive {
+		t.Error("Expected only the ended and full surveys to be closed")
+	}
+
+	want := map[int]models.SurveyStatusChange{
+		startedID: {FromStatus: models.SurveyStatusScheduled, ToStatus: models.SurveyStatusOpen, Reason: models.StatusChangeStartDate},
+		endedID:   {FromStatus: models.SurveyStatusOpen, ToStatus: models.SurveyStatusClosed, Reason: models.StatusChangeEndDate},
+		fullID:    {FromStatus: models.SurveyStatusOpen, ToStatus: models.SurveyStatusClosed, Reason: models.StatusChangeQuota},
+	}
+	if len(mockRepo.History) != len(want) {
+		t.Fatalf("Expected %d recorded transitions, got %d", len(want), len(mockRepo.History))
+	}
+	for _, change := range mockRepo.History {
+		expected := want[change.SurveyID]
+		if change.FromStatus != expected.FromStatus || change.ToStatus != expected.ToStatus || change.Reason != expected.Reason {
+			t.Errorf("Unexpected transition for survey %d: %+v", change.SurveyID, change)
+		}
+		if change.ChangedBy != nil {
+			t.Errorf("Expected scheduler transitions to have no user, got %d", *change.ChangedBy)
+		}
+	}
+
+	// Running again does not record anything twice
+	if err := scheduler.RunOnce(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(mockRepo.History) != len(want) {
+		t.Errorf("Expected transitions to be recorded once, got %d", len(mockRepo.History))
+	}
+}
+
+func TestUpdateSurveyStatusRecordsHistory(t *testing.T) {
+	mockRepo := mock.NewMockRepository()
+	service := NewSurveyService(mockRepo)
+	ownerCtx := setupTestContext(1, []string{"user"})
+
+	surveyID, err := service.CreateSurvey(ownerCtx, &models.Survey{Title: "Manual", IsActive: true}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating survey: %v", err)
+	}
+
+	if err := service.UpdateSurveyStatus(ownerCtx, surveyID, false); err != nil {
+		t.Fatalf("Unexpected error deactivating survey: %v", err)
+	}
+	// Deactivating again is not a transition
+	if err := service.UpdateSurveyStatus(ownerCtx, surveyID, false); err != nil {
+		t.Fatalf("Unexpected error deactivating survey: %v", err)
+	}
+
+	history, err := service.ListSurveyStatusHistory(ownerCtx, surveyID)
+	if err != nil {
+		t.Fatalf("Unexpected error listing history: %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("Expected 1 status change, got %d", len(history))
+	}
+	change := history[0]
+	if change.FromStatus != models.SurveyStatusOpen || change.ToStatus != models.SurveyStatusClosed || change.Reason != models.StatusChangeManual {
+		t.Errorf("Unexpected status change: %+v", change)
+	}
+	if change.ChangedBy == nil || *change.ChangedBy != 1 {
+		t.Errorf("Expected change to be attributed to user 1, got %v", change.ChangedBy)
+	}
+
+	if _, err := service.ListSurveyStatusHistory(setupTestContext(2, []string{"user"}), surveyID); err == nil {
+		t.Error("Expected error listing history as a non-owner")
+	}
+}
+
+func TestHTTPResponseCounterSendsServiceToken(t *testing.T) {
+	responseService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/surveys/7/responses/count" {
+			t.Errorf("Unexpected request path %s", r.URL.Path)
+		}
+		if r.Header.Get("X-Service-Token") != "shared-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"survey_id": 7, "count": 3}`))
+	}))
+	defer responseService.Close()
+
+	count, err := NewHTTPResponseCounter(responseService.URL, "shared-secret").CountResponses(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("Unexpected error counting responses: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 responses, got %d", count)
+	}
+
+	if _, err := NewHTTPResponseCounter(responseService.URL, "wrong").CountResponses(context.Background(), 7); err == nil {
+		t.Error("Expected an error when response-service rejects the token")
+	}
+}
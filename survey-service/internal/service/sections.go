@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrInvalidSection is returned when sections, or the assignment of questions to them, are inconsistent
+var ErrInvalidSection = errors.New("invalid section")
+
+// validateRequestedSections checks that questions refer to requested sections by position and that,
+// once a survey has sections, every question is on a page and the pages follow each other in order.
+func validateRequestedSections(sections []*models.Section, requestedQuestions []models.QuestionUpdateRequest) error {
+	previous := 0
+	for i, reqQuestion := range requestedQuestions {
+		position := i + 1
+		switch {
+		case reqQuestion.Section < 0 || reqQuestion.Section > len(sections):
+			return fmt.Errorf("%w: question %d refers to section %d, which does not exist", ErrInvalidSection, position, reqQuestion.Section)
+		case len(sections) > 0 && reqQuestion.Section == 0:
+			return fmt.Errorf("%w: question %d must be assigned to a section", ErrInvalidSection, position)
+		case reqQuestion.Section < previous:
+			return fmt.Errorf("%w: question %d on section %d follows a question on section %d; questions must be grouped by section in page order", ErrInvalidSection, position, reqQuestion.Section, previous)
+		}
+		previous = reqQuestion.Section
+	}
+	return nil
+}
+
+// syncSectionsTx reconciles the survey's stored sections with the requested ones, keyed on Section.ID like questions.
+// Each requested section gets its position as OrderNum. The stored IDs are returned by position, for assigning questions.
+func (s *SurveyService) syncSectionsTx(ctx context.Context, tx pgx.Tx, surveyID int, requestedSections []*models.Section) ([]int, error) {
+	existingSections, err := s.repo.GetSectionsBySurveyIDTx(ctx, tx, surveyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load existing sections: %w", err)
+	}
+	existingByID := make(map[int]*models.Section, len(existingSections))
+	for _, section := range existingSections {
+		existingByID[section.ID] = section
+	}
+
+	sectionIDs := make([]int, len(requestedSections))
+	keptIDs := make(map[int]bool, len(requestedSections))
+	for i, section := range requestedSections {
+		section.SurveyID = surveyID
+		section.OrderNum = i + 1
+
+		if section.ID == 0 {
+			if _, err := s.repo.CreateSectionTx(ctx, tx, section); err != nil {
+				return nil, fmt.Errorf("failed to create section in transaction: %w", err)
+			}
+		} else {
+			if _, ok := existingByID[section.ID]; !ok {
+				return nil, fmt.Errorf("%w: section %d does not belong to survey %d", ErrInvalidSection, section.ID, surveyID)
+			}
+			if keptIDs[section.ID] {
+				return nil, fmt.Errorf("%w: section %d appears more than once", ErrInvalidSection, section.ID)
+			}
+			if err := s.repo.UpdateSectionTx(ctx, tx, section); err != nil {
+				return nil, fmt.Errorf("failed to update section %d in transaction: %w", section.ID, err)
+			}
+		}
+		keptIDs[section.ID] = true
+		sectionIDs[i] = section.ID
+	}
+
+	for _, section := range existingSections {
+		if keptIDs[section.ID] {
+			continue
+		}
+		if err := s.repo.DeleteSectionTx(ctx, tx, section.ID); err != nil {
+			return nil, fmt.Errorf("failed to delete removed section %d: %w", section.ID, err)
+		}
+	}
+	return sectionIDs, nil
+}
+
+// checkQuestionSectionTx ensures a single added or updated question is on one of its survey's sections, if it has any
+func (s *SurveyService) checkQuestionSectionTx(ctx context.Context, tx pgx.Tx, surveyID int, sectionID *int) error {
+	sections, err := s.repo.GetSectionsBySurveyIDTx(ctx, tx, surveyID)
+	if err != nil {
+		return fmt.Errorf("failed to load sections of survey %d: %w", surveyID, err)
+	}
+	if sectionID == nil {
+		if len(sections) > 0 {
+			return fmt.Errorf("%w: questions of survey %d must be assigned to a section", ErrInvalidSection, surveyID)
+		}
+		return nil
+	}
+	for _, section := range sections {
+		if section.ID == *sectionID {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: section %d does not belong to survey %d", ErrInvalidSection, *sectionID, surveyID)
+}
+
+// sectionIDAt maps a 1-based section position from a request to the stored section ID, nil for no section
+func sectionIDAt(sectionIDs []int, position int) *int {
+	if position < 1 || position > len(sectionIDs) {
+		return nil
+	}
+	id := sectionIDs[position-1]
+	return &id
+}
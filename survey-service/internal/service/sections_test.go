@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/repository/mock"
+)
+
+func TestSurveySections(t *testing.T) {
+	mockRepo := mock.NewMockRepository()
+	service := NewSurveyService(mockRepo)
+	userCtx := setupTestContext(1, []string{"user"})
+
+	survey := &models.Survey{
+		Title: "Long survey",
+		Sections: []*models.Section{
+			{Title: "About you", Description: "A few basics"},
+			{Title: "Your experience"},
+		},
+	}
+	surveyID, err := service.CreateSurvey(userCtx, survey, []models.QuestionUpdateRequest{
+		{Text: "Name", Type: "text", Section: 1},
+		{Text: "Age", Type: "text", Section: 1},
+		{Text: "Rating", Type: "text", Section: 2},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error creating survey with sections: %v", err)
+	}
+
+	fetched, err := service.GetSurvey(userCtx, surveyID)
+	if err != nil {
+		t.Fatalf("Unexpected error getting survey: %v", err)
+	}
+	if len(fetched.Sections) != 2 || fetched.Sections[0].Title != "About you" || fetched.Sections[1].OrderNum != 2 {
+		t.Fatalf("Expected both sections in page order, got %+v", fetched.Sections)
+	}
+	firstID, secondID := fetched.Sections[0].ID, fetched.Sections[1].ID
+	for i, want := range []int{firstID, firstID, secondID} {
+		if got := fetched.Questions[i].SectionID; got == nil || *got != want {
+			t.Errorf("Expected question %d on section %d, got %v", i+1, want, got)
+		}
+	}
+
+	t.Run("Update keeps, reorders and removes sections", func(t *testing.T) {
+		questions, _ := mockRepo.GetQuestionsBySurveyID(context.Background(), surveyID)
+		ratingID := questions[2].ID
+		update := &models.Survey{
+			ID:    surveyID,
+			Title: "Long survey",
+			Sections: []*models.Section{
+				{ID: secondID, Title: "Your experience (edited)"},
+				{Title: "Wrap up"},
+			},
+		}
+		err := service.UpdateSurveyWithQuestions(userCtx, update, []models.QuestionUpdateRequest{
+			{ID: &ratingID, Text: "Rating", Type: "text", Section: 1},
+			{Text: "Anything else?", Type: "text", Section: 2},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error updating sections: %v", err)
+		}
+
+		sections, _ := mockRepo.GetSectionsBySurveyID(context.Background(), surveyID)
+		if len(sections) != 2 || sections[0].ID != secondID || sections[0].Title != "Your experience (edited)" {
+			t.Fatalf("Expected the kept section first with its ID, got %+v", sections)
+		}
+		if _, exists := mockRepo.Sections[firstID]; exists {
+			t.Errorf("Expected section %d to be deleted", firstID)
+		}
+		questions, _ = mockRepo.GetQuestionsBySurveyID(context.Background(), surveyID)
+		if *questions[1].SectionID != sections[1].ID {
+			t.Errorf("Expected the new question on the new section %d, got %d", sections[1].ID, *questions[1].SectionID)
+		}
+	})
+
+	invalid := map[string][]models.QuestionUpdateRequest{
+		"unknown section": {
+			{Text: "Q1", Type: "text", Section: 3},
+		},
+		"question without a section": {
+			{Text: "Q1", Type: "text", Section: 1},
+			{Text: "Q2", Type: "text"},
+		},
+		"sections out of page order": {
+			{Text: "Q1", Type: "text", Section: 2},
+			{Text: "Q2", Type: "text", Section: 1},
+		},
+	}
+	for name, requested := range invalid {
+		t.Run(name, func(t *testing.T) {
+			survey := &models.Survey{Title: "Invalid", Sections: []*models.Section{{Title: "One"}, {Title: "Two"}}}
+			if _, err := service.CreateSurvey(userCtx, survey, requested); !errors.Is(err, ErrInvalidSection) {
+				t.Errorf("Expected ErrInvalidSection, got %v", err)
+			}
+		})
+	}
+
+	t.Run("Added question must be on a section of the survey", func(t *testing.T) {
+		foreignID := 999
+		_, err := service.AddQuestion(userCtx, &models.CreateQuestionRequest{SurveyID: surveyID, Text: "Lost", Type: "text", OrderNum: 3, SectionID: &foreignID})
+		if !errors.Is(err, ErrInvalidSection) {
+			t.Errorf("Expected ErrInvalidSection for a foreign section, got %v", err)
+		}
+		_, err = service.AddQuestion(userCtx, &models.CreateQuestionRequest{SurveyID: surveyID, Text: "Homeless", Type: "text", OrderNum: 3})
+		if !errors.Is(err, ErrInvalidSection) {
+			t.Errorf("Expected ErrInvalidSection for a question without a section, got %v", err)
+		}
+	})
+}
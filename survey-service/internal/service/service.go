@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
 	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/repository"
+	"github.com/jackc/pgx/v5"
 )
 
 // ContextKey is the type for context keys to avoid collisions.
@@ -18,11 +20,16 @@ const (
 	UserIDKey ContextKey = "userID"
 	// UserRolesKey is the context key for the user's roles.
 	UserRolesKey ContextKey = "userRoles"
+	// OrgIDKey is the context key for the ID of the organization the user works in, absent outside every organization.
+	OrgIDKey ContextKey = "orgID"
 )
 
 // Custom error types
 var ErrForbidden = errors.New("forbidden")
 var ErrNotFound = errors.New("not found")
+var ErrInvalidQuestion = errors.New("invalid question")
+var ErrInvalidSchedule = errors.New("invalid schedule")
+var ErrAuthenticationRequired = errors.New("authentication required")
 
 // SurveyServiceInterface defines the interface for survey operations
 type SurveyServiceInterface interface {
@@ -30,12 +37,30 @@ type SurveyServiceInterface interface {
 	CreateSurvey(ctx context.Context, survey *models.Survey, questions []models.QuestionUpdateRequest) (int, error)
 	// GetSurveys(ctx context.Context) ([]*models.Survey, error) // Removing this as it's replaced by paginated versions
 	GetSurvey(ctx context.Context, id int) (*models.Survey, error)
+	GetSurveyForRespondent(ctx context.Context, id int, seed string) (*models.Survey, error)
 	UpdateSurvey(ctx context.Context, survey *models.Survey) error
 	UpdateSurveyWithQuestions(ctx context.Context, survey *models.Survey, questions []models.QuestionUpdateRequest) error
 	DeleteSurvey(ctx context.Context, id int) error
 	UpdateSurveyStatus(ctx context.Context, id int, isActive bool) error
-	ListUserSurveys(ctx context.Context, page, limit int) ([]*models.Survey, int, error)
-	ListAllPublicSurveys(ctx context.Context, page, limit int) ([]*models.Survey, int, error)
+	ListSurveyStatusHistory(ctx context.Context, surveyID int) ([]*models.SurveyStatusChange, error)
+	ListSurveyVersions(ctx context.Context, surveyID int) ([]*models.SurveyVersion, error)
+	GetSurveyVersion(ctx context.Context, surveyID, version int) (*models.Survey, error)
+	ListUserSurveys(ctx context.Context, status string, page, limit int) ([]*models.Survey, int, error)
+	ListAllPublicSurveys(ctx context.Context, status string, page, limit int) ([]*models.Survey, int, error)
+	ExportSurveyDefinition(ctx context.Context, surveyID int) (*models.SurveyDefinition, error)
+	ImportSurveyDefinition(ctx context.Context, definition *models.SurveyDefinition) (int, error)
+	ImportExternalSurvey(ctx context.Context, format string, data []byte) (*models.ImportReport, error)
+	CloneSurvey(ctx context.Context, surveyID int, title string) (int, error)
+	TransferSurveyOwnership(ctx context.Context, surveyID int, req models.TransferSurveyRequest) (*models.SurveyOwnershipTransfer, error)
+	ListSurveyOwnershipTransfers(ctx context.Context, surveyID int) ([]*models.SurveyOwnershipTransfer, error)
+	InviteCollaborator(ctx context.Context, surveyID int, req models.InviteCollaboratorRequest) (*models.SurveyCollaborator, error)
+	ListCollaborators(ctx context.Context, surveyID int) ([]*models.SurveyCollaborator, error)
+	RemoveCollaborator(ctx context.Context, surveyID, collaboratorID int) error
+
+	// Template operations
+	SetSurveyTemplate(ctx context.Context, surveyID int, visibility string) error
+	ListTemplates(ctx context.Context, page, limit int) ([]*models.Survey, int, error)
+	InstantiateTemplate(ctx context.Context, templateID int, title string) (int, error)
 
 	// Question operations
 	AddQuestion(ctx context.Context, req *models.CreateQuestionRequest) (int, error)
@@ -93,6 +118,23 @@ func getUserAndRolesFromContext(ctx context.Context) (userID int, roles []string
 	return uid, rs, nil
 }
 
+// orgIDFromContext returns the organization the user in context works in, or nil outside every organization.
+// Users only see and manage the surveys of that organization.
+func orgIDFromContext(ctx context.Context) *int {
+	if orgID, ok := ctx.Value(OrgIDKey).(int); ok && orgID > 0 {
+		return &orgID
+	}
+	return nil
+}
+
+// sameOrg reports whether two organization IDs, nil outside every organization, are the same tenant
+func sameOrg(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
 // Helper function to check if a slice contains a string
 func containsString(slice []string, str string) bool {
 	for _, item := range slice {
@@ -103,9 +145,32 @@ func containsString(slice []string, str string) bool {
 	return false
 }
 
-// authorizeSurveyAccess checks if the user in context can perform an action on the survey.
-// It returns the survey (if fetched and authorized), a boolean indicating if the user is an admin, and an error.
-func (s *SurveyService) authorizeSurveyAccess(ctx context.Context, surveyID int) (survey *models.Survey, isUserAdmin bool, err error) {
+// surveyAccess is what a user needs to do with a survey. Owners and admins may do everything;
+// collaborators may do what their role grants, see models.AccessRole*.
+type surveyAccess int
+
+const (
+	accessView   surveyAccess = iota // Read the survey with its versions and history; every collaborator
+	accessEdit                       // Change the survey, its sections and questions; editors
+	accessManage                     // Delete, transfer or share the survey; owners only
+)
+
+// grantedTo reports whether a collaborator role allows the access
+func (a surveyAccess) grantedTo(role string) bool {
+	switch a {
+	case accessView:
+		return models.IsValidCollaboratorRole(role)
+	case accessEdit:
+		return role == models.AccessRoleEditor
+	}
+	return false
+}
+
+// authorizeSurveyAccess checks if the user in context can access the survey as needed: as its owner, as an admin or
+// as a collaborator whose role grants the access. Nobody, admins included, may access surveys of another organization.
+// It returns the survey (if fetched and authorized) with the user's AccessRole, a boolean indicating if the user is
+// an admin, and an error.
+func (s *SurveyService) authorizeSurveyAccess(ctx context.Context, surveyID int, access surveyAccess) (survey *models.Survey, isUserAdmin bool, err error) {
 	userID, roles, err := getUserAndRolesFromContext(ctx)
 	if err != nil {
 		return nil, false, fmt.Errorf("authorization context error: %w", err)
@@ -120,16 +185,30 @@ func (s *SurveyService) authorizeSurveyAccess(ctx context.Context, surveyID int)
 	if survey == nil {
 		return nil, isUserAdmin, ErrNotFound
 	}
+	if !sameOrg(survey.OrgID, orgIDFromContext(ctx)) {
+		return nil, isUserAdmin, ErrForbidden // Another tenant's survey
+	}
+
+	if survey.CreatorID == userID {
+		survey.AccessRole = models.AccessRoleOwner
+		return survey, isUserAdmin, nil // Owner has access
+	}
+
+	role, err := s.repo.GetCollaboratorRole(ctx, surveyID, userID)
+	if err != nil {
+		return nil, isUserAdmin, err
+	}
+	survey.AccessRole = role
 
 	if isUserAdmin {
 		return survey, true, nil // Admin has access
 	}
 
-	if survey.CreatorID == userID {
-		return survey, false, nil // Owner has access
+	if access.grantedTo(role) {
+		return survey, false, nil // Collaborator has access
 	}
 
-	return nil, false, ErrForbidden // Neither admin nor owner
+	return nil, false, ErrForbidden // Neither admin, owner nor a collaborator allowed to
 }
 
 // CreateSurvey creates a new survey and its questions/options if provided
@@ -139,6 +218,22 @@ func (s *SurveyService) CreateSurvey(ctx context.Context, survey *models.Survey,
 		return 0, fmt.Errorf("CreateSurvey: %w", err) // Error getting user from context
 	}
 	survey.CreatorID = userID // Set CreatorID from context
+	survey.OrgID = orgIDFromContext(ctx)
+	if err := validateSchedule(survey); err != nil {
+		return 0, err
+	}
+	if err := validateRequestedQuestionTypes(requestedQuestions); err != nil {
+		return 0, err
+	}
+	if err := validateRequestedLogic(requestedQuestions); err != nil {
+		return 0, err
+	}
+	if err := validateRequestedSections(survey.Sections, requestedQuestions); err != nil {
+		return 0, err
+	}
+	if err := validateRequestedRandomization(survey.Sections, requestedQuestions); err != nil {
+		return 0, err
+	}
 
 	tx, err := s.repo.BeginTx(ctx)
 	if err != nil {
@@ -163,14 +258,26 @@ func (s *SurveyService) CreateSurvey(ctx context.Context, survey *models.Survey,
 	}
 	survey.ID = surveyID
 
+	var sectionIDs []int
+	sectionIDs, err = s.syncSectionsTx(ctx, tx, surveyID, survey.Sections)
+	if err != nil {
+		return 0, err
+	}
+
 	if len(requestedQuestions) > 0 {
+		stored := make([]*models.Question, 0, len(requestedQuestions))
 		for i, reqQuestion := range requestedQuestions {
 			questionModel := &models.Question{
-				SurveyID: surveyID,
-				Text:     reqQuestion.Text,
-				Type:     reqQuestion.Type,
-				Required: reqQuestion.Required,
-				OrderNum: i + 1,
+				SurveyID:         surveyID,
+				Text:             reqQuestion.Text,
+				Type:             reqQuestion.Type,
+				Required:         reqQuestion.Required,
+				OrderNum:         i + 1,
+				SectionID:        sectionIDAt(sectionIDs, reqQuestion.Section),
+				Config:           reqQuestion.Config,
+				DisplayRules:     reqQuestion.DisplayRules,
+				SkipRules:        reqQuestion.SkipRules,
+				RandomizeOptions: reqQuestion.RandomizeOptions,
 			}
 
 			newQuestionID, errCreate := s.repo.CreateQuestionTx(ctx, tx, questionModel)
@@ -181,10 +288,10 @@ func (s *SurveyService) CreateSurvey(ctx context.Context, survey *models.Survey,
 			questionModel.ID = newQuestionID
 
 			if len(reqQuestion.Options) > 0 {
-				for optIdx, optText := range reqQuestion.Options {
+				for optIdx, opt := range reqQuestion.Options {
 					optionModel := &models.QuestionOption{
 						QuestionID: newQuestionID,
-						Text:       optText,
+						Text:       opt.Text,
 						OrderNum:   optIdx + 1,
 					}
 					_, errCreateOpt := s.repo.CreateQuestionOptionTx(ctx, tx, optionModel)
@@ -192,8 +299,23 @@ func (s *SurveyService) CreateSurvey(ctx context.Context, survey *models.Survey,
 						err = fmt.Errorf("failed to create option for new question ID %d: %w", newQuestionID, errCreateOpt)
 						return 0, err
 					}
+					questionModel.Options = append(questionModel.Options, optionModel)
 				}
 			}
+			stored = append(stored, questionModel)
+		}
+
+		// Rules and pipes refer to positions and option texts until every question and option has its ID
+		if err = s.saveQuestionLogicTx(ctx, tx, stored); err != nil {
+			return 0, err
+		}
+	}
+
+	// Active surveys are published immediately, so their first version exists before any response arrives
+	if survey.IsActive {
+		survey.Version, err = s.publishVersionTx(ctx, tx, survey)
+		if err != nil {
+			return 0, err
 		}
 	}
 
@@ -202,9 +324,24 @@ func (s *SurveyService) CreateSurvey(ctx context.Context, survey *models.Survey,
 
 // GetSurvey gets a survey by ID, checking for active status or ownership/admin rights
 func (s *SurveyService) GetSurvey(ctx context.Context, id int) (*models.Survey, error) {
+	// Anonymous respondents may only read active surveys that allow anonymous responses
+	if _, ok := ctx.Value(UserIDKey).(int); !ok {
+		survey, err := s.repo.GetSurvey(ctx, id)
+		if err != nil || survey == nil {
+			return nil, ErrNotFound
+		}
+		if !survey.AllowAnonymous {
+			return nil, ErrAuthenticationRequired
+		}
+		if !survey.IsActive {
+			return nil, ErrForbidden
+		}
+		return s.withDerivedFields(ctx, survey)
+	}
+
 	// Call authorizeSurveyAccess. We don't need isUserAdmin directly in this function scope
 	// as the authorization decision is handled by the error or returned survey.
-	survey, _, err := s.authorizeSurveyAccess(ctx, id)
+	survey, _, err := s.authorizeSurveyAccess(ctx, id, accessView)
 	if err != nil {
 		// If error is Forbidden, but we want to allow public access to active surveys:
 		if errors.Is(err, ErrForbidden) {
@@ -217,15 +354,49 @@ func (s *SurveyService) GetSurvey(ctx context.Context, id int) (*models.Survey,
 				return nil, ErrNotFound
 			}
 			if publicSurvey.IsActive {
-				return publicSurvey, nil // Publicly accessible active survey
+				return s.withDerivedFields(ctx, publicSurvey) // Publicly accessible active survey
 			}
 		}
 		return nil, err // Original error (ErrForbidden if not active, ErrNotFound, or other)
 	}
-	// If authorizeSurveyAccess passed, survey is not nil and user is authorized (owner or admin)
+	// If authorizeSurveyAccess passed, survey is not nil and user is authorized (owner, collaborator or admin)
+	return s.withDerivedFields(ctx, survey)
+}
+
+// withDerivedFields sets the survey's effective status and its latest published version number,
+// which respondents' answers get pinned to
+func (s *SurveyService) withDerivedFields(ctx context.Context, survey *models.Survey) (*models.Survey, error) {
+	version, err := s.repo.GetLatestSurveyVersionNumber(ctx, survey.ID)
+	if err != nil {
+		return nil, err
+	}
+	survey.Version = version
+	survey.Status = survey.EffectiveStatus(time.Now())
 	return survey, nil
 }
 
+// validateSchedule rejects surveys whose end date is not after their start date or whose response limits are invalid
+func validateSchedule(survey *models.Survey) error {
+	if !survey.StartDate.IsZero() && !survey.EndDate.IsZero() && !survey.EndDate.After(survey.StartDate) {
+		return fmt.Errorf("%w: end date must be after start date", ErrInvalidSchedule)
+	}
+	if survey.MaxResponses != nil && *survey.MaxResponses < 1 {
+		return fmt.Errorf("%w: max_responses must be at least 1", ErrInvalidSchedule)
+	}
+	if !models.IsValidAnonymousLimit(survey.AnonymousLimit) {
+		return fmt.Errorf("%w: anonymous_limit must be empty, %q or %q", ErrInvalidSchedule, models.AnonymousLimitIP, models.AnonymousLimitDevice)
+	}
+	return nil
+}
+
+// setEffectiveStatuses sets the effective status of every listed survey
+func setEffectiveStatuses(surveys []*models.Survey) {
+	now := time.Now()
+	for _, survey := range surveys {
+		survey.Status = survey.EffectiveStatus(now)
+	}
+}
+
 // GetSurveys gets surveys based on user role (all for admin, own for user)
 /* // Removing this method as it's no longer routed and GetAllSurveys in repo was changed
 func (s *SurveyService) GetSurveys(ctx context.Context) ([]*models.Survey, error) {
@@ -249,21 +420,39 @@ func (s *SurveyService) UpdateSurvey(ctx context.Context, survey *models.Survey)
 	// This method would need to fetch the existing survey to check CreatorID if survey.CreatorID isn't reliable
 	// or ensure survey.CreatorID is set correctly by the caller based on existing record.
 	// For now, assuming UpdateSurveyWithQuestions is the primary update path.
-	_, _, err := s.authorizeSurveyAccess(ctx, survey.ID)
+	_, _, err := s.authorizeSurveyAccess(ctx, survey.ID, accessEdit)
 	if err != nil {
 		return err
 	}
+	if err := validateSchedule(survey); err != nil {
+		return err
+	}
 	return s.repo.UpdateSurvey(ctx, survey)
 }
 
 // UpdateSurveyWithQuestions updates a survey and its questions/options
 func (s *SurveyService) UpdateSurveyWithQuestions(ctx context.Context, surveyToUpdate *models.Survey, requestedQuestions []models.QuestionUpdateRequest) error {
-	existingSurvey, _, err := s.authorizeSurveyAccess(ctx, surveyToUpdate.ID)
+	existingSurvey, _, err := s.authorizeSurveyAccess(ctx, surveyToUpdate.ID, accessEdit)
 	if err != nil {
 		return err // Handles ErrForbidden, ErrNotFound, or other errors
 	}
-	// User is authorized (owner or admin)
+	// User is authorized (owner, editor or admin)
 	surveyToUpdate.CreatorID = existingSurvey.CreatorID // Ensure CreatorID is not changed from original
+	if err := validateSchedule(surveyToUpdate); err != nil {
+		return err
+	}
+	if err := validateRequestedQuestionTypes(requestedQuestions); err != nil {
+		return err
+	}
+	if err := validateRequestedLogic(requestedQuestions); err != nil {
+		return err
+	}
+	if err := validateRequestedSections(surveyToUpdate.Sections, requestedQuestions); err != nil {
+		return err
+	}
+	if err := validateRequestedRandomization(surveyToUpdate.Sections, requestedQuestions); err != nil {
+		return err
+	}
 
 	tx, err := s.repo.BeginTx(ctx)
 	if err != nil {
@@ -287,60 +476,187 @@ func (s *SurveyService) UpdateSurveyWithQuestions(ctx context.Context, surveyToU
 		return fmt.Errorf("failed to update survey entry in transaction: %w", err)
 	}
 
-	// 2. Process questions: diff between existing and requested to Add, Update, or Delete questions
-	// This logic is complex: fetch existing questions, compare with reqQuestions, then act.
-	// For simplicity in this step, the example below assumes full replacement or a more sophisticated repo method.
-	// A robust implementation would involve: s.repo.GetQuestionsBySurveyIDTx(...), then diffing.
-	// Then s.repo.DeleteQuestionTx, s.repo.UpdateQuestionTx, s.repo.CreateQuestionTx.
-	// For now, let's assume a placeholder for this complex diff logic or a simpler approach:
-	// Example: Delete all existing questions and recreate from request (simplistic, but shows transaction use)
-	err = s.repo.DeleteQuestionsBySurveyIDTx(ctx, tx, surveyToUpdate.ID)
+	// 2. Reconcile sections, then questions, against the request so that unchanged ones keep their IDs
+	sectionIDs, err := s.syncSectionsTx(ctx, tx, surveyToUpdate.ID, surveyToUpdate.Sections)
 	if err != nil {
-		return fmt.Errorf("failed to delete existing questions: %w", err)
+		return err
+	}
+	err = s.syncQuestionsTx(ctx, tx, surveyToUpdate.ID, sectionIDs, requestedQuestions)
+	if err != nil {
+		return err
 	}
 
-	if len(requestedQuestions) > 0 {
-		for i, reqQuestion := range requestedQuestions {
-			questionModel := &models.Question{
-				SurveyID: surveyToUpdate.ID,
-				Text:     reqQuestion.Text,
-				Type:     reqQuestion.Type,
-				Required: reqQuestion.Required,
-				OrderNum: i + 1, // Or reqQuestion.OrderNum
-			}
-			// If reqQuestion.ID is present and non-zero, it could imply an update to an existing question if not deleting all.
-			// In our simplistic delete-all approach, all are new.
+	// 3. Snapshot the edited survey as a new version if it is published
+	if surveyToUpdate.IsActive {
+		surveyToUpdate.Version, err = s.publishVersionTx(ctx, tx, surveyToUpdate)
+	}
+	return err // err will be handled by defer for commit/rollback
+}
+
+// syncQuestionsTx diffs the survey's stored questions against the requested list, keyed on QuestionUpdateRequest.ID.
+// Matched questions are updated in place, new ones are inserted and questions missing from the request are deleted,
+// so answers stored by response-service keep pointing at valid question IDs.
+// sectionIDs holds the stored IDs of the requested sections by position.
+func (s *SurveyService) syncQuestionsTx(ctx context.Context, tx pgx.Tx, surveyID int, sectionIDs []int, requestedQuestions []models.QuestionUpdateRequest) error {
+	existingQuestions, err := s.repo.GetQuestionsBySurveyIDTx(ctx, tx, surveyID)
+	if err != nil {
+		return fmt.Errorf("failed to load existing questions: %w", err)
+	}
+	existingByID := make(map[int]*models.Question, len(existingQuestions))
+	for _, q := range existingQuestions {
+		existingByID[q.ID] = q
+	}
+
+	keptIDs := make(map[int]bool, len(requestedQuestions))
+	stored := make([]*models.Question, 0, len(requestedQuestions))
+	for i, reqQuestion := range requestedQuestions {
+		questionModel := &models.Question{
+			SurveyID:         surveyID,
+			Text:             reqQuestion.Text,
+			Type:             reqQuestion.Type,
+			Required:         reqQuestion.Required,
+			OrderNum:         i + 1,
+			SectionID:        sectionIDAt(sectionIDs, reqQuestion.Section),
+			Config:           reqQuestion.Config,
+			DisplayRules:     reqQuestion.DisplayRules,
+			SkipRules:        reqQuestion.SkipRules,
+			RandomizeOptions: reqQuestion.RandomizeOptions,
+		}
+
+		isNew := reqQuestion.ID == nil || *reqQuestion.ID == 0
+		if isNew {
 			newQuestionID, errCreate := s.repo.CreateQuestionTx(ctx, tx, questionModel)
 			if errCreate != nil {
-				err = fmt.Errorf("failed to create question in transaction: %w", errCreate)
-				return err
+				return fmt.Errorf("failed to create question in transaction: %w", errCreate)
 			}
 			questionModel.ID = newQuestionID
+		} else {
+			questionID := *reqQuestion.ID
+			if _, ok := existingByID[questionID]; !ok {
+				return fmt.Errorf("%w: question %d does not belong to survey %d", ErrInvalidQuestion, questionID, surveyID)
+			}
+			if keptIDs[questionID] {
+				return fmt.Errorf("%w: question %d appears more than once", ErrInvalidQuestion, questionID)
+			}
+			keptIDs[questionID] = true
 
-			// Create options for the question if any are provided
-			if len(reqQuestion.Options) > 0 {
-				for optIdx, optText := range reqQuestion.Options {
-					optionModel := &models.QuestionOption{
-						QuestionID: newQuestionID,
-						Text:       optText,
-						OrderNum:   optIdx + 1,
-					}
-					_, errCreateOpt := s.repo.CreateQuestionOptionTx(ctx, tx, optionModel)
-					if errCreateOpt != nil {
-						err = fmt.Errorf("failed to create option for question ID %d: %w", newQuestionID, errCreateOpt)
-						return err
-					}
-				}
+			questionModel.ID = questionID
+			if errUpdate := s.repo.UpdateQuestionTx(ctx, tx, questionModel); errUpdate != nil {
+				return fmt.Errorf("failed to update question %d in transaction: %w", questionID, errUpdate)
 			}
 		}
+
+		options, errOpts := s.syncOptionsTx(ctx, tx, questionModel.ID, isNew, reqQuestion.Options)
+		if errOpts != nil {
+			return errOpts
+		}
+		questionModel.Options = options
+		stored = append(stored, questionModel)
 	}
 
-	return err // err will be handled by defer for commit/rollback
+	for _, q := range existingQuestions {
+		if keptIDs[q.ID] {
+			continue
+		}
+		if errDelete := s.repo.DeleteQuestionTx(ctx, tx, q.ID); errDelete != nil {
+			return fmt.Errorf("failed to delete removed question %d: %w", q.ID, errDelete)
+		}
+	}
+
+	// New questions and options only have IDs now, so rules and pipes can be resolved to them
+	return s.saveQuestionLogicTx(ctx, tx, stored)
+}
+
+// syncOptionsTx reconciles a question's options with the requested list and returns the stored options in order.
+// Options are matched by ID when given, otherwise by identical text, so renaming or reordering keeps option IDs stable.
+func (s *SurveyService) syncOptionsTx(ctx context.Context, tx pgx.Tx, questionID int, isNewQuestion bool, requestedOptions []models.OptionUpdateRequest) ([]*models.QuestionOption, error) {
+	var existingOptions []*models.QuestionOption
+	if !isNewQuestion {
+		var err error
+		existingOptions, err = s.repo.GetQuestionOptionsByQuestionIDTx(ctx, tx, questionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load options for question %d: %w", questionID, err)
+		}
+	}
+	matched, err := matchOptions(questionID, existingOptions, requestedOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make(map[int]bool, len(matched))
+	for _, id := range matched {
+		claimed[id] = true
+	}
+	for _, opt := range existingOptions {
+		if claimed[opt.ID] {
+			continue
+		}
+		if err := s.repo.DeleteQuestionOptionTx(ctx, tx, opt.ID); err != nil {
+			return nil, fmt.Errorf("failed to delete removed option %d: %w", opt.ID, err)
+		}
+	}
+
+	stored := make([]*models.QuestionOption, 0, len(requestedOptions))
+	for i, reqOpt := range requestedOptions {
+		optionModel := &models.QuestionOption{
+			ID:         matched[i],
+			QuestionID: questionID,
+			Text:       reqOpt.Text,
+			OrderNum:   i + 1,
+		}
+		stored = append(stored, optionModel)
+		if optionModel.ID != 0 {
+			if err := s.repo.UpdateQuestionOptionTx(ctx, tx, optionModel); err != nil {
+				return nil, fmt.Errorf("failed to update option %d for question %d: %w", optionModel.ID, questionID, err)
+			}
+			continue
+		}
+		if optionModel.ID, err = s.repo.CreateQuestionOptionTx(ctx, tx, optionModel); err != nil {
+			return nil, fmt.Errorf("failed to create option for question ID %d: %w", questionID, err)
+		}
+	}
+
+	return stored, nil
+}
+
+// matchOptions returns the IDs of the existing options the requested ones keep, 0 for options to create.
+// Options referenced by ID are claimed first, then the rest are matched by text among the unclaimed ones.
+func matchOptions(questionID int, existingOptions []*models.QuestionOption, requestedOptions []models.OptionUpdateRequest) ([]int, error) {
+	existingByID := make(map[int]*models.QuestionOption, len(existingOptions))
+	for _, opt := range existingOptions {
+		existingByID[opt.ID] = opt
+	}
+
+	matched := make([]int, len(requestedOptions))
+	claimed := make(map[int]bool, len(existingOptions))
+	for i, reqOpt := range requestedOptions {
+		if reqOpt.ID == nil || *reqOpt.ID == 0 {
+			continue
+		}
+		if _, ok := existingByID[*reqOpt.ID]; !ok || claimed[*reqOpt.ID] {
+			return nil, fmt.Errorf("%w: option %d does not belong to question %d", ErrInvalidQuestion, *reqOpt.ID, questionID)
+		}
+		claimed[*reqOpt.ID] = true
+		matched[i] = *reqOpt.ID
+	}
+	for i, reqOpt := range requestedOptions {
+		if matched[i] != 0 {
+			continue
+		}
+		for _, opt := range existingOptions {
+			if !claimed[opt.ID] && opt.Text == reqOpt.Text {
+				claimed[opt.ID] = true
+				matched[i] = opt.ID
+				break
+			}
+		}
+	}
+	return matched, nil
 }
 
 // DeleteSurvey deletes a survey
 func (s *SurveyService) DeleteSurvey(ctx context.Context, id int) error {
-	_, _, err := s.authorizeSurveyAccess(ctx, id)
+	_, _, err := s.authorizeSurveyAccess(ctx, id, accessManage)
 	if err != nil {
 		return err
 	}
@@ -352,18 +668,29 @@ func (s *SurveyService) DeleteSurvey(ctx context.Context, id int) error {
 // AddQuestion adds a question to an existing survey
 func (s *SurveyService) AddQuestion(ctx context.Context, req *models.CreateQuestionRequest) (int, error) {
 	// Authorize access to the survey first
-	_, _, err := s.authorizeSurveyAccess(ctx, req.SurveyID)
+	survey, _, err := s.authorizeSurveyAccess(ctx, req.SurveyID, accessEdit)
 	if err != nil {
 		return 0, fmt.Errorf("AddQuestion: not authorized for survey %d: %w", req.SurveyID, err)
 	}
-	// User is authorized (owner or admin) to modify this survey
+	// User is authorized (owner, editor or admin) to modify this survey
 
 	question := &models.Question{
-		SurveyID: req.SurveyID,
-		Text:     req.Text,
-		Type:     req.Type,
-		Required: req.Required,
-		OrderNum: req.OrderNum,
+		SurveyID:         req.SurveyID,
+		Text:             req.Text,
+		Type:             req.Type,
+		Required:         req.Required,
+		OrderNum:         req.OrderNum,
+		SectionID:        req.SectionID,
+		Config:           req.Config,
+		DisplayRules:     req.DisplayRules,
+		SkipRules:        req.SkipRules,
+		RandomizeOptions: req.RandomizeOptions,
+	}
+	if question.Config, err = validateQuestionType(req.OrderNum, req.Type, req.Config, len(req.Options)); err != nil {
+		return 0, err
+	}
+	for _, optReq := range req.Options {
+		question.Options = append(question.Options, &models.QuestionOption{Text: optReq.Text}) // Rules may name them by text
 	}
 
 	// Transaction for creating question and its options
@@ -383,34 +710,93 @@ func (s *SurveyService) AddQuestion(ctx context.Context, req *models.CreateQuest
 		}
 	}()
 
+	existingQuestions, err := s.repo.GetQuestionsBySurveyIDTx(ctx, tx, req.SurveyID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load existing questions: %w", err)
+	}
+	if err = validateQuestionLogic(replaceQuestion(existingQuestions, question)); err != nil {
+		return 0, err
+	}
+	if err = s.checkRandomizationTx(ctx, tx, req.SurveyID, replaceQuestion(existingQuestions, question)); err != nil {
+		return 0, err
+	}
+	if err = s.checkQuestionSectionTx(ctx, tx, req.SurveyID, question.SectionID); err != nil {
+		return 0, err
+	}
+
 	questionID, err = s.repo.CreateQuestionTx(ctx, tx, question)
 	if err != nil {
 		return 0, fmt.Errorf("failed to create question: %w", err)
 	}
 
-	for i, optReq := range req.Options {
-		option := &models.QuestionOption{
-			QuestionID: questionID,
-			Text:       optReq.Text,
-			OrderNum:   i + 1, // Or optReq.OrderNum
-		}
+	for i, option := range question.Options {
+		option.QuestionID = questionID
+		option.OrderNum = i + 1 // Or req.Options[i].OrderNum
 		_, err = s.repo.CreateQuestionOptionTx(ctx, tx, option)
 		if err != nil {
 			return 0, fmt.Errorf("failed to create question option: %w", err)
 		}
 	}
+	if err = s.saveQuestionLogicTx(ctx, tx, replaceQuestion(existingQuestions, question)); err != nil {
+		return 0, err
+	}
+
+	if survey.IsActive {
+		_, err = s.publishVersionTx(ctx, tx, survey)
+		if err != nil {
+			return 0, err
+		}
+	}
 
 	return questionID, err
 }
 
 // UpdateSurveyStatus updates a survey's active status
 func (s *SurveyService) UpdateSurveyStatus(ctx context.Context, id int, isActive bool) error {
-	_, _, err := s.authorizeSurveyAccess(ctx, id)
+	survey, _, err := s.authorizeSurveyAccess(ctx, id, accessEdit)
 	if err != nil {
 		return err
 	}
-	// User is authorized (owner or admin)
-	return s.repo.UpdateSurveyStatus(ctx, id, isActive) // Repo method needs to exist
+	// User is authorized (owner, editor or admin)
+	now := time.Now()
+	fromStatus := survey.EffectiveStatus(now)
+	if err := s.repo.UpdateSurveyStatus(ctx, id, isActive); err != nil {
+		return err
+	}
+
+	survey.IsActive = isActive
+	if toStatus := survey.EffectiveStatus(now); toStatus != fromStatus {
+		change := &models.SurveyStatusChange{
+			SurveyID:   id,
+			FromStatus: fromStatus,
+			ToStatus:   toStatus,
+			Reason:     models.StatusChangeManual,
+		}
+		if userID, _, ctxErr := getUserAndRolesFromContext(ctx); ctxErr == nil {
+			change.ChangedBy = &userID
+		}
+		if err := s.repo.CreateSurveyStatusChange(ctx, change); err != nil {
+			return err
+		}
+	}
+
+	if isActive {
+		// Publishing snapshots the survey unless it is unchanged since its latest version
+		return s.publishVersion(ctx, survey)
+	}
+	return nil
+}
+
+// ListSurveyStatusHistory lists when and why a survey changed status (owner, collaborator or admin)
+func (s *SurveyService) ListSurveyStatusHistory(ctx context.Context, surveyID int) ([]*models.SurveyStatusChange, error) {
+	if _, _, err := s.authorizeSurveyAccess(ctx, surveyID, accessView); err != nil {
+		return nil, err
+	}
+	history, err := s.repo.ListSurveyStatusHistory(ctx, surveyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list status history of survey %d: %w", surveyID, err)
+	}
+	return history, nil
 }
 
 // UpdateQuestion updates a question and its options
@@ -419,11 +805,14 @@ func (s *SurveyService) UpdateQuestion(ctx context.Context, question *models.Que
 	if question == nil {
 		return errors.New("question data cannot be nil")
 	}
-	_, _, err := s.authorizeSurveyAccess(ctx, question.SurveyID)
+	survey, _, err := s.authorizeSurveyAccess(ctx, question.SurveyID, accessEdit)
 	if err != nil {
 		return fmt.Errorf("UpdateQuestion: not authorized for survey %d: %w", question.SurveyID, err)
 	}
-	// User is authorized (owner or admin) to modify this survey's questions
+	// User is authorized (owner, editor or admin) to modify this survey's questions
+	if question.Config, err = validateQuestionType(question.OrderNum, question.Type, question.Config, len(options)); err != nil {
+		return err
+	}
 
 	// Transaction logic would be similar to AddQuestion or UpdateSurveyWithQuestions
 	tx, err := s.repo.BeginTx(ctx)
@@ -441,32 +830,63 @@ func (s *SurveyService) UpdateQuestion(ctx context.Context, question *models.Que
 		}
 	}()
 
+	existingQuestions, err := s.repo.GetQuestionsBySurveyIDTx(ctx, tx, question.SurveyID)
+	if err != nil {
+		return fmt.Errorf("failed to load existing questions: %w", err)
+	}
+	// Options are matched to the stored ones by text, so rules naming them by ID stay valid
+	requestedOptions := make([]models.OptionUpdateRequest, 0, len(options))
+	for _, opt := range options {
+		requestedOptions = append(requestedOptions, models.OptionUpdateRequest{Text: opt.Text})
+	}
+	var storedOptions []*models.QuestionOption
+	for _, q := range existingQuestions {
+		if q.ID == question.ID {
+			storedOptions = q.Options
+		}
+	}
+	matched, err := matchOptions(question.ID, storedOptions, requestedOptions)
+	if err != nil {
+		return err
+	}
+	question.Options = make([]*models.QuestionOption, 0, len(options))
+	for i, opt := range requestedOptions {
+		question.Options = append(question.Options, &models.QuestionOption{ID: matched[i], QuestionID: question.ID, Text: opt.Text})
+	}
+	if err = validateQuestionLogic(replaceQuestion(existingQuestions, question)); err != nil {
+		return err
+	}
+	if err = s.checkRandomizationTx(ctx, tx, question.SurveyID, replaceQuestion(existingQuestions, question)); err != nil {
+		return err
+	}
+	if err = s.checkQuestionSectionTx(ctx, tx, question.SurveyID, question.SectionID); err != nil {
+		return err
+	}
+
 	err = s.repo.UpdateQuestionTx(ctx, tx, question)
 	if err != nil {
 		return fmt.Errorf("failed to update question: %w", err)
 	}
 
-	// Simplistic: delete existing options and recreate
-	err = s.repo.DeleteQuestionOptionsTx(ctx, tx, question.ID)
+	question.Options, err = s.syncOptionsTx(ctx, tx, question.ID, false, requestedOptions)
 	if err != nil {
-		return fmt.Errorf("failed to delete existing options: %w", err)
+		return err
+	}
+	if err = s.saveQuestionLogicTx(ctx, tx, replaceQuestion(existingQuestions, question)); err != nil {
+		return err
 	}
 
-	for _, opt := range options {
-		opt.QuestionID = question.ID
-		_, err = s.repo.CreateQuestionOptionTx(ctx, tx, opt)
-		if err != nil {
-			return fmt.Errorf("failed to create option: %w", err)
-		}
+	if survey.IsActive {
+		_, err = s.publishVersionTx(ctx, tx, survey)
 	}
 	return err
 }
 
 // DeleteQuestion deletes a question
 // This also needs authorization at the survey level
-func (s *SurveyService) DeleteQuestion(ctx context.Context, id int) error {
+func (s *SurveyService) DeleteQuestion(ctx context.Context, id int) (err error) {
 	// Need to get question first to find its surveyID for authorization
-	question, err := s.repo.GetQuestionByID(ctx, id) // Assuming GetQuestionByID exists
+	question, err := s.repo.GetQuestionByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to get question %d: %w", id, err)
 	}
@@ -474,16 +894,54 @@ func (s *SurveyService) DeleteQuestion(ctx context.Context, id int) error {
 		return ErrNotFound
 	}
 
-	_, _, err = s.authorizeSurveyAccess(ctx, question.SurveyID)
+	survey, _, err := s.authorizeSurveyAccess(ctx, question.SurveyID, accessEdit)
 	if err != nil {
 		return fmt.Errorf("DeleteQuestion: not authorized for survey %d: %w", question.SurveyID, err)
 	}
-	// User is authorized (owner or admin)
-	return s.repo.DeleteQuestion(ctx, id)
+	// User is authorized (owner, editor or admin)
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+			panic(p)
+		} else if err != nil {
+			_ = tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	// Other questions' display rules, skip rules and pipes must not be left pointing at the deleted question
+	existingQuestions, err := s.repo.GetQuestionsBySurveyIDTx(ctx, tx, question.SurveyID)
+	if err != nil {
+		return fmt.Errorf("failed to load existing questions: %w", err)
+	}
+	remaining := make([]*models.Question, 0, len(existingQuestions))
+	for _, q := range existingQuestions {
+		if q.ID != id {
+			remaining = append(remaining, q)
+		}
+	}
+	if err = validateQuestionLogic(remaining); err != nil {
+		return fmt.Errorf("cannot delete question %d, remove the logic that depends on it first: %w", question.OrderNum, err)
+	}
+
+	if err = s.repo.DeleteQuestionTx(ctx, tx, id); err != nil {
+		return err
+	}
+	if survey.IsActive {
+		_, err = s.publishVersionTx(ctx, tx, survey)
+	}
+	return err
 }
 
-// ListUserSurveys retrieves surveys created by the current user with pagination.
-func (s *SurveyService) ListUserSurveys(ctx context.Context, page, limit int) ([]*models.Survey, int, error) {
+// ListUserSurveys retrieves surveys of the user's organization that they created or collaborate on with pagination.
+// A non-empty status only lists surveys in that effective status.
+func (s *SurveyService) ListUserSurveys(ctx context.Context, status string, page, limit int) ([]*models.Survey, int, error) {
 	userID, _, err := getUserAndRolesFromContext(ctx)
 	if err != nil {
 		return nil, 0, fmt.Errorf("ListUserSurveys: %w", err)
@@ -499,15 +957,17 @@ func (s *SurveyService) ListUserSurveys(ctx context.Context, page, limit int) ([
 
 	offset := (page - 1) * limit
 
-	surveys, total, err := s.repo.ListSurveysByCreatorID(ctx, userID, offset, limit)
+	surveys, total, err := s.repo.ListSurveysForUser(ctx, orgIDFromContext(ctx), userID, status, offset, limit)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list user surveys: %w", err)
 	}
+	setEffectiveStatuses(surveys)
 	return surveys, total, nil
 }
 
-// ListAllPublicSurveys retrieves all surveys (e.g., active ones for non-admins, all for admins) with pagination.
-func (s *SurveyService) ListAllPublicSurveys(ctx context.Context, page, limit int) ([]*models.Survey, int, error) {
+// ListAllPublicSurveys retrieves all surveys of the user's organization (e.g., active ones for non-admins, all for admins)
+// with pagination. A non-empty status only lists surveys in that effective status.
+func (s *SurveyService) ListAllPublicSurveys(ctx context.Context, status string, page, limit int) ([]*models.Survey, int, error) {
 	userID, roles, err := getUserAndRolesFromContext(ctx)
 	if err != nil {
 		// Depending on policy, this endpoint might still work for non-authenticated if some surveys are truly public.
@@ -526,9 +986,10 @@ func (s *SurveyService) ListAllPublicSurveys(ctx context.Context, page, limit in
 
 	isUserAdmin := containsString(roles, "admin")
 
-	surveys, total, err := s.repo.ListAllSurveys(ctx, isUserAdmin, offset, limit)
+	surveys, total, err := s.repo.ListAllSurveys(ctx, orgIDFromContext(ctx), isUserAdmin, status, offset, limit)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list all surveys: %w", err)
 	}
+	setEffectiveStatuses(surveys)
 	return surveys, total, nil
 }
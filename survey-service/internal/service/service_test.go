@@ -48,7 +48,7 @@ func TestCreateSurvey(t *testing.T) {
 			Type:     "single_choice",
 			Required: false,
 			OrderNum: 2,
-			Options:  []string{"Option 1", "Option 2"},
+			Options:  []models.OptionUpdateRequest{{Text: "Option 1"}, {Text: "Option 2"}},
 		},
 	}
 
@@ -213,6 +213,38 @@ func TestGetSurvey(t *testing.T) {
 	}
 }
 
+func TestGetSurveyAnonymously(t *testing.T) {
+	mockRepo := mock.NewMockRepository()
+	service := NewSurveyService(mockRepo)
+	ctx := context.Background() // No user in context, as for requests without a token
+
+	loginOnlyID, _ := mockRepo.CreateSurvey(ctx, &models.Survey{CreatorID: 1, Title: "Members only", IsActive: true})
+	publicSurvey := &models.Survey{CreatorID: 1, Title: "Public", IsActive: true, AllowAnonymous: true}
+	publicID, _ := mockRepo.CreateSurvey(ctx, publicSurvey)
+
+	if _, err := service.GetSurvey(ctx, loginOnlyID); !errors.Is(err, ErrAuthenticationRequired) {
+		t.Errorf("Expected ErrAuthenticationRequired for a survey without anonymous access, got %v", err)
+	}
+
+	fetched, err := service.GetSurvey(ctx, publicID)
+	if err != nil {
+		t.Fatalf("Unexpected error getting anonymous survey: %v", err)
+	}
+	if fetched.Status != models.SurveyStatusOpen {
+		t.Errorf("Expected status %q, got %q", models.SurveyStatusOpen, fetched.Status)
+	}
+
+	publicSurvey.IsActive = false
+	mockRepo.UpdateSurvey(ctx, publicSurvey)
+	if _, err := service.GetSurvey(ctx, publicID); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden for an inactive anonymous survey, got %v", err)
+	}
+
+	if _, err := service.GetSurvey(ctx, 9999); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing survey, got %v", err)
+	}
+}
+
 func TestUpdateSurveyWithQuestions(t *testing.T) {
 	mockRepo := mock.NewMockRepository()
 	service := NewSurveyService(mockRepo)
@@ -271,7 +303,7 @@ func TestUpdateSurveyWithQuestions(t *testing.T) {
 			Type:     "single_choice",
 			Required: true,
 			OrderNum: 2,
-			Options:  []string{"New Option 1", "New Option 2"},
+			Options:  []models.OptionUpdateRequest{{Text: "New Option 1"}, {Text: "New Option 2"}},
 		},
 	}
 
@@ -308,6 +340,74 @@ func TestUpdateSurveyWithQuestions(t *testing.T) {
 	}
 }
 
+func TestUpdateSurveyWithQuestionsKeepsIDs(t *testing.T) {
+	mockRepo := mock.NewMockRepository()
+	service := NewSurveyService(mockRepo)
+	userCtx := setupTestContext(1, []string{"user"})
+
+	surveyID, err := service.CreateSurvey(userCtx, &models.Survey{Title: "Diff Survey", IsActive: true}, []models.QuestionUpdateRequest{
+		{Text: "Keep me", Type: "text"},
+		{Text: "Pick one", Type: "multiple_choice", Options: []models.OptionUpdateRequest{{Text: "A"}, {Text: "B"}, {Text: "C"}}},
+		{Text: "Remove me", Type: "text"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error creating survey: %v", err)
+	}
+
+	original, _ := mockRepo.GetQuestionsBySurveyID(context.Background(), surveyID)
+	keptID, choiceID, removedID := original[0].ID, original[1].ID, original[2].ID
+	originalOptions, _ := mockRepo.GetQuestionOptionsByQuestionID(context.Background(), choiceID)
+	optionA, optionB := originalOptions[0].ID, originalOptions[1].ID
+
+	updated := []models.QuestionUpdateRequest{
+		{ID: &choiceID, Text: "Pick one (edited)", Type: "multiple_choice", Options: []models.OptionUpdateRequest{
+			{Text: "B"},                       // matched by text
+			{ID: &optionA, Text: "A renamed"}, // matched by ID
+			{Text: "D"},                       // new option; "C" is removed
+		}},
+		{ID: &keptID, Text: "Keep me", Type: "text"},
+		{Text: "Brand new", Type: "text"},
+	}
+	if err := service.UpdateSurveyWithQuestions(userCtx, &models.Survey{ID: surveyID, Title: "Diff Survey", IsActive: true}, updated); err != nil {
+		t.Fatalf("Unexpected error updating survey: %v", err)
+	}
+
+	questions, _ := mockRepo.GetQuestionsBySurveyID(context.Background(), surveyID)
+	if len(questions) != 3 {
+		t.Fatalf("Expected 3 questions, got %d", len(questions))
+	}
+	if questions[0].ID != choiceID || questions[0].Text != "Pick one (edited)" {
+		t.Errorf("Expected question %d to be updated in place, got ID %d text %q", choiceID, questions[0].ID, questions[0].Text)
+	}
+	if questions[1].ID != keptID {
+		t.Errorf("Expected question %d to keep its ID, got %d", keptID, questions[1].ID)
+	}
+	for _, q := range questions {
+		if q.ID == removedID {
+			t.Errorf("Expected question %d to be deleted", removedID)
+		}
+	}
+
+	options, _ := mockRepo.GetQuestionOptionsByQuestionID(context.Background(), choiceID)
+	if len(options) != 3 {
+		t.Fatalf("Expected 3 options, got %d", len(options))
+	}
+	if options[0].ID != optionB || options[1].ID != optionA || options[1].Text != "A renamed" {
+		t.Errorf("Expected options to be reconciled by identity, got %+v %+v", options[0], options[1])
+	}
+	if options[2].Text != "D" || options[2].ID == originalOptions[2].ID {
+		t.Errorf("Expected a new option D, got %+v", options[2])
+	}
+
+	foreignID := 999
+	err = service.UpdateSurveyWithQuestions(userCtx, &models.Survey{ID: surveyID, Title: "Diff Survey"}, []models.QuestionUpdateRequest{
+		{ID: &foreignID, Text: "Not mine", Type: "text"},
+	})
+	if !errors.Is(err, ErrInvalidQuestion) {
+		t.Errorf("Expected ErrInvalidQuestion for a foreign question ID, got %v", err)
+	}
+}
+
 func TestDeleteSurvey(t *testing.T) {
 	mockRepo := mock.NewMockRepository()
 	service := NewSurveyService(mockRepo)
@@ -461,7 +561,7 @@ func TestListUserSurveys(t *testing.T) {
 	userCtx := setupTestContext(1, []string{"user"})
 
 	// List surveys for user 1
-	surveys, total, err := service.ListUserSurveys(userCtx, 0, 10)
+	surveys, total, err := service.ListUserSurveys(userCtx, "", 0, 10)
 	if err != nil {
 		t.Fatalf("Unexpected error listing surveys: %v", err)
 	}
@@ -475,7 +575,7 @@ func TestListUserSurveys(t *testing.T) {
 	}
 
 	// Test pagination
-	surveys, total, err = service.ListUserSurveys(userCtx, 0, 2)
+	surveys, total, err = service.ListUserSurveys(userCtx, "", 0, 2)
 	if err != nil {
 		t.Fatalf("Unexpected error listing surveys with pagination: %v", err)
 	}
@@ -490,7 +590,7 @@ func TestListUserSurveys(t *testing.T) {
 
 	// Test error handling
 	mockRepo.ErrorMock = errors.New("test error")
-	_, _, err = service.ListUserSurveys(userCtx, 0, 10)
+	_, _, err = service.ListUserSurveys(userCtx, "", 0, 10)
 	if err == nil {
 		t.Error("Expected error but got nil")
 	}
@@ -527,7 +627,7 @@ func TestListAllPublicSurveys(t *testing.T) {
 	userCtx := setupTestContext(2, []string{"user"}) // Different user
 
 	// List public surveys
-	surveys, total, err := service.ListAllPublicSurveys(userCtx, 0, 10)
+	surveys, total, err := service.ListAllPublicSurveys(userCtx, "", 0, 10)
 	if err != nil {
 		t.Fatalf("Unexpected error listing public surveys: %v", err)
 	}
@@ -544,7 +644,7 @@ func TestListAllPublicSurveys(t *testing.T) {
 
 	// Admin should see all surveys
 	adminCtx := setupTestContext(3, []string{"admin"})
-	adminSurveys, adminTotal, err := service.ListAllPublicSurveys(adminCtx, 0, 10)
+	adminSurveys, adminTotal, err := service.ListAllPublicSurveys(adminCtx, "", 0, 10)
 	if err != nil {
 		t.Fatalf("Unexpected error listing surveys for admin: %v", err)
 	}
@@ -561,8 +661,58 @@ func TestListAllPublicSurveys(t *testing.T) {
 
 	// Test error handling
 	mockRepo.ErrorMock = errors.New("test error")
-	_, _, err = service.ListAllPublicSurveys(userCtx, 0, 10)
+	_, _, err = service.ListAllPublicSurveys(userCtx, "", 0, 10)
 	if err == nil {
 		t.Error("Expected error but got nil")
 	}
 }
+
+func TestSurveyScheduling(t *testing.T) {
+	mockRepo := mock.NewMockRepository()
+	service := NewSurveyService(mockRepo)
+	ownerCtx := setupTestContext(1, []string{"user"})
+	now := time.Now()
+
+	scheduledID, err := service.CreateSurvey(ownerCtx, &models.Survey{Title: "Next week", IsActive: true, StartDate: now.Add(7 * 24 * time.Hour)}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating scheduled survey: %v", err)
+	}
+	if _, err := service.CreateSurvey(ownerCtx, &models.Survey{Title: "Running", IsActive: true, EndDate: now.Add(time.Hour)}, nil); err != nil {
+		t.Fatalf("Unexpected error creating open survey: %v", err)
+	}
+	if _, err := service.CreateSurvey(ownerCtx, &models.Survey{Title: "Finished", IsActive: true, EndDate: now.Add(-time.Hour)}, nil); err != nil {
+		t.Fatalf("Unexpected error creating closed survey: %v", err)
+	}
+
+	survey, err := service.GetSurvey(ownerCtx, scheduledID)
+	if err != nil {
+		t.Fatalf("Unexpected error getting survey: %v", err)
+	}
+	if survey.Status != models.SurveyStatusScheduled {
+		t.Errorf("Expected status %q, got %q", models.SurveyStatusScheduled, survey.Status)
+	}
+
+	for status, wantTitle := range map[string]string{
+		models.SurveyStatusScheduled: "Next week",
+		models.SurveyStatusOpen:      "Running",
+		models.SurveyStatusClosed:    "Finished",
+	} {
+		surveys, total, err := service.ListUserSurveys(ownerCtx, status, 1, 10)
+		if err != nil {
+			t.Fatalf("Unexpected error listing %s surveys: %v", status, err)
+		}
+		if total != 1 || len(surveys) != 1 || surveys[0].Title != wantTitle {
+			t.Errorf("Expected only %q when filtering by %s, got %d surveys", wantTitle, status, total)
+			continue
+		}
+		if surveys[0].Status != status {
+			t.Errorf("Expected listed survey to have status %q, got %q", status, surveys[0].Status)
+		}
+	}
+
+	// The end date must come after the start date
+	_, err = service.CreateSurvey(ownerCtx, &models.Survey{Title: "Backwards", StartDate: now, EndDate: now.Add(-time.Hour)}, nil)
+	if !errors.Is(err, ErrInvalidSchedule) {
+		t.Errorf("Expected ErrInvalidSchedule, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
+)
+
+// ErrInvalidTemplate is returned for an unknown template visibility
+var ErrInvalidTemplate = errors.New("invalid template")
+
+// SetSurveyTemplate marks a survey as a template with the given visibility, or unmarks it for an empty visibility.
+// Only the survey's owner or an admin may change it, and only admins may publish global templates.
+func (s *SurveyService) SetSurveyTemplate(ctx context.Context, surveyID int, visibility string) error {
+	_, isUserAdmin, err := s.authorizeSurveyAccess(ctx, surveyID, accessManage)
+	if err != nil {
+		return err
+	}
+	if visibility != "" && !models.IsValidTemplateVisibility(visibility) {
+		return fmt.Errorf("%w: visibility must be %q, %q or %q", ErrInvalidTemplate,
+			models.TemplateVisibilityPrivate, models.TemplateVisibilityOrg, models.TemplateVisibilityGlobal)
+	}
+	if visibility == models.TemplateVisibilityGlobal && !isUserAdmin {
+		return fmt.Errorf("%w: only admins may publish global templates", ErrForbidden)
+	}
+	return s.repo.SetSurveyTemplateVisibility(ctx, surveyID, visibility)
+}
+
+// ListTemplates lists the templates the current user may start surveys from, with pagination: global ones, and those
+// of their organization that are shared with it or that they created. Admins get every template of their organization.
+func (s *SurveyService) ListTemplates(ctx context.Context, page, limit int) ([]*models.Survey, int, error) {
+	userID, roles, err := getUserAndRolesFromContext(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ListTemplates: %w", err)
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	templates, total, err := s.repo.ListTemplates(ctx, orgIDFromContext(ctx), userID, containsString(roles, "admin"), offset, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list templates: %w", err)
+	}
+	return templates, total, nil
+}
+
+// InstantiateTemplate starts a new survey owned by the current user, in their organization, from a template, copying
+// its settings, sections, questions and options in one transaction. The new survey is inactive until its owner opens
+// it, has no schedule and is not a template itself. An empty title keeps the template's.
+func (s *SurveyService) InstantiateTemplate(ctx context.Context, templateID int, title string) (surveyID int, err error) {
+	userID, roles, err := getUserAndRolesFromContext(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("InstantiateTemplate: %w", err)
+	}
+
+	template, err := s.repo.GetSurvey(ctx, templateID)
+	if err != nil || template == nil || template.TemplateVisibility == "" {
+		return 0, ErrNotFound
+	}
+	// Only global templates are shared across organizations
+	if template.TemplateVisibility != models.TemplateVisibilityGlobal && !sameOrg(template.OrgID, orgIDFromContext(ctx)) {
+		return 0, ErrNotFound
+	}
+	// Private templates are hidden from everyone but their creator and admins
+	if template.TemplateVisibility == models.TemplateVisibilityPrivate && template.CreatorID != userID && !containsString(roles, "admin") {
+		return 0, ErrNotFound
+	}
+
+	if title == "" {
+		title = template.Title
+	}
+	return s.copySurvey(ctx, template, userID, title)
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/repository/mock"
+)
+
+func TestSurveyTemplates(t *testing.T) {
+	mockRepo := mock.NewMockRepository()
+	service := NewSurveyService(mockRepo)
+	ownerCtx := setupTestContext(1, []string{"user"})
+	otherCtx := setupTestContext(2, []string{"user"})
+	adminCtx := setupTestContext(3, []string{"admin"})
+
+	maxResponses := 100
+	template := &models.Survey{
+		Title:          "NPS",
+		Description:    "How likely are you to recommend us?",
+		IsActive:       true,
+		MaxResponses:   &maxResponses,
+		AllowAnonymous: true,
+		Sections:       []*models.Section{{Title: "Score"}, {Title: "Why", RandomizeQuestions: true}},
+	}
+	templateID, err := service.CreateSurvey(ownerCtx, template, []models.QuestionUpdateRequest{
+		{Text: "Score", Type: "single_choice", Section: 1, Required: true, RandomizeOptions: true,
+			Options: []models.OptionUpdateRequest{{Text: "Promoter"}, {Text: "Detractor"}}},
+		{Text: "What could we do better?", Type: "text", Section: 2,
+			DisplayRules: []models.DisplayRule{{Question: 1, Operator: models.LogicOperatorEquals, Value: "Detractor"}}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error creating survey: %v", err)
+	}
+
+	if _, err := service.InstantiateTemplate(otherCtx, templateID, ""); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound for a survey that is not a template, got %v", err)
+	}
+	if err := service.SetSurveyTemplate(otherCtx, templateID, models.TemplateVisibilityOrg); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden when marking another user's survey, got %v", err)
+	}
+	if err := service.SetSurveyTemplate(ownerCtx, templateID, "public"); !errors.Is(err, ErrInvalidTemplate) {
+		t.Errorf("Expected ErrInvalidTemplate for an unknown visibility, got %v", err)
+	}
+	if err := service.SetSurveyTemplate(ownerCtx, templateID, models.TemplateVisibilityGlobal); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden when a user publishes a global template, got %v", err)
+	}
+
+	t.Run("Private templates are only visible to their creator", func(t *testing.T) {
+		if err := service.SetSurveyTemplate(ownerCtx, templateID, models.TemplateVisibilityPrivate); err != nil {
+			t.Fatalf("Unexpected error marking template: %v", err)
+		}
+		if templates, total, _ := service.ListTemplates(ownerCtx, 1, 10); total != 1 || templates[0].ID != templateID {
+			t.Errorf("Expected the owner to see their template, got %d", total)
+		}
+		if _, total, _ := service.ListTemplates(otherCtx, 1, 10); total != 0 {
+			t.Errorf("Expected other users not to see a private template, got %d", total)
+		}
+		if _, total, _ := service.ListTemplates(adminCtx, 1, 10); total != 1 {
+			t.Errorf("Expected admins to see every template, got %d", total)
+		}
+		if _, err := service.InstantiateTemplate(otherCtx, templateID, ""); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for another user's private template, got %v", err)
+		}
+	})
+
+	t.Run("Instantiating deep-copies the template", func(t *testing.T) {
+		if err := service.SetSurveyTemplate(ownerCtx, templateID, models.TemplateVisibilityOrg); err != nil {
+			t.Fatalf("Unexpected error sharing template: %v", err)
+		}
+		surveyID, err := service.InstantiateTemplate(otherCtx, templateID, "Q3 NPS")
+		if err != nil {
+			t.Fatalf("Unexpected error instantiating template: %v", err)
+		}
+		if surveyID == templateID {
+			t.Fatal("Expected a new survey")
+		}
+
+		copied, err := service.GetSurvey(otherCtx, surveyID)
+		if err != nil {
+			t.Fatalf("Unexpected error getting the new survey: %v", err)
+		}
+		if copied.CreatorID != 2 || copied.Title != "Q3 NPS" || copied.Description != template.Description {
+			t.Errorf("Expected a survey owned by the caller with the requested title, got %+v", copied)
+		}
+		if copied.IsActive || copied.TemplateVisibility != "" || !copied.AllowAnonymous || copied.MaxResponses == nil || *copied.MaxResponses != 100 {
+			t.Errorf("Expected an inactive non-template survey with the template's settings, got %+v", copied)
+		}
+		if len(copied.Sections) != 2 || copied.Sections[0].ID == template.Sections[0].ID || !copied.Sections[1].RandomizeQuestions {
+			t.Fatalf("Expected copied sections, got %+v", copied.Sections)
+		}
+		if len(copied.Questions) != 2 {
+			t.Fatalf("Expected 2 copied questions, got %d", len(copied.Questions))
+		}
+		score, why := copied.Questions[0], copied.Questions[1]
+		if score.SectionID == nil || *score.SectionID != copied.Sections[0].ID || why.SectionID == nil || *why.SectionID != copied.Sections[1].ID {
+			t.Errorf("Expected questions on the copied sections, got %v and %v", score.SectionID, why.SectionID)
+		}
+		if !score.Required || !score.RandomizeOptions || len(score.Options) != 2 || score.Options[1].Text != "Detractor" {
+			t.Errorf("Expected the question and its options to be copied, got %+v", score)
+		}
+		if score.Options[0].ID == template.Questions[0].Options[0].ID {
+			t.Error("Expected the options to be new rows")
+		}
+		if len(why.DisplayRules) != 1 || why.DisplayRules[0].QuestionID != score.ID || why.DisplayRules[0].OptionID != score.Options[1].ID {
+			t.Errorf("Expected display rules to be copied onto the copied question and option, got %+v", why.DisplayRules)
+		}
+
+		original, _ := mockRepo.GetSurvey(ownerCtx, templateID)
+		if len(original.Questions) != 2 || original.Questions[0].SurveyID != templateID {
+			t.Errorf("Expected the template to be unchanged, got %+v", original.Questions)
+		}
+	})
+
+	t.Run("Unmarked surveys leave the library", func(t *testing.T) {
+		if err := service.SetSurveyTemplate(ownerCtx, templateID, ""); err != nil {
+			t.Fatalf("Unexpected error unmarking template: %v", err)
+		}
+		if _, total, _ := service.ListTemplates(ownerCtx, 1, 10); total != 0 {
+			t.Errorf("Expected no templates, got %d", total)
+		}
+	})
+}
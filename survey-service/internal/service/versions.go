@@ -0,0 +1,147 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// publishVersionTx snapshots the survey with its current sections, questions and options as a new numbered version.
+// If nothing changed since the latest version, no new version is created and the latest number is returned.
+func (s *SurveyService) publishVersionTx(ctx context.Context, tx pgx.Tx, survey *models.Survey) (int, error) {
+	sections, err := s.repo.GetSectionsBySurveyIDTx(ctx, tx, survey.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load sections for survey %d snapshot: %w", survey.ID, err)
+	}
+	questions, err := s.repo.GetQuestionsBySurveyIDTx(ctx, tx, survey.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load questions for survey %d snapshot: %w", survey.ID, err)
+	}
+	snapshot := newSurveySnapshot(survey, sections, questions)
+
+	latest, err := s.repo.GetLatestSurveyVersionTx(ctx, tx, survey.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load latest version of survey %d: %w", survey.ID, err)
+	}
+	if latest != nil && sameSnapshot(latest.Snapshot, snapshot) {
+		return latest.Version, nil
+	}
+
+	version := &models.SurveyVersion{
+		SurveyID: survey.ID,
+		Snapshot: snapshot,
+	}
+	if userID, _, ctxErr := getUserAndRolesFromContext(ctx); ctxErr == nil {
+		version.CreatedBy = &userID
+	}
+
+	number, err := s.repo.CreateSurveyVersionTx(ctx, tx, version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create version for survey %d: %w", survey.ID, err)
+	}
+	return number, nil
+}
+
+// publishVersion runs publishVersionTx in its own transaction, for callers that do not already hold one
+func (s *SurveyService) publishVersion(ctx context.Context, survey *models.Survey) (err error) {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+			panic(p)
+		} else if err != nil {
+			_ = tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	_, err = s.publishVersionTx(ctx, tx, survey)
+	return err
+}
+
+// newSurveySnapshot copies the survey content that gives answers their meaning.
+// Timestamps and status are left out so that unchanged content produces an identical snapshot.
+func newSurveySnapshot(survey *models.Survey, sections []*models.Section, questions []*models.Question) *models.Survey {
+	snapshot := &models.Survey{
+		ID:          survey.ID,
+		CreatorID:   survey.CreatorID,
+		Title:       survey.Title,
+		Description: survey.Description,
+		StartDate:   survey.StartDate.UTC(),
+		EndDate:     survey.EndDate.UTC(),
+		Questions:   make([]*models.Question, 0, len(questions)),
+	}
+	for _, sec := range sections {
+		section := *sec
+		section.CreatedAt, section.UpdatedAt = time.Time{}, time.Time{}
+		snapshot.Sections = append(snapshot.Sections, &section)
+	}
+	for _, q := range questions {
+		question := *q
+		question.CreatedAt, question.UpdatedAt = time.Time{}, time.Time{}
+		question.Options = make([]*models.QuestionOption, 0, len(q.Options))
+		for _, opt := range q.Options {
+			option := *opt
+			option.CreatedAt, option.UpdatedAt = time.Time{}, time.Time{}
+			question.Options = append(question.Options, &option)
+		}
+		snapshot.Questions = append(snapshot.Questions, &question)
+	}
+	return snapshot
+}
+
+// sameSnapshot compares two snapshots by their JSON encoding
+func sameSnapshot(a, b *models.Survey) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return bytes.Equal(encodedA, encodedB)
+}
+
+// ListSurveyVersions lists the published versions of a survey (owner, collaborator or admin)
+func (s *SurveyService) ListSurveyVersions(ctx context.Context, surveyID int) ([]*models.SurveyVersion, error) {
+	if _, _, err := s.authorizeSurveyAccess(ctx, surveyID, accessView); err != nil {
+		return nil, err
+	}
+	versions, err := s.repo.ListSurveyVersions(ctx, surveyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions of survey %d: %w", surveyID, err)
+	}
+	return versions, nil
+}
+
+// GetSurveyVersion returns the survey as it was published in the given version.
+// Access follows GetSurvey: owners, collaborators and admins always, everyone else while the survey is active.
+func (s *SurveyService) GetSurveyVersion(ctx context.Context, surveyID, version int) (*models.Survey, error) {
+	current, err := s.GetSurvey(ctx, surveyID)
+	if err != nil {
+		return nil, err
+	}
+
+	surveyVersion, err := s.repo.GetSurveyVersion(ctx, surveyID, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get version %d of survey %d: %w", version, surveyID, err)
+	}
+
+	snapshot := *surveyVersion.Snapshot
+	snapshot.Version = surveyVersion.Version
+	snapshot.IsActive = current.IsActive
+	snapshot.Status = current.Status
+	snapshot.CreatedAt = surveyVersion.CreatedAt
+	snapshot.UpdatedAt = surveyVersion.CreatedAt
+	return &snapshot, nil
+}
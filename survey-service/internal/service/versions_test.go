@@ -0,0 +1,94 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/models"
+	"github.com/VitaliySynytskyi/survey-platform/survey-service/internal/repository/mock"
+)
+
+func TestSurveyVersions(t *testing.T) {
+	mockRepo := mock.NewMockRepository()
+	service := NewSurveyService(mockRepo)
+	ownerCtx := setupTestContext(1, []string{"user"})
+
+	questions := []models.QuestionUpdateRequest{
+		{Text: "How are you?", Type: "multiple_choice", Options: []models.OptionUpdateRequest{{Text: "Fine"}, {Text: "Bad"}}},
+	}
+	surveyID, err := service.CreateSurvey(ownerCtx, &models.Survey{Title: "Versioned", IsActive: true}, questions)
+	if err != nil {
+		t.Fatalf("Unexpected error creating survey: %v", err)
+	}
+
+	survey, err := service.GetSurvey(ownerCtx, surveyID)
+	if err != nil {
+		t.Fatalf("Unexpected error getting survey: %v", err)
+	}
+	if survey.Version != 1 {
+		t.Fatalf("Expected active survey to be published as version 1, got %d", survey.Version)
+	}
+	questionID := survey.Questions[0].ID
+
+	// Saving identical content must not create a new version
+	unchanged := []models.QuestionUpdateRequest{
+		{ID: &questionID, Text: "How are you?", Type: "multiple_choice", Options: []models.OptionUpdateRequest{{Text: "Fine"}, {Text: "Bad"}}},
+	}
+	if err := service.UpdateSurveyWithQuestions(ownerCtx, &models.Survey{ID: surveyID, Title: "Versioned", IsActive: true}, unchanged); err != nil {
+		t.Fatalf("Unexpected error updating survey: %v", err)
+	}
+	if versions, _ := service.ListSurveyVersions(ownerCtx, surveyID); len(versions) != 1 {
+		t.Fatalf("Expected 1 version after a no-op update, got %d", len(versions))
+	}
+
+	edited := []models.QuestionUpdateRequest{
+		{ID: &questionID, Text: "How are you today?", Type: "multiple_choice", Options: []models.OptionUpdateRequest{{Text: "Fine"}, {Text: "Terrible"}}},
+	}
+	if err := service.UpdateSurveyWithQuestions(ownerCtx, &models.Survey{ID: surveyID, Title: "Versioned", IsActive: true}, edited); err != nil {
+		t.Fatalf("Unexpected error updating survey: %v", err)
+	}
+
+	versions, err := service.ListSurveyVersions(ownerCtx, surveyID)
+	if err != nil {
+		t.Fatalf("Unexpected error listing versions: %v", err)
+	}
+	if len(versions) != 2 || versions[0].Version != 2 {
+		t.Fatalf("Expected versions [2 1], got %d versions", len(versions))
+	}
+
+	// Version 1 still describes the survey as it was first answered
+	first, err := service.GetSurveyVersion(ownerCtx, surveyID, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error getting version 1: %v", err)
+	}
+	if first.Version != 1 || first.Questions[0].Text != "How are you?" || first.Questions[0].Options[1].Text != "Bad" {
+		t.Errorf("Expected version 1 snapshot to be unchanged, got %+v", first.Questions[0])
+	}
+	if first.Questions[0].ID != questionID {
+		t.Errorf("Expected snapshot to keep question ID %d, got %d", questionID, first.Questions[0].ID)
+	}
+
+	// Respondents may read versions of an active survey, but not list its history
+	respondentCtx := setupTestContext(2, []string{"user"})
+	if _, err := service.GetSurveyVersion(respondentCtx, surveyID, 2); err != nil {
+		t.Errorf("Expected respondent to read a version of an active survey, got %v", err)
+	}
+	if _, err := service.ListSurveyVersions(respondentCtx, surveyID); err == nil {
+		t.Error("Expected error listing versions as a non-owner")
+	}
+
+	if _, err := service.GetSurveyVersion(ownerCtx, surveyID, 3); err == nil {
+		t.Error("Expected error for a version that does not exist")
+	}
+
+	// Inactive surveys are not published
+	draftID, _ := service.CreateSurvey(ownerCtx, &models.Survey{Title: "Draft"}, questions)
+	if draft, _ := service.GetSurvey(ownerCtx, draftID); draft.Version != 0 {
+		t.Errorf("Expected inactive survey to have no version, got %d", draft.Version)
+	}
+	if err := service.UpdateSurveyStatus(ownerCtx, draftID, true); err != nil {
+		t.Fatalf("Unexpected error activating survey: %v", err)
+	}
+	if draft, _ := service.GetSurvey(ownerCtx, draftID); draft.Version != 1 {
+		t.Errorf("Expected activation to publish version 1, got %d", draft.Version)
+	}
+}